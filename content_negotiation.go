@@ -0,0 +1,107 @@
+package chain
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// NegotiatedType the media type of the response negotiated with the Accept header of the request, see WithProduces.
+// Empty if the route does not declare the produced types.
+func (ctx *Context) NegotiatedType() string {
+	return ctx.root().negotiated
+}
+
+// NegotiateContentType selects the best of the offered media types (in order of preference) for the Accept header,
+// considering the quality values (q) and the specificity of the ranges ("text/html" > "text/*" > "*/*"). Returns the
+// first offer when the header is empty and "" when none of the offers is acceptable.
+//
+// ## Example
+//
+//	NegotiateContentType("text/*;q=0.5, application/json", []string{"text/csv", "application/json"})
+//	// application/json
+func NegotiateContentType(accept string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		quality, specificity := 0.0, -1
+		for _, r := range ranges {
+			if s := r.match(offer); s > specificity {
+				quality, specificity = r.quality, s
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
+
+// acceptRange media range of the Accept header
+type acceptRange struct {
+	mediaType string // lower case, without params
+	quality   float64
+}
+
+// match checks if the media type matches the range, returning the specificity of the range (2 = exact, 1 = "type/*",
+// 0 = "*/*") or -1 if it does not match
+func (r acceptRange) match(mediaType string) int {
+	mediaType = strings.ToLower(filterFlags(mediaType))
+	switch {
+	case r.mediaType == mediaType:
+		return 2
+	case r.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(mediaType, r.mediaType[:len(r.mediaType)-1]):
+		return 1
+	}
+	return -1
+}
+
+// parseAccept gets the media ranges of the Accept header
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, value := range strings.Split(header, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(value), ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if mediaType == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if q, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = parsed
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType, quality})
+	}
+	return ranges
+}
+
+// matchMediaType checks if the media type matches one of the patterns, see WithConsumes
+func matchMediaType(patterns []string, mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if (acceptRange{mediaType: strings.ToLower(pattern)}).match(mediaType) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBody checks if the request has a body (Content-Length or chunked)
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0
+}
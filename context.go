@@ -3,6 +3,7 @@ package chain
 import (
 	"context"
 	"net/http"
+	"net/url"
 )
 
 type chainContextKey struct{}
@@ -38,10 +39,20 @@ type Context struct {
 	parent            *Context
 	index             int
 	children          []*Context
+	aborted           bool
+	implicitStatus    int        // see WithImplicitStatus
+	negotiated        string     // see WithProduces
+	shareData         bool       // see WithParams
+	query             url.Values // see QueryValues
+	queryRaw          string
 }
 
 // Set define um valor compartilhado no contexto de execução da requisição
 func (ctx *Context) Set(key any, value any) {
+	if ctx.shareData && ctx.parent != nil {
+		ctx.parent.Set(key, value)
+		return
+	}
 	if ctx.data == nil {
 		ctx.data = make(map[any]any)
 	}
@@ -115,25 +126,35 @@ func (ctx *Context) Child() *Context {
 
 // }
 
+// WithParams returns a child Context with the given params merged into the params of this Context: params with the
+// same name have their value replaced, new params are appended (up to 32 params). The params of this Context are not
+// modified.
+//
+// Unlike Child, values defined with Set in the returned Context are shared with this Context, so it can be handed to
+// middlewares and handlers (ex. Mount, path rewrites) without losing the data they define.
+//
+// ## Example
+//
+//	child := ctx.WithParams([]string{"id", "format"}, []string{"10", "json"})
+//	child.GetParam("id") // "10"
 func (ctx *Context) WithParams(names []string, values []string) *Context {
 	child := ctx.Child()
-	child.paramCount = len(names)
-	child.paramNames = [32]string{}
-	child.paramValues = [32]string{}
-
-	for i, name := range ctx.paramNames {
-		child.paramNames[i] = name
-		child.paramValues[i] = ctx.paramValues[i]
-	}
-
-	for i := 0; i < len(names); i++ {
-		child.paramNames[i] = names[i]
-		child.paramValues[i] = values[i]
+	child.shareData = true
+	for i := 0; i < len(names) && i < len(values); i++ {
+		child.SetParam(names[i], values[i])
 	}
-
 	return child
 }
 
+// WithParam same as WithParams for a single param. Can be chained.
+//
+// ## Example
+//
+//	child := ctx.WithParam("id", "10").WithParam("format", "json")
+func (ctx *Context) WithParam(name string, value string) *Context {
+	return ctx.WithParams([]string{name}, []string{value})
+}
+
 // NewUID get a new KSUID.
 //
 // KSUID is for K-Sortable Unique IDentifier. It is a kind of globally unique identifier similar to a RFC 4122 UUID,
@@ -149,6 +170,39 @@ func (ctx *Context) Router() *Router {
 	return ctx.router
 }
 
+// Abort prevents the remaining middlewares and the route handler from being executed. Middlewares that have already
+// been executed (that called next()) are not affected, so they can still act on the response.
+//
+// ## Example
+//
+//	router.Use(func(ctx *chain.Context) {
+//		if ctx.Request.Header.Get("Authorization") == "" {
+//			ctx.AbortWithStatus(http.StatusUnauthorized)
+//		}
+//	})
+func (ctx *Context) Abort() {
+	ctx.root().aborted = true
+}
+
+// AbortWithStatus calls Abort and writes the status code
+func (ctx *Context) AbortWithStatus(status int) {
+	ctx.Abort()
+	ctx.WriteHeader(status)
+}
+
+// IsAborted checks if the current request was aborted (see Abort)
+func (ctx *Context) IsAborted() bool {
+	return ctx.root().aborted
+}
+
+// root the context created by the router for the request, children (see WithParams) share its state
+func (ctx *Context) root() *Context {
+	for ctx.parent != nil {
+		ctx = ctx.parent
+	}
+	return ctx
+}
+
 // BeforeSend Registers a callback to be invoked before the response is sent.
 //
 // Callbacks are invoked in the reverse order they are defined (callbacks defined first are invoked last).
@@ -159,17 +213,47 @@ func (ctx *Context) BeforeSend(callback func()) error {
 	return nil
 }
 
+// AfterSend Registers a callback to be invoked after the response is sent, also when the handler panics.
+//
+// Callbacks are invoked in the reverse order they are defined (callbacks defined first are invoked last).
 func (ctx *Context) AfterSend(callback func()) error {
+	return ctx.AfterSendInfo(func(info ResponseInfo) { callback() })
+}
+
+// AfterSendInfo Registers a callback to be invoked after the response is sent, also when the handler panics. The
+// callback receives the summary of the response (status, bytes, duration and error), allowing audit logs and metrics
+// without wrapping the writer.
+//
+// Callbacks are invoked in the reverse order they are defined, together with the AfterSend callbacks.
+//
+// ## Example
+//
+//	router.Use(func(ctx *chain.Context) {
+//		ctx.AfterSendInfo(func(info chain.ResponseInfo) {
+//			metrics.Observe(ctx.Route.Path(), info.Status, info.Bytes, info.Duration)
+//		})
+//	})
+func (ctx *Context) AfterSendInfo(callback func(info ResponseInfo)) error {
 	if spy, is := ctx.Writer.(*ResponseWriterSpy); is {
 		return spy.afterWrite(callback)
 	}
 	return nil
 }
 
+// write writes the implicit status (see Router.ImplicitStatus) when the handler returns without writing a response
 func (ctx *Context) write() {
 	if spy, is := ctx.Writer.(*ResponseWriterSpy); is {
 		if !spy.writeStarted {
-			ctx.WriteHeader(http.StatusOK)
+			status := ctx.implicitStatus
+			if status == 0 && ctx.router != nil {
+				status = ctx.router.ImplicitStatus
+			}
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status != NoImplicitStatus {
+				ctx.WriteHeader(status)
+			}
 		}
 	}
 }
@@ -22,7 +22,7 @@ var (
 	BindingFormMultipart Binding = formMultipartBinding{}  // form
 	BindingQuery         Binding = queryBinding{}          // query
 	BindingHeader        Binding = headerBinding{}         // header
-	BindingDefault       Binding = &BindingDefaultStruct{} // query, json, xml, form
+	BindingDefault       Binding = &BindingDefaultStruct{} // query, json, xml, form and the registered bindings (see RegisterBinding)
 )
 
 type BindingDefaultStruct struct {
@@ -42,15 +42,21 @@ func (s *BindingDefaultStruct) Bind(ctx *Context, obj any) error {
 	}
 
 	if ctx.Request.Method != http.MethodGet {
-		switch ctx.GetContentType() {
-		case "application/json":
-			bb = append(bb, BindingJSON)
-		case "application/xml", "text/xml":
-			bb = append(bb, BindingXML)
-		case "multipart/form-data":
-			bb = append(bb, BindingFormMultipart)
-		default: // case "application/x-www-form-urlencoded":
-			bb = append(bb, BindingForm)
+		contentType := ctx.GetContentType()
+		if binding := getBinding(contentType); binding != nil {
+			// see RegisterBinding
+			bb = append(bb, binding)
+		} else {
+			switch contentType {
+			case "application/json":
+				bb = append(bb, BindingJSON)
+			case "application/xml", "text/xml":
+				bb = append(bb, BindingXML)
+			case "multipart/form-data":
+				bb = append(bb, BindingFormMultipart)
+			default: // case "application/x-www-form-urlencoded":
+				bb = append(bb, BindingForm)
+			}
 		}
 	}
 
@@ -5,6 +5,6 @@ package chain
 type queryBinding struct{}
 
 func (queryBinding) Bind(ctx *Context, obj any) error {
-	values := ctx.Request.URL.Query()
+	values := ctx.QueryValues()
 	return mapFormByTag(obj, values, "query")
 }
@@ -0,0 +1,149 @@
+package chain
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// SetTrustedProxies defines the proxies (IPs or CIDR ranges) trusted to inform the client IP with the
+// "X-Forwarded-For" and "X-Real-Ip" headers, see Context.ClientIP. Without trusted proxies the headers are ignored.
+//
+// ## Example
+//
+//	if err := router.SetTrustedProxies("10.0.0.0/8", "192.168.1.10"); err != nil {
+//		panic(err)
+//	}
+func (r *Router) SetTrustedProxies(proxies ...string) error {
+	prefixes, err := ParseIPRanges(proxies...)
+	if err != nil {
+		return err
+	}
+	r.trustedProxies.Store(&prefixes)
+	return nil
+}
+
+// isTrustedProxy checks if the address is a trusted proxy of this router
+func (r *Router) isTrustedProxy(addr netip.Addr) bool {
+	if proxies := r.trustedProxies.Load(); proxies != nil {
+		return IPRangesContain(*proxies, addr)
+	}
+	return false
+}
+
+// ParseIPRanges parses IPs ("10.0.0.1") and CIDR ranges ("10.0.0.0/8"), IPs are converted to single address ranges
+// ("10.0.0.1/32")
+func ParseIPRanges(values ...string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.IndexByte(value, '/') >= 0 {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR range %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		} else {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP %q: %w", value, err)
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return prefixes, nil
+}
+
+// IPRangesContain checks if the address is in one of the ranges (see ParseIPRanges)
+func IPRangesContain(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP the IP of the client.
+//
+// When the request comes from a trusted proxy (see Router.SetTrustedProxies), the IP is resolved from the
+// "X-Forwarded-For" header (the first address from the right that is not a trusted proxy) or from the "X-Real-Ip"
+// header. Otherwise, the IP of the remote address of the connection is used.
+func (ctx *Context) ClientIP() string {
+	if addr, ok := ctx.ClientAddr(); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+// ClientAddr the IP of the client as netip.Addr (see ClientIP), ok is false if the IP could not be parsed
+func (ctx *Context) ClientAddr() (addr netip.Addr, ok bool) {
+	remote, valid := parseRemoteAddr(ctx.Request.RemoteAddr)
+	if !valid {
+		return netip.Addr{}, false
+	}
+	if ctx.router == nil || !ctx.router.isTrustedProxy(remote) {
+		return remote, true
+	}
+
+	if forwarded := ctx.Request.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		ips := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip, err := netip.ParseAddr(strings.TrimSpace(ips[i]))
+			if err != nil {
+				// malformed header, uses the last valid hop
+				break
+			}
+			remote = ip.Unmap()
+			if !ctx.router.isTrustedProxy(remote) {
+				return remote, true
+			}
+		}
+		return remote, true
+	}
+
+	if ip, err := netip.ParseAddr(strings.TrimSpace(ctx.Request.Header.Get("X-Real-Ip"))); err == nil {
+		return ip.Unmap(), true
+	}
+	return remote, true
+}
+
+// parseRemoteAddr parses the http.Request.RemoteAddr ("ip:port" or "ip")
+func parseRemoteAddr(remoteAddr string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// Scheme the scheme of the request ("http" or "https").
+//
+// When the request comes from a trusted proxy (see Router.SetTrustedProxies), the scheme is resolved from the
+// "X-Forwarded-Proto" header, allowing TLS termination at the proxy. Otherwise, the connection is checked.
+func (ctx *Context) Scheme() string {
+	if ctx.Request.TLS != nil {
+		return "https"
+	}
+	if ctx.router != nil {
+		if remote, ok := parseRemoteAddr(ctx.Request.RemoteAddr); ok && ctx.router.isTrustedProxy(remote) {
+			proto, _, _ := strings.Cut(ctx.Request.Header.Get("X-Forwarded-Proto"), ",")
+			if proto = strings.ToLower(strings.TrimSpace(proto)); proto == "https" || proto == "http" {
+				return proto
+			}
+		}
+	}
+	return "http"
+}
+
+// IsTLS checks if the request was made over HTTPS, see Context.Scheme
+func (ctx *Context) IsTLS() bool {
+	return ctx.Scheme() == "https"
+}
@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Context_ClientIP(t *testing.T) {
+	router := New()
+	if err := router.SetTrustedProxies("10.0.0.0/8", "::1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote   string
+		forward  string
+		realIp   string
+		expected string
+	}{
+		{"203.0.113.1:1234", "", "", "203.0.113.1"},
+		{"203.0.113.1:1234", "198.51.100.7", "", "203.0.113.1"}, // untrusted, header ignored
+		{"10.0.0.1:1234", "198.51.100.7", "", "198.51.100.7"},
+		{"10.0.0.1:1234", "198.51.100.7, 10.0.0.2", "", "198.51.100.7"},
+		{"10.0.0.1:1234", "1.1.1.1, 198.51.100.7, 10.0.0.2", "", "198.51.100.7"}, // spoofed first hop
+		{"10.0.0.1:1234", "10.0.0.3", "", "10.0.0.3"},
+		{"10.0.0.1:1234", "invalid, 10.0.0.3", "", "10.0.0.3"},
+		{"10.0.0.1:1234", "", "198.51.100.8", "198.51.100.8"},
+		{"[::1]:1234", "2001:db8::1", "", "2001:db8::1"},
+		{"[::ffff:10.0.0.1]:1234", "198.51.100.7", "", "198.51.100.7"},
+		{"invalid", "", "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = tt.remote
+		if tt.forward != "" {
+			req.Header.Set("X-Forwarded-For", tt.forward)
+		}
+		if tt.realIp != "" {
+			req.Header.Set("X-Real-Ip", tt.realIp)
+		}
+		ctx := router.poolGetContext(req, httptest.NewRecorder(), "")
+		if ip := ctx.ClientIP(); ip != tt.expected {
+			t.Errorf("ClientIP() failed: Invalid IP for %s (%s)\n   actual: %v\n expected: %v", tt.remote, tt.forward, ip, tt.expected)
+		}
+		router.poolPutContext(ctx)
+	}
+}
+
+func Test_Router_SetTrustedProxies_Invalid(t *testing.T) {
+	router := New()
+	for _, proxy := range []string{"10.0.0.0/33", "10.0.0", "localhost"} {
+		if err := router.SetTrustedProxies(proxy); err == nil {
+			t.Errorf("SetTrustedProxies(%q) failed: expected error", proxy)
+		}
+	}
+}
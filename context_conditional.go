@@ -0,0 +1,157 @@
+package chain
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified sets the Last-Modified and ETag headers of the response (when informed) and checks the If-None-Match and
+// If-Modified-Since headers of the request. When the client copy is still fresh, replies with 304 Not Modified and
+// returns true, the handler must not write the content.
+//
+// Useful for handler-generated content that does not flow through Context.ServeContent (ex. templates, JSON), use
+// CheckPreconditions to also validate the If-Match and If-Unmodified-Since headers of the unsafe methods.
+//
+// ## Example
+//
+//	router.GET("/posts/:id", func(ctx *chain.Context) error {
+//		post := posts.Get(ctx.GetParam("id"))
+//		if ctx.NotModified(post.UpdatedAt, strconv.Itoa(post.Version)) {
+//			return nil
+//		}
+//		ctx.Json(post)
+//		return nil
+//	})
+func (ctx *Context) NotModified(lastModified time.Time, etag string) bool {
+	etag = ctx.setValidators(lastModified, etag)
+	if !isSafeMethod(ctx.Request.Method) || !ctx.checkIfNoneMatch(lastModified, etag) {
+		return false
+	}
+	ctx.writeNotModified()
+	return true
+}
+
+// CheckPreconditions sets the Last-Modified and ETag headers of the response (when informed) and evaluates the
+// conditional headers of the request in the order of RFC 7232 (section 6): If-Match, If-Unmodified-Since,
+// If-None-Match and If-Modified-Since.
+//
+// Returns true when the response has been written: 412 Precondition Failed (ex. a PUT with an outdated If-Match, the
+// lost update problem) or 304 Not Modified (GET and HEAD with a fresh client copy).
+//
+// ## Example
+//
+//	router.PUT("/posts/:id", func(ctx *chain.Context) error {
+//		post := posts.Get(ctx.GetParam("id"))
+//		if ctx.CheckPreconditions(post.UpdatedAt, strconv.Itoa(post.Version)) {
+//			return nil
+//		}
+//		// ... update the post
+//	})
+func (ctx *Context) CheckPreconditions(lastModified time.Time, etag string) bool {
+	etag = ctx.setValidators(lastModified, etag)
+	header := ctx.Request.Header
+
+	if ifMatch := header.Get("If-Match"); ifMatch != "" {
+		if !matchETag(ifMatch, etag, false) {
+			ctx.WriteHeader(http.StatusPreconditionFailed)
+			return true
+		}
+	} else if modified, ok := isModifiedSince(header.Get("If-Unmodified-Since"), lastModified); ok && modified {
+		ctx.WriteHeader(http.StatusPreconditionFailed)
+		return true
+	}
+
+	if !ctx.checkIfNoneMatch(lastModified, etag) {
+		return false
+	}
+	if isSafeMethod(ctx.Request.Method) {
+		ctx.writeNotModified()
+	} else {
+		ctx.WriteHeader(http.StatusPreconditionFailed)
+	}
+	return true
+}
+
+// checkIfNoneMatch checks if the client copy matches the current representation (If-None-Match or, when absent,
+// If-Modified-Since for GET and HEAD)
+func (ctx *Context) checkIfNoneMatch(lastModified time.Time, etag string) bool {
+	header := ctx.Request.Header
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		return matchETag(ifNoneMatch, etag, true)
+	}
+	if !isSafeMethod(ctx.Request.Method) {
+		return false
+	}
+	modified, ok := isModifiedSince(header.Get("If-Modified-Since"), lastModified)
+	return ok && !modified
+}
+
+// setValidators sets the Last-Modified and ETag headers, returns the quoted etag
+func (ctx *Context) setValidators(lastModified time.Time, etag string) string {
+	if etag != "" {
+		if !strings.HasPrefix(etag, `"`) && !strings.HasPrefix(etag, `W/"`) {
+			etag = `"` + etag + `"`
+		}
+		ctx.SetHeader("ETag", etag)
+	}
+	if !isZeroTime(lastModified) {
+		ctx.SetHeader("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	return etag
+}
+
+// writeNotModified replies 304, without the representation headers (RFC 7232, section 4.1)
+func (ctx *Context) writeNotModified() {
+	header := ctx.Writer.Header()
+	delete(header, "Content-Type")
+	delete(header, "Content-Length")
+	delete(header, "Content-Encoding")
+	ctx.WriteHeader(http.StatusNotModified)
+}
+
+// matchETag checks if the etag matches one of the tags of the If-Match/If-None-Match header. The weak comparison
+// ignores the "W/" prefix.
+func matchETag(header string, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	if etag == "" || (!weak && strings.HasPrefix(etag, "W/")) {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// isModifiedSince checks if the resource was modified after the date of the header (second precision), ok is false
+// when the header or the lastModified are absent or invalid
+func isModifiedSince(date string, lastModified time.Time) (modified bool, ok bool) {
+	if date == "" || isZeroTime(lastModified) {
+		return false, false
+	}
+	since, err := http.ParseTime(date)
+	if err != nil {
+		return false, false
+	}
+	return lastModified.Truncate(time.Second).After(since), true
+}
+
+func isZeroTime(t time.Time) bool {
+	return t.IsZero() || t.Equal(UnixEpoch)
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
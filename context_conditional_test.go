@@ -0,0 +1,97 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Context_NotModified(t *testing.T) {
+	modified := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	router := New()
+	router.GET("/post", func(ctx *Context) error {
+		if ctx.NotModified(modified, "v3") {
+			return nil
+		}
+		ctx.Json(map[string]string{"title": "hello"})
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"no conditional", "", "", http.StatusOK},
+		{"etag match", "If-None-Match", `"v2", "v3"`, http.StatusNotModified},
+		{"weak etag match", "If-None-Match", `W/"v3"`, http.StatusNotModified},
+		{"etag mismatch", "If-None-Match", `"v2"`, http.StatusOK},
+		{"not modified since", "If-Modified-Since", modified.Format(http.TimeFormat), http.StatusNotModified},
+		{"modified since", "If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/post", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.expected {
+			t.Errorf("NotModified(%s) failed: Invalid Code\n   actual: %v\n expected: %v", tt.name, w.Code, tt.expected)
+		}
+		if etag := w.Header().Get("ETag"); etag != `"v3"` {
+			t.Errorf("NotModified(%s) failed: Invalid ETag\n   actual: %v\n expected: %v", tt.name, etag, `"v3"`)
+		}
+		if lastModified := w.Header().Get("Last-Modified"); lastModified != modified.Format(http.TimeFormat) {
+			t.Errorf("NotModified(%s) failed: Invalid Last-Modified\n   actual: %v\n expected: %v", tt.name, lastModified, modified.Format(http.TimeFormat))
+		}
+		if tt.expected == http.StatusNotModified && (w.Body.Len() > 0 || w.Header().Get("Content-Type") != "") {
+			t.Errorf("NotModified(%s) failed: unexpected content\n   actual: %q", tt.name, w.Body.String())
+		}
+	}
+}
+
+func Test_Context_CheckPreconditions(t *testing.T) {
+	modified := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	router := New()
+	handler := func(ctx *Context) error {
+		if ctx.CheckPreconditions(modified, `"v3"`) {
+			return nil
+		}
+		ctx.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	router.GET("/post", handler)
+	router.PUT("/post", handler)
+
+	tests := []struct {
+		method   string
+		header   string
+		value    string
+		expected int
+	}{
+		{http.MethodPut, "If-Match", `"v3"`, http.StatusNoContent},
+		{http.MethodPut, "If-Match", `"v2"`, http.StatusPreconditionFailed},
+		{http.MethodPut, "If-Match", `W/"v3"`, http.StatusPreconditionFailed},
+		{http.MethodPut, "If-Match", `*`, http.StatusNoContent},
+		{http.MethodPut, "If-Unmodified-Since", modified.Format(http.TimeFormat), http.StatusNoContent},
+		{http.MethodPut, "If-Unmodified-Since", modified.Add(-time.Hour).Format(http.TimeFormat), http.StatusPreconditionFailed},
+		{http.MethodPut, "If-None-Match", `*`, http.StatusPreconditionFailed},
+		{http.MethodPut, "If-Modified-Since", modified.Format(http.TimeFormat), http.StatusNoContent},
+		{http.MethodGet, "If-None-Match", `"v3"`, http.StatusNotModified},
+		{http.MethodGet, "If-Match", `"v2"`, http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, "/post", nil)
+		req.Header.Set(tt.header, tt.value)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.expected {
+			t.Errorf("CheckPreconditions(%s %s: %s) failed: Invalid Code\n   actual: %v\n expected: %v", tt.method, tt.header, tt.value, w.Code, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File replies to the request with the contents of the file (see http.ServeContent), handling Range requests and the
+// conditional headers (If-Match, If-None-Match, If-Modified-Since, If-Range).
+//
+// The Content-Type is deduced from the file extension (or from the content, see http.DetectContentType), the
+// Last-Modified and ETag headers are computed from the file info (size and modification time), an ETag already set
+// by the handler is preserved.
+//
+// Replies with 404 (see Context.NotFound) when the file does not exist or is a directory, other errors are returned.
+//
+// ## Example
+//
+//	router.GET("/reports/:name", func(ctx *chain.Context) error {
+//		return ctx.File(filepath.Join("/var/reports", filepath.Base(ctx.GetParam("name"))))
+//	})
+func (ctx *Context) File(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return ctx.fileError(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		ctx.NotFound()
+		return nil
+	}
+	return ctx.serveFile(file, info)
+}
+
+// FileFS replies to the request with the contents of the file from the file system (ex. embed.FS), same as
+// Context.File.
+//
+// Files that do not implement io.Seeker are read into memory to allow the Range requests.
+//
+// ## Example
+//
+//	//go:embed static
+//	var static embed.FS
+//
+//	router.GET("/static/*filepath", func(ctx *chain.Context) error {
+//		return ctx.FileFS(static, path.Join("static", ctx.GetWildcard("filepath")))
+//	})
+func (ctx *Context) FileFS(fsys fs.FS, name string) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return ctx.fileError(err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		ctx.NotFound()
+		return nil
+	}
+	return ctx.serveFile(file, info)
+}
+
+// Attachment replies to the request with the content of the reader as a download, the Content-Disposition header
+// informs the filename to the browser (non-ASCII filenames are encoded as per RFC 6266).
+//
+// When the reader implements io.Seeker (ex. *os.File, *bytes.Reader), Range requests are supported. The Content-Type
+// is deduced from the filename extension, when not already set.
+//
+// ## Example
+//
+//	router.GET("/invoices/:id/pdf", func(ctx *chain.Context) error {
+//		content, err := invoices.PDF(ctx.GetParam("id"))
+//		if err != nil {
+//			return err
+//		}
+//		return ctx.Attachment(bytes.NewReader(content), "Fatura nº "+ctx.GetParam("id")+".pdf")
+//	})
+func (ctx *Context) Attachment(reader io.Reader, filename string) error {
+	ctx.SetHeader("Content-Disposition", ContentDisposition("attachment", filename))
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		http.ServeContent(ctx.Writer, ctx.Request, filename, time.Time{}, seeker)
+		return nil
+	}
+
+	if ctx.Writer.Header().Get("Content-Type") == "" {
+		if ctype := mime.TypeByExtension(filepath.Ext(filename)); ctype != "" {
+			ctx.SetHeader("Content-Type", ctype)
+		} else {
+			ctx.SetHeader("Content-Type", "application/octet-stream")
+		}
+	}
+	ctx.WriteHeader(http.StatusOK)
+	if ctx.Request.Method == http.MethodHead {
+		return nil
+	}
+	_, err := io.Copy(ctx.Writer, reader)
+	return err
+}
+
+// ContentDisposition formats the value of the Content-Disposition header ("attachment" or "inline") with the filename.
+// Non-ASCII filenames are sent in the `filename*` parameter (RFC 5987), with an ASCII fallback in `filename` for
+// old clients.
+//
+// ## Example
+//
+//	ContentDisposition("attachment", "relatório.pdf")
+//	// attachment; filename="relat_rio.pdf"; filename*=UTF-8''relat%C3%B3rio.pdf
+func ContentDisposition(dispositionType string, filename string) string {
+	filename = filepath.Base(filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return dispositionType
+	}
+
+	ascii := true
+	fallback := make([]byte, 0, len(filename))
+	for _, r := range filename {
+		switch {
+		case r >= 0x80:
+			ascii = false
+			fallback = append(fallback, '_')
+		case r < 0x20 || r == 0x7f || r == '"' || r == '\\':
+			fallback = append(fallback, '_')
+		default:
+			fallback = append(fallback, byte(r))
+		}
+	}
+
+	value := dispositionType + `; filename="` + string(fallback) + `"`
+	if !ascii {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// encodeRFC5987 percent-encodes the value, keeping only the attr-char of the RFC 5987
+func encodeRFC5987(value string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0f])
+		}
+	}
+	return b.String()
+}
+
+// serveFile replies with the file, using the file info for the ETag and Last-Modified headers
+func (ctx *Context) serveFile(file fs.File, info fs.FileInfo) error {
+	header := ctx.Writer.Header()
+	if header.Get("ETag") == "" {
+		header.Set("ETag", fileETag(info))
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(data)
+	}
+	http.ServeContent(ctx.Writer, ctx.Request, info.Name(), info.ModTime(), content)
+	return nil
+}
+
+// fileError replies 404 when the file does not exist (or the name is invalid in the fs.FS), other errors are returned
+func (ctx *Context) fileError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrInvalid) {
+		ctx.NotFound()
+		return nil
+	}
+	return err
+}
+
+// fileETag strong validator of the file, based on the modification time and size
+func fileETag(info fs.FileInfo) string {
+	return `"` + strconv.FormatInt(info.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36) + `"`
+}
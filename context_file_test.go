@@ -0,0 +1,153 @@
+package chain
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func Test_Context_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	router := New()
+	router.GET("/file", func(ctx *Context) error {
+		return ctx.File(path)
+	})
+	router.GET("/missing", func(ctx *Context) error {
+		return ctx.File(filepath.Join(dir, "missing.txt"))
+	})
+	router.GET("/dir", func(ctx *Context) error {
+		return ctx.File(dir)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/file", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Errorf("File() failed: Invalid response\n   actual: %v %q\n expected: %v %q", w.Code, w.Body.String(), http.StatusOK, "0123456789")
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "text/plain") {
+		t.Errorf("File() failed: Invalid Content-Type\n   actual: %v\n expected: %v", ctype, "text/plain")
+	}
+	if w.Header().Get("Last-Modified") == "" {
+		t.Errorf("File() failed: expected Last-Modified header")
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("File() failed: expected ETag header")
+	}
+
+	// range
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/file", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2345" {
+		t.Errorf("File() failed: Invalid range response\n   actual: %v %q\n expected: %v %q", w.Code, w.Body.String(), http.StatusPartialContent, "2345")
+	}
+
+	// conditional
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/file", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("File() failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusNotModified)
+	}
+
+	for _, path := range []string{"/missing", "/dir"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("File(%s) failed: Invalid Code\n   actual: %v\n expected: %v", path, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func Test_Context_FileFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/app.css": &fstest.MapFile{Data: []byte("body{}"), ModTime: time.Now()},
+	}
+
+	router := New()
+	router.GET("/static/*filepath", func(ctx *Context) error {
+		return ctx.FileFS(fsys, path.Join("static", ctx.GetWildcard("filepath")))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/static/app.css", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "body{}" {
+		t.Errorf("FileFS() failed: Invalid response\n   actual: %v %q\n expected: %v %q", w.Code, w.Body.String(), http.StatusOK, "body{}")
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "text/css") {
+		t.Errorf("FileFS() failed: Invalid Content-Type\n   actual: %v\n expected: %v", ctype, "text/css")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/static/app.js", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("FileFS() failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func Test_Context_Attachment(t *testing.T) {
+	router := New()
+	router.GET("/seeker", func(ctx *Context) error {
+		return ctx.Attachment(bytes.NewReader([]byte("%PDF-1.4")), "relatório.pdf")
+	})
+	router.GET("/reader", func(ctx *Context) error {
+		return ctx.Attachment(struct{ io.Reader }{strings.NewReader("a,b")}, "data.csv")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/seeker", nil)
+	req.Header.Set("Range", "bytes=0-3")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "%PDF" {
+		t.Errorf("Attachment() failed: Invalid response\n   actual: %v %q\n expected: %v %q", w.Code, w.Body.String(), http.StatusPartialContent, "%PDF")
+	}
+	expected := `attachment; filename="relat_rio.pdf"; filename*=UTF-8''relat%C3%B3rio.pdf`
+	if disposition := w.Header().Get("Content-Disposition"); disposition != expected {
+		t.Errorf("Attachment() failed: Invalid Content-Disposition\n   actual: %v\n expected: %v", disposition, expected)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/reader", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "a,b" {
+		t.Errorf("Attachment() failed: Invalid response\n   actual: %v %q\n expected: %v %q", w.Code, w.Body.String(), http.StatusOK, "a,b")
+	}
+	if ctype := w.Header().Get("Content-Type"); !strings.HasPrefix(ctype, "text/csv") {
+		t.Errorf("Attachment() failed: Invalid Content-Type\n   actual: %v\n expected: %v", ctype, "text/csv")
+	}
+}
+
+func Test_ContentDisposition(t *testing.T) {
+	tests := map[string]string{
+		"report.pdf":        `attachment; filename="report.pdf"`,
+		"/tmp/report.pdf":   `attachment; filename="report.pdf"`,
+		`my "best" doc.txt`: `attachment; filename="my _best_ doc.txt"`,
+		"日本.txt":            `attachment; filename="__.txt"; filename*=UTF-8''%E6%97%A5%E6%9C%AC.txt`,
+		"":                  `attachment`,
+	}
+	for filename, expected := range tests {
+		if actual := ContentDisposition("attachment", filename); actual != expected {
+			t.Errorf("ContentDisposition(%q) failed: Invalid value\n   actual: %v\n expected: %v", filename, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package chain
+
+// Translator translates the messages to the locale negotiated for the request, see Context.T. Defined on the Context by
+// the localization middlewares (ex. middlewares/i18n).
+type Translator interface {
+	Locale() string
+	Translate(key string, args ...any) string
+}
+
+// translatorKey key of the Translator in the Context data
+var translatorKey = NewContextKey[Translator]("chain.translator")
+
+// SetTranslator defines the Translator of the request, used by Context.T and Context.Locale
+func (ctx *Context) SetTranslator(translator Translator) {
+	translatorKey.Set(ctx, translator)
+}
+
+// Translator gets the Translator of the request, nil if it is not defined
+func (ctx *Context) Translator() Translator {
+	return translatorKey.Value(ctx)
+}
+
+// Locale the locale negotiated for the request (ex. "pt-BR"), empty if the request has no Translator
+func (ctx *Context) Locale() string {
+	if translator := ctx.Translator(); translator != nil {
+		return translator.Locale()
+	}
+	return ""
+}
+
+// T translates the message to the locale of the request (see SetTranslator). Returns the key if the request has no
+// Translator.
+//
+// ## Example
+//
+//	router.Use(&i18n.I18n{Locales: []string{"en", "pt-BR"}, Catalog: catalog})
+//
+//	router.GET("/hello", func(ctx *chain.Context) error {
+//		ctx.Write([]byte(ctx.T("hello", ctx.QueryParam("name"))))
+//		return nil
+//	})
+func (ctx *Context) T(key string, args ...any) string {
+	if translator := ctx.Translator(); translator != nil {
+		return translator.Translate(key, args...)
+	}
+	return key
+}
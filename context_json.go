@@ -0,0 +1,148 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"unicode/utf8"
+)
+
+// DefaultSecureJsonPrefix prefix of the Context.SecureJson responses
+const DefaultSecureJsonPrefix = "while(1);"
+
+// IndentedJson same as Context.Json, with the JSON indented (4 spaces) for human readability. Prefer Context.Json in
+// production, the indentation increases the size of the response.
+func (ctx *Context) IndentedJson(v any) {
+	ctx.writeJson(v, "application/json", func(encoded []byte) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		if err := json.Indent(buf, encoded, "", "    "); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	})
+}
+
+// AsciiJson same as Context.Json, with the non-ASCII characters escaped ("ç" -> "\u00e7"), for clients that don't
+// handle UTF-8.
+func (ctx *Context) AsciiJson(v any) {
+	ctx.writeJson(v, "application/json", func(encoded []byte) ([]byte, error) {
+		return asciiJson(encoded), nil
+	})
+}
+
+// SecureJson same as Context.Json, with the prefix (default DefaultSecureJsonPrefix) before the JSON, preventing JSON
+// hijacking through <script> tags in old browsers. The client must remove the prefix before parsing.
+//
+// ## Example
+//
+//	router.GET("/accounts", func(ctx *chain.Context) {
+//		ctx.SecureJson("", accounts) // while(1);[{"id":1},{"id":2}]
+//	})
+func (ctx *Context) SecureJson(prefix string, v any) {
+	if prefix == "" {
+		prefix = DefaultSecureJsonPrefix
+	}
+	ctx.writeJson(v, "application/json", func(encoded []byte) ([]byte, error) {
+		return append([]byte(prefix), encoded...), nil
+	})
+}
+
+// JsonP replies the data as JSONP, calling the callback function with the JSON ("callback({...});"), for cross-domain
+// requests of legacy clients. Without callback, same as Context.Json. Invalid callback names (only letters, digits,
+// "_", "$" and "." are accepted) are rejected with 400 Bad Request.
+//
+// ## Example
+//
+//	router.GET("/widgets", func(ctx *chain.Context) {
+//		ctx.JsonP(ctx.QueryParam("callback"), widgets) // /widgets?callback=render -> render([...]);
+//	})
+func (ctx *Context) JsonP(callback string, v any) {
+	if callback == "" {
+		ctx.Json(v)
+		return
+	}
+	if !isValidCallback(callback) {
+		ctx.BadRequest()
+		return
+	}
+	ctx.SetHeader("X-Content-Type-Options", "nosniff")
+	ctx.writeJson(v, "application/javascript", func(encoded []byte) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		// the comment prevents the Rosetta Flash attack
+		buf.WriteString("/**/")
+		buf.WriteString(callback)
+		buf.WriteByte('(')
+		// U+2028 and U+2029 are valid in JSON, but not in JavaScript strings
+		encoded = bytes.ReplaceAll(encoded, []byte("\u2028"), []byte(`\u2028`))
+		encoded = bytes.ReplaceAll(encoded, []byte("\u2029"), []byte(`\u2029`))
+		buf.Write(encoded)
+		buf.WriteString(");")
+		return buf.Bytes(), nil
+	})
+}
+
+// writeJson encodes the data (see encodeJson) and writes the transformed content, with the ETag and Content-Length
+// headers (see Context.ServeContent)
+func (ctx *Context) writeJson(v any, contentType string, transform func(encoded []byte) ([]byte, error)) {
+	encoded, err := encodeJson(v)
+	if err == nil {
+		encoded, err = transform(encoded)
+	}
+	if err != nil {
+		ctx.Error(err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ctx.SetHeader("Content-Type", contentType)
+	ctx.ServeContent(encoded, "", UnixEpoch)
+}
+
+// encodeJson encodes the data with the Serializer registered for "application/json", see RegisterSerializer
+func encodeJson(v any) ([]byte, error) {
+	if serializer := GetSerializer("application/json"); serializer != nil {
+		return serializer.Encode(v)
+	}
+	return jsonSerializer.Encode(v)
+}
+
+// asciiJson escapes the non-ASCII characters of the JSON, as \uXXXX (surrogate pairs for the characters out of the
+// Basic Multilingual Plane)
+func asciiJson(encoded []byte) []byte {
+	const hex = "0123456789abcdef"
+	buf := bytes.NewBuffer(make([]byte, 0, len(encoded)))
+	writeEscaped := func(r rune) {
+		buf.WriteString(`\u`)
+		buf.WriteByte(hex[r>>12&0xf])
+		buf.WriteByte(hex[r>>8&0xf])
+		buf.WriteByte(hex[r>>4&0xf])
+		buf.WriteByte(hex[r&0xf])
+	}
+	for len(encoded) > 0 {
+		r, size := utf8.DecodeRune(encoded)
+		switch {
+		case r < utf8.RuneSelf:
+			buf.WriteByte(encoded[0])
+		case r > 0xFFFF:
+			r -= 0x10000
+			writeEscaped(0xD800 + (r>>10)&0x3FF)
+			writeEscaped(0xDC00 + r&0x3FF)
+		default:
+			writeEscaped(r)
+		}
+		encoded = encoded[size:]
+	}
+	return buf.Bytes()
+}
+
+// isValidCallback checks the name of the JSONP callback (ex. "jQuery123_456", "app.render")
+func isValidCallback(callback string) bool {
+	if len(callback) > 128 {
+		return false
+	}
+	for i := 0; i < len(callback); i++ {
+		c := callback[i]
+		if !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '_' || c == '$' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func Test_Context_Json_Variants(t *testing.T) {
+	data := map[string]any{"name": "João 😀", "tags": []string{"a"}}
+
+	router := New()
+	router.GET("/indented", func(ctx *Context) { ctx.IndentedJson(data) })
+	router.GET("/ascii", func(ctx *Context) { ctx.AsciiJson(data) })
+	router.GET("/secure", func(ctx *Context) { ctx.SecureJson("", []int{1, 2}) })
+	router.GET("/jsonp", func(ctx *Context) { ctx.JsonP(ctx.QueryParam("callback"), map[string]string{"text": "a\u2028b"}) })
+
+	tests := []struct {
+		path        string
+		code        int
+		contentType string
+		expected    string
+	}{
+		{"/indented", http.StatusOK, "application/json", "{\n    \"name\": \"João 😀\",\n    \"tags\": [\n        \"a\"\n    ]\n}"},
+		{"/ascii", http.StatusOK, "application/json", `{"name":"Jo\u00e3o \ud83d\ude00","tags":["a"]}`},
+		{"/secure", http.StatusOK, "application/json", `while(1);[1,2]`},
+		{"/jsonp?callback=app.render", http.StatusOK, "application/javascript", `/**/app.render({"text":"a\u2028b"});`},
+		{"/jsonp", http.StatusOK, "application/json", `{"text":"a\u2028b"}`},
+		{"/jsonp?callback=alert(1)", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s failed: Invalid Code\n   actual: %v\n expected: %v", tt.path, w.Code, tt.code)
+			continue
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		if w.Body.String() != tt.expected {
+			t.Errorf("%s failed: Invalid body\n   actual: %s\n expected: %s", tt.path, w.Body.String(), tt.expected)
+		}
+		if ctype := w.Header().Get("Content-Type"); ctype != tt.contentType {
+			t.Errorf("%s failed: Invalid Content-Type\n   actual: %v\n expected: %v", tt.path, ctype, tt.contentType)
+		}
+		if length := w.Header().Get("Content-Length"); length != strconv.Itoa(len(tt.expected)) || w.Header().Get("ETag") == "" {
+			t.Errorf("%s failed: Invalid Content-Length/ETag\n   actual: %v %v\n expected: %v", tt.path, length, w.Header().Get("ETag"), len(tt.expected))
+		}
+	}
+}
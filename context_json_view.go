@@ -0,0 +1,286 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// JsonViewTag struct tag with the views of the field (ex. `json-view:"public,admin"`), see Context.JsonView
+const JsonViewTag = "json-view"
+
+// FieldsQueryParam query param with the field mask of the response (ex. "?fields=id,name,address.city"), see
+// Context.JsonView
+const FieldsQueryParam = "fields"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	viewFieldsCache   sync.Map // map[reflect.Type][]viewField
+)
+
+// JsonView same as Context.Json, writing only the fields of the view, allowing different projections of the same
+// struct (ex. role-based field visibility).
+//
+// Fields with the JsonViewTag are written only in the listed views, fields without the tag are written in all the
+// views. An empty view ignores the tags. The field mask of the FieldsQueryParam, when informed, further restricts the
+// fields, using the JSON names and dots for the nested fields. The view is applied to nested structs, slices and maps.
+//
+// ## Example
+//
+//	type User struct {
+//		Id    int    `json:"id"`
+//		Name  string `json:"name"`
+//		Email string `json:"email" json-view:"owner,admin"`
+//		Notes string `json:"notes" json-view:"admin"`
+//	}
+//
+//	router.GET("/users/:id", func(ctx *chain.Context) {
+//		ctx.JsonView(users.Get(ctx.GetParam("id")), "public") // {"id":1,"name":"Alex"}
+//	})
+//
+//	// GET /users/1?fields=name -> {"name":"Alex"}
+func (ctx *Context) JsonView(v any, view string) {
+	ctx.Json(JsonProject(v, view, ParseFieldMask(ctx.QueryParam(FieldsQueryParam))))
+}
+
+// FieldMask selected fields of a projection (see JsonProject), by JSON name. The nested mask of a field is nil when
+// all its subfields are selected.
+type FieldMask map[string]FieldMask
+
+// ParseFieldMask parses a comma separated list of fields (ex. "id,name,address.city"), returns nil (all fields) when
+// the value is empty
+func ParseFieldMask(value string) FieldMask {
+	var mask FieldMask
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		if mask == nil {
+			mask = FieldMask{}
+		}
+		mask.add(strings.Split(path, "."))
+	}
+	return mask
+}
+
+func (m FieldMask) add(path []string) {
+	nested, exists := m[path[0]]
+	if len(path) == 1 {
+		m[path[0]] = nil
+		return
+	}
+	if exists && nested == nil {
+		// the whole field is already selected
+		return
+	}
+	if nested == nil {
+		nested = FieldMask{}
+		m[path[0]] = nested
+	}
+	nested.add(path[1:])
+}
+
+// JsonProject the projection of the value with the fields of the view and of the mask (nil selects all the fields),
+// encoded by encoding/json with the same field order of the structs. See Context.JsonView.
+func JsonProject(v any, view string, mask FieldMask) any {
+	if view == "" && mask == nil {
+		return v
+	}
+	return project(reflect.ValueOf(v), view, mask)
+}
+
+// jsonObject JSON object that keeps the order of the fields
+type jsonObject []jsonObjectField
+
+type jsonObjectField struct {
+	name  string
+	value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, field := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// viewField exported field of a struct, see viewFields
+type viewField struct {
+	index     []int
+	name      string
+	views     []string // nil = all the views
+	omitEmpty bool
+}
+
+func (f viewField) inView(view string) bool {
+	if view == "" || f.views == nil {
+		return true
+	}
+	for _, v := range f.views {
+		if v == view {
+			return true
+		}
+	}
+	return false
+}
+
+func project(value reflect.Value, view string, mask FieldMask) any {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Type().Implements(jsonMarshalerType) {
+		return value.Interface()
+	}
+	if value.Kind() != reflect.Pointer && reflect.PointerTo(value.Type()).Implements(jsonMarshalerType) {
+		if value.CanAddr() {
+			return value.Addr().Interface()
+		}
+		return value.Interface()
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return project(value.Elem(), view, mask)
+	case reflect.Struct:
+		object := jsonObject{}
+		for _, field := range viewFields(value.Type()) {
+			if !field.inView(view) {
+				continue
+			}
+			nested, selected := mask[field.name]
+			if mask != nil && !selected {
+				continue
+			}
+			fieldValue, ok := fieldByIndex(value, field.index)
+			if !ok || (field.omitEmpty && isEmptyValue(fieldValue)) {
+				continue
+			}
+			object = append(object, jsonObjectField{field.name, project(fieldValue, view, nested)})
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && (value.IsNil() || value.Type().Elem().Kind() == reflect.Uint8) {
+			return value.Interface()
+		}
+		items := make([]any, value.Len())
+		for i := range items {
+			items[i] = project(value.Index(i), view, mask)
+		}
+		return items
+	case reflect.Map:
+		if value.IsNil() || value.Type().Key().Kind() != reflect.String {
+			return value.Interface()
+		}
+		items := map[string]any{}
+		iter := value.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			nested, selected := mask[key]
+			if mask != nil && !selected {
+				continue
+			}
+			items[key] = project(iter.Value(), view, nested)
+		}
+		return items
+	}
+	return value.Interface()
+}
+
+// isEmptyValue same rules of the "omitempty" option of encoding/json
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	}
+	return value.IsZero()
+}
+
+// fieldByIndex same as reflect.Value.FieldByIndex, ok is false when an embedded pointer is nil
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value, true
+}
+
+// viewFields the fields of the struct encoded by encoding/json, embedded structs without name are flattened
+func viewFields(t reflect.Type) []viewField {
+	if cached, exists := viewFieldsCache.Load(t); exists {
+		return cached.([]viewField)
+	}
+
+	var fields []viewField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			embedded := sf.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for _, field := range viewFields(embedded) {
+					field.index = append([]int{i}, field.index...)
+					fields = append(fields, field)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		field := viewField{index: []int{i}, name: name}
+		for _, option := range strings.Split(options, ",") {
+			if option == "omitempty" {
+				field.omitEmpty = true
+			}
+		}
+		if views, exists := sf.Tag.Lookup(JsonViewTag); exists {
+			field.views = []string{}
+			for _, view := range strings.Split(views, ",") {
+				if view = strings.TrimSpace(view); view != "" {
+					field.views = append(field.views, view)
+				}
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	viewFieldsCache.Store(t, fields)
+	return fields
+}
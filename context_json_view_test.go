@@ -0,0 +1,87 @@
+package chain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type viewAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street" json-view:"admin"`
+}
+
+type viewAudit struct {
+	CreatedAt time.Time `json:"created_at" json-view:"admin"`
+}
+
+type viewUser struct {
+	viewAudit
+	Id       int               `json:"id"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty" json-view:"owner,admin"`
+	Password string            `json:"-"`
+	Address  *viewAddress      `json:"address"`
+	Tags     []string          `json:"tags,omitempty"`
+	Friends  []viewUser        `json:"friends,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	internal string
+}
+
+func Test_JsonProject(t *testing.T) {
+	created := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	user := &viewUser{
+		viewAudit: viewAudit{CreatedAt: created},
+		Id:        1,
+		Name:      "Alex",
+		Email:     "alex@example.com",
+		Password:  "secret",
+		Address:   &viewAddress{City: "Recife", Street: "Rua A"},
+		Friends:   []viewUser{{Id: 2, Name: "Sam", Email: "sam@example.com"}},
+		Meta:      map[string]string{"plan": "pro", "source": "ads"},
+		internal:  "x",
+	}
+
+	tests := []struct {
+		view     string
+		mask     string
+		expected string
+	}{
+		{"public", "", `{"id":1,"name":"Alex","address":{"city":"Recife"},"friends":[{"id":2,"name":"Sam","address":null}],"meta":{"plan":"pro","source":"ads"}}`},
+		{"owner", "", `{"id":1,"name":"Alex","email":"alex@example.com","address":{"city":"Recife"},"friends":[{"id":2,"name":"Sam","email":"sam@example.com","address":null}],"meta":{"plan":"pro","source":"ads"}}`},
+		{"admin", "id,created_at,address.street", `{"created_at":"2024-03-10T12:00:00Z","id":1,"address":{"street":"Rua A"}}`},
+		{"public", "name,email,friends.name,meta.plan", `{"name":"Alex","friends":[{"name":"Sam"}],"meta":{"plan":"pro"}}`},
+		{"", "address,address.city", `{"address":{"city":"Recife","street":"Rua A"}}`},
+	}
+	for _, tt := range tests {
+		encoded, err := json.Marshal(JsonProject(user, tt.view, ParseFieldMask(tt.mask)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != tt.expected {
+			t.Errorf("JsonProject(%q, %q) failed: Invalid JSON\n   actual: %s\n expected: %s", tt.view, tt.mask, encoded, tt.expected)
+		}
+	}
+}
+
+func Test_Context_JsonView(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", func(ctx *Context) {
+		ctx.JsonView(viewUser{Id: 1, Name: "Alex", Email: "alex@example.com"}, "public")
+	})
+
+	tests := map[string]string{
+		"/users/1":             `{"id":1,"name":"Alex","address":null}`,
+		"/users/1?fields=name": `{"name":"Alex"}`,
+	}
+	for path, expected := range tests {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Body.String() != expected {
+			t.Errorf("JsonView(%s) failed: Invalid response\n   actual: %s\n expected: %s", path, w.Body.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package chain
+
+// RequestIDKey the id of the request, defined by the request id middlewares. Used by Recovery to identify the request
+// (see PanicInfo.RequestID), before the request id header.
+var RequestIDKey = NewContextKey[string]("chain.request-id")
+
+// TypedKey key of a value of type T in the Context data (see Context.Set), created by NewContextKey. Allows the
+// middlewares to share data with the handlers without casts.
+//
+// Keys are identified by the name and the type, keys with the same name and different types never collide. Prefix
+// the names with the package (ex. "myapp.auth.user") to avoid collisions between middlewares.
+type TypedKey[T any] struct {
+	name string
+}
+
+// typedKeyId the key used in the Context data, the type parameter separates the keys of different types
+type typedKeyId[T any] struct {
+	name string
+}
+
+// NewContextKey creates a typed key for the Context data.
+//
+// ## Example
+//
+//	var UserKey = chain.NewContextKey[*User]("myapp.auth.user")
+//
+//	router.Use(func(ctx *chain.Context, next func() error) error {
+//		user, err := auth.Authenticate(ctx.Request)
+//		if err != nil {
+//			ctx.Unauthorized()
+//			return nil
+//		}
+//		UserKey.Set(ctx, user)
+//		return next()
+//	})
+//
+//	router.GET("/me", func(ctx *chain.Context) {
+//		user, _ := UserKey.Get(ctx)
+//		ctx.Json(user)
+//	})
+func NewContextKey[T any](name string) TypedKey[T] {
+	return TypedKey[T]{name: name}
+}
+
+// Name the name of the key
+func (k TypedKey[T]) Name() string {
+	return k.name
+}
+
+// Set defines the value of the key on the Context
+func (k TypedKey[T]) Set(ctx *Context, value T) {
+	ctx.Set(typedKeyId[T]{k.name}, value)
+}
+
+// Get gets the value of the key from the Context, exists is false when the value was not defined
+func (k TypedKey[T]) Get(ctx *Context) (value T, exists bool) {
+	if v, found := ctx.Get(typedKeyId[T]{k.name}); found {
+		value, exists = v.(T)
+	}
+	return
+}
+
+// Value gets the value of the key from the Context, the zero value of T when it was not defined
+func (k TypedKey[T]) Value(ctx *Context) T {
+	value, _ := k.Get(ctx)
+	return value
+}
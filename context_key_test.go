@@ -0,0 +1,59 @@
+package chain
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Context_TypedKey(t *testing.T) {
+	type user struct{ Name string }
+	userKey := NewContextKey[*user]("test.user")
+	nameKey := NewContextKey[string]("test.user")
+
+	ctx := &Context{}
+	if value, exists := userKey.Get(ctx); exists || value != nil {
+		t.Errorf("Get() failed: Invalid value\n   actual: %v %v\n expected: nil false", value, exists)
+	}
+
+	userKey.Set(ctx, &user{Name: "Alex"})
+	nameKey.Set(ctx, "Sam")
+	if value, exists := userKey.Get(ctx); !exists || value.Name != "Alex" {
+		t.Errorf("Get() failed: Invalid value\n   actual: %v %v\n expected: %v true", value, exists, "Alex")
+	}
+	if value := nameKey.Value(ctx); value != "Sam" {
+		t.Errorf("Value() failed: keys with different types collide\n   actual: %v\n expected: %v", value, "Sam")
+	}
+	if value := NewContextKey[string]("test.user").Value(ctx); value != "Sam" {
+		t.Errorf("Value() failed: Invalid value of the key with the same name and type\n   actual: %v\n expected: %v", value, "Sam")
+	}
+	if _, exists := ctx.Get("test.user"); exists {
+		t.Errorf("Get() failed: typed key collides with the string key")
+	}
+}
+
+func Test_Router_Recovery_RequestIDKey(t *testing.T) {
+	var info *PanicInfo
+
+	router := New()
+	router.Recovery = &Recovery{
+		Logger:  slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		OnPanic: func(ctx *Context, i *PanicInfo) { info = i },
+	}
+	router.Use(func(ctx *Context, next func() error) error {
+		RequestIDKey.Set(ctx, "generated-1")
+		return next()
+	})
+	router.GET("/panic", func(ctx *Context) {
+		panic("oops!")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req-123")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if info == nil || info.RequestID != "generated-1" {
+		t.Errorf("Recovery failed: Invalid RequestID\n   actual: %+v\n expected: %v", info, "generated-1")
+	}
+}
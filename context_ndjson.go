@@ -0,0 +1,92 @@
+package chain
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// DefaultNDJSONFlushInterval max interval between the flushes of Context.NDJSON
+var DefaultNDJSONFlushInterval = 200 * time.Millisecond
+
+// NDJSON streams newline-delimited JSON objects (application/x-ndjson), encoding each value sent to `yield` as a line.
+// Allows exporting large datasets without building the entire response in memory (as Context.Json does).
+//
+// The lines are buffered and flushed to the client periodically (see DefaultNDJSONFlushInterval) and at the end. The
+// `yield` returns false when the stream must stop (client disconnected or write error), the iterator must return.
+//
+// Returns the error of the encoding or of the write, the response may be partial as the header has already been sent.
+//
+// ## Example
+//
+//	router.GET("/export", func(ctx *chain.Context) error {
+//		rows, err := db.QueryContext(ctx.Request.Context(), "SELECT id, name FROM users")
+//		if err != nil {
+//			return err
+//		}
+//		defer rows.Close()
+//
+//		return ctx.NDJSON(func(yield func(v any) bool) {
+//			for rows.Next() {
+//				var user User
+//				rows.Scan(&user.Id, &user.Name)
+//				if !yield(user) {
+//					return
+//				}
+//			}
+//		})
+//	})
+func (ctx *Context) NDJSON(iter func(yield func(v any) bool)) (err error) {
+	if ctx.Writer.Header().Get("Content-Type") == "" {
+		ctx.SetHeader("Content-Type", "application/x-ndjson")
+	}
+	if !ctx.WriteHeaderCalled() {
+		ctx.WriteHeader(http.StatusOK)
+	}
+
+	// the router releases the context when the client disconnects
+	writer := ctx.Writer
+	requestCtx := ctx.Request.Context()
+
+	buffer := bufio.NewWriter(writer)
+	encoder := json.NewEncoder(buffer)
+	lastFlush := time.Now()
+
+	flush := func() error {
+		if err := buffer.Flush(); err != nil {
+			return err
+		}
+		if flusher, ok := writer.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		lastFlush = time.Now()
+		return nil
+	}
+
+	iter(func(v any) bool {
+		if err != nil {
+			return false
+		}
+		select {
+		case <-requestCtx.Done():
+			err = requestCtx.Err()
+			return false
+		default:
+		}
+		if err = encoder.Encode(v); err != nil {
+			return false
+		}
+		if time.Since(lastFlush) >= DefaultNDJSONFlushInterval {
+			if err = flush(); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
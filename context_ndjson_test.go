@@ -0,0 +1,89 @@
+package chain
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Context_NDJSON(t *testing.T) {
+	router := New()
+	router.GET("/export", func(ctx *Context) error {
+		return ctx.NDJSON(func(yield func(v any) bool) {
+			for i := 1; i <= 3; i++ {
+				if !yield(map[string]int{"id": i}) {
+					return
+				}
+			}
+		})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("NDJSON() failed: Invalid Content-Type\n   actual: %v\n expected: %v", contentType, "application/x-ndjson")
+	}
+	expected := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	if w.Body.String() != expected {
+		t.Errorf("NDJSON() failed: Invalid body\n   actual: %q\n expected: %q", w.Body.String(), expected)
+	}
+}
+
+func Test_Context_NDJSON_Streaming(t *testing.T) {
+	interval := DefaultNDJSONFlushInterval
+	DefaultNDJSONFlushInterval = 0
+	defer func() { DefaultNDJSONFlushInterval = interval }()
+
+	received := make(chan struct{})
+	result := make(chan error, 1)
+
+	router := New()
+	router.GET("/export", func(ctx *Context) error {
+		err := ctx.NDJSON(func(yield func(v any) bool) {
+			yield("first")
+			// the first line is delivered before the end of the stream
+			select {
+			case <-received:
+			case <-time.After(2 * time.Second):
+			}
+			for yield("more") {
+				time.Sleep(time.Millisecond)
+			}
+		})
+		result <- err
+		return nil
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	requestCtx, cancel := context.WithCancel(context.Background())
+	request, _ := http.NewRequestWithContext(requestCtx, http.MethodGet, server.URL+"/export", nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	if err != nil || line != "\"first\"\n" {
+		t.Fatalf("NDJSON() failed: Invalid first line\n   actual: %q, %v\n expected: %q", line, err, "\"first\"\n")
+	}
+	close(received)
+
+	// client disconnects, the iterator stops
+	cancel()
+	select {
+	case err = <-result:
+		// context.Canceled or the write error, depending on which is detected first
+		if err == nil {
+			t.Errorf("NDJSON() failed: expected error after client disconnection")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("NDJSON() failed: iterator not stopped after client disconnection")
+	}
+}
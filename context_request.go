@@ -2,9 +2,11 @@ package chain
 
 import (
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 // BodyBytes get body as array of bytes
@@ -37,37 +39,125 @@ func (ctx *Context) GetParam(name string) string {
 	return ""
 }
 
+// SetParam defines the value of the param in this Context, replacing the value of the first Param which key matches
+// the given name or adding a new Param. A Context holds up to 32 params, additional params are ignored.
+func (ctx *Context) SetParam(name string, value string) {
+	for i := 0; i < ctx.paramCount; i++ {
+		if ctx.paramNames[i] == name {
+			ctx.paramValues[i] = value
+			return
+		}
+	}
+	if ctx.paramCount == len(ctx.paramNames) {
+		slog.Warn("[chain] max number of params exceeded", slog.String("Param", name), slog.String("Path", ctx.path))
+		return
+	}
+	ctx.addParameter(name, value)
+}
+
 // GetParamByIndex get one parameter per index
 func (ctx *Context) GetParamByIndex(index int) string {
 	return ctx.paramValues[index]
 }
 
-// @TODO: cache
+// QueryValues returns the parsed query of the request. The values are parsed once and cached in the Context, while
+// the request URL.RawQuery does not change.
+func (ctx *Context) QueryValues() url.Values {
+	if ctx.parent != nil && ctx.parent.Request == ctx.Request {
+		return ctx.parent.QueryValues()
+	}
+	if ctx.query == nil || ctx.queryRaw != ctx.Request.URL.RawQuery {
+		ctx.queryRaw = ctx.Request.URL.RawQuery
+		ctx.query, _ = url.ParseQuery(ctx.queryRaw)
+	}
+	return ctx.query
+}
+
+// QueryParam returns the first value of the query param, or the defaultValue if the param is missing or empty
 func (ctx *Context) QueryParam(name string, defaultValue ...string) string {
-	if val := ctx.Request.URL.Query().Get(name); val != "" {
+	if val := ctx.QueryValues().Get(name); val != "" {
 		return val
 	}
-	for _, v := range defaultValue {
-		return v
+	return queryDefault(defaultValue)
+}
+
+// QueryParams returns all values of the query param. Ex. "?id=1&id=2" returns ["1", "2"]
+func (ctx *Context) QueryParams(name string) []string {
+	return ctx.QueryValues()[name]
+}
+
+// QueryMap returns the query params in the "prefix[key]=value" format as a map.
+//
+// ## Example
+//
+//	// GET /search?filter[name]=john&filter[status]=active
+//	filter := ctx.QueryMap("filter") // map[name:john status:active]
+func (ctx *Context) QueryMap(prefix string) map[string]string {
+	dict := map[string]string{}
+	for key, values := range ctx.QueryValues() {
+		if len(values) == 0 || len(key) < len(prefix)+3 || key[:len(prefix)] != prefix {
+			continue
+		}
+		if key[len(prefix)] == '[' && key[len(key)-1] == ']' {
+			dict[key[len(prefix)+1:len(key)-1]] = values[0]
+		}
 	}
-	return ""
+	return dict
 }
 
+// QueryParamInt returns the query param as int, or the defaultValue if the param is missing or invalid
 func (ctx *Context) QueryParamInt(name string, defaultValue ...int) int {
-	str := ctx.QueryParam(name, "0")
-	if str == "" {
-		for _, v := range defaultValue {
-			return v
-		}
-		return 0
+	val, err := strconv.Atoi(ctx.QueryParam(name))
+	if err != nil {
+		return queryDefault(defaultValue)
+	}
+	return val
+}
+
+// QueryParamBool returns the query param as bool (see strconv.ParseBool), or the defaultValue if the param is missing
+// or invalid
+func (ctx *Context) QueryParamBool(name string, defaultValue ...bool) bool {
+	val, err := strconv.ParseBool(ctx.QueryParam(name))
+	if err != nil {
+		return queryDefault(defaultValue)
 	}
-	val, err := strconv.Atoi(str)
+	return val
+}
+
+// QueryParamFloat returns the query param as float64, or the defaultValue if the param is missing or invalid
+func (ctx *Context) QueryParamFloat(name string, defaultValue ...float64) float64 {
+	val, err := strconv.ParseFloat(ctx.QueryParam(name), 64)
 	if err != nil {
-		return 0
+		return queryDefault(defaultValue)
 	}
 	return val
 }
 
+// QueryParamTime returns the query param parsed with the layout (default time.RFC3339), or the defaultValue if the
+// param is missing or invalid.
+//
+// ## Example
+//
+//	// GET /events?from=2024-01-31
+//	from := ctx.QueryParamTime("from", time.DateOnly, time.Now().AddDate(0, 0, -7))
+func (ctx *Context) QueryParamTime(name string, layout string, defaultValue ...time.Time) time.Time {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	val, err := time.Parse(layout, ctx.QueryParam(name))
+	if err != nil {
+		return queryDefault(defaultValue)
+	}
+	return val
+}
+
+func queryDefault[T any](defaultValue []T) (value T) {
+	if len(defaultValue) > 0 {
+		value = defaultValue[0]
+	}
+	return
+}
+
 // Host host as string
 func (ctx *Context) Host() string {
 	return ctx.Request.Host
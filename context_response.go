@@ -2,6 +2,7 @@ package chain
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,9 +13,10 @@ var jsonSerializer = &JsonSerializer{}
 
 // Json encode and writes the data to the connection as part of an HTTP reply.
 //
-// The Content-Length and Content-Type headers are added automatically.
+// The Content-Length and Content-Type headers are added automatically. The data is encoded by the Serializer
+// registered for "application/json" (see RegisterSerializer), allowing a faster JSON engine.
 func (ctx *Context) Json(v any) {
-	if encoded, err := jsonSerializer.Encode(v); err != nil {
+	if encoded, err := encodeJson(v); err != nil {
 		ctx.Error(err.Error(), http.StatusInternalServerError)
 	} else {
 		ctx.SetHeader("Content-Type", "application/json")
@@ -70,7 +72,10 @@ func (ctx *Context) WriteHeaderCalled() bool {
 // If the caller has set w's ETag header formatted per RFC 7232, section 2.3,
 // ServeContent uses it to handle requests using If-Match, If-None-Match, or If-Range.
 func (ctx *Context) ServeContent(content []byte, name string, modtime time.Time) {
-	ctx.SetHeader("ETag", HashXxh64(content))
+	if ctx.Writer.Header().Get("ETag") == "" {
+		// preserves the ETag set by the handler, ex. Context.NotModified
+		ctx.SetHeader("ETag", HashXxh64(content))
+	}
 	ctx.SetHeader("Content-Length", strconv.Itoa(len(content)))
 	http.ServeContent(ctx.Writer, ctx.Request, name, modtime, bytes.NewReader(content))
 }
@@ -199,6 +204,44 @@ func (ctx *Context) WriteHeader(statusCode int) {
 	ctx.Writer.WriteHeader(statusCode)
 }
 
+// Push initiates an HTTP/2 server push of the target (see http.Pusher). It is a no-op when the underlying writer
+// does not support server push (ex. HTTP/1.x connections or a client that disabled push).
+//
+// ## Example
+//
+//	ctx.Push("/static/app.css", nil)
+//	ctx.Push("/static/app.js", &http.PushOptions{Header: http.Header{"Accept-Encoding": {"gzip"}}})
+func (ctx *Context) Push(target string, opts *http.PushOptions) error {
+	writer := ctx.Writer
+	if spy, ok := writer.(*ResponseWriterSpy); ok {
+		writer = spy.ResponseWriter
+	}
+	if pusher, ok := writer.(http.Pusher); ok {
+		if err := pusher.Push(target, opts); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+	return nil
+}
+
+// EarlyHints sends a 103 Early Hints informational response with the given Link headers, allowing the client to
+// preload resources while the final response is prepared. It is a no-op if the response has already been started or
+// the request is not HTTP/1.1 or later.
+//
+// ## Example
+//
+//	ctx.EarlyHints("</static/app.css>; rel=preload; as=style", "</static/app.js>; rel=preload; as=script")
+func (ctx *Context) EarlyHints(links ...string) {
+	if len(links) == 0 || ctx.WriteStarted() || !ctx.Request.ProtoAtLeast(1, 1) {
+		return
+	}
+	header := ctx.Writer.Header()
+	for _, link := range links {
+		header.Add("Link", link)
+	}
+	ctx.Writer.WriteHeader(http.StatusEarlyHints)
+}
+
 // WriteHeader sends an HTTP response header with the provided status code.
 func (ctx *Context) Status(statusCode int) {
 	ctx.WriteHeader(statusCode)
@@ -241,8 +284,12 @@ func (ctx *Context) Forbidden() {
 	ctx.Error("403 Forbidden", http.StatusForbidden)
 }
 
-// NotFound replies to the request with an HTTP 404 not found error.
+// NotFound replies to the request with an HTTP 404 not found error, using the error page of the router if defined (see
+// Router.ErrorPage).
 func (ctx *Context) NotFound() {
+	if ctx.router != nil && ctx.router.renderErrorPage(ctx, http.StatusNotFound, nil) {
+		return
+	}
 	http.NotFound(ctx.Writer, ctx.Request)
 }
 
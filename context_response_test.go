@@ -0,0 +1,142 @@
+package chain
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+type pusherResponseWriter struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (w *pusherResponseWriter) Push(target string, opts *http.PushOptions) error {
+	w.pushed = append(w.pushed, target)
+	return nil
+}
+
+func Test_Context_Push(t *testing.T) {
+	router := New()
+	router.GET("/", func(ctx *Context) error {
+		if err := ctx.Push("/app.css", nil); err != nil {
+			t.Errorf("Push() failed: unexpected error: %v", err)
+		}
+		return nil
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+
+	// supported
+	w := &pusherResponseWriter{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, req)
+	if len(w.pushed) != 1 || w.pushed[0] != "/app.css" {
+		t.Errorf("Push() failed: Invalid pushed\n   actual: %v\n expected: %v", w.pushed, []string{"/app.css"})
+	}
+
+	// not supported, no-op
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func Test_Context_EarlyHints(t *testing.T) {
+	router := New()
+	router.GET("/", func(ctx *Context) error {
+		ctx.EarlyHints("</app.css>; rel=preload; as=style")
+		ctx.Write([]byte("ok"))
+		return nil
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, header.Get("Link"))
+			}
+			return nil
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("EarlyHints() failed: Invalid Code\n   actual: %v\n expected: %v", res.StatusCode, http.StatusOK)
+	}
+	if len(hints) != 1 || hints[0] != "</app.css>; rel=preload; as=style" {
+		t.Errorf("EarlyHints() failed: Invalid hints\n   actual: %v", hints)
+	}
+}
+
+func Test_ResponseWriterSpy_Passthrough(t *testing.T) {
+	var hookCalled bool
+
+	router := New()
+	router.GET("/flush", func(ctx *Context) error {
+		ctx.BeforeSend(func() { hookCalled = true })
+		ctx.Writer.(http.Flusher).Flush()
+		return nil
+	})
+	router.GET("/controller", func(ctx *Context) error {
+		return http.NewResponseController(ctx.Writer).Flush()
+	})
+	router.GET("/hijack", func(ctx *Context) error {
+		conn, _, err := ctx.Writer.(http.Hijacker).Hijack()
+		if err != nil {
+			return err
+		}
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked"))
+		return conn.Close()
+	})
+	router.GET("/readfrom", func(ctx *Context) error {
+		_, err := ctx.Writer.(io.ReaderFrom).ReadFrom(strings.NewReader("content"))
+		return err
+	})
+
+	// flush
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/flush", nil)
+	router.ServeHTTP(w, req)
+	if !w.Flushed || !hookCalled {
+		t.Errorf("Flush() failed: Flushed %v, hook called %v", w.Flushed, hookCalled)
+	}
+
+	// unwrap (http.ResponseController)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/controller", nil)
+	router.ServeHTTP(w, req)
+	if !w.Flushed || w.Code != http.StatusOK {
+		t.Errorf("ResponseController failed: Flushed %v, Code %v", w.Flushed, w.Code)
+	}
+
+	// read from
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/readfrom", nil)
+	router.ServeHTTP(w, req)
+	if w.Body.String() != "content" {
+		t.Errorf("ReadFrom() failed: Invalid Body\n   actual: %v\n expected: %v", w.Body.String(), "content")
+	}
+
+	// hijack
+	server := httptest.NewServer(router)
+	defer server.Close()
+	res, err := http.Get(server.URL + "/hijack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "hijacked" {
+		t.Errorf("Hijack() failed: Invalid Body\n   actual: %v\n expected: %v", string(body), "hijacked")
+	}
+}
@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	bindings             = map[string]Binding{}
+	serializers          = map[string]Serializer{"application/json": jsonSerializer}
+	serializersMutex     sync.RWMutex
+	ErrSerializerMissing = errors.New("no serializer registered for the content type")
+)
+
+// RegisterBinding registers the binding of the request bodies with the Content-Type, used by BindingDefault (see
+// Context.Bind). Replaces the binding previously registered (or the built-in binding) of the Content-Type.
+//
+// ## Example
+//
+//	chain.RegisterBinding("application/x-protobuf", chain.BindingFunc(func(ctx *chain.Context, obj any) error {
+//		body, err := ctx.BodyBytes()
+//		if err != nil {
+//			return err
+//		}
+//		return proto.Unmarshal(body, obj.(proto.Message))
+//	}))
+func RegisterBinding(contentType string, binding Binding) {
+	serializersMutex.Lock()
+	defer serializersMutex.Unlock()
+	bindings[strings.ToLower(contentType)] = binding
+}
+
+// RegisterSerializer registers the Serializer of the Content-Type, used to bind the request bodies (see
+// RegisterBinding and BindingSerializer) and to render the responses (see Context.Render).
+//
+// ## Example
+//
+//	chain.RegisterSerializer("application/msgpack", &MsgpackSerializer{})
+//
+//	router.POST("/events", func(ctx *chain.Context) error {
+//		event := &Event{}
+//		if err := ctx.Bind(event); err != nil { // Content-Type: application/msgpack
+//			return err
+//		}
+//		return ctx.Render("application/msgpack", event)
+//	})
+func RegisterSerializer(contentType string, serializer Serializer) {
+	contentType = strings.ToLower(contentType)
+	serializersMutex.Lock()
+	defer serializersMutex.Unlock()
+	serializers[contentType] = serializer
+	bindings[contentType] = BindingSerializer(serializer)
+}
+
+// GetSerializer get the Serializer registered for the Content-Type (params are ignored)
+func GetSerializer(contentType string) Serializer {
+	serializersMutex.RLock()
+	defer serializersMutex.RUnlock()
+	return serializers[strings.ToLower(filterFlags(contentType))]
+}
+
+// getBinding get the Binding registered for the Content-Type
+func getBinding(contentType string) Binding {
+	serializersMutex.RLock()
+	defer serializersMutex.RUnlock()
+	return bindings[strings.ToLower(contentType)]
+}
+
+// BindingFunc an adapter to allow the use of ordinary functions as Binding
+type BindingFunc func(ctx *Context, obj any) error
+
+func (f BindingFunc) Bind(ctx *Context, obj any) error {
+	return f(ctx, obj)
+}
+
+// BindingSerializer Binding that decodes the request body with the Serializer
+func BindingSerializer(serializer Serializer) Binding {
+	return BindingFunc(func(ctx *Context, obj any) error {
+		body, err := ctx.BodyBytes()
+		if err != nil {
+			return err
+		}
+		_, err = serializer.Decode(body, obj)
+		return err
+	})
+}
+
+// Render encodes the value with the Serializer registered for the Content-Type (see RegisterSerializer) and writes it
+// as the response, with the Content-Type header. Returns ErrSerializerMissing if there is no serializer for the type.
+//
+// Combined with WithProduces, the response follows the Accept header of the request:
+//
+//	router.GET("/users/:id", func(ctx *chain.Context) error {
+//		return ctx.Render(ctx.NegotiatedType(), users.Get(ctx.GetParam("id")))
+//	}, chain.WithProduces("application/json", "application/msgpack"))
+func (ctx *Context) Render(contentType string, v any) error {
+	serializer := GetSerializer(contentType)
+	if serializer == nil {
+		return ErrSerializerMissing
+	}
+	encoded, err := serializer.Encode(v)
+	if err != nil {
+		return err
+	}
+	ctx.SetHeader("Content-Type", contentType)
+	ctx.ServeContent(encoded, "", UnixEpoch)
+	return nil
+}
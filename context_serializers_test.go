@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type kvPayload struct {
+	Name string
+}
+
+// kvSerializer test serializer, "name=<value>"
+type kvSerializer struct{}
+
+func (s *kvSerializer) Encode(v any) ([]byte, error) {
+	return []byte("name=" + v.(*kvPayload).Name), nil
+}
+
+func (s *kvSerializer) Decode(data []byte, v any) (any, error) {
+	value, found := strings.CutPrefix(string(data), "name=")
+	if !found {
+		return nil, errors.New("invalid kv")
+	}
+	v.(*kvPayload).Name = value
+	return v, nil
+}
+
+func Test_Context_Bind_RegisterSerializer(t *testing.T) {
+	RegisterSerializer("application/x-kv", &kvSerializer{})
+	defer func() {
+		serializersMutex.Lock()
+		delete(serializers, "application/x-kv")
+		delete(bindings, "application/x-kv")
+		serializersMutex.Unlock()
+	}()
+
+	router := New()
+	router.POST("/echo", func(ctx *Context) error {
+		payload := &kvPayload{}
+		if err := ctx.Bind(payload); err != nil {
+			return nil
+		}
+		payload.Name = strings.ToUpper(payload.Name)
+		return ctx.Render(ctx.NegotiatedType(), payload)
+	}, WithProduces("application/x-kv", "application/json"))
+
+	req, _ := http.NewRequest(http.MethodPost, "/echo", strings.NewReader("name=alex"))
+	req.Header.Set("Content-Type", "application/x-kv; charset=utf-8")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "name=ALEX" {
+		t.Errorf("Render() failed: Invalid response\n   actual: %v %q\n expected: %v %q", w.Code, w.Body.String(), http.StatusOK, "name=ALEX")
+	}
+	if ctype := w.Header().Get("Content-Type"); ctype != "application/x-kv" {
+		t.Errorf("Render() failed: Invalid Content-Type\n   actual: %v\n expected: %v", ctype, "application/x-kv")
+	}
+
+	// json response, negotiated
+	req, _ = http.NewRequest(http.MethodPost, "/echo", strings.NewReader("name=alex"))
+	req.Header.Set("Content-Type", "application/x-kv")
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if expected := `{"Name":"ALEX"}`; w.Body.String() != expected {
+		t.Errorf("Render() failed: Invalid response\n   actual: %q\n expected: %q", w.Body.String(), expected)
+	}
+
+	// invalid body
+	req, _ = http.NewRequest(http.MethodPost, "/echo", strings.NewReader("alex"))
+	req.Header.Set("Content-Type", "application/x-kv")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Bind() failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusBadRequest)
+	}
+
+	ctx := &Context{}
+	if err := ctx.Render("application/x-unknown", nil); !errors.Is(err, ErrSerializerMissing) {
+		t.Errorf("Render() failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrSerializerMissing)
+	}
+}
@@ -0,0 +1,196 @@
+package chain
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Context_WithParams(t *testing.T) {
+	ctx := &Context{}
+	ctx.addParameter("id", "1")
+	ctx.addParameter("name", "gopher")
+	ctx.addParameter("format", "xml")
+
+	child := ctx.WithParams([]string{"format", "page"}, []string{"json", "2"})
+
+	expected := map[string]string{"id": "1", "name": "gopher", "format": "json", "page": "2"}
+	for name, value := range expected {
+		if actual := child.GetParam(name); actual != value {
+			t.Errorf("ctx.WithParams() failed: Invalid Param %s\n   actual: %v\n expected: %v", name, actual, value)
+		}
+	}
+	if child.paramCount != 4 {
+		t.Errorf("ctx.WithParams() failed: Invalid Param Count\n   actual: %v\n expected: %v", child.paramCount, 4)
+	}
+
+	// parent is not modified
+	if actual := ctx.GetParam("format"); actual != "xml" {
+		t.Errorf("ctx.WithParams() failed: parent modified\n   actual: %v\n expected: %v", actual, "xml")
+	}
+	if actual := ctx.GetParam("page"); actual != "" {
+		t.Errorf("ctx.WithParams() failed: parent modified\n   actual: %v\n expected: %v", actual, "")
+	}
+
+	// data is shared with the parent
+	child.Set("key", "value")
+	if value, exists := ctx.Get("key"); !exists || value != "value" {
+		t.Errorf("ctx.WithParams() failed: Invalid Data\n   actual: %v\n expected: %v", value, "value")
+	}
+}
+
+func Test_Context_WithParam_Chaining(t *testing.T) {
+	ctx := &Context{}
+	child := ctx.WithParam("a", "1").WithParam("b", "2").WithParam("a", "3")
+
+	if actual := child.GetParam("a"); actual != "3" {
+		t.Errorf("ctx.WithParam() failed: Invalid Param\n   actual: %v\n expected: %v", actual, "3")
+	}
+	if actual := child.GetParam("b"); actual != "2" {
+		t.Errorf("ctx.WithParam() failed: Invalid Param\n   actual: %v\n expected: %v", actual, "2")
+	}
+	if child.paramCount != 2 {
+		t.Errorf("ctx.WithParam() failed: Invalid Param Count\n   actual: %v\n expected: %v", child.paramCount, 2)
+	}
+
+	child.Set("key", "value")
+	if _, exists := ctx.Get("key"); !exists {
+		t.Errorf("ctx.WithParam() failed: data not shared with the root context")
+	}
+}
+
+func Test_Context_SetParam(t *testing.T) {
+	ctx := &Context{}
+	ctx.SetParam("id", "1")
+	ctx.SetParam("id", "2")
+
+	if actual := ctx.GetParam("id"); actual != "2" {
+		t.Errorf("ctx.SetParam() failed: Invalid Param\n   actual: %v\n expected: %v", actual, "2")
+	}
+	if ctx.paramCount != 1 {
+		t.Errorf("ctx.SetParam() failed: Invalid Param Count\n   actual: %v\n expected: %v", ctx.paramCount, 1)
+	}
+
+	for i := 0; i < 40; i++ {
+		ctx.SetParam("p"+string(rune('a'+i)), "v")
+	}
+	if ctx.paramCount != 32 {
+		t.Errorf("ctx.SetParam() failed: Invalid Param Count\n   actual: %v\n expected: %v", ctx.paramCount, 32)
+	}
+}
+
+func Test_Context_WithParams_Middleware_Data(t *testing.T) {
+	router := New()
+	router.Use("/user/:id", func(ctx *Context) {
+		ctx.Set("user", ctx.GetParam("id"))
+	})
+	var user any
+	router.GET("/user/:id", func(ctx *Context) error {
+		user, _ = ctx.Get("user")
+		return nil
+	})
+
+	PerformRequest(router, http.MethodGet, "/user/10")
+
+	if user != "10" {
+		t.Errorf("ctx.WithParams() failed: Invalid Data\n   actual: %v\n expected: %v", user, "10")
+	}
+}
+
+func Test_Context_QueryParams(t *testing.T) {
+	req, _ := http.NewRequest("GET", "/?id=1&id=2&page=3&active=true&ratio=0.5&from=2024-01-31&filter[name]=john&filter[status]=active&filters=x&bad=x", nil)
+	ctx := &Context{Request: req}
+
+	if actual := ctx.QueryParam("page"); actual != "3" {
+		t.Errorf("ctx.QueryParam() failed: Invalid Value\n   actual: %v\n expected: %v", actual, "3")
+	}
+	if actual := ctx.QueryParam("missing", "default"); actual != "default" {
+		t.Errorf("ctx.QueryParam() failed: Invalid Default\n   actual: %v\n expected: %v", actual, "default")
+	}
+	if actual := ctx.QueryParams("id"); !reflect.DeepEqual(actual, []string{"1", "2"}) {
+		t.Errorf("ctx.QueryParams() failed: Invalid Values\n   actual: %v\n expected: %v", actual, []string{"1", "2"})
+	}
+	expectedMap := map[string]string{"name": "john", "status": "active"}
+	if actual := ctx.QueryMap("filter"); !reflect.DeepEqual(actual, expectedMap) {
+		t.Errorf("ctx.QueryMap() failed: Invalid Values\n   actual: %v\n expected: %v", actual, expectedMap)
+	}
+	if actual := ctx.QueryParamInt("page"); actual != 3 {
+		t.Errorf("ctx.QueryParamInt() failed: Invalid Value\n   actual: %v\n expected: %v", actual, 3)
+	}
+	if actual := ctx.QueryParamInt("bad", 10); actual != 10 {
+		t.Errorf("ctx.QueryParamInt() failed: Invalid Default\n   actual: %v\n expected: %v", actual, 10)
+	}
+	if actual := ctx.QueryParamBool("active"); !actual {
+		t.Errorf("ctx.QueryParamBool() failed: Invalid Value\n   actual: %v\n expected: %v", actual, true)
+	}
+	if actual := ctx.QueryParamFloat("ratio"); actual != 0.5 {
+		t.Errorf("ctx.QueryParamFloat() failed: Invalid Value\n   actual: %v\n expected: %v", actual, 0.5)
+	}
+	expectedTime := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	if actual := ctx.QueryParamTime("from", time.DateOnly); !actual.Equal(expectedTime) {
+		t.Errorf("ctx.QueryParamTime() failed: Invalid Value\n   actual: %v\n expected: %v", actual, expectedTime)
+	}
+
+	// cached until the query changes
+	ctx.QueryValues().Set("page", "4")
+	if actual := ctx.QueryParam("page"); actual != "4" {
+		t.Errorf("ctx.QueryValues() failed: Not Cached\n   actual: %v\n expected: %v", actual, "4")
+	}
+	req.URL.RawQuery = "page=5"
+	if actual := ctx.QueryParam("page"); actual != "5" {
+		t.Errorf("ctx.QueryValues() failed: Invalid Cache\n   actual: %v\n expected: %v", actual, "5")
+	}
+}
+
+func Test_Context_AfterSend(t *testing.T) {
+	handlerErr := errors.New("handler error")
+
+	var infos []ResponseInfo
+	var signature string
+
+	router := New()
+	router.Recovery = &Recovery{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	router.Use(func(ctx *Context) {
+		ctx.AfterSend(func() { signature += "A" })
+		ctx.AfterSendInfo(func(info ResponseInfo) {
+			signature += "B"
+			infos = append(infos, info)
+		})
+	})
+	router.GET("/ok", func(ctx *Context) {
+		ctx.Write([]byte("hello"))
+	})
+	router.GET("/error", func(ctx *Context) error {
+		return handlerErr
+	})
+	router.GET("/panic", func(ctx *Context) {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/ok", "/error", "/panic"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if signature != "BABABA" {
+		t.Errorf("AfterSend() failed: Invalid signature\n   actual: %v\n expected: %v", signature, "BABABA")
+	}
+	if len(infos) != 3 {
+		t.Fatalf("AfterSend() failed: Invalid infos\n   actual: %v\n expected: %v", len(infos), 3)
+	}
+	if info := infos[0]; info.Status != http.StatusOK || info.Bytes != 5 || info.Error != nil || info.Duration <= 0 {
+		t.Errorf("AfterSend() failed: Invalid info (ok)\n   actual: %+v", info)
+	}
+	if info := infos[1]; info.Status != http.StatusInternalServerError || !errors.Is(info.Error, handlerErr) {
+		t.Errorf("AfterSend() failed: Invalid info (error)\n   actual: %+v", info)
+	}
+	var panicErr *PanicInfo
+	if info := infos[2]; info.Status != http.StatusInternalServerError || !errors.As(info.Error, &panicErr) || panicErr.Value != "boom" {
+		t.Errorf("AfterSend() failed: Invalid info (panic)\n   actual: %+v", info)
+	}
+}
@@ -0,0 +1,47 @@
+package chain
+
+import (
+	"path"
+	"strings"
+)
+
+// GetWildcard gets the remainder of the path matched by the wildcard param (ex. "*filepath"), without the leading
+// slash, safe to be joined to a directory (see filepath.Join).
+//
+// The value is normalized to prevent path traversal: backslashes are treated as separators, the dot segments ("." and
+// "..") are resolved without going above the wildcard, and repeated slashes are removed. Values with NUL bytes result
+// in an empty string. See Router.CleanWildcard to apply the same normalization to Context.GetParam.
+//
+// ## Example
+//
+//	router.GET("/assets/*filepath", func(ctx *chain.Context) error {
+//		// "/assets/js/../../app.js" -> "app.js"
+//		return ctx.File(filepath.Join("./public", ctx.GetWildcard("filepath")))
+//	})
+func (ctx *Context) GetWildcard(name string) string {
+	return CleanWildcard(ctx.GetParam(name))
+}
+
+// CleanWildcard normalizes the remainder of a path matched by a wildcard, see Context.GetWildcard
+//
+// ## Example
+//
+//	CleanWildcard("/js//app.js")       // "js/app.js"
+//	CleanWildcard("/../../etc/passwd") // "etc/passwd"
+//	CleanWildcard(`/..\..\app.js`)     // "app.js"
+func CleanWildcard(value string) string {
+	if value == "" || strings.IndexByte(value, 0) >= 0 {
+		return ""
+	}
+	value = strings.ReplaceAll(value, "\\", "/")
+	// rooted, the ".." can't go above the wildcard
+	return strings.TrimPrefix(path.Clean("/"+value), "/")
+}
+
+// wildcardValue the value of the wildcard param, see Router.CleanWildcard
+func (ctx *Context) wildcardValue(value string) string {
+	if ctx.router != nil && ctx.router.CleanWildcard {
+		return CleanWildcard(value)
+	}
+	return value
+}
@@ -0,0 +1,54 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_CleanWildcard(t *testing.T) {
+	tests := map[string]string{
+		"":                   "",
+		"/":                  "",
+		"/inc/framework.js":  "inc/framework.js",
+		"/js//app.js":        "js/app.js",
+		"/js/./app.js":       "js/app.js",
+		"/js/../app.js":      "app.js",
+		"/../../etc/passwd":  "etc/passwd",
+		`/..\..\etc\passwd`:  "etc/passwd",
+		"/docs/":             "docs",
+		"/file\x00.txt":      "",
+		"/a/b/../../../../c": "c",
+	}
+	for value, expected := range tests {
+		if actual := CleanWildcard(value); actual != expected {
+			t.Errorf("CleanWildcard(%q) failed: Invalid value\n   actual: %v\n expected: %v", value, actual, expected)
+		}
+	}
+}
+
+func Test_Context_GetWildcard(t *testing.T) {
+	var param, wildcard string
+
+	router := New()
+	router.GET("/assets/*filepath", func(ctx *Context) {
+		param = ctx.GetParam("filepath")
+		wildcard = ctx.GetWildcard("filepath")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/assets/inc/framework.js", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if param != "/inc/framework.js" {
+		t.Errorf("GetParam() failed: Invalid value\n   actual: %v\n expected: %v", param, "/inc/framework.js")
+	}
+	if wildcard != "inc/framework.js" {
+		t.Errorf("GetWildcard() failed: Invalid value\n   actual: %v\n expected: %v", wildcard, "inc/framework.js")
+	}
+
+	// Router.CleanWildcard
+	router.CleanWildcard = true
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if param != "inc/framework.js" {
+		t.Errorf("GetParam() failed: Invalid value with CleanWildcard\n   actual: %v\n expected: %v", param, "inc/framework.js")
+	}
+}
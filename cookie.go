@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	CookiePrefixHost   = "__Host-"   // Cookie must be Secure, with Path "/" and without Domain
+	CookiePrefixSecure = "__Secure-" // Cookie must be Secure
+)
+
+// CookieOptions app-wide defaults of the cookies created by Context.SetSecureCookie (including the session cookies,
+// see middlewares/session). Fields of the cookie that are set take precedence.
+//
+// ## Example
+//
+//	router := chain.New()
+//	router.Cookie = chain.CookieOptions{Domain: "example.com", SameSite: http.SameSiteStrictMode}
+type CookieOptions struct {
+	Path         string        // Default "/"
+	Domain       string        // Default host only
+	SameSite     http.SameSite // Default http.SameSiteLaxMode
+	Secure       bool          // Always set the Secure flag. By default, it is inferred from the request
+	ScriptAccess bool          // Allows scripts to read the cookies (no HttpOnly flag). Default false
+}
+
+// SetSecureCookie adds a Set-Cookie header with safe defaults, using the Router.Cookie options for the unset fields:
+//
+//   - SameSite is Lax, unless defined
+//   - HttpOnly is set, unless CookieOptions.ScriptAccess is enabled
+//   - Secure is set if the request is over TLS (or "X-Forwarded-Proto: https", when behind a proxy)
+//   - Cookies with the "__Secure-" prefix are always Secure, "__Host-" are always Secure, with Path "/" and without
+//     Domain, as required by the browsers
+//
+// Returns an error if the cookie is invalid (see http.Cookie.Valid), in that case the cookie is not sent.
+//
+// ## Example
+//
+//	err := ctx.SetSecureCookie(&http.Cookie{Name: "__Host-token", Value: token, MaxAge: 3600})
+func (ctx *Context) SetSecureCookie(cookie *http.Cookie) error {
+	var options CookieOptions
+	if ctx.router != nil {
+		options = ctx.router.Cookie
+	}
+
+	if cookie.Path == "" {
+		cookie.Path = options.Path
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+	}
+	if cookie.Domain == "" {
+		cookie.Domain = options.Domain
+	}
+	if cookie.SameSite == 0 {
+		cookie.SameSite = options.SameSite
+		if cookie.SameSite == 0 {
+			cookie.SameSite = http.SameSiteLaxMode
+		}
+	}
+	if !options.ScriptAccess {
+		cookie.HttpOnly = true
+	}
+	if !cookie.Secure {
+		cookie.Secure = options.Secure || ctx.IsSecure() || cookie.SameSite == http.SameSiteNoneMode
+	}
+
+	if strings.HasPrefix(cookie.Name, CookiePrefixHost) {
+		cookie.Secure = true
+		cookie.Path = "/"
+		cookie.Domain = ""
+	} else if strings.HasPrefix(cookie.Name, CookiePrefixSecure) {
+		cookie.Secure = true
+	}
+
+	if err := cookie.Valid(); err != nil {
+		return err
+	}
+	http.SetCookie(ctx.Writer, cookie)
+	return nil
+}
+
+// IsSecure checks if the request was made over TLS, directly or through a proxy ("X-Forwarded-Proto: https")
+func (ctx *Context) IsSecure() bool {
+	if ctx.Request == nil {
+		return false
+	}
+	if ctx.Request.TLS != nil {
+		return true
+	}
+	return strings.EqualFold(ctx.Request.Header.Get("X-Forwarded-Proto"), "https")
+}
@@ -0,0 +1,56 @@
+package chain
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Context_SetSecureCookie(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  CookieOptions
+		cookie   *http.Cookie
+		tls      bool
+		proto    string
+		expected string
+	}{
+		{"defaults", CookieOptions{}, &http.Cookie{Name: "a", Value: "1"}, false, "",
+			"a=1; Path=/; HttpOnly; SameSite=Lax"},
+		{"tls", CookieOptions{}, &http.Cookie{Name: "a", Value: "1"}, true, "",
+			"a=1; Path=/; HttpOnly; Secure; SameSite=Lax"},
+		{"proxy", CookieOptions{}, &http.Cookie{Name: "a", Value: "1"}, false, "https",
+			"a=1; Path=/; HttpOnly; Secure; SameSite=Lax"},
+		{"router options", CookieOptions{Domain: "example.com", SameSite: http.SameSiteStrictMode, ScriptAccess: true},
+			&http.Cookie{Name: "a", Value: "1"}, false, "",
+			"a=1; Path=/; Domain=example.com; SameSite=Strict"},
+		{"cookie fields", CookieOptions{Path: "/app"}, &http.Cookie{Name: "a", Value: "1", Path: "/api", MaxAge: 60}, false, "",
+			"a=1; Path=/api; Max-Age=60; HttpOnly; SameSite=Lax"},
+		{"host prefix", CookieOptions{Domain: "example.com"}, &http.Cookie{Name: "__Host-a", Value: "1", Path: "/api"}, false, "",
+			"__Host-a=1; Path=/; HttpOnly; Secure; SameSite=Lax"},
+		{"secure prefix", CookieOptions{Domain: "example.com"}, &http.Cookie{Name: "__Secure-a", Value: "1"}, false, "",
+			"__Secure-a=1; Path=/; Domain=example.com; HttpOnly; Secure; SameSite=Lax"},
+	}
+	for _, tt := range tests {
+		router := New()
+		router.Cookie = tt.options
+		router.GET("/", func(ctx *Context) error {
+			return ctx.SetSecureCookie(tt.cookie)
+		})
+
+		req, _ := http.NewRequest("GET", "/", nil)
+		if tt.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		if tt.proto != "" {
+			req.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if actual := w.Header().Get("Set-Cookie"); actual != tt.expected {
+			t.Errorf("ctx.SetSecureCookie() failed: Invalid Cookie (%s)\n   actual: %v\n expected: %v", tt.name, actual, tt.expected)
+		}
+	}
+}
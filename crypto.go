@@ -9,86 +9,115 @@ import (
 
 type SecretKeySyncFunc func(key string)
 
-var (
-	// secretKeys stores the key data received by SetSecretKeyBase() function. It is ordered in such a way where the last
-	// key (index len(secretKeys)-1) is the primary key (the most recent key for rotation)
-	secretKeys         [][]byte
-	secretKeysMutex    = sync.RWMutex{}
-	secretKeySync      []SecretKeySyncFunc
-	secretKeySyncMutex = sync.RWMutex{}
-)
+// secretKeys global SecretKeyBase, used by the routers that do not define their own (see Router.SetSecretKeyBase)
+var secretKeys = &secretKeyStore{}
 
-// SetSecretKeyBase see SecretKeyBase()
-func SetSecretKeyBase(secret string) error {
+// secretKeyStore stores the key data received by SetSecretKeyBase. Keys are ordered in such a way where the last key
+// (index len(keys)-1) is the primary key (the most recent key for rotation)
+type secretKeyStore struct {
+	keys       [][]byte
+	keysMutex  sync.RWMutex
+	syncs      []*SecretKeySyncFunc
+	syncsMutex sync.RWMutex
+}
+
+func (s *secretKeyStore) set(secret string) error {
 	key := []byte(secret)
 	if err := crypto.ValidateKey(key); err != nil {
 		return err
 	}
 
-	secretKeysMutex.Lock()
-	defer secretKeysMutex.Unlock()
+	s.keysMutex.Lock()
+	defer s.keysMutex.Unlock()
 
-	if l := len(secretKeys); l > 0 && bytes.Equal(secretKeys[l-1], key) {
+	if l := len(s.keys); l > 0 && bytes.Equal(s.keys[l-1], key) {
 		return nil
 	}
-	secretKeys = append(secretKeys, key)
+	// a previous key becomes the primary key again (ex. A -> B -> A)
+	keys := make([][]byte, 0, len(s.keys)+1)
+	for _, installed := range s.keys {
+		if !bytes.Equal(installed, key) {
+			keys = append(keys, installed)
+		}
+	}
+	s.keys = append(keys, key)
 
-	secretKeySyncMutex.RLock()
-	defer secretKeySyncMutex.RUnlock()
-	for _, sync := range secretKeySync {
-		sync(secret)
+	s.syncsMutex.RLock()
+	defer s.syncsMutex.RUnlock()
+	for _, sync := range s.syncs {
+		(*sync)(secret)
 	}
 	return nil
 }
 
-// SecretKeyBase A secret key used to verify and encrypt data.
-//
-// This data must be never used directly, always use chain.Crypto().KeyGenerate() to derive keys from it
-func SecretKeyBase() string {
-	secretKeysMutex.RLock()
-	defer secretKeysMutex.RUnlock()
-	if l := len(secretKeys); l > 0 {
-		return string(secretKeys[l-1])
+func (s *secretKeyStore) primary() string {
+	s.keysMutex.RLock()
+	defer s.keysMutex.RUnlock()
+	if l := len(s.keys); l > 0 {
+		return string(s.keys[l-1])
 	}
 	return ""
 }
 
-// SecretKeys gets the list of all SecretKeyBase that have been defined. Can be used in key rotation algorithms
-//
-// The LAST item in the list is the most recent key (primary key)
-func SecretKeys() []string {
-	secretKeysMutex.RLock()
-	defer secretKeysMutex.RUnlock()
-	keys := make([]string, len(secretKeys))
-	l := len(keys) - 1
-	for i, key := range keys {
-		keys[l-i] = string(key)
+func (s *secretKeyStore) all() []string {
+	s.keysMutex.RLock()
+	defer s.keysMutex.RUnlock()
+	keys := make([]string, len(s.keys))
+	for i, key := range s.keys {
+		keys[i] = string(key)
 	}
 	return keys
 }
 
-// SecretKeySync is used to transmit SecretKeyBase changes
-func SecretKeySync(sync SecretKeySyncFunc) (cancel func()) {
+func (s *secretKeyStore) sync(sync SecretKeySyncFunc) (cancel func()) {
+	ref := &sync
 
 	cancel = func() {
-		secretKeySyncMutex.Lock()
-		var syncs []SecretKeySyncFunc
-		for _, s := range secretKeySync {
-			if &s != &sync {
+		s.syncsMutex.Lock()
+		var syncs []*SecretKeySyncFunc
+		for _, s := range s.syncs {
+			if s != ref {
 				syncs = append(syncs, s)
 			}
 		}
-		secretKeySync = syncs
-		secretKeySyncMutex.Unlock()
+		s.syncs = syncs
+		s.syncsMutex.Unlock()
 	}
 
-	secretKeySyncMutex.Lock()
-	secretKeySync = append(secretKeySync, sync)
-	secretKeySyncMutex.Unlock()
+	s.syncsMutex.Lock()
+	s.syncs = append(s.syncs, ref)
+	s.syncsMutex.Unlock()
 
-	for _, key := range SecretKeys() {
+	for _, key := range s.all() {
 		sync(key)
 	}
 
 	return cancel
 }
+
+// SetSecretKeyBase see SecretKeyBase()
+func SetSecretKeyBase(secret string) error {
+	return secretKeys.set(secret)
+}
+
+// SecretKeyBase A secret key used to verify and encrypt data.
+//
+// This data must be never used directly, always use chain.Crypto().KeyGenerate() to derive keys from it
+func SecretKeyBase() string {
+	return secretKeys.primary()
+}
+
+// SecretKeys gets the list of all SecretKeyBase that have been defined. Can be used in key rotation algorithms
+//
+// The LAST item in the list is the most recent key (primary key)
+func SecretKeys() []string {
+	return secretKeys.all()
+}
+
+// SecretKeySync is used to transmit SecretKeyBase changes.
+//
+// The sync function is immediately invoked with all the keys already defined (from the oldest to the most recent),
+// and then for each new key defined by SetSecretKeyBase.
+func SecretKeySync(sync SecretKeySyncFunc) (cancel func()) {
+	return secretKeys.sync(sync)
+}
@@ -65,6 +65,17 @@ func Test_MessageVerifier(t *testing.T) {
 	}
 }
 
+func Test_MessageVerifier_Malformed(t *testing.T) {
+	verifier := MessageVerifier{}
+	secret := []byte("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy")
+
+	for _, token := range []string{"", "invalid", "invalid.token"} {
+		if _, err := verifier.Verify(secret, []byte(token)); err == nil {
+			t.Errorf("MessageVerifier failed: expected error for malformed token %q", token)
+		}
+	}
+}
+
 func Test_MessageEncryptor(t *testing.T) {
 	encryptor := MessageEncryptor{}
 	generator := KeyGenerator{}
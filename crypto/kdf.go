@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF a key derivation function, with its parameters. See PBKDF2, Argon2id and Scrypt.
+type KDF interface {
+	// Derive returns a key of `length` octets derived from secret and salt
+	Derive(secret []byte, salt []byte, length int) ([]byte, error)
+	// String identifies the function and its parameters, used as part of the cache key
+	String() string
+}
+
+// PBKDF2 parameters of the PBKDF2 key derivation function (RFC 2898). Used by KeyGenerator.Generate
+type PBKDF2 struct {
+	Iterations int    // Defaults to 1000 (increase to at least 2^16 if used for passwords)
+	Digest     string // hmac function to use as the pseudo-random function. Defaults to `sha256`
+}
+
+func (p PBKDF2) Derive(secret []byte, salt []byte, length int) ([]byte, error) {
+	iterations := p.Iterations
+	if iterations < 1 {
+		iterations = 1000
+	}
+	sha2Func, _ := getSha2Func(p.Digest)
+	return pbkdf2.Key(secret, salt, iterations, length, sha2Func), nil
+}
+
+func (p PBKDF2) String() string {
+	iterations := p.Iterations
+	if iterations < 1 {
+		iterations = 1000
+	}
+	_, digest := getSha2Func(p.Digest)
+	return fmt.Sprintf("pbkdf2:%d:%s", iterations, digest)
+}
+
+// Argon2id parameters of the Argon2id key derivation function (RFC 9106). Memory-hard, recommended for passwords.
+type Argon2id struct {
+	Time    uint32 // Number of passes over the memory. Defaults to 1
+	Memory  uint32 // Memory size in KiB. Defaults to 64*1024 (64 MiB)
+	Threads uint8  // Degree of parallelism. Defaults to 4
+}
+
+func (p Argon2id) Derive(secret []byte, salt []byte, length int) ([]byte, error) {
+	if p.Time < 1 {
+		p.Time = 1
+	}
+	if p.Memory < 1 {
+		p.Memory = 64 * 1024
+	}
+	if p.Threads < 1 {
+		p.Threads = 4
+	}
+	return argon2.IDKey(secret, salt, p.Time, p.Memory, p.Threads, uint32(length)), nil
+}
+
+func (p Argon2id) String() string {
+	return fmt.Sprintf("argon2id:%d:%d:%d", p.Time, p.Memory, p.Threads)
+}
+
+// Scrypt parameters of the scrypt key derivation function (RFC 7914)
+type Scrypt struct {
+	N int // CPU/memory cost parameter, must be a power of two greater than 1. Defaults to 32768
+	R int // Block size. Defaults to 8
+	P int // Parallelization. Defaults to 1
+}
+
+func (p Scrypt) Derive(secret []byte, salt []byte, length int) ([]byte, error) {
+	if p.N < 2 {
+		p.N = 32768
+	}
+	if p.R < 1 {
+		p.R = 8
+	}
+	if p.P < 1 {
+		p.P = 1
+	}
+	return scrypt.Key(secret, salt, p.N, p.R, p.P, length)
+}
+
+func (p Scrypt) String() string {
+	return fmt.Sprintf("scrypt:%d:%d:%d", p.N, p.R, p.P)
+}
+
+// Derive returns a key derived from secret and salt using the given KDF (defaults to PBKDF2).
+//
+// When KeyGenerator.CacheSize is set, derivations are kept in the LRU cache of the generator by (secret, salt, kdf,
+// length), avoiding repeated expensive derivations (ex. per request).
+//
+// ## Example
+//
+//	generator := crypto.KeyGenerator{}
+//	key, err := generator.Derive(secret, salt, 32, crypto.Argon2id{Time: 1, Memory: 64 * 1024, Threads: 4})
+func (g *KeyGenerator) Derive(secret []byte, salt []byte, length int, kdf KDF) ([]byte, error) {
+	if length < 1 {
+		length = 32
+	}
+	if kdf == nil {
+		kdf = PBKDF2{}
+	}
+
+	cache := g.keyCache()
+	if cache == nil {
+		return kdf.Derive(secret, salt, length)
+	}
+
+	cacheKey := derivedKeyCacheKey(secret, salt, length, kdf)
+	if key, exists := cache.get(cacheKey); exists {
+		return bytes.Clone(key), nil
+	}
+
+	key, err := kdf.Derive(secret, salt, length)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(cacheKey, bytes.Clone(key))
+	return key, nil
+}
+
+// derivedKeyCacheKey hash of the inputs, so the secret is not kept in memory as the cache key
+func derivedKeyCacheKey(secret []byte, salt []byte, length int, kdf KDF) [sha256.Size]byte {
+	h := sha256.New()
+	var sizes [12]byte
+	binary.BigEndian.PutUint32(sizes[0:4], uint32(len(secret)))
+	binary.BigEndian.PutUint32(sizes[4:8], uint32(len(salt)))
+	binary.BigEndian.PutUint32(sizes[8:12], uint32(length))
+	h.Write(sizes[:])
+	h.Write(secret)
+	h.Write(salt)
+	h.Write([]byte(kdf.String()))
+	var out [sha256.Size]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
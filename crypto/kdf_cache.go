@@ -0,0 +1,71 @@
+package crypto
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// DefaultKeyCacheSize suggested KeyGenerator.CacheSize for generators that derive keys from server secrets
+const DefaultKeyCacheSize = 256
+
+type keyCacheEntry struct {
+	id  [sha256.Size]byte
+	key []byte
+}
+
+// keyCache thread safe LRU cache of derived keys
+type keyCache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // front is the most recently used
+}
+
+func newKeyCache(size int) *keyCache {
+	return &keyCache{
+		size:    size,
+		entries: map[[sha256.Size]byte]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+func (c *keyCache) get(id [sha256.Size]byte) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, exists := c.entries[id]; exists {
+		c.order.MoveToFront(element)
+		return element.Value.(*keyCacheEntry).key, true
+	}
+	return nil, false
+}
+
+func (c *keyCache) put(id [sha256.Size]byte, key []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if element, exists := c.entries[id]; exists {
+		c.order.MoveToFront(element)
+		element.Value.(*keyCacheEntry).key = key
+		return
+	}
+	c.entries[id] = c.order.PushFront(&keyCacheEntry{id: id, key: key})
+	c.evict()
+}
+
+func (c *keyCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// evict removes the least recently used entries, must be invoked with the lock held
+func (c *keyCache) evict() {
+	for c.order.Len() > 0 && c.order.Len() > c.size {
+		element := c.order.Back()
+		c.order.Remove(element)
+		delete(c.entries, element.Value.(*keyCacheEntry).id)
+	}
+}
@@ -0,0 +1,101 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_KeyGenerator_Derive(t *testing.T) {
+	generator := KeyGenerator{}
+	secret := []byte("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy")
+	salt := []byte("kdf.salt")
+
+	kdfs := []KDF{
+		PBKDF2{},
+		PBKDF2{Iterations: 10, Digest: "sha512"},
+		Argon2id{Time: 1, Memory: 1024, Threads: 1},
+		Scrypt{N: 1024, R: 8, P: 1},
+	}
+
+	var keys [][]byte
+	for _, kdf := range kdfs {
+		key, err := generator.Derive(secret, salt, 32, kdf)
+		if err != nil {
+			t.Fatalf("Derive() failed: %s, unexpected error: %v", kdf, err)
+		}
+		if len(key) != 32 {
+			t.Errorf("Derive() failed: %s, Invalid length\n   actual: %d\n expected: %d", kdf, len(key), 32)
+		}
+		for _, other := range keys {
+			if bytes.Equal(key, other) {
+				t.Errorf("Derive() failed: %s, must produce a different key", kdf)
+			}
+		}
+		keys = append(keys, key)
+
+		// deterministic (cached or not)
+		again, _ := kdf.Derive(secret, salt, 32)
+		if !bytes.Equal(key, again) {
+			t.Errorf("Derive() failed: %s, Invalid cached key", kdf)
+		}
+	}
+
+	// compatible with Generate
+	if !bytes.Equal(keys[0], generator.Generate(secret, salt, 0, 0, "")) {
+		t.Errorf("Derive() failed: PBKDF2 must be compatible with Generate")
+	}
+
+	// invalid scrypt params
+	if _, err := generator.Derive(secret, salt, 32, Scrypt{N: 1000}); err == nil {
+		t.Errorf("Derive() failed: expected error for invalid scrypt N")
+	}
+}
+
+func Test_KeyGenerator_Cache(t *testing.T) {
+	generator := &KeyGenerator{CacheSize: 2}
+	secret := []byte("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy")
+
+	for _, salt := range []string{"a", "b", "c", "a"} {
+		generator.Generate(secret, []byte(salt), 0, 0, "")
+	}
+	derivedKeys := generator.keyCache()
+	if size := derivedKeys.len(); size != 2 {
+		t.Errorf("KeyGenerator cache failed: Invalid size\n   actual: %d\n expected: %d", size, 2)
+	}
+
+	// "b" is the least recently used
+	if _, exists := derivedKeys.get(derivedKeyCacheKey(secret, []byte("b"), 32, PBKDF2{})); exists {
+		t.Errorf("KeyGenerator cache failed: least recently used entry not evicted")
+	}
+	if _, exists := derivedKeys.get(derivedKeyCacheKey(secret, []byte("a"), 32, PBKDF2{Iterations: 1000})); !exists {
+		t.Errorf("KeyGenerator cache failed: entry not found")
+	}
+
+	// disabled by default, each generator has its own cache
+	uncached := &KeyGenerator{}
+	uncached.Generate(secret, []byte("a"), 0, 0, "")
+	if uncached.keyCache() != nil {
+		t.Errorf("KeyGenerator cache failed: cache must be disabled by default")
+	}
+	if size := (&KeyGenerator{CacheSize: 2}).keyCache().len(); size != 0 {
+		t.Errorf("KeyGenerator cache failed: Invalid size\n   actual: %d\n expected: %d", size, 0)
+	}
+}
+
+func BenchmarkKeyGenerator_Generate(b *testing.B) {
+	generator := KeyGenerator{}
+	secret := []byte("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy")
+	salt := []byte("benchmark.salt")
+
+	b.Run("cached", func(b *testing.B) {
+		cached := &KeyGenerator{CacheSize: DefaultKeyCacheSize}
+		for i := 0; i < b.N; i++ {
+			cached.Generate(secret, salt, 0, 0, "")
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			generator.Generate(secret, salt, 0, 0, "")
+		}
+	})
+}
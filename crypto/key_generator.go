@@ -3,7 +3,7 @@
 
 package crypto
 
-import "golang.org/x/crypto/pbkdf2"
+import "sync"
 
 // KeyGenerator uses PBKDF2 (Password-Based Key Derivation Function 2), part of PKCS #5 v2.0 (Password-Based
 // Cryptography Specification).
@@ -14,8 +14,17 @@ import "golang.org/x/crypto/pbkdf2"
 // The returned key is a binary. You may invoke functions in the `base64` module, such as
 // `base64.StdEncoding.EncodeToString()`, to convert this binary into a textual representation.
 //
+// Other key derivation functions (Argon2id, scrypt) are available through KeyGenerator.Derive.
+//
 // See http://tools.ietf.org/html/rfc2898#section-5.2
 type KeyGenerator struct {
+	// CacheSize max number of derived keys kept in the LRU cache of this generator, avoiding repeated expensive
+	// derivations. Zero (default) disables the cache. Cached keys stay in memory and a cache hit is faster than a
+	// derivation, so do not enable it for generators that derive keys from passwords or other user input.
+	CacheSize int
+
+	cacheOnce sync.Once
+	cache     *keyCache
 }
 
 // Generate Returns a derived key suitable for use.
@@ -23,13 +32,20 @@ type KeyGenerator struct {
 //   - `iterations` - defaults to 1000 (increase to at least 2^16 if used for passwords);
 //   - `length`     - a length in octets for the derived key. Defaults to 32;
 //   - `digest`     - an hmac function to use as the pseudo-random function. Defaults to `sha256`;
+//
+// Derived keys are cached when KeyGenerator.CacheSize is set.
 func (g *KeyGenerator) Generate(secret []byte, salt []byte, iterations int, length int, digest string) []byte {
-	if iterations < 1 {
-		iterations = 1000
-	}
-	if length < 1 {
-		length = 32
+	key, _ := g.Derive(secret, salt, length, PBKDF2{Iterations: iterations, Digest: digest})
+	return key
+}
+
+// keyCache the cache of derived keys of this generator, nil when disabled
+func (g *KeyGenerator) keyCache() *keyCache {
+	if g.CacheSize <= 0 {
+		return nil
 	}
-	sha2Func, _ := getSha2Func(digest)
-	return pbkdf2.Key(secret, salt, iterations, length, sha2Func)
+	g.cacheOnce.Do(func() {
+		g.cache = newKeyCache(g.CacheSize)
+	})
+	return g.cache
 }
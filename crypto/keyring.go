@@ -3,7 +3,9 @@ package crypto
 import (
 	"bytes"
 	"errors"
+	"io"
 	"sync"
+	"time"
 )
 
 var (
@@ -20,46 +22,138 @@ type Keyring struct {
 	// message decryption.
 	keys [][]byte
 
+	// replacedAt stores when each key (same index of keys) stopped being the primary key. Zero for the primary key
+	replacedAt []time.Time
+
+	policy RotationPolicy
+
 	// The keyring lock is used while performing IO operations on the keyring.
 	mutex sync.RWMutex
 }
 
-// AddKey will install a new key on the ring. Adding a key to the ring will make it available for use in decryption. If
-// the key already exists on the ring, this function will just return noop.
+// RotationPolicy bounds the number of keys on a Keyring, limiting the decryption attempts and memory.
+//
+// The primary key is never evicted.
+type RotationPolicy struct {
+	MaxKeys int              // Max number of keys on the ring, the oldest keys are evicted. Zero means unlimited
+	MaxAge  time.Duration    // How long a key is kept after being replaced as the primary key. Zero means forever
+	OnEvict func(key []byte) // Invoked when a key is removed from the ring
+}
+
+// SetRotationPolicy defines the RotationPolicy of this Keyring, evicting the keys that no longer satisfy the policy.
+//
+// ## Example
+//
+//	keyring := chain.NewKeyring("my.salt", 1000, 32, "sha256")
+//	keyring.SetRotationPolicy(crypto.RotationPolicy{
+//		MaxKeys: 3,
+//		MaxAge:  7 * 24 * time.Hour,
+//		OnEvict: func(key []byte) {
+//			slog.Info("key evicted from keyring")
+//		},
+//	})
+func (k *Keyring) SetRotationPolicy(policy RotationPolicy) {
+	k.mutex.Lock()
+	k.policy = policy
+	evicted, onEvict := k.prune()
+	k.mutex.Unlock()
+
+	notifyEvicted(evicted, onEvict)
+}
+
+// AddKey will install a new key on the ring as the primary key. Adding a key to the ring will make it available for
+// use in decryption. If the key already exists on the ring, it is moved to the front and becomes the primary key again
+// (ex. rotating the keys A -> B -> A).
 func (k *Keyring) AddKey(key []byte) error {
 	if err := ValidateKey(key); err != nil {
 		return err
 	}
 	k.mutex.Lock()
-	defer k.mutex.Unlock()
 
-	if k.keys == nil {
-		k.keys = make([][]byte, 0)
-	}
-
-	// No-op if key is already installed
-	for _, installedKey := range k.keys {
+	installed := -1
+	for i, installedKey := range k.keys {
 		if bytes.Equal(installedKey, key) {
-			return nil
+			installed = i
+			break
 		}
 	}
 
-	var primaryKey []byte
-	if len(k.keys) > 0 {
-		primaryKey = k.keys[0]
+	// No-op if key is already the primary key
+	if installed == 0 {
+		k.mutex.Unlock()
+		return nil
 	}
-	if primaryKey == nil {
-		primaryKey = key
+
+	if len(k.replacedAt) > 0 {
+		k.replacedAt[0] = time.Now()
+	}
+	keys := make([][]byte, 0, len(k.keys)+1)
+	replacedAt := make([]time.Time, 0, len(k.keys)+1)
+	keys = append(keys, key)
+	replacedAt = append(replacedAt, time.Time{})
+	for i := range k.keys {
+		if i != installed {
+			keys = append(keys, k.keys[i])
+			replacedAt = append(replacedAt, k.replacedAt[i])
+		}
 	}
-	k.keys = append([][]byte{key}, k.keys...)
+	k.keys = keys
+	k.replacedAt = replacedAt
+
+	evicted, onEvict := k.prune()
+	k.mutex.Unlock()
+
+	notifyEvicted(evicted, onEvict)
 	return nil
 }
 
 // GetKeys returns the current set of keys on the ring.
 func (k *Keyring) GetKeys() [][]byte {
 	k.mutex.RLock()
-	defer k.mutex.RUnlock()
-	return k.keys
+	expired := k.policy.MaxAge > 0 && len(k.keys) > 1 &&
+		time.Since(k.replacedAt[len(k.replacedAt)-1]) > k.policy.MaxAge
+	if !expired {
+		defer k.mutex.RUnlock()
+		return k.keys
+	}
+	k.mutex.RUnlock()
+
+	k.mutex.Lock()
+	evicted, onEvict := k.prune()
+	keys := k.keys
+	k.mutex.Unlock()
+
+	notifyEvicted(evicted, onEvict)
+	return keys
+}
+
+// prune removes the keys that do not satisfy the RotationPolicy, must be invoked with the lock held
+func (k *Keyring) prune() (evicted [][]byte, onEvict func(key []byte)) {
+	onEvict = k.policy.OnEvict
+	keep := len(k.keys)
+	if k.policy.MaxKeys > 0 && keep > k.policy.MaxKeys {
+		keep = k.policy.MaxKeys
+	}
+	if k.policy.MaxAge > 0 {
+		// keys are ordered from the newest to the oldest, index 0 (primary) is never evicted
+		for keep > 1 && time.Since(k.replacedAt[keep-1]) > k.policy.MaxAge {
+			keep--
+		}
+	}
+	if keep < len(k.keys) {
+		evicted = append(evicted, k.keys[keep:]...)
+		k.keys = k.keys[:keep:keep]
+		k.replacedAt = k.replacedAt[:keep:keep]
+	}
+	return
+}
+
+func notifyEvicted(evicted [][]byte, onEvict func(key []byte)) {
+	if onEvict != nil {
+		for _, key := range evicted {
+			onEvict(key)
+		}
+	}
 }
 
 // GetPrimaryKey returns the key on the ring at position 0. This is the key used
@@ -141,3 +235,25 @@ func (k *Keyring) MessageVerify(signed []byte) (decoded []byte, err error) {
 	}
 	return nil, ErrKeyringCannotVerify
 }
+
+// EncryptWriter returns a writer that encrypts a stream using Keyring primary key. See EncryptWriter.
+func (k *Keyring) EncryptWriter(w io.Writer, aad []byte) (io.WriteCloser, error) {
+	key := k.GetPrimaryKey()
+	if key == nil {
+		return nil, ErrKeyringEmpty
+	}
+	return EncryptWriter(key, w, aad)
+}
+
+// DecryptReader returns a reader that decrypts a stream produced by EncryptWriter using Keyring keys.
+func (k *Keyring) DecryptReader(r io.Reader, aad []byte) (io.Reader, error) {
+	keys := k.GetKeys()
+	if len(keys) == 0 {
+		return nil, ErrKeyringEmpty
+	}
+	reader, err := decryptReader(keys, r, aad)
+	if err != nil {
+		return nil, ErrKeyringCannotDecrypt
+	}
+	return reader, nil
+}
@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testKey(i int) []byte {
+	return []byte(fmt.Sprintf("keyring.test.key.%015d", i))
+}
+
+func Test_Keyring_RotationPolicy_MaxKeys(t *testing.T) {
+	var evicted [][]byte
+	keyring := &Keyring{}
+	for i := 0; i < 3; i++ {
+		keyring.AddKey(testKey(i))
+	}
+
+	keyring.SetRotationPolicy(RotationPolicy{
+		MaxKeys: 2,
+		OnEvict: func(key []byte) {
+			evicted = append(evicted, key)
+		},
+	})
+
+	if len(evicted) != 1 || !bytes.Equal(evicted[0], testKey(0)) {
+		t.Errorf("SetRotationPolicy() failed: Invalid evicted\n   actual: %s\n expected: %s", evicted, testKey(0))
+	}
+
+	keyring.AddKey(testKey(3))
+
+	keys := keyring.GetKeys()
+	if len(keys) != 2 || !bytes.Equal(keys[0], testKey(3)) || !bytes.Equal(keys[1], testKey(2)) {
+		t.Errorf("AddKey() failed: Invalid keys\n   actual: %s", keys)
+	}
+	if len(evicted) != 2 || !bytes.Equal(evicted[1], testKey(1)) {
+		t.Errorf("AddKey() failed: Invalid evicted\n   actual: %s\n expected: %s", evicted, testKey(1))
+	}
+}
+
+func Test_Keyring_RotationPolicy_MaxAge(t *testing.T) {
+	var evicted [][]byte
+	keyring := &Keyring{}
+	keyring.SetRotationPolicy(RotationPolicy{
+		MaxAge: 20 * time.Millisecond,
+		OnEvict: func(key []byte) {
+			evicted = append(evicted, key)
+		},
+	})
+
+	keyring.AddKey(testKey(0))
+	keyring.AddKey(testKey(1))
+
+	if keys := keyring.GetKeys(); len(keys) != 2 {
+		t.Errorf("GetKeys() failed: Invalid number of keys\n   actual: %d\n expected: %d", len(keys), 2)
+	}
+
+	<-time.After(30 * time.Millisecond)
+
+	// primary key is never evicted
+	keys := keyring.GetKeys()
+	if len(keys) != 1 || !bytes.Equal(keys[0], testKey(1)) {
+		t.Errorf("GetKeys() failed: Invalid keys\n   actual: %s", keys)
+	}
+	if len(evicted) != 1 || !bytes.Equal(evicted[0], testKey(0)) {
+		t.Errorf("GetKeys() failed: Invalid evicted\n   actual: %s\n expected: %s", evicted, testKey(0))
+	}
+}
+
+func Test_Keyring_AddKey_Installed(t *testing.T) {
+	keyring := &Keyring{}
+	keyring.AddKey(testKey(0))
+	keyring.AddKey(testKey(1))
+	keyring.AddKey(testKey(0))
+
+	keys := keyring.GetKeys()
+	if len(keys) != 2 || !bytes.Equal(keys[0], testKey(0)) || !bytes.Equal(keys[1], testKey(1)) {
+		t.Errorf("AddKey() failed: Invalid keys\n   actual: %s\n expected: %s", keys, [][]byte{testKey(0), testKey(1)})
+	}
+	if !bytes.Equal(keyring.GetPrimaryKey(), testKey(0)) {
+		t.Errorf("AddKey() failed: Invalid primary key\n   actual: %s\n expected: %s", keyring.GetPrimaryKey(), testKey(0))
+	}
+}
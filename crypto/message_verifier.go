@@ -77,10 +77,18 @@ func (v *MessageVerifier) decodeToken(token []byte) (
 	// algo name
 	rest := token[0:]
 	index := bytes.IndexByte(rest, '.')
+	if index < 0 {
+		err = ErrInvalidSignature
+		return
+	}
 	algo64 = rest[0:index]
 
 	rest = rest[index+1:]
 	index = bytes.IndexByte(rest, '.')
+	if index < 0 {
+		err = ErrInvalidSignature
+		return
+	}
 	payload64 = rest[0:index]
 
 	plainText = make([]byte, len(algo64)+len(payload64)+1)
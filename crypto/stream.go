@@ -0,0 +1,277 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// DefaultStreamChunkSize size of the plaintext chunks encrypted by EncryptWriter
+const DefaultStreamChunkSize = 64 * 1024
+
+const (
+	streamVersion     = byte(1)
+	streamNoncePrefix = 7 // nonce = [prefix: 7 bytes][counter: 4 bytes][last chunk flag: 1 byte]
+	streamMaxChunk    = 16 * 1024 * 1024
+	streamTagSize     = 16
+	streamMaxChunks   = 1<<32 - 1
+	streamHeaderFixed = 1 + 4 + 2 // [version][chunk size: uint32][encrypted CEK length: uint16]
+)
+
+var (
+	ErrStreamClosed      = errors.New("stream is closed")
+	ErrStreamTruncated   = errors.New("stream is truncated")
+	ErrStreamInvalid     = errors.New("invalid stream header")
+	ErrStreamTooLarge    = errors.New("stream exceeds the maximum number of chunks")
+	ErrStreamUnsupported = errors.New("unsupported stream version")
+)
+
+// EncryptWriter returns a writer that encrypts everything written to it into `w`, without holding the whole content
+// in memory. The caller MUST invoke Close to write the final chunk.
+//
+// The content is split into chunks of DefaultStreamChunkSize bytes, each chunk is encrypted with AES-256-GCM using a
+// random content encryption key (CEK) and a nonce derived from the chunk counter, the last chunk is flagged so
+// truncation and reordering are detected. The CEK is encrypted with `secret` and `aad` (see Encrypt).
+//
+// Stream format:
+//
+//	[version: 1][chunk size: 4][CEK length: 2][encrypted CEK][nonce prefix: 7][chunk 0]...[chunk N (last)]
+//
+// ## Example
+//
+//	writer, err := crypto.EncryptWriter(secret, file, nil)
+//	if err != nil {
+//		return err
+//	}
+//	if _, err = io.Copy(writer, source); err != nil {
+//		return err
+//	}
+//	return writer.Close()
+func EncryptWriter(secret []byte, w io.Writer, aad []byte) (io.WriteCloser, error) {
+	cek := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return nil, err
+	}
+
+	encryptedCEK, err := Encrypt(secret, cek, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newStreamAEAD(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, streamNoncePrefix)
+	if _, err = io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderFixed, streamHeaderFixed+len(encryptedCEK)+streamNoncePrefix)
+	header[0] = streamVersion
+	binary.BigEndian.PutUint32(header[1:5], DefaultStreamChunkSize)
+	binary.BigEndian.PutUint16(header[5:7], uint16(len(encryptedCEK)))
+	header = append(append(header, encryptedCEK...), prefix...)
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &streamWriter{
+		w:         w,
+		gcm:       gcm,
+		nonce:     append(prefix, make([]byte, 5)...),
+		chunkSize: DefaultStreamChunkSize,
+		buffer:    make([]byte, 0, DefaultStreamChunkSize),
+	}, nil
+}
+
+// DecryptReader returns a reader that decrypts and verifies the stream produced by EncryptWriter. Each chunk is
+// verified before being returned; if the stream was tampered with or truncated, Read returns an error.
+func DecryptReader(secret []byte, r io.Reader, aad []byte) (io.Reader, error) {
+	return decryptReader([][]byte{secret}, r, aad)
+}
+
+func decryptReader(secrets [][]byte, r io.Reader, aad []byte) (io.Reader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, streamHeaderFixed)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, ErrStreamInvalid
+	}
+	if header[0] != streamVersion {
+		return nil, ErrStreamUnsupported
+	}
+	chunkSize := int(binary.BigEndian.Uint32(header[1:5]))
+	if chunkSize < 1 || chunkSize > streamMaxChunk {
+		return nil, ErrStreamInvalid
+	}
+
+	rest := make([]byte, int(binary.BigEndian.Uint16(header[5:7]))+streamNoncePrefix)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, ErrStreamInvalid
+	}
+	encryptedCEK, prefix := rest[:len(rest)-streamNoncePrefix], rest[len(rest)-streamNoncePrefix:]
+
+	var cek []byte
+	var err error
+	for _, secret := range secrets {
+		if cek, err = Decrypt(secret, encryptedCEK, aad); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newStreamAEAD(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		r:         br,
+		gcm:       gcm,
+		nonce:     append(append([]byte{}, prefix...), make([]byte, 5)...),
+		chunk:     make([]byte, chunkSize+streamTagSize),
+		chunkSize: chunkSize,
+	}, nil
+}
+
+func newStreamAEAD(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func setStreamNonce(nonce []byte, counter uint32, last bool) {
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefix:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	} else {
+		nonce[len(nonce)-1] = 0
+	}
+}
+
+type streamWriter struct {
+	w         io.Writer
+	gcm       cipher.AEAD
+	nonce     []byte
+	counter   uint32
+	chunkSize int
+	buffer    []byte
+	sealed    []byte
+	closed    bool
+}
+
+func (s *streamWriter) Write(p []byte) (n int, err error) {
+	if s.closed {
+		return 0, ErrStreamClosed
+	}
+	for len(p) > 0 {
+		// only flushes when there is more data, so the last chunk is always written by Close
+		if len(s.buffer) == s.chunkSize {
+			if err = s.flush(false); err != nil {
+				return
+			}
+		}
+		size := s.chunkSize - len(s.buffer)
+		if size > len(p) {
+			size = len(p)
+		}
+		s.buffer = append(s.buffer, p[:size]...)
+		p = p[size:]
+		n += size
+	}
+	return
+}
+
+// Close writes the last chunk. It does not close the underlying writer.
+func (s *streamWriter) Close() error {
+	if s.closed {
+		return ErrStreamClosed
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+func (s *streamWriter) flush(last bool) error {
+	if s.counter == streamMaxChunks {
+		return ErrStreamTooLarge
+	}
+	setStreamNonce(s.nonce, s.counter, last)
+	s.sealed = s.gcm.Seal(s.sealed[:0], s.nonce, s.buffer, nil)
+	s.counter++
+	s.buffer = s.buffer[:0]
+	_, err := s.w.Write(s.sealed)
+	return err
+}
+
+type streamReader struct {
+	r         *bufio.Reader
+	gcm       cipher.AEAD
+	nonce     []byte
+	counter   uint32
+	chunk     []byte
+	chunkSize int
+	plain     []byte
+	done      bool
+	err       error
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.done {
+			return 0, io.EOF
+		}
+		s.err = s.next()
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+// next reads and decrypts the next chunk
+func (s *streamReader) next() error {
+	n, err := io.ReadFull(s.r, s.chunk)
+	last := false
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		// full chunk, it's the last one if there is no more data
+		if _, err = s.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if n < streamTagSize {
+		return ErrStreamTruncated
+	}
+
+	setStreamNonce(s.nonce, s.counter, last)
+	plain, err := s.gcm.Open(s.chunk[:0], s.nonce, s.chunk[:n], nil)
+	if err != nil {
+		if last {
+			// the last chunk read was not flagged as last, the stream was truncated
+			return ErrStreamTruncated
+		}
+		return err
+	}
+	s.counter++
+	s.plain = plain
+	s.done = last
+	return nil
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func Test_Stream(t *testing.T) {
+	secret := []byte("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy")
+	aad := []byte("stream.aad")
+
+	sizes := []int{0, 1, 1000, DefaultStreamChunkSize - 1, DefaultStreamChunkSize, DefaultStreamChunkSize + 1, DefaultStreamChunkSize*3 + 100}
+	for _, size := range sizes {
+		content := make([]byte, size)
+		rand.Read(content)
+
+		var encrypted bytes.Buffer
+		writer, err := EncryptWriter(secret, &encrypted, aad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// small writes
+		for i := 0; i < len(content); i += 1000 {
+			end := i + 1000
+			if end > len(content) {
+				end = len(content)
+			}
+			if _, err = writer.Write(content[i:end]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		reader, err := DecryptReader(secret, bytes.NewReader(encrypted.Bytes()), aad)
+		if err != nil {
+			t.Fatalf("DecryptReader() failed: size %d, unexpected error: %v", size, err)
+		}
+		decrypted, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("DecryptReader() failed: size %d, unexpected error: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, content) {
+			t.Errorf("DecryptReader() failed: size %d, Invalid content", size)
+		}
+
+		// truncated on chunk boundary
+		if size > DefaultStreamChunkSize {
+			headerSize := encrypted.Len() - (size + ((size/DefaultStreamChunkSize)+1)*streamTagSize)
+			truncated := encrypted.Bytes()[:headerSize+DefaultStreamChunkSize+streamTagSize]
+			reader, _ = DecryptReader(secret, bytes.NewReader(truncated), aad)
+			if _, err = io.ReadAll(reader); err != ErrStreamTruncated {
+				t.Errorf("DecryptReader() failed: size %d, Invalid error\n   actual: %v\n expected: %v", size, err, ErrStreamTruncated)
+			}
+		}
+
+		// tampered
+		if size > 0 {
+			tampered := bytes.Clone(encrypted.Bytes())
+			tampered[len(tampered)-1] ^= 0xFF
+			reader, _ = DecryptReader(secret, bytes.NewReader(tampered), aad)
+			if _, err = io.ReadAll(reader); err == nil {
+				t.Errorf("DecryptReader() failed: size %d, tampered stream must fail", size)
+			}
+		}
+	}
+}
+
+func Test_Keyring_Stream(t *testing.T) {
+	keyring := &Keyring{}
+	keyring.AddKey(testKey(0))
+
+	var encrypted bytes.Buffer
+	writer, _ := keyring.EncryptWriter(&encrypted, nil)
+	writer.Write([]byte("content"))
+	writer.Close()
+
+	// rotation
+	keyring.AddKey(testKey(1))
+
+	reader, err := keyring.DecryptReader(&encrypted, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted, _ := io.ReadAll(reader); string(decrypted) != "content" {
+		t.Errorf("DecryptReader() failed: Invalid content\n   actual: %s\n expected: %s", decrypted, "content")
+	}
+}
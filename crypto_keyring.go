@@ -12,22 +12,41 @@ import (
 //   - `iterations` 	- defaults to 1000 (increase to at least 2^16 if used for passwords)
 //   - `length`     	- a length in octets for the derived key. Defaults to 32
 //   - `digest`     	- a hmac function to use as the pseudo-random function. Defaults to `sha256`
+//
+// Keys are accumulated as SetSecretKeyBase is invoked, use Keyring.SetRotationPolicy to bound the number of keys.
 func NewKeyring(salt string, iterations int, length int, digest string) *crypto.Keyring {
-
 	if iterations < 1 {
 		iterations = 1000
 	}
-	if length < 1 {
-		length = 32
-	}
 	if digest == "" {
 		digest = "sha256"
 	}
+	return NewKeyringKDF(salt, length, crypto.PBKDF2{Iterations: iterations, Digest: digest})
+}
+
+// NewKeyringKDF starts a Keyring that will be updated whenever SecretKeySync() is invoked, deriving the keys with the
+// given key derivation function (crypto.PBKDF2, crypto.Argon2id or crypto.Scrypt).
+//
+// ## Example
+//
+//	keyring := chain.NewKeyringKDF("my.salt", 32, crypto.Argon2id{Time: 1, Memory: 64 * 1024, Threads: 4})
+func NewKeyringKDF(salt string, length int, kdf crypto.KDF) *crypto.Keyring {
+	return newKeyring(secretKeys, salt, length, kdf)
+}
+
+// newKeyring creates a Keyring with keys derived from the SecretKeyBase of the given store
+func newKeyring(secrets *secretKeyStore, salt string, length int, kdf crypto.KDF) *crypto.Keyring {
+	if length < 1 {
+		length = 32
+	}
 	k := &crypto.Keyring{}
 
-	SecretKeySync(func(secretKeyBase string) {
-		key := crypt.KeyGenerate([]byte(secretKeyBase), []byte(salt), iterations, length, digest)
-		if err := k.AddKey(key); err != nil {
+	secrets.sync(func(secretKeyBase string) {
+		key, err := keyringKeyGenerator.Derive([]byte(secretKeyBase), []byte(salt), length, kdf)
+		if err == nil {
+			err = k.AddKey(key)
+		}
+		if err != nil {
 			slog.Error("[chain.keyring] error deriving key from SecretKeyBase", slog.Any("error", err))
 			return
 		}
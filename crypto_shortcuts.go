@@ -7,6 +7,10 @@ var (
 	msgVerifier  = crypto.MessageVerifier{}
 	msgEncryptor = crypto.MessageEncryptor{}
 	keyGenerator = crypto.KeyGenerator{}
+
+	// keyringKeyGenerator derives the keys of the keyrings from the SecretKeyBase (see NewKeyringKDF), the only
+	// derivations cached
+	keyringKeyGenerator = crypto.KeyGenerator{CacheSize: crypto.DefaultKeyCacheSize}
 )
 
 // Crypto get the reference to a structure that has shortcut to all encryption related functions
@@ -0,0 +1,244 @@
+package debug
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/pubsub"
+	"github.com/nidorx/chain/socket"
+)
+
+// DefaultMaxErrors number of recent errors kept by the Dashboard
+const DefaultMaxErrors = 50
+
+// methods displayed on the routes of the Dashboard (chain.Router.Walk is not used, it invalidates the routes being served)
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	http.MethodOptions, http.MethodConnect, http.MethodTrace,
+}
+
+// Dashboard mountable debug UI showing the registered routes, the active socket sessions and their joined topics, the
+// pubsub subscriptions of this node and the recent errors. Invaluable during the development of real-time apps.
+//
+// Two endpoints are registered: GET "{endpoint}" renders the HTML page and GET "{endpoint}/data" the same Snapshot as
+// JSON. Errors returned by the route handlers (see chain.Router.OnDispatch) are recorded, as well as the refused socket
+// joins of the handlers with socket.Handler.Events enabled.
+//
+// The dashboard exposes the internals of the application, mount it only during development or protect the endpoint.
+//
+// ## Example
+//
+//	router.Use("/_chain/*", authMiddleware)
+//	router.Configure("/_chain", &debug.Dashboard{Sockets: []*socket.Handler{AppSocket}})
+type Dashboard struct {
+	Sockets      []*socket.Handler // Socket handlers displayed
+	MaxErrors    int               // Number of recent errors kept. Default DefaultMaxErrors
+	router       *chain.Router
+	errors       []ErrorInfo
+	errorsMutex  sync.Mutex
+	removeHook   func()
+	eventsHandle pubsub.Dispatcher
+}
+
+// Snapshot state of the application displayed by the Dashboard
+type Snapshot struct {
+	Node    string              `json:"node"`
+	Time    time.Time           `json:"time"`
+	Routes  []RouteInfo         `json:"routes"`
+	Sockets []SocketInfo        `json:"sockets"`
+	Topics  []pubsub.TopicStats `json:"topics"`
+	Errors  []ErrorInfo         `json:"errors"` // Most recent first
+}
+
+// RouteInfo a registered route
+type RouteInfo struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Priority int    `json:"priority"`
+}
+
+// SocketInfo the sessions and metrics of a socket.Handler
+type SocketInfo struct {
+	Sessions []socket.SessionStats            `json:"sessions"`
+	Metrics  map[string]socket.ChannelMetrics `json:"metrics"` // by Channel.TopicPattern
+}
+
+// ErrorInfo an error recorded by the Dashboard
+type ErrorInfo struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"` // "http" or "socket"
+	Method string    `json:"method,omitempty"`
+	Path   string    `json:"path"` // Path of the request or topic of the socket
+	Route  string    `json:"route,omitempty"`
+	Error  string    `json:"error"`
+}
+
+func (d *Dashboard) Configure(router *chain.Router, endpoint string) {
+	if d.MaxErrors <= 0 {
+		d.MaxErrors = DefaultMaxErrors
+	}
+	d.router = router
+
+	d.removeHook = router.OnDispatch(&chain.DispatchHook{
+		OnError: func(ctx *chain.Context, route *chain.Route, err error) {
+			d.addError(ErrorInfo{
+				Source: "http",
+				Method: ctx.Request.Method,
+				Path:   ctx.Request.URL.Path,
+				Route:  route.Path(),
+				Error:  err.Error(),
+			})
+		},
+	})
+
+	for _, handler := range d.Sockets {
+		if handler.Events {
+			d.eventsHandle = socket.SubscribeEvents(func(event socket.Event) {
+				if event.Kind == socket.EventJoin && event.Error != "" {
+					d.addError(ErrorInfo{Source: "socket", Path: event.Topic, Error: event.Error})
+				}
+			})
+			break
+		}
+	}
+
+	router.GET(endpoint, func(ctx *chain.Context) error {
+		ctx.SetHeader("Cache-Control", "no-store")
+		ctx.SetHeader("Content-Type", "text/html; charset=utf-8")
+		ctx.WriteHeader(http.StatusOK)
+		return dashboardPage.Execute(ctx.Writer, d.Snapshot())
+	})
+
+	router.GET(endpoint+"/data", func(ctx *chain.Context) {
+		ctx.SetHeader("Cache-Control", "no-store")
+		ctx.Json(d.Snapshot())
+	})
+}
+
+// Close stops recording the errors
+func (d *Dashboard) Close() {
+	if d.removeHook != nil {
+		d.removeHook()
+	}
+	if d.eventsHandle != nil {
+		pubsub.Unsubscribe(socket.EventsTopic, d.eventsHandle)
+	}
+}
+
+// Snapshot gets the current state of the application
+func (d *Dashboard) Snapshot() Snapshot {
+	snapshot := Snapshot{
+		Node:    pubsub.Self(),
+		Time:    time.Now(),
+		Routes:  []RouteInfo{},
+		Sockets: []SocketInfo{},
+		Topics:  pubsub.Subscriptions(),
+		Errors:  d.Errors(),
+	}
+
+	if d.router != nil {
+		for _, method := range methods {
+			for _, route := range d.router.Routes(method) {
+				snapshot.Routes = append(snapshot.Routes, RouteInfo{
+					Method:   method,
+					Path:     route.Path(),
+					Priority: route.Info.Priority(),
+				})
+			}
+		}
+		sort.SliceStable(snapshot.Routes, func(i, j int) bool {
+			return snapshot.Routes[i].Path < snapshot.Routes[j].Path
+		})
+	}
+
+	for _, handler := range d.Sockets {
+		snapshot.Sockets = append(snapshot.Sockets, SocketInfo{
+			Sessions: handler.Sessions(),
+			Metrics:  handler.Metrics(),
+		})
+	}
+	if snapshot.Topics == nil {
+		snapshot.Topics = []pubsub.TopicStats{}
+	}
+	return snapshot
+}
+
+// Errors gets the recent errors, most recent first
+func (d *Dashboard) Errors() []ErrorInfo {
+	d.errorsMutex.Lock()
+	defer d.errorsMutex.Unlock()
+
+	list := make([]ErrorInfo, len(d.errors))
+	for i, info := range d.errors {
+		list[len(d.errors)-1-i] = info
+	}
+	return list
+}
+
+func (d *Dashboard) addError(info ErrorInfo) {
+	info.Time = time.Now()
+
+	d.errorsMutex.Lock()
+	defer d.errorsMutex.Unlock()
+	if len(d.errors) >= d.MaxErrors {
+		d.errors = append(d.errors[:0], d.errors[len(d.errors)-d.MaxErrors+1:]...)
+	}
+	d.errors = append(d.errors, info)
+}
+
+var dashboardPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="5">
+<title>chain debug</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+h2 { font-size: 1.1em; margin-top: 2em; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 2px 12px 2px 0; vertical-align: top; }
+th { border-bottom: 1px solid #ccc; }
+.muted { color: #888; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<h1>chain <span class="muted">node {{.Node}} &middot; {{.Time.Format "15:04:05"}}</span></h1>
+
+<h2>Routes ({{len .Routes}})</h2>
+<table>
+<tr><th>Method</th><th>Path</th><th>Priority</th></tr>
+{{range .Routes}}<tr><td>{{.Method}}</td><td>{{.Path}}</td><td>{{.Priority}}</td></tr>
+{{end}}</table>
+
+{{range .Sockets}}
+<h2>Socket sessions ({{len .Sessions}})</h2>
+<table>
+<tr><th>Socket id</th><th>Endpoint</th><th>Topics</th><th>Last active</th><th>Dropped</th></tr>
+{{range .Sessions}}<tr><td>{{.SocketId}}</td><td>{{.Endpoint}}</td><td>{{range .Topics}}{{.}}<br>{{end}}</td><td>{{.LastActive.Format "15:04:05"}}</td><td>{{.Dropped}}</td></tr>
+{{end}}</table>
+<table>
+<tr><th>Channel</th><th>Joins</th><th>Leaves</th><th>Messages</th><th>Errors</th><th>Mean latency</th></tr>
+{{range $pattern, $metrics := .Metrics}}<tr><td>{{$pattern}}</td><td>{{$metrics.Joins}}</td><td>{{$metrics.Leaves}}</td><td>{{$metrics.Messages}}</td><td>{{$metrics.Errors}}</td><td>{{$metrics.Latency.Mean}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>PubSub subscriptions ({{len .Topics}})</h2>
+<table>
+<tr><th>Topic</th><th>Adapter</th><th>Dispatchers</th><th>Received</th><th>Sent</th></tr>
+{{range .Topics}}<tr><td>{{.Topic}}</td><td>{{.Adapter}}</td><td>{{.Dispatchers}}</td><td>{{.Received}}</td><td>{{.Sent}}</td></tr>
+{{end}}</table>
+
+<h2>Recent errors ({{len .Errors}})</h2>
+<table>
+<tr><th>Time</th><th>Source</th><th>Request</th><th>Error</th></tr>
+{{range .Errors}}<tr><td>{{.Time.Format "15:04:05"}}</td><td>{{.Source}}</td><td>{{.Method}} {{.Path}}{{if .Route}} <span class="muted">({{.Route}})</span>{{end}}</td><td class="error">{{.Error}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
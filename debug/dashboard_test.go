@@ -0,0 +1,73 @@
+package debug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/socket"
+)
+
+func Test_Dashboard(t *testing.T) {
+	router := chain.New()
+	router.GET("/fail", func(ctx *chain.Context) error {
+		return errors.New("database is down")
+	})
+
+	handler := &socket.Handler{
+		Channels: []*socket.Channel{
+			socket.NewChannel("room:*", func(channel *socket.Channel) {}),
+		},
+	}
+	router.Configure("/socket", handler)
+
+	dashboard := &Dashboard{Sockets: []*socket.Handler{handler}, MaxErrors: 2}
+	router.Configure("/_chain", dashboard)
+	defer dashboard.Close()
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/fail", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/_chain/data", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Dashboard failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusOK)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, route := range snapshot.Routes {
+		if route.Method == http.MethodGet && route.Path == "/fail" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Dashboard failed: route not listed\n   actual: %v", snapshot.Routes)
+	}
+	if len(snapshot.Sockets) != 1 || snapshot.Sockets[0].Metrics["room:*"].Joins != 0 {
+		t.Errorf("Dashboard failed: Invalid sockets\n   actual: %v", snapshot.Sockets)
+	}
+	if len(snapshot.Errors) != 2 {
+		t.Fatalf("Dashboard failed: Invalid errors count\n   actual: %v\n expected: %v", len(snapshot.Errors), 2)
+	}
+	if info := snapshot.Errors[0]; info.Source != "http" || info.Path != "/fail" || info.Error != "database is down" {
+		t.Errorf("Dashboard failed: Invalid error\n   actual: %+v", info)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/_chain", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "database is down") {
+		t.Errorf("Dashboard failed: Invalid page\n   actual: %v %s", w.Code, w.Body.String())
+	}
+}
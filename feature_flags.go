@@ -0,0 +1,83 @@
+package chain
+
+// FeatureFlags evaluates the feature flags of the requests (see Router.FeatureFlags), allowing the integration with any
+// flag provider. The flags can be evaluated with the attributes of the request (user of the session, headers, ...).
+type FeatureFlags interface {
+	Enabled(ctx *Context, flag string) bool
+}
+
+// FeatureFlagsFunc adapter to allow the use of ordinary functions as FeatureFlags
+type FeatureFlagsFunc func(ctx *Context, flag string) bool
+
+func (f FeatureFlagsFunc) Enabled(ctx *Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// StaticFeatureFlags flags with fixed values (ex. loaded from the configuration), flags not in the map are disabled
+type StaticFeatureFlags map[string]bool
+
+func (f StaticFeatureFlags) Enabled(ctx *Context, flag string) bool {
+	return f[flag]
+}
+
+// featureFlagKey key of the evaluated flag in the Context data
+func featureFlagKey(flag string) TypedKey[bool] {
+	return NewContextKey[bool]("chain.feature-flag." + flag)
+}
+
+// FeatureEnabled checks if the feature flag is enabled for the request (see Router.FeatureFlags).
+//
+// The flag is evaluated once per request, so the handler and the middlewares get the same value even if the provider
+// changes during the request. Returns false if the router has no FeatureFlags.
+//
+// ## Example
+//
+//	router.FeatureFlags = chain.FeatureFlagsFunc(func(ctx *chain.Context, flag string) bool {
+//		sess, _ := session.Fetch(ctx)
+//		return flags.IsEnabled(flag, sess.Get("user_id"), ctx.GetHeader("X-Beta"))
+//	})
+//
+//	router.GET("/checkout", func(ctx *chain.Context) error {
+//		if ctx.FeatureEnabled("new-checkout") {
+//			return newCheckout(ctx)
+//		}
+//		return checkout(ctx)
+//	})
+func (ctx *Context) FeatureEnabled(flag string) bool {
+	key := featureFlagKey(flag)
+	if enabled, exists := key.Get(ctx); exists {
+		return enabled
+	}
+
+	enabled := false
+	if ctx.router != nil && ctx.router.FeatureFlags != nil {
+		enabled = ctx.router.FeatureFlags.Enabled(ctx, flag)
+	}
+	key.Set(ctx, enabled)
+	return enabled
+}
+
+// RequireFeature middleware that short-circuits the routes behind a disabled feature flag, replying as if the route
+// does not exist (see Router.NotFoundHandler). Use WithFeature to gate a single route.
+//
+// ## Example
+//
+//	router.Use("/beta/*", chain.RequireFeature("beta"))
+func RequireFeature(flag string) func(ctx *Context, next func() error) error {
+	return func(ctx *Context, next func() error) error {
+		if ctx.FeatureEnabled(flag) {
+			return next()
+		}
+		ctx.featureDisabled()
+		return nil
+	}
+}
+
+// featureDisabled replies the requests of routes behind disabled feature flags
+func (ctx *Context) featureDisabled() {
+	if ctx.router != nil {
+		ctx.router.notFound(ctx, ctx.Writer, ctx.Request)
+	} else {
+		ctx.NotFound()
+	}
+}
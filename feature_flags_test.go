@@ -0,0 +1,74 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Context_FeatureEnabled(t *testing.T) {
+	router := New()
+
+	evaluations := 0
+	router.FeatureFlags = FeatureFlagsFunc(func(ctx *Context, flag string) bool {
+		evaluations++
+		return flag == "new-checkout" && ctx.Request.Header.Get("X-Beta") == "1"
+	})
+
+	var enabled []bool
+	router.GET("/checkout", func(ctx *Context) error {
+		enabled = append(enabled, ctx.FeatureEnabled("new-checkout"), ctx.FeatureEnabled("new-checkout"))
+		enabled = append(enabled, ctx.FeatureEnabled("other"))
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/checkout", nil)
+	req.Header.Set("X-Beta", "1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(enabled) != 3 || !enabled[0] || !enabled[1] || enabled[2] {
+		t.Errorf("FeatureEnabled() failed: Invalid flags\n   actual: %v\n expected: %v", enabled, []bool{true, true, false})
+	}
+	if evaluations != 2 {
+		t.Errorf("FeatureEnabled() failed: flag evaluated more than once per request\n   actual: %v\n expected: %v", evaluations, 2)
+	}
+
+	ctx := &Context{}
+	if ctx.FeatureEnabled("new-checkout") {
+		t.Errorf("FeatureEnabled() failed: flag enabled without FeatureFlags")
+	}
+}
+
+func Test_RequireFeature(t *testing.T) {
+	router := New()
+	flags := StaticFeatureFlags{"beta": false, "new-checkout": false}
+	router.FeatureFlags = flags
+
+	router.Use("/beta/*", RequireFeature("beta"))
+	router.GET("/beta/page", func(ctx *Context) error {
+		ctx.Write([]byte("beta"))
+		return nil
+	})
+	router.GET("/checkout/v2", func(ctx *Context) error {
+		ctx.Write([]byte("v2"))
+		return nil
+	}, WithFeature("new-checkout"))
+
+	for _, path := range []string{"/beta/page", "/checkout/v2"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("RequireFeature() failed: Invalid status of %s with flag disabled\n   actual: %v\n expected: %v", path, w.Code, http.StatusNotFound)
+		}
+	}
+
+	flags["beta"] = true
+	flags["new-checkout"] = true
+	for _, path := range []string{"/beta/page", "/checkout/v2"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("RequireFeature() failed: Invalid status of %s with flag enabled\n   actual: %v\n expected: %v", path, w.Code, http.StatusOK)
+		}
+	}
+}
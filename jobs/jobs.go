@@ -0,0 +1,274 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 256
+)
+
+var (
+	ErrQueueFull     = errors.New("jobs queue is full")
+	ErrRunnerClosed  = errors.New("jobs runner is closed")
+	ErrInvalidJob    = errors.New("job function is nil")
+	ErrJobPanicked   = errors.New("job panicked")
+	errScheduleEnded = errors.New("schedule has no next activation")
+)
+
+// Job a unit of work. The ctx is cancelled when the Runner.Shutdown deadline is exceeded.
+type Job func(ctx context.Context) error
+
+// Runner executes background jobs on a pool of workers, either enqueued (Runner.Enqueue) or periodically
+// (Runner.Schedule). Panics are recovered and logged, so a failing job doesn't stop the application. The zero value is
+// ready to use.
+//
+// Runner.Shutdown stops the schedules, rejects new jobs and waits for the pending ones, use Runner.Attach to drain the
+// jobs when the chain.Router is shut down.
+//
+// ## Example
+//
+//	runner := &jobs.Runner{}
+//	runner.Attach(router)
+//
+//	runner.Schedule("@every 1m", "session.gc", func(ctx context.Context) error {
+//		return sessions.DeleteExpired(ctx)
+//	})
+//
+//	router.POST("/reports", func(ctx *chain.Context) {
+//		runner.Enqueue("report.build", func(ctx context.Context) error {
+//			return buildReport(ctx)
+//		})
+//		ctx.WriteHeader(http.StatusAccepted)
+//	})
+type Runner struct {
+	Workers     int                          // Number of concurrent jobs. Default DefaultWorkers
+	QueueSize   int                          // Max number of pending jobs. Default DefaultQueueSize
+	Location    *time.Location               // Location of the cron expressions. Default time.Local
+	OnError     func(name string, err error) // Invoked when a job fails (returns an error or panics)
+	queue       chan *entry
+	ctx         context.Context // cancelled when the Shutdown deadline is exceeded
+	cancel      context.CancelFunc
+	stop        chan struct{} // closed on Shutdown, stops the schedules
+	pending     sync.WaitGroup
+	workers     sync.WaitGroup
+	schedules   sync.WaitGroup
+	startOnce   sync.Once
+	closed      bool
+	closedMutex sync.RWMutex
+}
+
+type entry struct {
+	name    string
+	job     Job
+	running *atomic.Bool // scheduled jobs, prevents overlapping executions
+}
+
+func (r *Runner) start() {
+	r.startOnce.Do(func() {
+		if r.Workers <= 0 {
+			r.Workers = DefaultWorkers
+		}
+		if r.QueueSize <= 0 {
+			r.QueueSize = DefaultQueueSize
+		}
+		if r.Location == nil {
+			r.Location = time.Local
+		}
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+		r.stop = make(chan struct{})
+		r.queue = make(chan *entry, r.QueueSize)
+		for i := 0; i < r.Workers; i++ {
+			r.workers.Add(1)
+			go r.work()
+		}
+	})
+}
+
+// Attach registers the Runner.Shutdown on the router, see chain.Router.OnShutdown
+func (r *Runner) Attach(router *chain.Router) {
+	router.OnShutdown(r.Shutdown)
+}
+
+// Enqueue adds the job to the queue. Returns ErrQueueFull if the queue is full.
+func (r *Runner) Enqueue(name string, job Job) error {
+	if job == nil {
+		return ErrInvalidJob
+	}
+	r.start()
+	return r.enqueue(&entry{name: name, job: job})
+}
+
+// Schedule runs the job periodically, according to the spec (see ParseSchedule). If the previous execution is still
+// running (or queued) the activation is skipped. Returns a function that cancels the schedule.
+func (r *Runner) Schedule(spec string, name string, job Job) (cancel func(), err error) {
+	if job == nil {
+		return nil, ErrInvalidJob
+	}
+	r.start()
+
+	var schedule Schedule
+	if schedule, err = ParseSchedule(spec, r.Location); err != nil {
+		return nil, err
+	}
+	return r.ScheduleFunc(schedule, name, job)
+}
+
+// ScheduleFunc same as Runner.Schedule, using a custom Schedule
+func (r *Runner) ScheduleFunc(schedule Schedule, name string, job Job) (cancel func(), err error) {
+	if job == nil {
+		return nil, ErrInvalidJob
+	}
+	r.start()
+
+	r.closedMutex.RLock()
+	defer r.closedMutex.RUnlock()
+	if r.closed {
+		return nil, ErrRunnerClosed
+	}
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	e := &entry{name: name, job: job, running: &atomic.Bool{}}
+
+	r.schedules.Add(1)
+	go func() {
+		defer r.schedules.Done()
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				slog.Warn(
+					"[chain.jobs] schedule stopped",
+					slog.String("Job", name),
+					slog.Any("Error", errScheduleEnded),
+				)
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-r.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if !e.running.CompareAndSwap(false, true) {
+				slog.Debug("[chain.jobs] previous execution still running, skipping", slog.String("Job", name))
+				continue
+			}
+			if err := r.enqueue(e); err != nil {
+				e.running.Store(false)
+				slog.Warn(
+					"[chain.jobs] could not enqueue scheduled job",
+					slog.String("Job", name),
+					slog.Any("Error", err),
+				)
+			}
+		}
+	}()
+
+	return func() {
+		stopOnce.Do(func() {
+			close(stop)
+		})
+	}, nil
+}
+
+// Shutdown stops the schedules, rejects new jobs and waits for the pending ones. If the ctx is done before the jobs
+// finish, the ctx of the running jobs is cancelled and the ctx error is returned. Subsequent calls do nothing.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.start()
+
+	r.closedMutex.Lock()
+	if r.closed {
+		r.closedMutex.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.stop)
+	r.closedMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.schedules.Wait()
+		r.pending.Wait()
+		close(r.queue)
+		r.workers.Wait()
+		r.cancel()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		return ctx.Err()
+	}
+}
+
+func (r *Runner) enqueue(e *entry) error {
+	r.closedMutex.RLock()
+	defer r.closedMutex.RUnlock()
+	if r.closed {
+		return ErrRunnerClosed
+	}
+
+	r.pending.Add(1)
+	select {
+	case r.queue <- e:
+		return nil
+	default:
+		r.pending.Done()
+		return ErrQueueFull
+	}
+}
+
+func (r *Runner) work() {
+	defer r.workers.Done()
+	for e := range r.queue {
+		r.run(e)
+	}
+}
+
+func (r *Runner) run(e *entry) {
+	defer r.pending.Done()
+	if e.running != nil {
+		defer e.running.Store(false)
+	}
+
+	if err := r.execute(e); err != nil {
+		slog.Warn("[chain.jobs] job failed", slog.String("Job", e.name), slog.Any("Error", err))
+		if r.OnError != nil {
+			r.OnError(e.name, err)
+		}
+	}
+}
+
+func (r *Runner) execute(e *entry) (err error) {
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			slog.Error(
+				"[chain.jobs] panic running job",
+				slog.String("Job", e.name),
+				slog.Any("Panic", rcv),
+				slog.String("Stack", string(debug.Stack())),
+			)
+			err = fmt.Errorf("%w: %v", ErrJobPanicked, rcv)
+		}
+	}()
+	return e.job(r.ctx)
+}
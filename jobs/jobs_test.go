@@ -0,0 +1,153 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_ParseSchedule(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.UTC) // wednesday
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 31, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.January, 31, 10, 15, 0, 0, time.UTC)},
+		{"5 10 * * *", time.Date(2024, time.February, 1, 10, 5, 0, 0, time.UTC)},
+		{"0 9-18/3 * * *", time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1,7", time.Date(2024, time.February, 4, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)}, // day of month OR day of week
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.spec, time.UTC)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) failed: Unexpected Error\n   actual: %v", tt.spec, err)
+			continue
+		}
+		if next := schedule.Next(from); !next.Equal(tt.expected) {
+			t.Errorf("ParseSchedule(%q) failed: Invalid Next\n   actual: %v\n expected: %v", tt.spec, next, tt.expected)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "@every x"} {
+		if _, err := ParseSchedule(spec, time.UTC); err == nil {
+			t.Errorf("ParseSchedule(%q) failed: Expected Error", spec)
+		}
+	}
+}
+
+func Test_Runner_Enqueue(t *testing.T) {
+	var failed atomic.Int32
+	runner := &Runner{
+		Workers: 2,
+		OnError: func(name string, err error) {
+			if name == "panic" && errors.Is(err, ErrJobPanicked) {
+				failed.Add(1)
+			}
+		},
+	}
+
+	var executed atomic.Int32
+	for i := 0; i < 10; i++ {
+		if err := runner.Enqueue("job", func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			executed.Add(1)
+			return nil
+		}); err != nil {
+			t.Fatalf("Enqueue failed: Unexpected Error\n   actual: %v", err)
+		}
+	}
+	_ = runner.Enqueue("panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: Unexpected Error\n   actual: %v", err)
+	}
+	if executed.Load() != 10 {
+		t.Errorf("Shutdown failed: Pending jobs not drained\n   actual: %v\n expected: %v", executed.Load(), 10)
+	}
+	if failed.Load() != 1 {
+		t.Errorf("Enqueue failed: Panic not recovered\n   actual: %v\n expected: %v", failed.Load(), 1)
+	}
+	if err := runner.Enqueue("job", func(ctx context.Context) error { return nil }); err != ErrRunnerClosed {
+		t.Errorf("Enqueue failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrRunnerClosed)
+	}
+}
+
+func Test_Runner_Shutdown_Deadline(t *testing.T) {
+	runner := &Runner{}
+
+	cancelled := make(chan struct{})
+	_ = runner.Enqueue("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := runner.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown failed: Invalid Error\n   actual: %v\n expected: %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Errorf("Shutdown failed: Job context not cancelled")
+	}
+}
+
+func Test_Runner_Schedule(t *testing.T) {
+	router := chain.New()
+	runner := &Runner{}
+	runner.Attach(router)
+
+	var executed atomic.Int32
+	var concurrent atomic.Int32
+	var overlapped atomic.Bool
+	_, err := runner.ScheduleFunc(Every(time.Millisecond), "tick", func(ctx context.Context) error {
+		if concurrent.Add(1) > 1 {
+			overlapped.Store(true)
+		}
+		time.Sleep(3 * time.Millisecond)
+		concurrent.Add(-1)
+		executed.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Schedule failed: Unexpected Error\n   actual: %v", err)
+	}
+
+	if _, err = runner.Schedule("invalid", "invalid", func(ctx context.Context) error { return nil }); err == nil {
+		t.Errorf("Schedule failed: Expected Error")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err = router.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: Unexpected Error\n   actual: %v", err)
+	}
+
+	if executed.Load() == 0 {
+		t.Errorf("Schedule failed: Job not executed")
+	}
+	if overlapped.Load() {
+		t.Errorf("Schedule failed: Executions overlapped")
+	}
+	count := executed.Load()
+	time.Sleep(10 * time.Millisecond)
+	if executed.Load() != count {
+		t.Errorf("Shutdown failed: Schedule not stopped\n   actual: %v\n expected: %v", executed.Load(), count)
+	}
+}
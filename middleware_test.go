@@ -174,3 +174,123 @@ func Test_Middleware_Abort(t *testing.T) {
 		t.Errorf("router.Use() failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "ACD")
 	}
 }
+
+func Test_Middleware_Abort_Context(t *testing.T) {
+	signature := ""
+	router := New()
+	router.Use(func(c *Context, next func() error) error {
+		signature += "A"
+		err := next()
+		signature += "a"
+		return err
+	})
+	router.Use("/private/*", func(c *Context) {
+		signature += "B"
+		c.AbortWithStatus(http.StatusUnauthorized)
+	})
+	router.Use(func(c *Context) {
+		signature += "C"
+	})
+	router.GET("/private/:id", func(c *Context) error {
+		signature += "X"
+		return nil
+	})
+
+	w := PerformRequest(router, "GET", "/private/1")
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("ctx.Abort() failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusUnauthorized)
+	}
+	if signature != "ABa" {
+		t.Errorf("ctx.Abort() failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "ABa")
+	}
+}
+
+func Test_Middleware_Priority(t *testing.T) {
+	signature := ""
+	router := New()
+	router.Use(func(c *Context) {
+		signature += "A"
+	})
+	router.GET("/", func(c *Context) error {
+		signature += "X"
+		return nil
+	})
+	router.Use(PriorityLast, func(c *Context) {
+		signature += "B"
+	})
+	router.Use(PriorityFirst, func(c *Context) {
+		signature += "C"
+	}, func(c *Context) {
+		signature += "D"
+	})
+	router.Use(func(c *Context) {
+		signature += "E"
+	})
+
+	PerformRequest(router, "GET", "/")
+
+	if signature != "CDAEBX" {
+		t.Errorf("Priority failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "CDAEBX")
+	}
+
+	// routes registered after the middlewares
+	signature = ""
+	router.GET("/late", func(c *Context) error {
+		signature += "X"
+		return nil
+	})
+	PerformRequest(router, "GET", "/late")
+
+	if signature != "CDAEBX" {
+		t.Errorf("Priority failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "CDAEBX")
+	}
+}
+
+func Test_Middleware_UseIf(t *testing.T) {
+	signature := ""
+	router := New()
+	router.Use(func(c *Context) {
+		signature += "A"
+	})
+	router.UseIf(func(c *Context) bool {
+		return c.Request.Header.Get("X-Feature") == "on"
+	}, func(c *Context) {
+		signature += "B"
+	}, func(c *Context) {
+		signature += "C"
+	})
+	router.UseIf(func(c *Context) bool {
+		return true
+	}, "GET", "/admin/*", func(c *Context) {
+		signature += "D"
+	})
+	router.GET("/", func(c *Context) error {
+		signature += "X"
+		return nil
+	})
+	router.GET("/admin/users", func(c *Context) error {
+		signature += "X"
+		return nil
+	})
+
+	tests := []struct {
+		path     string
+		feature  string
+		expected string
+	}{
+		{"/", "", "AX"},
+		{"/", "on", "ABCX"},
+		{"/admin/users", "", "ADX"},
+		{"/admin/users", "on", "ABCDX"},
+	}
+	for _, tt := range tests {
+		signature = ""
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		req.Header.Set("X-Feature", tt.feature)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if signature != tt.expected {
+			t.Errorf("UseIf failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, tt.expected)
+		}
+	}
+}
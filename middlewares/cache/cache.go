@@ -0,0 +1,245 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+const (
+	DefaultTTL         = time.Minute
+	DefaultMaxBodySize = 1 << 20
+)
+
+// Cache middleware that caches GET (and HEAD) responses in a Store, keyed by path, query, host and Vary headers.
+//
+// Cache-Control is honored: requests with "no-cache" or "no-store" bypass the cache, responses with "no-store",
+// "no-cache" or "private" are not stored and "s-maxage"/"max-age" define the TTL of the entry (see chain.WithCache).
+// Only 200 responses without Set-Cookie are cached. Responses served from the cache have the "X-Cache: HIT" header.
+//
+// Requests with credentials (Authorization or Cookie headers) are personalized by default: their responses are only
+// stored, and they are only served from the cache, when the response is explicitly "Cache-Control: public". Responses
+// with a Vary header that lists request headers not configured in Cache.Vary (or "*") are not stored.
+//
+// ## Example
+//
+//	responseCache := &cache.Cache{TTL: 30 * time.Second, Vary: []string{"Accept-Language"}}
+//	router.Use("/products/*", responseCache)
+//
+//	router.POST("/products/:id", func(ctx *chain.Context) error {
+//		// ...
+//		responseCache.Invalidate("/products/" + ctx.GetParam("id"))
+//		return nil
+//	})
+type Cache struct {
+	TTL         time.Duration // TTL used when the response does not define max-age. Default DefaultTTL
+	Vary        []string      // Request headers that are part of the cache key
+	MaxBodySize int           // Responses larger than this are not cached. Default DefaultMaxBodySize
+	Store       Store         // Default MemoryStore. See PubSubStore for cluster-wide invalidation
+}
+
+func (c *Cache) Init(method string, path string, router *chain.Router) {
+	if c.TTL <= 0 {
+		c.TTL = DefaultTTL
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = DefaultMaxBodySize
+	}
+	if c.Store == nil {
+		c.Store = &MemoryStore{}
+	}
+}
+
+func (c *Cache) Handle(ctx *chain.Context, next func() error) error {
+	req := ctx.Request
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return next()
+	}
+
+	requestDirectives := parseCacheControl(req.Header.Get("Cache-Control"))
+	_, noStore := requestDirectives["no-store"]
+	_, noCache := requestDirectives["no-cache"]
+
+	credentialed := hasCredentials(req)
+	key := c.Key(req)
+	if !noStore && !noCache {
+		if entry, found := c.Store.Get(key); found && (!credentialed || isPublic(entry.Header)) {
+			c.serve(ctx, entry)
+			return nil
+		}
+	}
+
+	spy, isSpy := ctx.Writer.(*chain.ResponseWriterSpy)
+	if !isSpy || noStore {
+		return next()
+	}
+
+	body := &limitedBuffer{max: c.MaxBodySize}
+	spy.Tee(body)
+	_ = ctx.BeforeSend(func() {
+		spy.Header().Set("X-Cache", "MISS")
+	})
+
+	if err := next(); err != nil {
+		return err
+	}
+
+	if body.overflow || spy.Status() != http.StatusOK || spy.Header().Get("Set-Cookie") != "" {
+		return nil
+	}
+
+	if (credentialed && !isPublic(spy.Header())) || !c.varyCovered(spy.Header()) {
+		return nil
+	}
+
+	ttl, cacheable := c.ttl(spy.Header().Get("Cache-Control"))
+	if !cacheable || req.Method != http.MethodGet {
+		return nil
+	}
+
+	header := spy.Header().Clone()
+	header.Del("X-Cache")
+	now := time.Now()
+	c.Store.Set(key, &Entry{
+		Status:  spy.Status(),
+		Header:  header,
+		Body:    bytes.Clone(body.Bytes()),
+		Created: now,
+		Expires: now.Add(ttl),
+	})
+	return nil
+}
+
+// Key the cache key of the request: path, query, host and the values of the Vary headers
+func (c *Cache) Key(req *http.Request) string {
+	var sb strings.Builder
+	sb.WriteString(req.URL.Path)
+	sb.WriteByte('?')
+	sb.WriteString(req.URL.RawQuery)
+	sb.WriteByte('\n')
+	sb.WriteString(strings.ToLower(req.Host))
+	for _, header := range c.Vary {
+		sb.WriteByte('\n')
+		sb.WriteString(req.Header.Get(header))
+	}
+	return sb.String()
+}
+
+// InvalidateKey removes the entry with the given key (see Cache.Key)
+func (c *Cache) InvalidateKey(key string) {
+	c.Store.Delete(key)
+}
+
+// Invalidate removes all entries (any query or Vary values) of the given path
+func (c *Cache) Invalidate(path string) {
+	c.Store.DeletePrefix(path + "?")
+}
+
+// InvalidatePrefix removes all entries whose path starts with the given prefix
+func (c *Cache) InvalidatePrefix(prefix string) {
+	c.Store.DeletePrefix(prefix)
+}
+
+func (c *Cache) serve(ctx *chain.Context, entry *Entry) {
+	header := ctx.Writer.Header()
+	for key, values := range entry.Header {
+		header[key] = values
+	}
+	header.Set("X-Cache", "HIT")
+	header.Set("Age", strconv.Itoa(int(time.Since(entry.Created).Seconds())))
+	ctx.WriteHeader(entry.Status)
+	if ctx.Request.Method != http.MethodHead {
+		_, _ = ctx.Write(entry.Body)
+	}
+}
+
+// ttl gets the TTL of the response from the Cache-Control header
+func (c *Cache) ttl(cacheControl string) (time.Duration, bool) {
+	directives := parseCacheControl(cacheControl)
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, exists := directives[directive]; exists {
+			return 0, false
+		}
+	}
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, exists := directives[directive]; exists {
+			if seconds, err := strconv.Atoi(value); err == nil {
+				if seconds <= 0 {
+					return 0, false
+				}
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+	}
+	return c.TTL, true
+}
+
+// varyCovered checks if all the request headers of the Vary header of the response are part of the cache key
+func (c *Cache) varyCovered(header http.Header) bool {
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return false
+			}
+			covered := false
+			for _, vary := range c.Vary {
+				if strings.EqualFold(vary, name) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasCredentials checks if the request has credentials, whose responses are usually personalized
+func hasCredentials(req *http.Request) bool {
+	return req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != ""
+}
+
+// isPublic checks if the response is explicitly cacheable by shared caches ("Cache-Control: public")
+func isPublic(header http.Header) bool {
+	_, public := parseCacheControl(header.Get("Cache-Control"))["public"]
+	return public
+}
+
+func parseCacheControl(value string) map[string]string {
+	directives := map[string]string{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
+
+// limitedBuffer keeps up to `max` bytes, flagging overflow
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow || b.Len()+len(p) > b.max {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func performRequest(router *chain.Router, url string, header map[string]string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest("GET", url, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+
+func Test_Cache(t *testing.T) {
+	calls := 0
+	responseCache := &Cache{Vary: []string{"Accept-Language"}}
+
+	router := chain.New()
+	router.Use(responseCache)
+	router.GET("/products/:id", func(ctx *chain.Context) error {
+		calls++
+		ctx.Write([]byte(ctx.GetParam("id") + ":" + ctx.Request.Header.Get("Accept-Language") + ":" + strconv.Itoa(calls)))
+		return nil
+	})
+	router.GET("/private", func(ctx *chain.Context) error {
+		calls++
+		ctx.SetHeader("Cache-Control", "private")
+		ctx.Write([]byte(strconv.Itoa(calls)))
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		url    string
+		header map[string]string
+		before func()
+		body   string
+		cache  string
+	}{
+		{"miss", "/products/1", nil, nil, "1::1", "MISS"},
+		{"hit", "/products/1", nil, nil, "1::1", "HIT"},
+		{"vary", "/products/1", map[string]string{"Accept-Language": "pt"}, nil, "1:pt:2", "MISS"},
+		{"vary hit", "/products/1", map[string]string{"Accept-Language": "pt"}, nil, "1:pt:2", "HIT"},
+		{"request no-cache", "/products/1", map[string]string{"Cache-Control": "no-cache"}, nil, "1::3", "MISS"},
+		{"after no-cache", "/products/1", nil, nil, "1::3", "HIT"},
+		{"invalidate", "/products/1", nil, func() { responseCache.Invalidate("/products/1") }, "1::4", "MISS"},
+		{"other path", "/products/2", nil, nil, "2::5", "MISS"},
+		{"invalidate prefix", "/products/2", nil, func() { responseCache.InvalidatePrefix("/products/") }, "2::6", "MISS"},
+		{"private", "/private", nil, nil, "7", "MISS"},
+		{"private again", "/private", nil, nil, "8", "MISS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+			w := performRequest(router, tt.url, tt.header)
+			if w.Body.String() != tt.body {
+				t.Errorf("Cache failed: Invalid Body\n   actual: %v\n expected: %v", w.Body.String(), tt.body)
+			}
+			if actual := w.Header().Get("X-Cache"); actual != tt.cache {
+				t.Errorf("Cache failed: Invalid X-Cache\n   actual: %v\n expected: %v", actual, tt.cache)
+			}
+		})
+	}
+}
+
+func Test_Cache_MaxAge(t *testing.T) {
+	store := &MemoryStore{}
+	router := chain.New()
+	router.Use(&Cache{Store: store})
+	router.GET("/", func(ctx *chain.Context) error {
+		ctx.Write([]byte("ok"))
+		return nil
+	}, chain.WithCache(10*time.Second))
+
+	performRequest(router, "/", nil)
+
+	entry, found := store.Get("/?\n")
+	if !found {
+		t.Fatal("Cache failed: entry not stored")
+	}
+	if ttl := entry.Expires.Sub(entry.Created); ttl != 10*time.Second {
+		t.Errorf("Cache failed: Invalid TTL\n   actual: %v\n expected: %v", ttl, 10*time.Second)
+	}
+}
+
+func Test_Cache_Credentials(t *testing.T) {
+	calls := 0
+	router := chain.New()
+	router.Use(&Cache{})
+	router.GET("/me", func(ctx *chain.Context) error {
+		calls++
+		ctx.Write([]byte(ctx.Request.Header.Get("Authorization") + ":" + strconv.Itoa(calls)))
+		return nil
+	})
+	router.GET("/public", func(ctx *chain.Context) error {
+		calls++
+		ctx.SetHeader("Cache-Control", "public, max-age=60")
+		ctx.Write([]byte(strconv.Itoa(calls)))
+		return nil
+	})
+	router.GET("/vary", func(ctx *chain.Context) error {
+		calls++
+		ctx.SetHeader("Vary", "X-Tenant")
+		ctx.Write([]byte(strconv.Itoa(calls)))
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		url    string
+		header map[string]string
+		body   string
+		cache  string
+	}{
+		{"authorization", "/me", map[string]string{"Authorization": "alice"}, "alice:1", "MISS"},
+		{"authorization other", "/me", map[string]string{"Authorization": "bob"}, "bob:2", "MISS"},
+		{"cookie", "/me", map[string]string{"Cookie": "session=alice"}, ":3", "MISS"},
+		{"anonymous", "/me", nil, ":4", "MISS"},
+		{"anonymous hit", "/me", nil, ":4", "HIT"},
+		{"cookie after anonymous", "/me", map[string]string{"Cookie": "session=alice"}, ":5", "MISS"},
+		{"host", "/me", map[string]string{"Host": "other.example.com"}, ":6", "MISS"},
+		{"public", "/public", map[string]string{"Cookie": "session=alice"}, "7", "MISS"},
+		{"public hit", "/public", map[string]string{"Cookie": "session=bob"}, "7", "HIT"},
+		{"response vary", "/vary", nil, "8", "MISS"},
+		{"response vary again", "/vary", nil, "9", "MISS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", tt.url, nil)
+			for k, v := range tt.header {
+				if k == "Host" {
+					r.Host = v
+				} else {
+					r.Header.Set(k, v)
+				}
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Body.String() != tt.body {
+				t.Errorf("Cache failed: Invalid Body\n   actual: %v\n expected: %v", w.Body.String(), tt.body)
+			}
+			if actual := w.Header().Get("X-Cache"); actual != tt.cache {
+				t.Errorf("Cache failed: Invalid X-Cache\n   actual: %v\n expected: %v", actual, tt.cache)
+			}
+		})
+	}
+}
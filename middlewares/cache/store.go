@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry a cached response
+type Entry struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	Created time.Time
+	Expires time.Time
+}
+
+// Expired checks if the entry is no longer valid
+func (e *Entry) Expired() bool {
+	return !time.Now().Before(e.Expires)
+}
+
+// Store storage of cached responses.
+//
+// Implementations must be safe for concurrent use. See MemoryStore and PubSubStore.
+type Store interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry)
+	Delete(key string)
+	DeletePrefix(prefix string)
+}
+
+// MemoryStore in-memory Store, expired entries are removed on access or by Cleanup
+type MemoryStore struct {
+	mutex   sync.RWMutex
+	entries map[string]*Entry
+}
+
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	s.mutex.RLock()
+	entry, exists := s.entries[key]
+	s.mutex.RUnlock()
+
+	if !exists {
+		return nil, false
+	}
+	if entry.Expired() {
+		s.Delete(key)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *MemoryStore) Set(key string, entry *Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.entries == nil {
+		s.entries = map[string]*Entry{}
+	}
+	s.entries[key] = entry
+}
+
+func (s *MemoryStore) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, key)
+}
+
+func (s *MemoryStore) DeletePrefix(prefix string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Cleanup removes expired entries
+func (s *MemoryStore) Cleanup() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, entry := range s.entries {
+		if entry.Expired() {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"github.com/nidorx/chain/pubsub"
+)
+
+const DefaultInvalidationTopic = "chain.cache.invalidate"
+
+const (
+	invalidateKey    = byte('K')
+	invalidatePrefix = byte('P')
+)
+
+// PubSubStore wraps a Store, broadcasting the invalidations (Delete and DeletePrefix) cluster-wide using pubsub, so
+// all nodes remove the entry from their local Store.
+//
+// ## Example
+//
+//	router.Use(&cache.Cache{
+//		Store: cache.NewPubSubStore(&cache.MemoryStore{}, ""),
+//	})
+type PubSubStore struct {
+	Store
+	topic      string
+	dispatcher pubsub.Dispatcher
+}
+
+// NewPubSubStore creates a PubSubStore that subscribes to the topic (default DefaultInvalidationTopic)
+func NewPubSubStore(store Store, topic string) *PubSubStore {
+	if topic == "" {
+		topic = DefaultInvalidationTopic
+	}
+	s := &PubSubStore{Store: store, topic: topic}
+	s.dispatcher = pubsub.DispatcherFunc(s.dispatch)
+	pubsub.Subscribe(topic, s.dispatcher)
+	return s
+}
+
+// Close stops receiving invalidations from other nodes
+func (s *PubSubStore) Close() {
+	pubsub.Unsubscribe(s.topic, s.dispatcher)
+}
+
+func (s *PubSubStore) Delete(key string) {
+	s.Store.Delete(key)
+	s.broadcast(invalidateKey, key)
+}
+
+func (s *PubSubStore) DeletePrefix(prefix string) {
+	s.Store.DeletePrefix(prefix)
+	s.broadcast(invalidatePrefix, prefix)
+}
+
+func (s *PubSubStore) broadcast(kind byte, value string) {
+	// local invalidation has already been done, errors only affect other nodes
+	_ = pubsub.Broadcast(s.topic, append([]byte{kind}, value...))
+}
+
+func (s *PubSubStore) dispatch(topic string, message any, from string) {
+	if from == pubsub.Self() {
+		return
+	}
+	bytes, ok := message.([]byte)
+	if !ok || len(bytes) == 0 {
+		return
+	}
+	switch bytes[0] {
+	case invalidateKey:
+		s.Store.Delete(string(bytes[1:]))
+	case invalidatePrefix:
+		s.Store.DeletePrefix(string(bytes[1:]))
+	}
+}
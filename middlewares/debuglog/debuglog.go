@@ -0,0 +1,190 @@
+package debuglog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+const (
+	DefaultMaxBodySize = 4 * 1024
+	Redacted           = "[REDACTED]"
+)
+
+// DefaultRedactFields fields redacted when BodyLogger.RedactFields is empty
+var DefaultRedactFields = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token", "api_key", "apikey", "client_secret",
+}
+
+// DefaultRedactHeaders headers redacted when BodyLogger.RedactHeaders is empty
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization", "X-Api-Key"}
+
+// BodyLogger middleware that logs request and response bodies (size-capped), for debugging environments.
+//
+// Values of the fields in RedactFields are replaced by "[REDACTED]" in JSON and form-urlencoded bodies, as well as
+// the headers in RedactHeaders. The response is captured with chain.ResponseWriterSpy.Tee, without buffering, so
+// streaming responses keep working.
+//
+// ## Example
+//
+//	router.Use(&debuglog.BodyLogger{
+//		MaxBodySize:  1024,
+//		RedactFields: []string{"password", "credit_card"},
+//	})
+type BodyLogger struct {
+	MaxBodySize   int          // Max number of bytes captured from each body. Default DefaultMaxBodySize
+	RedactFields  []string     // Body fields redacted (case-insensitive). Default DefaultRedactFields
+	RedactHeaders []string     // Headers redacted. Default DefaultRedactHeaders
+	Level         slog.Level   // Log level. Default slog.LevelDebug
+	Logger        *slog.Logger // Default slog.Default()
+	Skip          func(ctx *chain.Context) bool
+	fieldsRegex   *regexp.Regexp
+	formRegex     *regexp.Regexp
+	headers       map[string]bool
+}
+
+func (l *BodyLogger) Init(method string, path string, router *chain.Router) {
+	if l.MaxBodySize <= 0 {
+		l.MaxBodySize = DefaultMaxBodySize
+	}
+	if len(l.RedactFields) == 0 {
+		l.RedactFields = DefaultRedactFields
+	}
+	if len(l.RedactHeaders) == 0 {
+		l.RedactHeaders = DefaultRedactHeaders
+	}
+
+	var fields []string
+	for _, field := range l.RedactFields {
+		fields = append(fields, regexp.QuoteMeta(field))
+	}
+	names := strings.Join(fields, "|")
+
+	// JSON: "field": "value" | "field": 123 (also works on truncated bodies)
+	l.fieldsRegex = regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	// form-urlencoded: field=value
+	l.formRegex = regexp.MustCompile(`(?i)((?:^|&)(?:` + names + `)=)([^&]*)`)
+
+	l.headers = map[string]bool{}
+	for _, header := range l.RedactHeaders {
+		l.headers[http.CanonicalHeaderKey(header)] = true
+	}
+}
+
+func (l *BodyLogger) Handle(ctx *chain.Context, next func() error) error {
+	if l.Skip != nil && l.Skip(ctx) {
+		return next()
+	}
+
+	logger := l.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !logger.Enabled(ctx.Request.Context(), l.Level) {
+		return next()
+	}
+
+	start := time.Now()
+
+	var requestBody []byte
+	if ctx.Request.Body != nil && ctx.Request.Body != http.NoBody {
+		requestBody = l.captureRequest(ctx.Request)
+	}
+
+	responseBody := &cappedBuffer{max: l.MaxBodySize}
+	spy, isSpy := ctx.Writer.(*chain.ResponseWriterSpy)
+	if isSpy {
+		spy.Tee(responseBody)
+	}
+
+	err := next()
+
+	attrs := []slog.Attr{
+		slog.String("Method", ctx.Request.Method),
+		slog.String("Path", ctx.Request.URL.Path),
+		slog.Duration("Duration", time.Since(start)),
+		slog.Any("RequestHeaders", l.redactHeaders(ctx.Request.Header)),
+		slog.String("RequestBody", l.redactBody(requestBody, ctx.Request.Header.Get("Content-Type"))),
+	}
+	if isSpy {
+		attrs = append(attrs,
+			slog.Int("Status", spy.Status()),
+			slog.Any("ResponseHeaders", l.redactHeaders(spy.Header())),
+			slog.String("ResponseBody", l.redactBody(responseBody.Bytes(), spy.Header().Get("Content-Type"))),
+			slog.Bool("ResponseTruncated", responseBody.truncated),
+		)
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("Error", err))
+	}
+
+	logger.LogAttrs(context.Background(), l.Level, "[chain.debuglog] request", attrs...)
+
+	return err
+}
+
+// captureRequest reads up to MaxBodySize bytes from the request body, restoring the body so handlers can read it
+func (l *BodyLogger) captureRequest(r *http.Request) []byte {
+	original := r.Body
+	captured, _ := io.ReadAll(io.LimitReader(original, int64(l.MaxBodySize)))
+	r.Body = &readCloser{
+		Reader: io.MultiReader(bytes.NewReader(captured), original),
+		Closer: original,
+	}
+	return captured
+}
+
+func (l *BodyLogger) redactBody(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+		return l.formRegex.ReplaceAllString(string(body), "${1}"+Redacted)
+	}
+	return l.fieldsRegex.ReplaceAllString(string(body), `${1}"`+Redacted+`"`)
+}
+
+func (l *BodyLogger) redactHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for key, values := range header {
+		if l.headers[http.CanonicalHeaderKey(key)] {
+			out[key] = Redacted
+		} else {
+			out[key] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// cappedBuffer keeps the first `max` bytes written, discarding the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.max - b.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.Buffer.Write(p[:remaining])
+			b.truncated = true
+		} else {
+			b.Buffer.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
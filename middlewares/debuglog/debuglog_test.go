@@ -0,0 +1,83 @@
+package debuglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_BodyLogger(t *testing.T) {
+	var output bytes.Buffer
+
+	router := chain.New()
+	router.Use(&BodyLogger{
+		MaxBodySize: 64,
+		Logger:      slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+
+	var received string
+	router.POST("/login", func(ctx *chain.Context) error {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		received = string(body)
+		ctx.SetHeader("Content-Type", "application/json")
+		ctx.Write([]byte(`{"user":"alex","token":"abc123","data":"` + strings.Repeat("x", 100) + `"}`))
+		return nil
+	})
+
+	requestBody := `{"user":"alex","password":"s3cr3t"}`
+	r, _ := http.NewRequest("POST", "/login", strings.NewReader(requestBody))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer abc123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if received != requestBody {
+		t.Errorf("BodyLogger failed: Invalid request body\n   actual: %v\n expected: %v", received, requestBody)
+	}
+	if !strings.HasPrefix(w.Body.String(), `{"user":"alex","token":"abc123"`) || w.Body.Len() < 100 {
+		t.Errorf("BodyLogger failed: Invalid response body\n   actual: %v", w.Body.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(output.Bytes(), &entry); err != nil {
+		t.Fatalf("BodyLogger failed: invalid log entry: %v\n%s", err, output.String())
+	}
+
+	tests := []struct {
+		key      string
+		expected any
+	}{
+		{"RequestBody", `{"user":"alex","password":"[REDACTED]"}`},
+		{"ResponseBody", `{"user":"alex","token":"[REDACTED]","data":"` + strings.Repeat("x", 24)},
+		{"ResponseTruncated", true},
+		{"Status", float64(200)},
+	}
+	for _, tt := range tests {
+		if entry[tt.key] != tt.expected {
+			t.Errorf("BodyLogger failed: Invalid %s\n   actual: %v\n expected: %v", tt.key, entry[tt.key], tt.expected)
+		}
+	}
+
+	headers := entry["RequestHeaders"].(map[string]any)
+	if headers["Authorization"] != Redacted {
+		t.Errorf("BodyLogger failed: Invalid Authorization\n   actual: %v\n expected: %v", headers["Authorization"], Redacted)
+	}
+}
+
+func Test_BodyLogger_Redact_Form(t *testing.T) {
+	l := &BodyLogger{}
+	l.Init("", "", nil)
+
+	actual := l.redactBody([]byte("user=alex&password=123&Token=xyz"), "application/x-www-form-urlencoded")
+	expected := "user=alex&password=[REDACTED]&Token=[REDACTED]"
+	if actual != expected {
+		t.Errorf("BodyLogger failed: Invalid form redaction\n   actual: %v\n expected: %v", actual, expected)
+	}
+}
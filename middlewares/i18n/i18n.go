@@ -0,0 +1,202 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nidorx/chain"
+)
+
+const (
+	DefaultCookie     = "locale"
+	DefaultQueryParam = "locale"
+)
+
+// Catalog the messages of the locales, see MapCatalog
+type Catalog interface {
+	// Message gets the message of the key in the locale
+	Message(locale string, key string) (message string, exists bool)
+}
+
+// MapCatalog in-memory Catalog, messages by key by locale
+//
+// ## Example
+//
+//	catalog := i18n.MapCatalog{
+//		"en":    {"hello": "Hello, %s!"},
+//		"pt-BR": {"hello": "Olá, %s!"},
+//	}
+type MapCatalog map[string]map[string]string
+
+func (c MapCatalog) Message(locale string, key string) (string, bool) {
+	message, exists := c[locale][key]
+	return message, exists
+}
+
+// I18n middleware that negotiates the locale of the request and defines the Translator of the Context, so handlers can
+// localize the responses with ctx.T (see chain.Context.T).
+//
+// The locale is selected from the query param (QueryParam), the cookie (Cookie) or the Accept-Language header, in that
+// order, and must be one of the supported Locales. A language without region matches the regional locales ("pt"
+// selects "pt-BR") and vice versa. When no locale matches, the first of Locales is used.
+//
+// Messages are formatted with fmt.Sprintf when ctx.T receives arguments. When a message does not exist on the locale,
+// the language ("pt" for "pt-BR") and the default locale are tried, then the key is returned.
+//
+// ## Example
+//
+//	router.Use(&i18n.I18n{
+//		Locales: []string{"en", "pt-BR", "es"},
+//		Catalog: i18n.MapCatalog{
+//			"en":    {"hello": "Hello, %s!"},
+//			"pt-BR": {"hello": "Olá, %s!"},
+//		},
+//	})
+//
+//	router.GET("/hello", func(ctx *chain.Context) error {
+//		ctx.Write([]byte(ctx.T("hello", "Alex"))) // Accept-Language: pt-BR,pt;q=0.9 -> "Olá, Alex!"
+//		return nil
+//	})
+type I18n struct {
+	Locales    []string          // Supported locales, the first is the default
+	Catalog    Catalog           // Messages of the locales
+	Cookie     string            // Cookie that overrides the Accept-Language. Default DefaultCookie
+	QueryParam string            // Query param that overrides the cookie and the Accept-Language. Default DefaultQueryParam
+	locales    map[string]string // supported locales by lower case name
+}
+
+func (i *I18n) Init(method string, path string, router *chain.Router) {
+	if len(i.Locales) == 0 {
+		panic(fmt.Sprintf("[chain.i18n] is necessary to inform the supported locales. Path: %s", path))
+	}
+	if i.Catalog == nil {
+		i.Catalog = MapCatalog{}
+	}
+	if i.Cookie == "" {
+		i.Cookie = DefaultCookie
+	}
+	if i.QueryParam == "" {
+		i.QueryParam = DefaultQueryParam
+	}
+	i.locales = map[string]string{}
+	for _, locale := range i.Locales {
+		i.locales[strings.ToLower(locale)] = locale
+	}
+}
+
+func (i *I18n) Handle(ctx *chain.Context, next func() error) error {
+	ctx.SetTranslator(&translator{locale: i.Negotiate(ctx.Request), i18n: i})
+	return next()
+}
+
+// Negotiate selects the locale of the request
+func (i *I18n) Negotiate(req *http.Request) string {
+	if locale, ok := i.match(req.URL.Query().Get(i.QueryParam)); ok {
+		return locale
+	}
+	if cookie, err := req.Cookie(i.Cookie); err == nil {
+		if locale, ok := i.match(cookie.Value); ok {
+			return locale
+		}
+	}
+	for _, tag := range parseAcceptLanguage(req.Header.Get("Accept-Language")) {
+		if locale, ok := i.match(tag); ok {
+			return locale
+		}
+	}
+	return i.Locales[0]
+}
+
+// match finds the supported locale of the language tag
+func (i *I18n) match(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" {
+		return "", false
+	}
+	if locale, exists := i.locales[tag]; exists {
+		return locale, true
+	}
+	// "pt-BR" -> "pt"
+	language, _, _ := strings.Cut(tag, "-")
+	if locale, exists := i.locales[language]; exists {
+		return locale, true
+	}
+	// "pt" -> "pt-BR"
+	for _, locale := range i.Locales {
+		if prefix, _, _ := strings.Cut(strings.ToLower(locale), "-"); prefix == language {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// translate the message of the key, using the language and the default locale as fallback
+func (i *I18n) translate(locale string, key string, args ...any) string {
+	message, exists := i.Catalog.Message(locale, key)
+	if !exists {
+		if language, _, found := strings.Cut(locale, "-"); found {
+			message, exists = i.Catalog.Message(language, key)
+		}
+	}
+	if !exists && locale != i.Locales[0] {
+		message, exists = i.Catalog.Message(i.Locales[0], key)
+	}
+	if !exists {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// translator chain.Translator of the request
+type translator struct {
+	locale string
+	i18n   *I18n
+}
+
+func (t *translator) Locale() string {
+	return t.locale
+}
+
+func (t *translator) Translate(key string, args ...any) string {
+	return t.i18n.translate(t.locale, key, args...)
+}
+
+// parseAcceptLanguage gets the language tags of the Accept-Language header, sorted by quality (q=0 are ignored)
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var tags []weighted
+	for _, value := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(value), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag, quality})
+		}
+	}
+	sort.SliceStable(tags, func(a, b int) bool {
+		return tags[a].quality > tags[b].quality
+	})
+
+	result := make([]string, len(tags))
+	for index, tag := range tags {
+		result[index] = tag.tag
+	}
+	return result
+}
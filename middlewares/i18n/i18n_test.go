@@ -0,0 +1,75 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_I18n(t *testing.T) {
+	router := chain.New()
+	router.Use(&I18n{
+		Locales: []string{"en", "pt-BR", "es"},
+		Catalog: MapCatalog{
+			"en":    {"hello": "Hello, %s!", "bye": "Bye"},
+			"pt":    {"bye": "Tchau"},
+			"pt-BR": {"hello": "Olá, %s!"},
+			"es":    {"hello": "¡Hola, %s!"},
+		},
+	})
+	router.GET("/hello", func(ctx *chain.Context) error {
+		ctx.Write([]byte(ctx.Locale() + "|" + ctx.T("hello", "Alex") + "|" + ctx.T("bye") + "|" + ctx.T("missing")))
+		return nil
+	})
+
+	tests := []struct {
+		path     string
+		language string
+		cookie   string
+		expected string
+	}{
+		{"/hello", "", "", "en|Hello, Alex!|Bye|missing"},
+		{"/hello", "pt-BR,pt;q=0.9,en;q=0.8", "", "pt-BR|Olá, Alex!|Tchau|missing"},
+		{"/hello", "pt", "", "pt-BR|Olá, Alex!|Tchau|missing"},
+		{"/hello", "fr-FR, es;q=0.5, en;q=0.7", "", "en|Hello, Alex!|Bye|missing"},
+		{"/hello", "fr-FR, es-AR;q=0.9, en;q=0", "", "es|¡Hola, Alex!|Bye|missing"},
+		{"/hello", "en", "pt-br", "pt-BR|Olá, Alex!|Tchau|missing"},
+		{"/hello?locale=es", "en", "pt-BR", "es|¡Hola, Alex!|Bye|missing"},
+		{"/hello?locale=invalid", "es", "", "es|¡Hola, Alex!|Bye|missing"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.language != "" {
+			req.Header.Set("Accept-Language", tt.language)
+		}
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: DefaultCookie, Value: tt.cookie})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if body := w.Body.String(); body != tt.expected {
+			t.Errorf("I18n failed: Invalid response of %s (%s, %s)\n   actual: %v\n expected: %v", tt.path, tt.language, tt.cookie, body, tt.expected)
+		}
+	}
+}
+
+func Test_parseAcceptLanguage(t *testing.T) {
+	actual := parseAcceptLanguage("da, en-gb;q=0.8, en;q=0.7, *;q=0.1, fr;q=0, de;q=invalid")
+	expected := []string{"da", "en-gb", "en"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("parseAcceptLanguage() failed: Invalid tags\n   actual: %v\n expected: %v", actual, expected)
+	}
+}
+
+func Test_Context_T_Without_Translator(t *testing.T) {
+	ctx := &chain.Context{}
+	if value := ctx.T("hello"); value != "hello" {
+		t.Errorf("T() failed: Invalid value\n   actual: %v\n expected: %v", value, "hello")
+	}
+	if locale := ctx.Locale(); locale != "" {
+		t.Errorf("Locale() failed: Invalid value\n   actual: %v\n expected: %v", locale, "")
+	}
+}
@@ -0,0 +1,96 @@
+package ipfilter
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+
+	"github.com/nidorx/chain"
+)
+
+// Decider custom decision of the filter (ex. geo lookups), receives the client IP and the decision of the Allow and
+// Deny lists, returns if the request is allowed. The IP is invalid (netip.Addr.IsValid) when it could not be resolved.
+type Decider func(ctx *chain.Context, ip netip.Addr, allowed bool) bool
+
+// Filter middleware that allows or denies requests based on the client IP (see chain.Context.ClientIP, which resolves
+// the IP from the trusted proxies of the router).
+//
+// IPs in the Deny list are always denied. When the Allow list is defined, only the IPs in the list are allowed. The
+// lists accept IPs and CIDR ranges (IPv4 and IPv6). Requests whose IP cannot be resolved are denied when Allow is
+// defined.
+//
+// The path informed in Router.Use limits the routes filtered, allowing per-route configuration.
+//
+// ## Example
+//
+//	router.SetTrustedProxies("10.0.0.0/8")
+//
+//	router.Use("/admin/*", &ipfilter.Filter{
+//		Allow: []string{"192.168.0.0/16", "2001:db8::/32"},
+//	})
+//
+//	router.Use(&ipfilter.Filter{
+//		Deny: []string{"203.0.113.7"},
+//		Decide: func(ctx *chain.Context, ip netip.Addr, allowed bool) bool {
+//			return allowed && geo.Country(ip) != "XX"
+//		},
+//	})
+type Filter struct {
+	Allow   []string                 // IPs or CIDR ranges allowed, all the other IPs are denied. Allows all if empty
+	Deny    []string                 // IPs or CIDR ranges denied, takes priority over Allow
+	Decide  Decider                  // Custom decision, invoked for each request after the lists
+	Status  int                      // Status of the denied requests. Default http.StatusForbidden
+	OnDeny  func(ctx *chain.Context) // Replies the denied requests, replaces the default reply (Status)
+	allowed []netip.Prefix
+	denied  []netip.Prefix
+}
+
+func (f *Filter) Init(method string, path string, router *chain.Router) {
+	var err error
+	if f.allowed, err = chain.ParseIPRanges(f.Allow...); err != nil {
+		panic(fmt.Sprintf("[chain.ipfilter] invalid Allow list. Path: %s, Error: %s", path, err.Error()))
+	}
+	if f.denied, err = chain.ParseIPRanges(f.Deny...); err != nil {
+		panic(fmt.Sprintf("[chain.ipfilter] invalid Deny list. Path: %s, Error: %s", path, err.Error()))
+	}
+	if f.Status == 0 {
+		f.Status = http.StatusForbidden
+	}
+}
+
+func (f *Filter) Handle(ctx *chain.Context, next func() error) error {
+	ip, _ := ctx.ClientAddr()
+	if f.Allowed(ctx, ip) {
+		return next()
+	}
+
+	slog.Debug(
+		"[chain.ipfilter] request denied",
+		slog.String("IP", ip.String()),
+		slog.String("Path", ctx.Request.URL.Path),
+	)
+	if f.OnDeny != nil {
+		f.OnDeny(ctx)
+	} else {
+		ctx.Error(http.StatusText(f.Status), f.Status)
+	}
+	return nil
+}
+
+// Allowed checks if the IP is allowed by the filter
+func (f *Filter) Allowed(ctx *chain.Context, ip netip.Addr) bool {
+	allowed := true
+	if !ip.IsValid() {
+		allowed = len(f.allowed) == 0
+	} else if chain.IPRangesContain(f.denied, ip) {
+		allowed = false
+	} else if len(f.allowed) > 0 {
+		allowed = chain.IPRangesContain(f.allowed, ip)
+	}
+
+	if f.Decide != nil {
+		allowed = f.Decide(ctx, ip, allowed)
+	}
+	return allowed
+}
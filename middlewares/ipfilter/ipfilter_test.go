@@ -0,0 +1,75 @@
+package ipfilter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Filter(t *testing.T) {
+	router := chain.New()
+	if err := router.SetTrustedProxies("10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	router.Use("/admin/*", &Filter{
+		Allow: []string{"192.168.0.0/16", "2001:db8::/32"},
+		Deny:  []string{"192.168.1.1"},
+	})
+	router.Use("/public/*", &Filter{
+		Deny:   []string{"203.0.113.0/24"},
+		Status: http.StatusUnavailableForLegalReasons,
+		Decide: func(ctx *chain.Context, ip netip.Addr, allowed bool) bool {
+			return allowed && ip != netip.MustParseAddr("198.51.100.9")
+		},
+	})
+
+	handler := func(ctx *chain.Context) error {
+		ctx.Write([]byte("ok"))
+		return nil
+	}
+	router.GET("/admin/users", handler)
+	router.GET("/public/posts", handler)
+
+	tests := []struct {
+		path     string
+		remote   string
+		forward  string
+		expected int
+	}{
+		{"/admin/users", "192.168.0.10:1234", "", http.StatusOK},
+		{"/admin/users", "[2001:db8::5]:1234", "", http.StatusOK},
+		{"/admin/users", "192.168.1.1:1234", "", http.StatusForbidden},
+		{"/admin/users", "203.0.113.1:1234", "", http.StatusForbidden},
+		{"/admin/users", "10.0.0.1:1234", "192.168.0.10", http.StatusOK},
+		{"/admin/users", "10.0.0.1:1234", "203.0.113.1", http.StatusForbidden},
+		{"/admin/users", "192.168.0.10:1234", "203.0.113.1", http.StatusOK}, // untrusted proxy
+		{"/admin/users", "invalid", "", http.StatusForbidden},
+		{"/public/posts", "192.168.0.10:1234", "", http.StatusOK},
+		{"/public/posts", "203.0.113.1:1234", "", http.StatusUnavailableForLegalReasons},
+		{"/public/posts", "198.51.100.9:1234", "", http.StatusUnavailableForLegalReasons},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.RemoteAddr = tt.remote
+		if tt.forward != "" {
+			req.Header.Set("X-Forwarded-For", tt.forward)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.expected {
+			t.Errorf("Filter failed: Invalid status of %s from %s (%s)\n   actual: %v\n expected: %v", tt.path, tt.remote, tt.forward, w.Code, tt.expected)
+		}
+	}
+}
+
+func Test_Filter_Invalid_List(t *testing.T) {
+	defer func() {
+		if rcv := recover(); rcv == nil {
+			t.Errorf("Init() failed: expected panic for invalid CIDR")
+		}
+	}()
+	chain.New().Use(&Filter{Allow: []string{"192.168.0.0/40"}})
+}
@@ -0,0 +1,211 @@
+package maintenance
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/crypto"
+	"github.com/nidorx/chain/pubsub"
+)
+
+const (
+	DefaultRetryAfter   = 5 * time.Minute
+	DefaultBypassHeader = "X-Maintenance-Bypass"
+	DefaultBypassCookie = "_maintenance_bypass"
+	DefaultTopic        = "chain.maintenance"
+)
+
+const (
+	bypassPrefix = "chain.maintenance.bypass:"
+	toggleOn     = byte('1')
+	toggleOff    = byte('0')
+)
+
+var (
+	defaultSalt = "chain.middleware.maintenance.keyring.salt"
+
+	ErrInvalidBypassToken = errors.New("invalid maintenance bypass token")
+	ErrExpiredBypassToken = errors.New("expired maintenance bypass token")
+	ErrNoKeyring          = errors.New("maintenance bypass tokens require a Keyring, register the middleware with Router.Use")
+)
+
+// Maintenance middleware that replies 503 (Service Unavailable) with the Retry-After header to all requests while the
+// maintenance mode is enabled, useful during deployments. The mode is toggled at runtime with Enable and Disable.
+//
+// Requests with a valid bypass token (see BypassToken), sent in the BypassHeader header or in the BypassCookie cookie
+// (see SetBypassCookie), are not blocked, allowing the team to check the application before opening it to everyone.
+// Tokens are signed with keys derived from the SecretKeyBase of the router (see chain.Router.DeriveKeyring).
+//
+// With PubSub enabled, the toggles are broadcast to all the nodes of the cluster.
+//
+// ## Example
+//
+//	var mode = &maintenance.Maintenance{PubSub: true}
+//
+//	router.Use(mode)
+//
+//	router.POST("/admin/maintenance", func(ctx *chain.Context) error {
+//		mode.Enable()
+//		token, err := mode.BypassToken(time.Hour)
+//		// ...
+//	})
+type Maintenance struct {
+	RetryAfter   time.Duration            // Value of the Retry-After header. Default DefaultRetryAfter
+	BypassHeader string                   // Header with the bypass token. Default DefaultBypassHeader
+	BypassCookie string                   // Cookie with the bypass token. Default DefaultBypassCookie
+	Keyring      *crypto.Keyring          // Signs the bypass tokens. Default, a Keyring derived from the router SecretKeyBase
+	PubSub       bool                     // Broadcasts the toggles to the cluster (see pubsub.Broadcast)
+	Topic        string                   // Topic of the toggles. Default DefaultTopic
+	OnBlock      func(ctx *chain.Context) // Replies the blocked requests (ex. maintenance page), the Retry-After is already set
+	enabled      atomic.Bool
+	initOnce     sync.Once
+	dispatcher   pubsub.Dispatcher
+}
+
+func (m *Maintenance) Init(method string, path string, router *chain.Router) {
+	m.initOnce.Do(func() {
+		if m.RetryAfter <= 0 {
+			m.RetryAfter = DefaultRetryAfter
+		}
+		if m.BypassHeader == "" {
+			m.BypassHeader = DefaultBypassHeader
+		}
+		if m.BypassCookie == "" {
+			m.BypassCookie = DefaultBypassCookie
+		}
+		if m.Topic == "" {
+			m.Topic = DefaultTopic
+		}
+
+		if m.Keyring == nil {
+			m.Keyring = router.DeriveKeyring(defaultSalt, 32, nil)
+		}
+
+		if m.PubSub {
+			m.dispatcher = pubsub.DispatcherFunc(m.dispatch)
+			pubsub.Subscribe(m.Topic, m.dispatcher)
+		}
+	})
+}
+
+func (m *Maintenance) Handle(ctx *chain.Context, next func() error) error {
+	if !m.enabled.Load() || m.bypass(ctx) {
+		return next()
+	}
+
+	ctx.SetHeader("Retry-After", strconv.Itoa(int(m.RetryAfter.Seconds())))
+	if m.OnBlock != nil {
+		m.OnBlock(ctx)
+	} else {
+		ctx.Error(http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	}
+	return nil
+}
+
+// Enable the maintenance mode
+func (m *Maintenance) Enable() {
+	m.set(true)
+}
+
+// Disable the maintenance mode
+func (m *Maintenance) Disable() {
+	m.set(false)
+}
+
+// Enabled checks if the maintenance mode is enabled
+func (m *Maintenance) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Close stops receiving the toggles from other nodes
+func (m *Maintenance) Close() {
+	if m.dispatcher != nil {
+		pubsub.Unsubscribe(m.Topic, m.dispatcher)
+	}
+}
+
+func (m *Maintenance) set(enabled bool) {
+	m.enabled.Store(enabled)
+	if m.PubSub {
+		toggle := toggleOff
+		if enabled {
+			toggle = toggleOn
+		}
+		// local toggle has already been done, errors only affect other nodes
+		_ = pubsub.Broadcast(m.Topic, []byte{toggle})
+	}
+}
+
+func (m *Maintenance) dispatch(topic string, message any, from string) {
+	if from == pubsub.Self() {
+		return
+	}
+	if bytes, ok := message.([]byte); ok && len(bytes) == 1 {
+		m.enabled.Store(bytes[0] == toggleOn)
+	}
+}
+
+// BypassToken creates a signed token that bypasses the maintenance mode until it expires. Returns ErrNoKeyring if the
+// Keyring is not defined and the middleware was not registered in a router (see Router.Use).
+func (m *Maintenance) BypassToken(ttl time.Duration) (string, error) {
+	if m.Keyring == nil {
+		return "", ErrNoKeyring
+	}
+	expires := time.Now().Add(ttl).Unix()
+	return m.Keyring.MessageSign([]byte(bypassPrefix+strconv.FormatInt(expires, 10)), "sha256")
+}
+
+// VerifyBypassToken checks if the token was created by BypassToken and is not expired. Returns ErrNoKeyring if the
+// Keyring is not defined and the middleware was not registered in a router (see Router.Use).
+func (m *Maintenance) VerifyBypassToken(token string) error {
+	if m.Keyring == nil {
+		return ErrNoKeyring
+	}
+	decoded, err := m.Keyring.MessageVerify([]byte(token))
+	if err != nil {
+		return ErrInvalidBypassToken
+	}
+	expires, found := strings.CutPrefix(string(decoded), bypassPrefix)
+	if !found {
+		return ErrInvalidBypassToken
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidBypassToken
+	}
+	if time.Now().Unix() > unix {
+		return ErrExpiredBypassToken
+	}
+	return nil
+}
+
+// SetBypassCookie sends the bypass cookie to the client (see chain.Context.SetSecureCookie), the next requests of the
+// browser bypass the maintenance mode until the token expires.
+func (m *Maintenance) SetBypassCookie(ctx *chain.Context, ttl time.Duration) error {
+	token, err := m.BypassToken(ttl)
+	if err != nil {
+		return err
+	}
+	return ctx.SetSecureCookie(&http.Cookie{
+		Name:   m.BypassCookie,
+		Value:  token,
+		Path:   "/",
+		MaxAge: int(ttl.Seconds()),
+	})
+}
+
+func (m *Maintenance) bypass(ctx *chain.Context) bool {
+	if token := ctx.Request.Header.Get(m.BypassHeader); token != "" && m.VerifyBypassToken(token) == nil {
+		return true
+	}
+	if cookie := ctx.GetCookie(m.BypassCookie); cookie != nil && m.VerifyBypassToken(cookie.Value) == nil {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package maintenance
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func newTestRouter(t *testing.T, mode *Maintenance) *chain.Router {
+	router := chain.New()
+	if err := router.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(mode)
+	router.GET("/", func(ctx *chain.Context) error {
+		ctx.Write([]byte("ok"))
+		return nil
+	})
+	router.GET("/bypass", func(ctx *chain.Context) error {
+		return mode.SetBypassCookie(ctx, time.Hour)
+	})
+	return router
+}
+
+func serve(router *chain.Router, path string, prepare func(req *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if prepare != nil {
+		prepare(req)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func Test_Maintenance(t *testing.T) {
+	mode := &Maintenance{RetryAfter: 2 * time.Minute}
+	router := newTestRouter(t, mode)
+
+	if w := serve(router, "/", nil); w.Code != http.StatusOK {
+		t.Errorf("Maintenance failed: Invalid status when disabled\n   actual: %v\n expected: %v", w.Code, http.StatusOK)
+	}
+
+	mode.Enable()
+	w := serve(router, "/", nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Maintenance failed: Invalid status when enabled\n   actual: %v\n expected: %v", w.Code, http.StatusServiceUnavailable)
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "120" {
+		t.Errorf("Maintenance failed: Invalid Retry-After\n   actual: %v\n expected: %v", retryAfter, "120")
+	}
+
+	token, err := mode.BypassToken(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(router, "/", func(req *http.Request) {
+		req.Header.Set(DefaultBypassHeader, token)
+	}); w.Code != http.StatusOK {
+		t.Errorf("Maintenance failed: Invalid status with bypass header\n   actual: %v\n expected: %v", w.Code, http.StatusOK)
+	}
+	if w := serve(router, "/", func(req *http.Request) {
+		req.Header.Set(DefaultBypassHeader, token+"x")
+	}); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Maintenance failed: Invalid status with tampered token\n   actual: %v\n expected: %v", w.Code, http.StatusServiceUnavailable)
+	}
+
+	mode.Disable()
+	cookies := serve(router, "/bypass", nil).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != DefaultBypassCookie {
+		t.Fatalf("SetBypassCookie() failed: cookie not sent")
+	}
+	mode.Enable()
+	if w := serve(router, "/", func(req *http.Request) {
+		req.AddCookie(cookies[0])
+	}); w.Code != http.StatusOK {
+		t.Errorf("Maintenance failed: Invalid status with bypass cookie\n   actual: %v\n expected: %v", w.Code, http.StatusOK)
+	}
+}
+
+func Test_Maintenance_Expired_Token(t *testing.T) {
+	mode := &Maintenance{}
+	newTestRouter(t, mode)
+
+	token, err := mode.BypassToken(-time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mode.VerifyBypassToken(token); !errors.Is(err, ErrExpiredBypassToken) {
+		t.Errorf("VerifyBypassToken() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrExpiredBypassToken)
+	}
+	if err := mode.VerifyBypassToken("invalid"); !errors.Is(err, ErrInvalidBypassToken) {
+		t.Errorf("VerifyBypassToken() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrInvalidBypassToken)
+	}
+}
+
+func Test_Maintenance_Router_Keyring_Not_Shared(t *testing.T) {
+	mode := &Maintenance{}
+	router := chain.New()
+	if err := router.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		t.Fatal(err)
+	}
+	router.Keyring = router.NewKeyring("maintenance.router.keyring.salt", 1000, 32, "sha256")
+	router.Use(mode)
+
+	// a message signed by the application with the router keyring (ex. user input) is not a bypass token
+	forged, err := router.Keyring.MessageSign([]byte(bypassPrefix+"9999999999"), "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mode.VerifyBypassToken(forged); !errors.Is(err, ErrInvalidBypassToken) {
+		t.Errorf("VerifyBypassToken() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrInvalidBypassToken)
+	}
+}
+
+func Test_Maintenance_PubSub_Toggle(t *testing.T) {
+	mode := &Maintenance{PubSub: true}
+	newTestRouter(t, mode)
+	defer mode.Close()
+
+	mode.dispatch(DefaultTopic, []byte{toggleOn}, "other-node")
+	if !mode.Enabled() {
+		t.Errorf("Maintenance failed: toggle from other node not applied")
+	}
+	mode.dispatch(DefaultTopic, []byte{toggleOff}, "other-node")
+	if mode.Enabled() {
+		t.Errorf("Maintenance failed: toggle from other node not applied")
+	}
+}
+
+func Test_Maintenance_No_Keyring(t *testing.T) {
+	mode := &Maintenance{}
+	if _, err := mode.BypassToken(time.Hour); !errors.Is(err, ErrNoKeyring) {
+		t.Errorf("BypassToken() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrNoKeyring)
+	}
+	if err := mode.VerifyBypassToken("token"); !errors.Is(err, ErrNoKeyring) {
+		t.Errorf("VerifyBypassToken() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrNoKeyring)
+	}
+}
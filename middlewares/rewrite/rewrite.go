@@ -0,0 +1,186 @@
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nidorx/chain"
+)
+
+type originalURLKey struct{}
+
+// Rule a rewrite rule, returns true if the request URL was rewritten.
+type Rule interface {
+	Rewrite(req *http.Request) bool
+}
+
+// RuleFunc adapter to allow the use of ordinary functions as rewrite rules
+type RuleFunc func(req *http.Request) bool
+
+func (f RuleFunc) Rewrite(req *http.Request) bool {
+	return f(req)
+}
+
+// Rewrite maps request URLs onto other routes before routing, so legacy URL structures can be served by the new routes
+// without touching handlers.
+//
+// Rules are evaluated in order and only the first matching rule is applied. The rewrite is internal (the client is not
+// redirected), the original URL is available with OriginalURL.
+//
+// The path informed in Router.Configure limits the requests evaluated by the rules.
+//
+// ## Example
+//
+//	router.Configure("/", &rewrite.Rewrite{
+//		Rules: []rewrite.Rule{
+//			rewrite.Host("api.example.com", "/api"),
+//			rewrite.StripPrefix("/v1"),
+//			rewrite.Regex(`^/users/(?P<id>\d+)/profile\.php$`, "/profiles/${id}"),
+//			rewrite.ReplacePrefix("/blog/", "/posts/"),
+//		},
+//	})
+type Rewrite struct {
+	Rules []Rule
+	path  string
+}
+
+// Configure registers the rewrite on the router (see chain.Router.BeforeRouting)
+func (rw *Rewrite) Configure(router *chain.Router, path string) {
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "*"), "/")
+	rw.path = path
+	router.BeforeRouting(rw.rewrite)
+}
+
+func (rw *Rewrite) rewrite(req *http.Request) *http.Request {
+	if rw.path != "" && req.URL.Path != rw.path && !strings.HasPrefix(req.URL.Path, rw.path+"/") {
+		return nil
+	}
+
+	original := *req.URL
+	for _, rule := range rw.Rules {
+		if rule.Rewrite(req) {
+			req.URL.RawPath = ""
+			return req.WithContext(context.WithValue(req.Context(), originalURLKey{}, &original))
+		}
+	}
+	return nil
+}
+
+// OriginalURL gets the URL of the request before the rewrite (nil if the request was not rewritten)
+func OriginalURL(ctx *chain.Context) *url.URL {
+	if original, ok := ctx.Request.Context().Value(originalURLKey{}).(*url.URL); ok {
+		return original
+	}
+	return nil
+}
+
+// StripPrefix removes the prefix from the path.
+//
+// ## Example
+//
+//	rewrite.StripPrefix("/v1") // "/v1/users" -> "/users"
+func StripPrefix(prefix string) Rule {
+	return ReplacePrefix(prefix, "/")
+}
+
+// ReplacePrefix replaces the prefix of the path. The prefix only matches complete segments.
+//
+// ## Example
+//
+//	rewrite.ReplacePrefix("/blog", "/posts") // "/blog/hello" -> "/posts/hello"
+func ReplacePrefix(prefix string, replacement string) Rule {
+	prefix = strings.TrimSuffix(prefix, "/")
+	replacement = strings.TrimSuffix(replacement, "/")
+	return RuleFunc(func(req *http.Request) bool {
+		path := req.URL.Path
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			return false
+		}
+		path = replacement + strings.TrimPrefix(path, prefix)
+		if path == "" {
+			path = "/"
+		}
+		req.URL.Path = path
+		return true
+	})
+}
+
+// Regex rewrites paths matching the pattern to the target. The target can reference the captured groups ($1, ${name})
+// and have a query string, which is merged with the query of the request (target values take precedence).
+//
+// Panics if the pattern is invalid.
+//
+// ## Example
+//
+//	rewrite.Regex(`^/article\.php$`, "/articles")
+//	rewrite.Regex(`^/products/(\d+)/(\w+)$`, "/products/$1?tab=$2")
+func Regex(pattern string, target string) Rule {
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("[chain.rewrite] invalid pattern. Pattern: %s, Error: %s", pattern, err.Error()))
+	}
+	return RuleFunc(func(req *http.Request) bool {
+		match := regex.FindStringSubmatchIndex(req.URL.Path)
+		if match == nil {
+			return false
+		}
+		result := string(regex.ExpandString(nil, target, req.URL.Path, match))
+		path, query, hasQuery := strings.Cut(result, "?")
+		req.URL.Path = path
+		if hasQuery {
+			values := req.URL.Query()
+			if targetValues, err := url.ParseQuery(query); err == nil {
+				for key, value := range targetValues {
+					values[key] = value
+				}
+			}
+			req.URL.RawQuery = values.Encode()
+		}
+		return true
+	})
+}
+
+// Host prefixes the path of requests to the given host, allowing host-based routing. The host may start with a
+// wildcard ("*.example.com") to match all subdomains. The port of the request is ignored.
+//
+// ## Example
+//
+//	rewrite.Host("api.example.com", "/api") // "api.example.com/users" -> "/api/users"
+func Host(host string, prefix string) Rule {
+	host = strings.ToLower(host)
+	prefix = strings.TrimSuffix(prefix, "/")
+	return RuleFunc(func(req *http.Request) bool {
+		if !matchHost(host, requestHost(req)) {
+			return false
+		}
+		if req.URL.Path == "/" {
+			req.URL.Path = prefix
+		} else {
+			req.URL.Path = prefix + req.URL.Path
+		}
+		if req.URL.Path == "" {
+			req.URL.Path = "/"
+		}
+		return true
+	})
+}
+
+func requestHost(req *http.Request) string {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+func matchHost(pattern string, host string) bool {
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return pattern == host
+}
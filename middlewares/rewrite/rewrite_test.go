@@ -0,0 +1,104 @@
+package rewrite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Rewrite(t *testing.T) {
+	router := chain.New()
+
+	var original string
+	handler := func(ctx *chain.Context) error {
+		if url := OriginalURL(ctx); url != nil {
+			original = url.String()
+		}
+		_, _ = ctx.Write([]byte(ctx.Request.URL.Path + "|" + ctx.Request.URL.RawQuery + "|" + ctx.GetParam("id")))
+		return nil
+	}
+	router.GET("/users", handler)
+	router.GET("/profiles/:id", handler)
+	router.GET("/products/:id", handler)
+	router.GET("/posts/*id", handler)
+	router.GET("/api/status", handler)
+
+	router.Configure("/", &Rewrite{
+		Rules: []Rule{
+			Host("api.example.com", "/api"),
+			StripPrefix("/v1"),
+			Regex(`^/users/(?P<id>\d+)/profile\.php$`, "/profiles/${id}"),
+			Regex(`^/products/(\d+)/(\w+)$`, "/products/$1?tab=$2"),
+			ReplacePrefix("/blog", "/posts"),
+		},
+	})
+
+	tests := []struct {
+		host     string
+		url      string
+		expected string
+		original string
+	}{
+		{"", "/users", "/users||", ""},
+		{"", "/v1/users", "/users||", "/v1/users"},
+		{"", "/users/10/profile.php", "/profiles/10||10", "/users/10/profile.php"},
+		{"", "/products/5/reviews?page=2", "/products/5|page=2&tab=reviews|5", "/products/5/reviews?page=2"},
+		{"", "/blog/hello", "/posts/hello||/hello", "/blog/hello"},
+		{"api.example.com:8080", "/status", "/api/status||", "/status"},
+	}
+
+	for _, tt := range tests {
+		original = ""
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		if tt.host != "" {
+			req.Host = tt.host
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if body := w.Body.String(); body != tt.expected {
+			t.Errorf("Rewrite failed: Invalid Response for %s\n   actual: %v\n expected: %v", tt.url, body, tt.expected)
+		}
+		if original != tt.original {
+			t.Errorf("Rewrite failed: Invalid OriginalURL for %s\n   actual: %v\n expected: %v", tt.url, original, tt.original)
+		}
+	}
+}
+
+func Test_Rewrite_Scope(t *testing.T) {
+	router := chain.New()
+	router.GET("/new/page", func(ctx *chain.Context) error {
+		return nil
+	})
+	router.Configure("/legacy", &Rewrite{
+		Rules: []Rule{ReplacePrefix("/legacy", "/new")},
+	})
+	router.Configure("/other", &Rewrite{
+		Rules: []Rule{Regex(`.*`, "/new/page")},
+	})
+
+	for url, code := range map[string]int{
+		"/legacy/page": http.StatusOK,
+		"/legacyx":     http.StatusNotFound,
+		"/other/x":     http.StatusOK,
+		"/x/other":     http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != code {
+			t.Errorf("Rewrite failed: Invalid Code for %s\n   actual: %v\n expected: %v", url, w.Code, code)
+		}
+	}
+}
+
+func Test_Rewrite_Invalid_Regex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Rewrite failed: expected panic for invalid pattern")
+		}
+	}()
+	Regex(`(`, "/")
+}
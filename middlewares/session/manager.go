@@ -13,16 +13,60 @@ import (
 
 var globalManagers = map[*chain.Router]*Manager{}
 
-var (
-	sessionKey     = "chain.session."         // Session on chain.Context
-	managerKey     = "chain.session-manager." // Manager on chain.Context
-	ErrCannotFetch = errors.New("cannot fetch session, check if there is a session.Manager configured")
+var ErrCannotFetch = errors.New("cannot fetch session, check if there is a session.Manager configured")
+
+// sessionContextKey the Session of the Manager (by cookie key) on chain.Context
+func sessionContextKey(key string) chain.TypedKey[*Session] {
+	return chain.NewContextKey[*Session]("chain.session." + key)
+}
+
+// managerContextKey the Manager (by cookie key) on chain.Context
+func managerContextKey(key string) chain.TypedKey[*Manager] {
+	return chain.NewContextKey[*Manager]("chain.session-manager." + key)
+}
+
+// scopeContextKey the Manager of the scope on chain.Context
+func scopeContextKey(name string) chain.TypedKey[*Manager] {
+	return chain.NewContextKey[*Manager]("chain.session-scope." + name)
+}
+
+// WritePolicy defines how the Manager saves sessions modified by concurrent requests
+type WritePolicy uint8
+
+const (
+	// LastWriteWins the data of the last request replaces the session, changes made by concurrent requests are lost
+	LastWriteWins WritePolicy = iota
+
+	// Merge only the keys modified by the request are applied over the current data of the store, holding the session
+	// lock while saving. Requires a server-side store that implements Locker, otherwise LastWriteWins is used.
+	Merge
 )
 
 // Manager cookie store expects conn.secret_key_base to be set
 type Manager struct {
 	Config
-	Store Store // session store module (required)
+	Store  Store                   // session store module (required)
+	Policy WritePolicy             // how concurrent changes are saved. Default LastWriteWins
+	Scopes map[string]*ScopeConfig // additional named sessions, see Scope
+	scopes map[string]*Manager
+}
+
+// ScopeConfig a named session, stored in a separate cookie with its own flags, allowing data with different requirements
+// to be managed by a single Manager. See the Scope function.
+//
+// ## Example
+//
+//	router.Use(&session.Manager{
+//		Config: session.Config{Key: "_app_session"},
+//		Store:  &session.Cookie{},
+//		Scopes: map[string]*session.ScopeConfig{
+//			"auth": {Config: session.Config{Key: "__Host-auth", Secure: true, SameSite: http.SameSiteStrictMode}},
+//			"ui":   {Config: session.Config{Key: "_app_ui", SameSite: http.SameSiteLaxMode}},
+//		},
+//	})
+type ScopeConfig struct {
+	Config       // cookie of the scope (Key is required)
+	Store  Store // Default, the Manager Store
 }
 
 func (m *Manager) Init(method string, path string, router *chain.Router) {
@@ -45,21 +89,49 @@ func (m *Manager) Init(method string, path string, router *chain.Router) {
 	if err := m.Store.Init(m.Config, router); err != nil {
 		panic(fmt.Sprintf("[chain.middlewares.session] error initializing store. store: %s", m.Store.Name()))
 	}
+
+	m.scopes = map[string]*Manager{}
+	for name, scope := range m.Scopes {
+		if strings.TrimSpace(scope.Key) == "" || scope.Key == m.Key {
+			panic(fmt.Sprintf("[chain.middlewares.session] scope requires a unique key. Scope: %s, Path: %s", name, path))
+		}
+		manager := &Manager{Config: scope.Config, Store: scope.Store, Policy: m.Policy}
+		if manager.Store == nil {
+			manager.Store = m.Store
+		} else if err := manager.Store.Init(manager.Config, router); err != nil {
+			panic(fmt.Sprintf("[chain.middlewares.session] error initializing store. store: %s", manager.Store.Name()))
+		}
+		m.scopes[name] = manager
+	}
 }
 
 func (m *Manager) Handle(ctx *chain.Context, next func() error) error {
-	ctx.Set(managerKey+m.Key, m)
+	managerContextKey(m.Key).Set(ctx, m)
+	for name, manager := range m.scopes {
+		managerContextKey(manager.Key).Set(ctx, manager)
+		scopeContextKey(name).Set(ctx, manager)
+	}
 	return next()
 }
 
+// load returns the session loaded in this request, or fetches it
+func (m *Manager) load(ctx *chain.Context) (*Session, error) {
+	if session := sessionContextKey(m.Key).Value(ctx); session != nil {
+		return session, nil
+	}
+	return m.fetch(ctx)
+}
+
 // fetch load the session
 func (m *Manager) fetch(ctx *chain.Context) (*Session, error) {
 	var sid string
+	var rawCookie string
 	var session *Session
 
 	if cookie := ctx.GetCookie(m.Key); cookie != nil {
 		var data map[string]any
-		if sid, data = m.Store.Get(ctx, cookie.Value); data == nil {
+		rawCookie = cookie.Value
+		if sid, data = m.Store.Get(ctx, rawCookie); data == nil {
 			data = map[string]any{}
 		}
 		session = &Session{data: data, state: none}
@@ -67,16 +139,26 @@ func (m *Manager) fetch(ctx *chain.Context) (*Session, error) {
 		// new session
 		session = &Session{data: map[string]any{}, state: write}
 	}
-	ctx.Set(sessionKey+m.Key, session)
-	if err := ctx.BeforeSend(func() { m.beforeSend(ctx, sid, session) }); err != nil {
+	session.hash, _ = hashData(session.data)
+	sessionContextKey(m.Key).Set(ctx, session)
+	if err := ctx.BeforeSend(func() { m.beforeSend(ctx, sid, rawCookie, session) }); err != nil {
 		return nil, err
 	}
 	return session, nil
 }
 
-func (m *Manager) beforeSend(ctx *chain.Context, sid string, session *Session) {
+func (m *Manager) beforeSend(ctx *chain.Context, sid string, rawCookie string, session *Session) {
 	switch session.state {
 	case write:
+		if !session.changed() {
+			// avoids sending the same cookie again (and creating empty sessions)
+			return
+		}
+		if sid != "" && m.Policy == Merge {
+			if unlock := m.merge(ctx, sid, rawCookie, session); unlock != nil {
+				defer unlock()
+			}
+		}
 		rawCookie, err := m.Store.Put(ctx, sid, session.data)
 		if err != nil {
 			slog.Error(
@@ -90,7 +172,9 @@ func (m *Manager) beforeSend(ctx *chain.Context, sid string, session *Session) {
 	case drop:
 		if sid != "" {
 			m.Store.Delete(ctx, sid)
-			ctx.RemoveCookie(m.Key)
+		}
+		if rawCookie != "" {
+			m.removeCookie(ctx)
 		}
 	case renew:
 		if sid != "" {
@@ -109,8 +193,31 @@ func (m *Manager) beforeSend(ctx *chain.Context, sid string, session *Session) {
 	}
 }
 
+// merge locks the session and applies the changes over the latest data of the store, see Merge
+func (m *Manager) merge(ctx *chain.Context, sid string, rawCookie string, session *Session) (unlock func()) {
+	locker, ok := m.Store.(Locker)
+	if !ok {
+		return nil
+	}
+	unlock, err := locker.Lock(ctx, sid)
+	if err != nil {
+		slog.Warn(
+			"[chain.middlewares.session] could not lock session, using last write wins",
+			slog.Any("Error", err),
+			slog.String("Store", m.Store.Name()),
+		)
+		return nil
+	}
+	if latestSid, latest := m.Store.Get(ctx, rawCookie); latestSid == sid && latest != nil {
+		session.merge(latest)
+	}
+	return unlock
+}
+
+// setCookie sends the session cookie, the unset fields of the Config use the chain.Router.Cookie defaults (see
+// chain.Context.SetSecureCookie)
 func (m *Manager) setCookie(ctx *chain.Context, rawCookie string) {
-	ctx.SetCookie(&http.Cookie{
+	err := ctx.SetSecureCookie(&http.Cookie{
 		Name:       m.Key,
 		Value:      rawCookie,
 		Path:       m.Path,
@@ -124,20 +231,35 @@ func (m *Manager) setCookie(ctx *chain.Context, rawCookie string) {
 		Raw:        m.Raw,
 		Unparsed:   m.Unparsed,
 	})
+	if err != nil {
+		slog.Error(
+			"[chain.middlewares.session] invalid session cookie",
+			slog.Any("Error", err),
+			slog.String("Key", m.Key),
+		)
+	}
+}
+
+// removeCookie removes the session cookie from the client, the cookie attributes (Path, Domain) must be the same used
+// by setCookie
+func (m *Manager) removeCookie(ctx *chain.Context) {
+	_ = ctx.SetSecureCookie(&http.Cookie{
+		Name:     m.Key,
+		Value:    "",
+		Path:     m.Path,
+		Domain:   m.Domain,
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		Secure:   m.Secure,
+		HttpOnly: m.HttpOnly,
+		SameSite: m.SameSite,
+	})
 }
 
 // FetchByKey LazyLoad session from context using a session.Manager Key
 func FetchByKey(ctx *chain.Context, key string) (*Session, error) {
-	if value, exist := ctx.Get(sessionKey + key); exist && value != nil {
-		if session, valid := value.(*Session); valid {
-			return session, nil
-		}
-	}
-
-	if value, exist := ctx.Get(managerKey + key); exist && value != nil {
-		if manager, valid := value.(*Manager); valid {
-			return manager.fetch(ctx)
-		}
+	if manager := managerContextKey(key).Value(ctx); manager != nil {
+		return manager.load(ctx)
 	}
 
 	return nil, ErrCannotFetch
@@ -147,8 +269,63 @@ func FetchByKey(ctx *chain.Context, key string) (*Session, error) {
 func Fetch(ctx *chain.Context) (*Session, error) {
 	router := ctx.Router()
 	if manager, exist := globalManagers[router]; exist {
-		return manager.fetch(ctx)
+		return manager.load(ctx)
+	}
+
+	return nil, ErrCannotFetch
+}
+
+// Scope LazyLoad the named session scope (see Manager.Scopes) from context.
+//
+// ## Example
+//
+//	auth, err := session.Scope(ctx, "auth")
+//	if err != nil {
+//		return err
+//	}
+//	auth.Put("user_id", user.Id)
+func Scope(ctx *chain.Context, name string) (*Session, error) {
+	if manager := scopeContextKey(name).Value(ctx); manager != nil {
+		return manager.load(ctx)
 	}
 
 	return nil, ErrCannotFetch
 }
+
+// Renew renews the session id of the global session (see Fetch and Session.Renew)
+func Renew(ctx *chain.Context) error {
+	session, err := Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	session.Renew()
+	return nil
+}
+
+// PutAuthenticated puts the identity of the user in the global session after a login, renewing the session id (see
+// Fetch and Session.PutAuthenticated)
+//
+// ## Example
+//
+//	if err := session.PutAuthenticated(ctx, "user_id", user.Id); err != nil {
+//		return err
+//	}
+func PutAuthenticated(ctx *chain.Context, key string, value any) error {
+	session, err := Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	session.PutAuthenticated(key, value)
+	return nil
+}
+
+// ClearAuthenticated clears the global session after a logout, renewing the session id (see Fetch and
+// Session.ClearAuthenticated)
+func ClearAuthenticated(ctx *chain.Context) error {
+	session, err := Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	session.ClearAuthenticated()
+	return nil
+}
@@ -1,26 +1,89 @@
 package session
 
+import (
+	"encoding/json"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// sessionState what the Manager does with the session before sending the response. States are ordered by precedence,
+// a transition only happens to a state of higher precedence (see transition):
+//
+//	none   -> nothing is sent
+//	write  -> the session is saved when the data changed (Put, Delete, Clear)
+//	renew  -> the old session is deleted and the data is saved with a new session id (Renew)
+//	drop   -> the session is deleted and the cookie removed (Destroy)
+//	ignore -> the changes of the request are discarded (IgnoreChanges)
 type sessionState uint8
 
 const (
 	none sessionState = iota
 	write
-	drop
 	renew
+	drop
 	ignore
 )
 
+// Session the data of the session of the request, see Fetch, FetchByKey and Scope
 type Session struct {
-	state sessionState
-	data  map[string]any
+	state    sessionState
+	data     map[string]any
+	hash     uint64              // hash of the data when loaded, see changed
+	modified map[string]struct{} // keys modified by the request, see merge
+	cleared  bool
+}
+
+// hashData hash of the serialized data, returns false if the data cannot be serialized
+func hashData(data map[string]any) (uint64, bool) {
+	// json.Marshal sorts the keys of the map, so the same data always produces the same hash
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return 0, false
+	}
+	return xxhash.Sum64(encoded), true
+}
+
+// transition changes the state of the session, if it has a higher precedence than the current one
+func (s *Session) transition(state sessionState) {
+	if state > s.state {
+		s.state = state
+	}
+}
+
+func (s *Session) touch(key string) {
+	if s.modified == nil {
+		s.modified = map[string]struct{}{}
+	}
+	s.modified[key] = struct{}{}
+}
+
+// merge applies the keys modified by the request over the latest data of the store, see Merge
+func (s *Session) merge(latest map[string]any) {
+	if s.cleared {
+		return
+	}
+	for key := range s.modified {
+		if value, exists := s.data[key]; exists {
+			latest[key] = value
+		} else {
+			delete(latest, key)
+		}
+	}
+	s.data = latest
+}
+
+// changed checks if the data was modified since it was loaded. Put, Delete and Clear mark the session as written, but
+// the data may remain the same (ex. storing the same value again)
+func (s *Session) changed() bool {
+	hash, ok := hashData(s.data)
+	return !ok || hash != s.hash
 }
 
 // Put puts the specified `value` in the session for the given `key`.
 func (s *Session) Put(key string, value any) {
-	if s.state == none {
-		s.state = write
-	}
+	s.transition(write)
 	s.data[key] = value
+	s.touch(key)
 }
 
 // Get Returns session value for the given `key`. If `key` is not set, `nil` is returned.
@@ -41,10 +104,9 @@ func (s *Session) GetMap() map[string]any {
 
 // Delete Deletes `key` from session.
 func (s *Session) Delete(key string) {
-	if s.state == none {
-		s.state = write
-	}
+	s.transition(write)
 	delete(s.data, key)
+	s.touch(key)
 }
 
 // Clear Clears the entire session.
@@ -54,24 +116,52 @@ func (s *Session) Delete(key string) {
 // Note that, even if Clear is used, the session is still sent to the client. If the session should be
 // effectively *dropped*, Destroy should be used.
 func (s *Session) Clear() {
-	if s.state == none {
-		s.state = write
-	}
+	s.transition(write)
 	s.data = map[string]any{}
+	s.modified = nil
+	s.cleared = true
 }
 
-// Renew generates a new session id for the cookie
+// Renew generates a new session id for the cookie, keeping the data. The old session is deleted from the store.
+//
+// Must be invoked when the privileges of the user change (ex. login), preventing session fixation attacks, see
+// PutAuthenticated.
 func (s *Session) Renew() {
-	if s.state != ignore {
-		s.state = renew
-	}
+	s.transition(renew)
 }
 
-// Destroy drops the session, a session cookie will not be included in the response
+// Destroy drops the session, the session is deleted from the store and the cookie is removed from the client
 func (s *Session) Destroy() {
-	if s.state != ignore {
-		s.state = renew
-	}
+	s.transition(drop)
+}
+
+// PutAuthenticated puts the identity of the user (ex. "user_id") in the session after a login, renewing the session id
+// (see Renew) to prevent session fixation attacks.
+//
+// ## Example
+//
+//	router.POST("/login", func(ctx *chain.Context) error {
+//		user, err := authenticate(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		sess, err := session.Fetch(ctx)
+//		if err != nil {
+//			return err
+//		}
+//		sess.PutAuthenticated("user_id", user.Id)
+//		return nil
+//	})
+func (s *Session) PutAuthenticated(key string, value any) {
+	s.Put(key, value)
+	s.Renew()
+}
+
+// ClearAuthenticated clears the session after a logout, renewing the session id (see Renew). Unlike Destroy, the
+// client receives a new (empty) session.
+func (s *Session) ClearAuthenticated() {
+	s.Clear()
+	s.Renew()
 }
 
 // IgnoreChanges ignores all changes made to the session in this request cycle
@@ -0,0 +1,127 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Session_Transitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		actions  func(s *Session)
+		expected sessionState
+	}{
+		{"Put", func(s *Session) { s.Put("a", 1) }, write},
+		{"Renew", func(s *Session) { s.Renew() }, renew},
+		{"Put after Renew", func(s *Session) { s.Renew(); s.Put("a", 1) }, renew},
+		{"Renew after Put", func(s *Session) { s.Put("a", 1); s.Renew() }, renew},
+		{"Destroy", func(s *Session) { s.Destroy() }, drop},
+		{"Renew after Destroy", func(s *Session) { s.Destroy(); s.Renew() }, drop},
+		{"Destroy after Renew", func(s *Session) { s.Renew(); s.Destroy() }, drop},
+		{"Put after Destroy", func(s *Session) { s.Destroy(); s.Put("a", 1) }, drop},
+		{"IgnoreChanges", func(s *Session) { s.Destroy(); s.IgnoreChanges(); s.Renew() }, ignore},
+		{"PutAuthenticated", func(s *Session) { s.PutAuthenticated("user_id", 1) }, renew},
+		{"ClearAuthenticated", func(s *Session) { s.ClearAuthenticated() }, renew},
+	}
+	for _, test := range tests {
+		session := &Session{data: map[string]any{}}
+		test.actions(session)
+		if session.state != test.expected {
+			t.Errorf("Session.%s failed: Invalid state\n   actual: %v\n expected: %v", test.name, session.state, test.expected)
+		}
+	}
+}
+
+func Test_Manager_Renew_Destroy(t *testing.T) {
+	store := &Memory{}
+	router := chain.New()
+	router.Use(&Manager{
+		Config: Config{Key: "sid", Path: "/"},
+		Store:  store,
+	})
+	router.GET("/visit", func(ctx *chain.Context) error {
+		sess, err := Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		sess.Put("visited", true)
+		return nil
+	})
+	router.GET("/login", func(ctx *chain.Context) error {
+		return PutAuthenticated(ctx, "user_id", "alex")
+	})
+	router.GET("/logout", func(ctx *chain.Context) error {
+		sess, err := Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		sess.Destroy()
+		return nil
+	})
+
+	cookies := PerformRequest(router, "GET", "/visit", nil).Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Manager failed: Invalid cookies\n   actual: %v", cookies)
+	}
+	anonymous := cookies[0].Value
+
+	// login, the session id changes and the old session is deleted
+	cookies = PerformRequest(router, "GET", "/login", cookies).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == anonymous {
+		t.Fatalf("PutAuthenticated failed: the session id was not renewed\n   actual: %v", cookies)
+	}
+	if sid, data := store.Get(nil, anonymous); sid != "" || data != nil {
+		t.Errorf("PutAuthenticated failed: the old session was not deleted")
+	}
+	if _, data := store.Get(nil, cookies[0].Value); data["user_id"] != "alex" || data["visited"] != true {
+		t.Errorf("PutAuthenticated failed: Invalid data\n   actual: %v", data)
+	}
+	authenticated := cookies[0].Value
+
+	// logout, the session is deleted and the cookie removed
+	cookies = PerformRequest(router, "GET", "/logout", cookies).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("Destroy failed: the cookie was not removed\n   actual: %v", cookies)
+	}
+	if _, data := store.Get(nil, authenticated); data != nil {
+		t.Errorf("Destroy failed: the session was not deleted")
+	}
+}
+
+func Test_Manager_Destroy_Cookie_Store(t *testing.T) {
+	router := chain.New()
+	if err := router.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(&Manager{
+		Config: Config{Key: "sid", Path: "/app"},
+		Store:  &Cookie{},
+	})
+	router.GET("/app/login", func(ctx *chain.Context) error {
+		return PutAuthenticated(ctx, "user_id", "alex")
+	})
+	router.GET("/app/logout", func(ctx *chain.Context) error {
+		sess, err := Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		sess.Destroy()
+		return nil
+	})
+
+	cookies := PerformRequest(router, "GET", "/app/login", nil).Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("PutAuthenticated failed: Invalid cookies\n   actual: %v", cookies)
+	}
+
+	// the Cookie store has no session id, the cookie must be removed anyway
+	cookies = PerformRequest(router, "GET", "/app/logout", cookies).Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 || cookies[0].Path != "/app" {
+		t.Errorf("Destroy failed: the cookie was not removed\n   actual: %v", cookies)
+	}
+
+	if w := PerformRequest(router, "GET", "/app/logout", nil); len(w.Result().Cookies()) != 0 {
+		t.Errorf("Destroy failed: no cookie must be sent for requests without session\n   actual: %v", w.Result().Cookies())
+	}
+}
@@ -45,3 +45,11 @@ type Store interface {
 	// Delete Removes the session associated with given session id from the store.
 	Delete(ctx *chain.Context, sid string)
 }
+
+// Locker implemented by server-side stores that support exclusive access to a session, used by the Manager with the
+// Merge policy to avoid concurrent requests of the same session overwriting each other. The Cookie store can't
+// implement it, the session data lives on the client.
+type Locker interface {
+	// Lock acquires the exclusive access to the session, until unlock is invoked
+	Lock(ctx *chain.Context, sid string) (unlock func(), err error)
+}
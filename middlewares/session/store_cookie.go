@@ -3,11 +3,14 @@
 // This cookie store is based on `chain.MessageVerifier` and `chain.MessageEncryptor` which encrypts and signs
 // each cookie to ensure they can't be read nor tampered with.
 //
-// Since this store uses crypto features, it requires you to set the `SecretKeyBase` field in your router. This
-// can be easily achieved with:
+// Since this store uses crypto features, it requires a SecretKeyBase, global or exclusive to the router. This can be
+// easily achieved with:
 //
+//	chain.SetSecretKeyBase("--- 32 BYTES SECRET KEY BASE ---")
+//
+//	// or, to use different keys per router
 //	router := chain.New()
-//	router.SecretKeyBase = "-- LONG STRING WITH AT LEAST 64 BYTES --"
+//	router.SetSecretKeyBase("--- 32 BYTES SECRET KEY BASE ---")
 //
 // ## Example
 //
@@ -28,19 +31,27 @@ import (
 )
 
 var (
-	defaultSerializer     = &chain.JsonSerializer{}
-	defaultSigningKeyring = chain.NewKeyring("chain.middleware.session.keyring.salt", 1000, 32, "sha256")
-	defaultEncryptionAAD  = []byte("chain.middleware.session.cookie.aad")
+	defaultSerializer    = &chain.JsonSerializer{}
+	defaultSigningSalt   = "chain.middleware.session.keyring.salt"
+	defaultEncryptionAAD = []byte("chain.middleware.session.cookie.aad")
 )
 
 // Cookie Stores the session in a cookie.
 // https://edgeapi.rubyonrails.org/classes/ActionDispatch/Session/CookieStore.html
 // https://funcptr.net/2013/08/25/user-sessions,-what-data-should-be-stored-where-/
+//
+// Keys are derived from chain.SecretKeyBase and rotated automatically: whenever chain.SetSecretKeyBase is invoked, a new
+// key is installed on the keyrings, new cookies use the newest key and cookies signed (or encrypted) with old keys
+// still verify. When the router defines its own SecretKeyBase (Router.SetSecretKeyBase), it is used instead
+// of the global ones.
 type Cookie struct {
 	Serializer        chain.Serializer // cookie serializer module that defines `Encode(any)` and `Decode(any)`. Defaults to `json`.
 	SigningKeyring    *crypto.Keyring  // a crypto.Keyring used with for signing/verifying a cookie.
+	SigningSalt       string           // when informed (and SigningKeyring is nil), a Keyring is derived from SecretKeyBase with this salt
 	EncryptionKeyring *crypto.Keyring  // a crypto.Keyring used for encrypting/decrypting a cookie.
+	EncryptionSalt    string           // when informed (and EncryptionKeyring is nil), enables encryption with a Keyring derived from SecretKeyBase with this salt
 	EncryptionAAD     []byte           // Additional authenticated data (AAD)
+	KDF               crypto.KDF       // key derivation function used with SigningSalt and EncryptionSalt. Defaults to crypto.PBKDF2
 }
 
 func (c *Cookie) Name() string { return "Cookie" }
@@ -48,7 +59,15 @@ func (c *Cookie) Name() string { return "Cookie" }
 func (c *Cookie) Init(config Config, router *chain.Router) (err error) {
 
 	if c.SigningKeyring == nil {
-		c.SigningKeyring = defaultSigningKeyring
+		salt := c.SigningSalt
+		if salt == "" {
+			salt = defaultSigningSalt
+		}
+		c.SigningKeyring = router.DeriveKeyring(salt, 32, c.kdf())
+	}
+
+	if c.EncryptionKeyring == nil && c.EncryptionSalt != "" {
+		c.EncryptionKeyring = router.DeriveKeyring(c.EncryptionSalt, 32, c.kdf())
 	}
 
 	if c.Serializer == nil {
@@ -58,6 +77,13 @@ func (c *Cookie) Init(config Config, router *chain.Router) (err error) {
 	return
 }
 
+func (c *Cookie) kdf() crypto.KDF {
+	if c.KDF == nil {
+		return crypto.PBKDF2{Iterations: 1000, Digest: "sha256"}
+	}
+	return c.KDF
+}
+
 func (c *Cookie) Get(ctx *chain.Context, rawCookie string) (sid string, data map[string]any) {
 	var (
 		err        error
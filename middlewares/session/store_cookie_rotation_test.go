@@ -0,0 +1,158 @@
+package session
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Store_Cookie_Key_Rotation(t *testing.T) {
+	oldSecret := "rotation.old.secret.key.base.012"
+	newSecret := "rotation.new.secret.key.base.012"
+
+	if previous := chain.SecretKeyBase(); previous != "" {
+		t.Cleanup(func() {
+			_ = chain.SetSecretKeyBase(previous)
+		})
+	}
+
+	if err := chain.SetSecretKeyBase(oldSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	stores := []*Cookie{
+		{SigningSalt: "test.rotation.signing"},
+		{EncryptionSalt: "test.rotation.encryption"},
+	}
+
+	for _, store := range stores {
+		if err := store.Init(Config{}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var oldCookies []string
+	for _, store := range stores {
+		cookie, err := store.Put(nil, "", map[string]any{"value": "OLD"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		oldCookies = append(oldCookies, cookie)
+	}
+
+	// rotate
+	if err := chain.SetSecretKeyBase(newSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, store := range stores {
+		keyring := store.SigningKeyring
+		salt := store.SigningSalt
+		if store.EncryptionKeyring != nil {
+			keyring = store.EncryptionKeyring
+			salt = store.EncryptionSalt
+		}
+
+		expectedKey := chain.Crypto().KeyGenerate([]byte(newSecret), []byte(salt), 1000, 32, "sha256")
+		if !bytes.Equal(keyring.GetPrimaryKey(), expectedKey) {
+			t.Errorf("Cookie rotation failed: primary key was not derived from the newest SecretKeyBase")
+		}
+		for _, key := range keyring.GetKeys() {
+			if bytes.Equal(key, chain.Crypto().KeyGenerate([]byte(""), []byte(salt), 1000, 32, "sha256")) {
+				t.Errorf("Cookie rotation failed: key derived from empty SecretKeyBase")
+			}
+		}
+
+		// cookies created with old keys still valid
+		if _, data := store.Get(nil, oldCookies[i]); data == nil || data["value"] != "OLD" {
+			t.Errorf("Cookie rotation failed: Invalid data from old cookie\n   actual: %v\n expected: %v", data, "OLD")
+		}
+
+		// new cookies
+		cookie, err := store.Put(nil, "", map[string]any{"value": "NEW"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cookie == oldCookies[i] {
+			t.Errorf("Cookie rotation failed: new cookie must use the newest key")
+		}
+		if _, data := store.Get(nil, cookie); data == nil || data["value"] != "NEW" {
+			t.Errorf("Cookie rotation failed: Invalid data from new cookie\n   actual: %v\n expected: %v", data, "NEW")
+		}
+	}
+}
+
+func Test_SecretKeySync_Cancel(t *testing.T) {
+	calls := 0
+	cancel := chain.SecretKeySync(func(key string) {
+		calls++
+	})
+	cancel()
+
+	initial := calls
+	if err := chain.SetSecretKeyBase("secret.key.sync.cancel.012345678"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != initial {
+		t.Errorf("SecretKeySync cancel failed: sync invoked after cancel")
+	}
+}
+
+func Test_Store_Cookie_Router_SecretKeyBase(t *testing.T) {
+	if err := chain.SetSecretKeyBase("cookie.global.secret.key.base.01"); err != nil {
+		t.Fatal(err)
+	}
+
+	tenant := chain.New()
+	if err := tenant.SetSecretKeyBase("cookie.tenant.secret.key.base.01"); err != nil {
+		t.Fatal(err)
+	}
+
+	global := &Cookie{}
+	scoped := &Cookie{}
+	if err := global.Init(Config{}, chain.New()); err != nil {
+		t.Fatal(err)
+	}
+	if err := scoped.Init(Config{}, tenant); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKey := chain.Crypto().KeyGenerate([]byte("cookie.tenant.secret.key.base.01"), []byte(defaultSigningSalt), 1000, 32, "sha256")
+	if !bytes.Equal(scoped.SigningKeyring.GetPrimaryKey(), expectedKey) {
+		t.Errorf("Cookie failed: key was not derived from the router SecretKeyBase")
+	}
+
+	cookie, err := scoped.Put(nil, "", map[string]any{"value": "TENANT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, data := global.Get(nil, cookie); data != nil {
+		t.Errorf("Cookie failed: cookie of a router must not be valid with the global keys")
+	}
+	if _, data := scoped.Get(nil, cookie); data == nil || data["value"] != "TENANT" {
+		t.Errorf("Cookie failed: Invalid data\n   actual: %v\n expected: %v", data, "TENANT")
+	}
+}
+
+func Test_Store_Cookie_Router_Keyring_Not_Shared(t *testing.T) {
+	router := chain.New()
+	router.Keyring = chain.NewKeyring("cookie.router.keyring.salt", 1000, 32, "sha256")
+
+	store := &Cookie{}
+	if err := store.Init(Config{}, router); err != nil {
+		t.Fatal(err)
+	}
+	if store.SigningKeyring == router.Keyring {
+		t.Fatal("Cookie failed: the Router.Keyring must not be used to sign the session cookies")
+	}
+
+	// a message signed by the application with the router keyring (ex. user input) is not a valid session cookie
+	forged, err := router.Keyring.MessageSign([]byte(`{"value":"FORGED"}`), "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, data := store.Get(nil, forged); data != nil {
+		t.Errorf("Cookie failed: message signed with the Router.Keyring was accepted\n   actual: %v", data)
+	}
+}
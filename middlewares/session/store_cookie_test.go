@@ -4,6 +4,7 @@ import (
 	"github.com/nidorx/chain"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -83,3 +84,123 @@ func Test_Store_Cookie(t *testing.T) {
 		t.Errorf("Store.Cookie failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, expected)
 	}
 }
+
+func Test_Store_Cookie_Unchanged(t *testing.T) {
+	if err := chain.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		panic(err)
+	}
+
+	router := chain.New()
+	router.Use(&Manager{
+		Config: Config{Key: "sid", Path: "/"},
+		Store:  &Cookie{},
+	})
+
+	router.GET("/put", func(ctx *chain.Context) error {
+		sess, err := Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		sess.Put("user", ctx.Request.URL.Query().Get("user"))
+
+		// fetch again, returns the same session
+		if other, _ := Fetch(ctx); other != sess {
+			t.Errorf("Fetch failed: Invalid Session\n   actual: %p\n expected: %p", other, sess)
+		}
+		return nil
+	})
+	router.GET("/read", func(ctx *chain.Context) error {
+		_, err := Fetch(ctx)
+		return err
+	})
+
+	tests := []struct {
+		path    string
+		cookie  bool
+		changed bool
+	}{
+		{"/read", false, false},      // new session, without data
+		{"/put?user=1", false, true}, // new session, with data
+		{"/read", true, false},       // existing session, not modified
+		{"/put?user=1", true, false}, // existing session, same data
+		{"/put?user=2", true, true},  // existing session, modified
+	}
+
+	var cookies []*http.Cookie
+	for _, tt := range tests {
+		var sent []*http.Cookie
+		if tt.cookie {
+			sent = cookies
+		}
+		w := PerformRequest(router, "GET", tt.path, sent)
+		setCookie := w.Header().Get("Set-Cookie") != ""
+		if setCookie != tt.changed {
+			t.Errorf("Store.Cookie failed: Invalid Set-Cookie for %s\n   actual: %v\n expected: %v", tt.path, setCookie, tt.changed)
+		}
+		if setCookie {
+			cookies = w.Result().Cookies()
+		}
+	}
+}
+
+func Test_Manager_Scopes(t *testing.T) {
+	if err := chain.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		panic(err)
+	}
+
+	router := chain.New()
+	router.Use(&Manager{
+		Config: Config{Key: "sid"},
+		Store:  &Cookie{},
+		Scopes: map[string]*ScopeConfig{
+			"auth": {Config: Config{Key: "__Host-auth", SameSite: http.SameSiteStrictMode}},
+			"ui":   {Config: Config{Key: "ui"}, Store: &Cookie{EncryptionSalt: "ui"}},
+		},
+	})
+
+	router.GET("/put", func(ctx *chain.Context) error {
+		for _, name := range []string{"auth", "ui"} {
+			sess, err := Scope(ctx, name)
+			if err != nil {
+				return err
+			}
+			sess.Put("value", name)
+		}
+		sess, _ := Fetch(ctx)
+		sess.Put("value", "main")
+		return nil
+	})
+
+	var values []any
+	router.GET("/read", func(ctx *chain.Context) error {
+		sess, _ := Fetch(ctx)
+		auth, _ := Scope(ctx, "auth")
+		ui, _ := Scope(ctx, "ui")
+		values = []any{sess.Get("value"), auth.Get("value"), ui.Get("value")}
+		if _, err := Scope(ctx, "missing"); err != ErrCannotFetch {
+			t.Errorf("Scope failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrCannotFetch)
+		}
+		return nil
+	})
+
+	w := PerformRequest(router, "GET", "/put", nil)
+	cookies := w.Result().Cookies()
+	flags := map[string]http.SameSite{}
+	for _, cookie := range cookies {
+		flags[cookie.Name] = cookie.SameSite
+	}
+	expectedFlags := map[string]http.SameSite{
+		"sid":         http.SameSiteLaxMode,
+		"__Host-auth": http.SameSiteStrictMode,
+		"ui":          http.SameSiteLaxMode,
+	}
+	if !reflect.DeepEqual(flags, expectedFlags) {
+		t.Errorf("Scope failed: Invalid Cookies\n   actual: %v\n expected: %v", flags, expectedFlags)
+	}
+
+	PerformRequest(router, "GET", "/read", cookies)
+	expected := []any{"main", "auth", "ui"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Scope failed: Invalid Values\n   actual: %v\n expected: %v", values, expected)
+	}
+}
@@ -0,0 +1,192 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/crypto"
+)
+
+// DefaultJWTMaxSize max size of the token, the usual limit of the browsers for a cookie
+const DefaultJWTMaxSize = 4096
+
+var (
+	defaultJWTSalt = "chain.middleware.session.jwt.salt"
+	jwtHeader      = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+	ErrJWTTooLarge = errors.New("the session token exceeds the max size")
+	ErrJWTInvalid  = errors.New("invalid session token")
+	ErrJWTExpired  = errors.New("expired session token")
+)
+
+// JWT Stores the session in a signed JSON Web Token (HS256), for API-focused apps that prefer tokens over opaque
+// cookies. The token can be verified by other services that share the keys.
+//
+// The session data is sent in the "data" claim and the session id in the "jti" claim, a new id is generated when the
+// session is renewed (see Session.Renew). The expiry ("exp" claim) follows the MaxAge (or Expires) of the Config.
+// Expired tokens, tokens of other issuers or audiences and tokens signed with unknown keys are ignored (new session).
+//
+// Tokens are signed with the first key of the Keyring and verified with all its keys, allowing the rotation of the
+// keys (see crypto.Keyring.AddKey). Like the Cookie store, the data is not encrypted and the tokens can't be revoked
+// before they expire.
+//
+// ## Example
+//
+//	router.Use(&session.Manager{
+//		Config: session.Config{Key: "_app_token", MaxAge: 3600, HttpOnly: true, Secure: true},
+//		Store:  &session.JWT{Issuer: "my-app"},
+//	})
+type JWT struct {
+	Keyring     *crypto.Keyring // signs/verifies the tokens. Default, a Keyring derived from SecretKeyBase (see chain.Router.DeriveKeyring)
+	SigningSalt string          // when informed (and Keyring is nil), a Keyring is derived from SecretKeyBase with this salt
+	KDF         crypto.KDF      // key derivation function used with SigningSalt. Defaults to crypto.PBKDF2
+	Issuer      string          // "iss" claim, when informed the tokens of other issuers are rejected
+	Audience    string          // "aud" claim, when informed the tokens of other audiences are rejected
+	MaxSize     int             // max size of the token, Put fails when exceeded. Default DefaultJWTMaxSize
+	config      Config
+}
+
+// jwtClaims claims of the session token
+type jwtClaims struct {
+	Id       string         `json:"jti"`
+	Issuer   string         `json:"iss,omitempty"`
+	Audience string         `json:"aud,omitempty"`
+	IssuedAt int64          `json:"iat"`
+	Expires  int64          `json:"exp,omitempty"`
+	Data     map[string]any `json:"data"`
+}
+
+func (j *JWT) Name() string { return "JWT" }
+
+func (j *JWT) Init(config Config, router *chain.Router) (err error) {
+	j.config = config
+
+	if j.Keyring == nil {
+		salt := j.SigningSalt
+		if salt == "" {
+			salt = defaultJWTSalt
+		}
+		j.Keyring = router.DeriveKeyring(salt, 32, j.kdf())
+	}
+
+	if j.MaxSize <= 0 {
+		j.MaxSize = DefaultJWTMaxSize
+	}
+	return
+}
+
+func (j *JWT) kdf() crypto.KDF {
+	if j.KDF == nil {
+		return crypto.PBKDF2{Iterations: 1000, Digest: "sha256"}
+	}
+	return j.KDF
+}
+
+func (j *JWT) Get(ctx *chain.Context, rawCookie string) (sid string, data map[string]any) {
+	claims, err := j.verify(rawCookie)
+	if err != nil {
+		slog.Debug(
+			"[chain.middlewares.session] could not decode serialized data",
+			slog.Any("Error", err),
+			slog.Any("Store", j.Name()),
+		)
+		return
+	}
+	return claims.Id, claims.Data
+}
+
+func (j *JWT) Put(ctx *chain.Context, sid string, data map[string]any) (rawCookie string, err error) {
+	if sid == "" {
+		sid = chain.NewUID()
+	}
+
+	now := time.Now()
+	claims := jwtClaims{
+		Id:       sid,
+		Issuer:   j.Issuer,
+		Audience: j.Audience,
+		IssuedAt: now.Unix(),
+		Data:     data,
+	}
+	if j.config.MaxAge > 0 {
+		claims.Expires = now.Add(time.Duration(j.config.MaxAge) * time.Second).Unix()
+	} else if !j.config.Expires.IsZero() {
+		claims.Expires = j.config.Expires.Unix()
+	}
+
+	var payload []byte
+	if payload, err = json.Marshal(claims); err != nil {
+		return
+	}
+
+	key := j.Keyring.GetPrimaryKey()
+	if key == nil {
+		return "", crypto.ErrKeyringEmpty
+	}
+
+	content := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	rawCookie = content + "." + base64.RawURLEncoding.EncodeToString(jwtSign(key, content))
+	if len(rawCookie) > j.MaxSize {
+		return "", ErrJWTTooLarge
+	}
+	return
+}
+
+// Delete the session token can't be revoked, the cookie is removed by the Manager
+func (j *JWT) Delete(ctx *chain.Context, sid string) {}
+
+// verify checks the signature and the registered claims of the token
+func (j *JWT) verify(token string) (claims *jwtClaims, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		// only HS256 is accepted, avoiding algorithm confusion ("alg":"none")
+		return nil, ErrJWTInvalid
+	}
+	content, payload, signature := parts[0]+"."+parts[1], parts[1], parts[2]
+
+	var decoded []byte
+	if decoded, err = base64.RawURLEncoding.DecodeString(signature); err != nil {
+		return nil, ErrJWTInvalid
+	}
+	valid := false
+	for _, key := range j.Keyring.GetKeys() {
+		if hmac.Equal(decoded, jwtSign(key, content)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, crypto.ErrKeyringCannotVerify
+	}
+
+	if decoded, err = base64.RawURLEncoding.DecodeString(payload); err != nil {
+		return nil, ErrJWTInvalid
+	}
+	claims = &jwtClaims{}
+	if err = json.Unmarshal(decoded, claims); err != nil {
+		return nil, ErrJWTInvalid
+	}
+	if claims.Expires > 0 && time.Now().Unix() >= claims.Expires {
+		return nil, ErrJWTExpired
+	}
+	if claims.Issuer != j.Issuer || claims.Audience != j.Audience {
+		return nil, ErrJWTInvalid
+	}
+	if claims.Data == nil {
+		claims.Data = map[string]any{}
+	}
+	return claims, nil
+}
+
+func jwtSign(key []byte, content string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,165 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/crypto"
+)
+
+func newJWTTestKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func Test_Store_JWT(t *testing.T) {
+	router := chain.New()
+	if err := router.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(&Manager{
+		Config: Config{Key: "token", Path: "/", MaxAge: 60},
+		Store:  &JWT{Issuer: "test"},
+	})
+	router.GET("/login", func(ctx *chain.Context) error {
+		sess, err := FetchByKey(ctx, "token")
+		if err != nil {
+			return err
+		}
+		sess.Put("user", "alex")
+		return nil
+	})
+
+	var user any
+	router.GET("/me", func(ctx *chain.Context) error {
+		sess, err := FetchByKey(ctx, "token")
+		if err != nil {
+			return err
+		}
+		user = sess.Get("user")
+		return nil
+	})
+
+	cookies := PerformRequest(router, "GET", "/login", nil).Result().Cookies()
+	if len(cookies) != 1 || strings.Count(cookies[0].Value, ".") != 2 {
+		t.Fatalf("Store.JWT failed: Invalid cookies\n   actual: %v", cookies)
+	}
+
+	PerformRequest(router, "GET", "/me", cookies)
+	if user != "alex" {
+		t.Errorf("Store.JWT failed: Invalid session data\n   actual: %v\n expected: %v", user, "alex")
+	}
+
+	// tampered
+	user = nil
+	parts := strings.Split(cookies[0].Value, ".")
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"jti":"x","iss":"test","iat":0,"data":{"user":"admin"}}`))
+	PerformRequest(router, "GET", "/me", []*http.Cookie{{Name: "token", Value: strings.Join(parts, ".")}})
+	if user != nil {
+		t.Errorf("Store.JWT failed: tampered token was accepted\n   actual: %v", user)
+	}
+}
+
+func Test_Store_JWT_Claims(t *testing.T) {
+	keyring := &crypto.Keyring{}
+	keyring.AddKey(newJWTTestKey(t))
+
+	store := &JWT{Keyring: keyring, Issuer: "app", Audience: "api", MaxSize: 512}
+	if err := store.Init(Config{MaxAge: 60}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := store.Put(nil, "", map[string]any{"value": "X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := store.verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Id == "" || claims.Issuer != "app" || claims.Audience != "api" {
+		t.Errorf("Store.JWT failed: Invalid claims\n   actual: %+v", claims)
+	}
+	if expected := time.Now().Add(time.Minute).Unix(); claims.Expires < expected-1 || claims.Expires > expected {
+		t.Errorf("Store.JWT failed: Invalid exp\n   actual: %v\n expected: %v", claims.Expires, expected)
+	}
+
+	// the session id is kept
+	if token, err = store.Put(nil, claims.Id, claims.Data); err != nil {
+		t.Fatal(err)
+	}
+	if sid, data := store.Get(nil, token); sid != claims.Id || data["value"] != "X" {
+		t.Errorf("Store.JWT failed: Invalid session\n   actual: %v %v\n expected: %v %v", sid, data, claims.Id, claims.Data)
+	}
+
+	// key rotation
+	keyring.AddKey(newJWTTestKey(t))
+	if sid, _ := store.Get(nil, token); sid != claims.Id {
+		t.Errorf("Store.JWT failed: token signed with old key must be valid")
+	}
+
+	// other audience
+	other := &JWT{Keyring: keyring, Issuer: "app", Audience: "web"}
+	other.Init(Config{}, nil)
+	if _, err := other.verify(token); !errors.Is(err, ErrJWTInvalid) {
+		t.Errorf("Store.JWT failed: Invalid error\n   actual: %v\n expected: %v", err, ErrJWTInvalid)
+	}
+
+	// expired
+	expired := &JWT{Keyring: keyring, Issuer: "app", Audience: "api"}
+	expired.Init(Config{Expires: time.Now().Add(-time.Minute)}, nil)
+	if token, err = expired.Put(nil, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.verify(token); !errors.Is(err, ErrJWTExpired) {
+		t.Errorf("Store.JWT failed: Invalid error\n   actual: %v\n expected: %v", err, ErrJWTExpired)
+	}
+
+	// alg none
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + strings.Split(token, ".")[1] + "."
+	if _, err := store.verify(none); !errors.Is(err, ErrJWTInvalid) {
+		t.Errorf("Store.JWT failed: Invalid error\n   actual: %v\n expected: %v", err, ErrJWTInvalid)
+	}
+
+	// size
+	if _, err = store.Put(nil, "", map[string]any{"value": strings.Repeat("X", 512)}); !errors.Is(err, ErrJWTTooLarge) {
+		t.Errorf("Store.JWT failed: Invalid error\n   actual: %v\n expected: %v", err, ErrJWTTooLarge)
+	}
+}
+
+func Test_Store_JWT_Router_Keyring_Not_Shared(t *testing.T) {
+	if err := chain.SetSecretKeyBase("jwt.global.secret.key.base.00001"); err != nil {
+		t.Fatal(err)
+	}
+	router := chain.New()
+	router.Keyring = &crypto.Keyring{}
+	router.Keyring.AddKey(newJWTTestKey(t))
+
+	store := &JWT{}
+	if err := store.Init(Config{}, router); err != nil {
+		t.Fatal(err)
+	}
+	if store.Keyring == router.Keyring {
+		t.Fatal("Store.JWT failed: the Router.Keyring must not be used to sign the tokens")
+	}
+
+	// token signed with the router keyring is not accepted
+	forged := &JWT{Keyring: router.Keyring}
+	forged.Init(Config{}, router)
+	token, err := forged.Put(nil, "", map[string]any{"user": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.verify(token); err == nil {
+		t.Errorf("Store.JWT failed: token signed with the Router.Keyring was accepted")
+	}
+}
@@ -0,0 +1,128 @@
+package session
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+// Memory Stores the sessions in memory, the cookie only holds the session id. Implements Locker, allowing the Merge
+// policy of the Manager.
+//
+// Sessions are lost when the application restarts and are not shared between instances, so this store is only
+// recommended for development and single instance applications.
+//
+// ## Example
+//
+//	router.Use(&session.Manager{
+//		Config: session.Config{Key: "_my_app_session"},
+//		Store:  &session.Memory{},
+//		Policy: session.Merge,
+//	})
+type Memory struct {
+	TTL      time.Duration // Sessions not written for this time are discarded. Default Config.MaxAge or 24h
+	mutex    sync.Mutex
+	sessions map[string]*memoryEntry
+	locks    map[string]*memoryLock
+	swept    time.Time
+}
+
+type memoryEntry struct {
+	data    map[string]any
+	expires time.Time
+}
+
+type memoryLock struct {
+	mutex sync.Mutex
+	refs  int
+}
+
+func (m *Memory) Name() string { return "Memory" }
+
+func (m *Memory) Init(config Config, router *chain.Router) error {
+	if m.TTL <= 0 {
+		if config.MaxAge > 0 {
+			m.TTL = time.Duration(config.MaxAge) * time.Second
+		} else {
+			m.TTL = 24 * time.Hour
+		}
+	}
+	m.sessions = map[string]*memoryEntry{}
+	m.locks = map[string]*memoryLock{}
+	return nil
+}
+
+func (m *Memory) Get(ctx *chain.Context, rawCookie string) (sid string, data map[string]any) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	entry, exists := m.sessions[rawCookie]
+	if !exists {
+		return
+	}
+	if time.Now().After(entry.expires) {
+		delete(m.sessions, rawCookie)
+		return
+	}
+	return rawCookie, copyData(entry.data)
+}
+
+func (m *Memory) Put(ctx *chain.Context, sid string, data map[string]any) (rawCookie string, err error) {
+	if sid == "" {
+		sid = chain.NewUID()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	m.sessions[sid] = &memoryEntry{data: copyData(data), expires: now.Add(m.TTL)}
+
+	// discards the expired sessions, at most once per minute
+	if now.Sub(m.swept) > time.Minute {
+		m.swept = now
+		for id, entry := range m.sessions {
+			if now.After(entry.expires) {
+				delete(m.sessions, id)
+			}
+		}
+	}
+	return sid, nil
+}
+
+func (m *Memory) Delete(ctx *chain.Context, sid string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.sessions, sid)
+}
+
+func (m *Memory) Lock(ctx *chain.Context, sid string) (unlock func(), err error) {
+	m.mutex.Lock()
+	lock := m.locks[sid]
+	if lock == nil {
+		lock = &memoryLock{}
+		m.locks[sid] = lock
+	}
+	lock.refs++
+	m.mutex.Unlock()
+
+	lock.mutex.Lock()
+	return func() {
+		lock.mutex.Unlock()
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(m.locks, sid)
+		}
+	}, nil
+}
+
+func copyData(data map[string]any) map[string]any {
+	cp := make(map[string]any, len(data))
+	for key, value := range data {
+		cp[key] = value
+	}
+	return cp
+}
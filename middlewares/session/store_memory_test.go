@@ -0,0 +1,72 @@
+package session
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Store_Memory_Policy(t *testing.T) {
+	tests := []struct {
+		policy   WritePolicy
+		expected int // number of values preserved
+	}{
+		{LastWriteWins, 1},
+		{Merge, 2},
+	}
+	for _, tt := range tests {
+		router := chain.New()
+		router.Use(&Manager{
+			Config: Config{Key: "sid", Path: "/"},
+			Store:  &Memory{},
+			Policy: tt.policy,
+		})
+
+		// both concurrent requests load the session before any of them saves it
+		var loaded sync.WaitGroup
+		loaded.Add(2)
+		router.GET("/put", func(ctx *chain.Context) error {
+			sess, err := Fetch(ctx)
+			if err != nil {
+				return err
+			}
+			sess.Put(ctx.QueryParam("key"), "X")
+			if ctx.QueryParam("key") != "init" {
+				loaded.Done()
+				loaded.Wait()
+			}
+			return nil
+		})
+
+		var values map[string]any
+		router.GET("/read", func(ctx *chain.Context) error {
+			sess, err := Fetch(ctx)
+			values = sess.GetMap()
+			return err
+		})
+
+		cookies := PerformRequest(router, "GET", "/put?key=init", nil).Result().Cookies()
+
+		var requests sync.WaitGroup
+		for _, key := range []string{"a", "b"} {
+			requests.Add(1)
+			go func(key string) {
+				defer requests.Done()
+				PerformRequest(router, "GET", "/put?key="+key, cookies)
+			}(key)
+		}
+		requests.Wait()
+
+		PerformRequest(router, "GET", "/read", cookies)
+		actual := 0
+		for _, key := range []string{"a", "b"} {
+			if values[key] == "X" {
+				actual++
+			}
+		}
+		if actual != tt.expected || values["init"] != "X" {
+			t.Errorf("Manager.Policy failed: Invalid Data\n   actual: %v\n expected: %v values", values, tt.expected)
+		}
+	}
+}
@@ -4,20 +4,44 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 var (
 	ErrInvalidPattern      = fmt.Errorf("invalid pattern")
-	ErrInvalidSplatPattern = fmt.Errorf("splat patterns must end with *")
+	ErrInvalidSplatPattern = fmt.Errorf("wildcards must be separated by literal text")
 	ErrItemAlreadyExist    = fmt.Errorf("item already exist")
+	ErrItemNotExist        = fmt.Errorf("item does not exist")
 )
 
 // WildcardStore utility to persist and search items using wildcards. Used for channels, topics and events
 //
-// IMPORTANT: It is not safe for insertion in a concurrent scenario. Items should only persist during system startup.
+// Patterns can be exact keys ("room:lobby"), prefixes ("room:*", matches the rest of the key) and patterns with
+// wildcards in the middle ("chat:*:admin") or named captures ("user:{id}:events"), which match one or more characters
+// and are extracted by MatchCaptures. Wildcards and captures must be separated by literal text.
+//
+// The exact key has precedence, then the patterns with wildcards in the middle or captures (more literal text first),
+// then the prefixes.
+//
+// MatchAll returns the exact item first, then the prefixes (less specific first) and then the patterns (more specific
+// first). The prefixes are indexed in a trie, the cost of the search depends on the size of the key and not on the
+// number of prefixes.
+//
+// It is safe for concurrent use, items can be inserted and deleted while serving traffic (ex. dynamic channels and
+// topics). The searches are lock-free, each change replaces an immutable copy of the items (copy-on-write), so changes
+// are expected to be much less frequent than searches.
 type WildcardStore[T any] struct {
-	exactly  map[string]T        // exactly match (Ex. /room:lobby)
-	wildcard []*wildcardEntry[T] // wildcard match (Ex. /room:*)
+	mutex sync.Mutex                       // serializes the changes
+	items atomic.Pointer[wildcardItems[T]] // immutable snapshot, replaced on each change
+}
+
+// wildcardItems immutable snapshot of the items of the WildcardStore
+type wildcardItems[T any] struct {
+	exactly  map[string]T          // exactly match (Ex. /room:lobby)
+	wildcard []*wildcardEntry[T]   // wildcard match (Ex. /room:*)
+	trie     *wildcardNode[T]      // index of the wildcard prefixes
+	patterns []*wildcardPattern[T] // mid-pattern wildcards and captures (Ex. chat:*:admin, user:{id}:events)
 }
 
 type wildcardEntry[T any] struct {
@@ -25,45 +49,175 @@ type wildcardEntry[T any] struct {
 	item   T
 }
 
+// wildcardNode node of the trie (radix tree) of prefixes
+type wildcardNode[T any] struct {
+	label    string // part of the prefix, from the parent node
+	children []*wildcardNode[T]
+	entry    *wildcardEntry[T] // the prefix ends at this node
+}
+
+type wildcardPattern[T any] struct {
+	pattern  string
+	parts    []wildcardPart
+	literals int // length of the literal text, the more specific patterns are matched first
+	item     T
+}
+
+// wildcardPart literal text or wildcard of a pattern
+type wildcardPart struct {
+	literal string
+	capture string // name of the capture, "*" for wildcards
+	rest    bool   // trailing "*", matches the rest of the key (even if empty)
+}
+
+// load gets the current snapshot of the items
+func (s *WildcardStore[T]) load() *wildcardItems[T] {
+	if items := s.items.Load(); items != nil {
+		return items
+	}
+	return &wildcardItems[T]{}
+}
+
+// clone copies the snapshot, to be changed and published
+func (w *wildcardItems[T]) clone() *wildcardItems[T] {
+	c := &wildcardItems[T]{
+		exactly:  make(map[string]T, len(w.exactly)),
+		wildcard: append([]*wildcardEntry[T]{}, w.wildcard...),
+		trie:     w.trie,
+		patterns: append([]*wildcardPattern[T]{}, w.patterns...),
+	}
+	for key, item := range w.exactly {
+		c.exactly[key] = item
+	}
+	return c
+}
+
 // Match returns the value corresponding to the first occurrence of the keyPattern that matches the given key
 func (s *WildcardStore[T]) Match(key string) (out T) {
-	if item, exist := s.exactly[key]; exist {
+	items := s.load()
+	if item, exist := items.exactly[key]; exist {
 		out = item
 		return
 	}
 
-	for _, entry := range s.wildcard {
-		if len(entry.prefix) > len(key) {
-			break
-		}
-		if entry.prefix == "" || strings.HasPrefix(key, entry.prefix) {
-			out = entry.item
+	for _, pattern := range items.patterns {
+		if matchWildcardParts(pattern.parts, key, nil) {
+			out = pattern.item
 			return
 		}
 	}
 
+	return items.matchPrefix(key)
+}
+
+// matchPrefix gets the least specific prefix that matches the key
+func (w *wildcardItems[T]) matchPrefix(key string) (out T) {
+	for node := w.trie; node != nil; node, key = node.next(key) {
+		if node.entry != nil {
+			return node.entry.item
+		}
+	}
+	return
+}
+
+// next gets the child node that matches the start of the key, and the rest of the key
+func (n *wildcardNode[T]) next(key string) (*wildcardNode[T], string) {
+	for _, child := range n.children {
+		if strings.HasPrefix(key, child.label) {
+			return child, key[len(child.label):]
+		}
+	}
+	return nil, key
+}
+
+// insert adds the entry to the trie, `key` is the rest of the prefix after this node
+func (n *wildcardNode[T]) insert(key string, entry *wildcardEntry[T]) {
+	if key == "" {
+		n.entry = entry
+		return
+	}
+	for _, child := range n.children {
+		if child.label[0] != key[0] {
+			continue
+		}
+		common := 1
+		for common < len(child.label) && common < len(key) && child.label[common] == key[common] {
+			common++
+		}
+		if common < len(child.label) {
+			// splits the node at the common part
+			split := &wildcardNode[T]{label: child.label[common:], children: child.children, entry: child.entry}
+			child.label = child.label[:common]
+			child.children = []*wildcardNode[T]{split}
+			child.entry = nil
+		}
+		child.insert(key[common:], entry)
+		return
+	}
+	n.children = append(n.children, &wildcardNode[T]{label: key, entry: entry})
+}
+
+// index rebuilds the trie of the prefixes
+func (w *wildcardItems[T]) index() {
+	w.trie = nil
+	if len(w.wildcard) > 0 {
+		w.trie = &wildcardNode[T]{}
+		for _, entry := range w.wildcard {
+			w.trie.insert(entry.prefix, entry)
+		}
+	}
+}
+
+// MatchCaptures is like Match, also returning the values of the named captures of the pattern that matches the key.
+//
+// ## Example
+//
+//	store.Insert("user:{id}:events", handler)
+//	handler, captures := store.MatchCaptures("user:42:events") // captures = {"id": "42"}
+func (s *WildcardStore[T]) MatchCaptures(key string) (out T, captures map[string]string) {
+	items := s.load()
+	if item, exist := items.exactly[key]; exist {
+		out = item
+		return
+	}
+
+	for _, pattern := range items.patterns {
+		found := map[string]string{}
+		if matchWildcardParts(pattern.parts, key, found) {
+			return pattern.item, found
+		}
+	}
+
+	out = items.matchPrefix(key)
 	return
 }
 
 // MatchAll returns all existing values that match the given key
 func (s *WildcardStore[T]) MatchAll(key string) []T {
-	var items []T
-	if item, exist := s.exactly[key]; exist {
-		items = append(items, item)
+	items := s.load()
+
+	var out []T
+	if item, exist := items.exactly[key]; exist {
+		out = append(out, item)
 	}
 
-	for _, entry := range s.wildcard {
-		if len(entry.prefix) > len(key) {
-			break
+	rest := key
+	for node := items.trie; node != nil; node, rest = node.next(rest) {
+		if node.entry != nil {
+			out = append(out, node.entry.item)
 		}
-		if entry.prefix == "" || strings.HasPrefix(key, entry.prefix) {
-			items = append(items, entry.item)
+	}
+
+	for _, pattern := range items.patterns {
+		if matchWildcardParts(pattern.parts, key, nil) {
+			out = append(out, pattern.item)
 		}
 	}
 
-	return items
+	return out
 }
 
+// Insert adds the item with the keyPattern, returns ErrItemAlreadyExist if the keyPattern is already in use.
 func (s *WildcardStore[T]) Insert(keyPattern string, value T) error {
 
 	keyPattern = strings.TrimSpace(keyPattern)
@@ -72,37 +226,190 @@ func (s *WildcardStore[T]) Insert(keyPattern string, value T) error {
 		return ErrInvalidPattern
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	items := s.load()
+
+	// mid-pattern wildcards and captures
+	if isWildcardPattern(keyPattern) {
+		parts, literals, err := parseWildcardPattern(keyPattern)
+		if err != nil {
+			return err
+		}
+		for _, p := range items.patterns {
+			if p.pattern == keyPattern {
+				return ErrItemAlreadyExist
+			}
+		}
+		items = items.clone()
+		items.patterns = append(items.patterns, &wildcardPattern[T]{
+			pattern:  keyPattern,
+			parts:    parts,
+			literals: literals,
+			item:     value,
+		})
+		sort.SliceStable(items.patterns, func(i, j int) bool {
+			return items.patterns[i].literals > items.patterns[j].literals
+		})
+		s.items.Store(items)
+		return nil
+	}
+
 	// wildcard
 	if strings.HasSuffix(keyPattern, "*") {
 		prefix := strings.TrimSuffix(keyPattern, "*")
 
-		if strings.ContainsRune(prefix, '*') {
-			return ErrInvalidSplatPattern
-		}
-
-		for _, w := range s.wildcard {
+		for _, w := range items.wildcard {
 			if w.prefix == prefix {
 				return ErrItemAlreadyExist
 			}
 		}
-		s.wildcard = append(s.wildcard, &wildcardEntry[T]{
+		items = items.clone()
+		items.wildcard = append(items.wildcard, &wildcardEntry[T]{
 			prefix: prefix,
 			item:   value,
 		})
-		sort.Slice(s.wildcard, func(i, j int) bool {
-			return len(s.wildcard[i].prefix) < len(s.wildcard[j].prefix)
-		})
+		items.index()
+		s.items.Store(items)
 		return nil
 	}
 
-	if s.exactly == nil {
-		s.exactly = map[string]T{}
+	if _, exist := items.exactly[keyPattern]; exist {
+		return ErrItemAlreadyExist
 	}
 
-	if _, exist := s.exactly[keyPattern]; exist {
-		return ErrItemAlreadyExist
+	items = items.clone()
+	items.exactly[keyPattern] = value
+	s.items.Store(items)
+	return nil
+}
+
+// Delete removes the item with the keyPattern (the same used in Insert), returns ErrItemNotExist if there is no item
+// with the keyPattern. Searches in progress still see the previous items.
+func (s *WildcardStore[T]) Delete(keyPattern string) error {
+	keyPattern = strings.TrimSpace(keyPattern)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	items := s.load()
+
+	if isWildcardPattern(keyPattern) {
+		for i, p := range items.patterns {
+			if p.pattern == keyPattern {
+				items = items.clone()
+				items.patterns = append(items.patterns[:i], items.patterns[i+1:]...)
+				s.items.Store(items)
+				return nil
+			}
+		}
+		return ErrItemNotExist
 	}
 
-	s.exactly[keyPattern] = value
+	if strings.HasSuffix(keyPattern, "*") {
+		prefix := strings.TrimSuffix(keyPattern, "*")
+		for i, w := range items.wildcard {
+			if w.prefix == prefix {
+				items = items.clone()
+				items.wildcard = append(items.wildcard[:i], items.wildcard[i+1:]...)
+				items.index()
+				s.items.Store(items)
+				return nil
+			}
+		}
+		return ErrItemNotExist
+	}
+
+	if _, exist := items.exactly[keyPattern]; !exist {
+		return ErrItemNotExist
+	}
+	items = items.clone()
+	delete(items.exactly, keyPattern)
+	s.items.Store(items)
 	return nil
 }
+
+// isWildcardPattern checks if the pattern has wildcards in the middle or captures
+func isWildcardPattern(keyPattern string) bool {
+	return strings.ContainsAny(keyPattern, "{}") || strings.ContainsRune(strings.TrimSuffix(keyPattern, "*"), '*')
+}
+
+// parseWildcardPattern splits the pattern in literal text and wildcards ("*" or "{name}")
+func parseWildcardPattern(pattern string) (parts []wildcardPart, literals int, err error) {
+	var literal strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		var capture string
+		switch pattern[i] {
+		case '*':
+			capture = "*"
+		case '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if end < 0 {
+				return nil, 0, ErrInvalidPattern
+			}
+			capture = pattern[i+1 : i+end]
+			if capture == "" || strings.ContainsAny(capture, "{*") {
+				return nil, 0, ErrInvalidPattern
+			}
+			i += end
+		case '}':
+			return nil, 0, ErrInvalidPattern
+		default:
+			literal.WriteByte(pattern[i])
+			continue
+		}
+
+		if literal.Len() > 0 {
+			parts = append(parts, wildcardPart{literal: literal.String()})
+			literals += literal.Len()
+			literal.Reset()
+		} else if len(parts) > 0 {
+			// wildcards must be separated by literal text
+			return nil, 0, ErrInvalidSplatPattern
+		}
+		parts = append(parts, wildcardPart{capture: capture, rest: capture == "*" && i == len(pattern)-1})
+	}
+	if literal.Len() > 0 {
+		parts = append(parts, wildcardPart{literal: literal.String()})
+		literals += literal.Len()
+	}
+	return
+}
+
+// matchWildcardParts checks if the key matches the pattern parts, filling the named captures (when not nil)
+func matchWildcardParts(parts []wildcardPart, key string, captures map[string]string) bool {
+	if len(parts) == 0 {
+		return key == ""
+	}
+
+	part := parts[0]
+	if part.capture == "" {
+		if !strings.HasPrefix(key, part.literal) {
+			return false
+		}
+		return matchWildcardParts(parts[1:], key[len(part.literal):], captures)
+	}
+
+	if len(parts) == 1 {
+		if key == "" && !part.rest {
+			return false
+		}
+		if captures != nil && part.capture != "*" {
+			captures[part.capture] = key
+		}
+		return true
+	}
+
+	// the wildcard matches one or more characters, until an occurrence of the next literal that matches the rest
+	next := parts[1].literal
+	for i := 1; i+len(next) <= len(key); i++ {
+		if strings.HasPrefix(key[i:], next) && matchWildcardParts(parts[1:], key[i:], captures) {
+			if captures != nil && part.capture != "*" {
+				captures[part.capture] = key[:i]
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,9 @@
 package pkg
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 )
 
@@ -137,6 +139,150 @@ func Test_WildcardStore_MatchAll(t *testing.T) {
 	}
 }
 
+func Test_WildcardStore_Patterns(t *testing.T) {
+	routes := []struct {
+		pattern  string
+		search   string
+		result   any
+		captures map[string]string
+	}{
+		{"chat:*:admin", "chat:lobby:admin", true, nil},
+		{"chat:*:admin", "chat:a:b:admin", true, nil},
+		{"chat:*:admin", "chat::admin", nil, nil},
+		{"chat:*:admin", "chat:lobby:user", nil, nil},
+		{"chat:*:admin", "chat:lobby:admin:x", nil, nil},
+		{"user:{id}:events", "user:42:events", true, map[string]string{"id": "42"}},
+		{"user:{id}:events", "user:42:logs", nil, nil},
+		{"user:{id}", "user:42", true, map[string]string{"id": "42"}},
+		{"user:{id}", "user:", nil, nil},
+		{"user:{id}:*", "user:42:", true, map[string]string{"id": "42"}},
+		{"user:{id}:*", "user:42:events:1", true, map[string]string{"id": "42"}},
+		{"{tenant}:room:{room}", "acme:room:1", true, map[string]string{"tenant": "acme", "room": "1"}},
+	}
+	for _, tt := range routes {
+		t.Run(tt.pattern+"="+tt.search, func(t *testing.T) {
+			store := &WildcardStore[any]{}
+			if err := store.Insert(tt.pattern, true); err != nil {
+				t.Fatalf("WildcardStore.Insert() | unexpected error %v", err)
+			}
+			if value := store.Match(tt.search); value != tt.result {
+				t.Errorf("WildcardStore.Match() | invalid \n   actual: %v\n expected: %v", value, tt.result)
+			}
+			value, captures := store.MatchCaptures(tt.search)
+			if value != tt.result || (tt.captures != nil && !reflect.DeepEqual(captures, tt.captures)) {
+				t.Errorf("WildcardStore.MatchCaptures() | invalid \n   actual: %v %v\n expected: %v %v", value, captures, tt.result, tt.captures)
+			}
+		})
+	}
+
+	// precedence: exact, patterns (more literal text first), prefixes
+	store := &WildcardStore[string]{}
+	store.Insert("chat:*", "prefix")
+	store.Insert("chat:*:admin", "pattern")
+	store.Insert("chat:{room}:admin:{id}", "specific")
+	store.Insert("chat:lobby:admin", "exact")
+
+	for search, expected := range map[string]string{
+		"chat:lobby:admin":   "exact",
+		"chat:main:admin":    "pattern",
+		"chat:main:admin:42": "specific",
+		"chat:main:user":     "prefix",
+	} {
+		if value := store.Match(search); value != expected {
+			t.Errorf("WildcardStore.Match() | invalid precedence for %s\n   actual: %v\n expected: %v", search, value, expected)
+		}
+	}
+}
+
+func Test_WildcardStore_Delete(t *testing.T) {
+	store := &WildcardStore[string]{}
+	store.Insert("room:lobby", "exact")
+	store.Insert("room:*", "prefix")
+	store.Insert("room:{id}:admin", "pattern")
+
+	tests := []struct {
+		pattern  string
+		search   string
+		expected string
+	}{
+		{"room:lobby", "room:lobby", "prefix"},
+		{"room:{id}:admin", "room:1:admin", "prefix"},
+		{"room:*", "room:1", ""},
+	}
+	for _, tt := range tests {
+		if err := store.Delete(tt.pattern); err != nil {
+			t.Fatalf("WildcardStore.Delete() | unexpected error %v", err)
+		}
+		if value := store.Match(tt.search); value != tt.expected {
+			t.Errorf("WildcardStore.Delete() | invalid match for %s\n   actual: %v\n expected: %v", tt.search, value, tt.expected)
+		}
+		if err := store.Delete(tt.pattern); err != ErrItemNotExist {
+			t.Errorf("WildcardStore.Delete() | invalid error \n   actual: %v\n expected: %v", err, ErrItemNotExist)
+		}
+	}
+
+	// can be inserted again
+	if err := store.Insert("room:*", "prefix"); err != nil {
+		t.Errorf("WildcardStore.Insert() | unexpected error %v", err)
+	}
+}
+
+func Test_WildcardStore_Concurrent(t *testing.T) {
+	store := &WildcardStore[int]{}
+	store.Insert("room:*", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				pattern := fmt.Sprintf("room:%d:%d", i, j)
+				store.Insert(pattern, j)
+				store.Delete(pattern)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if value := store.Match("room:x"); value != 0 {
+					t.Errorf("WildcardStore.Match() | invalid \n   actual: %v\n expected: %v", value, 0)
+				}
+				store.MatchAll("room:0:1")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if values := store.MatchAll("room:0:1"); len(values) != 1 {
+		t.Errorf("WildcardStore.MatchAll() | invalid \n   actual: %v\n expected: %v", values, []int{0})
+	}
+}
+
+func Test_WildcardStore_MatchAll_Order(t *testing.T) {
+	store := &WildcardStore[string]{}
+	for _, pattern := range []string{"room:lobby:*", "room:*", "room:{id}:*", "room:lobby", "room:l*", "r*", "room:*:admin", "room:lobby:admin"} {
+		if err := store.Insert(pattern, pattern); err != nil {
+			t.Fatalf("WildcardStore.Insert() | unexpected error %v", err)
+		}
+	}
+
+	// exact, prefixes (less specific first), patterns (more specific first)
+	expected := []string{"room:lobby:admin", "r*", "room:*", "room:l*", "room:lobby:*", "room:*:admin", "room:{id}:*"}
+	if values := store.MatchAll("room:lobby:admin"); !reflect.DeepEqual(values, expected) {
+		t.Errorf("WildcardStore.MatchAll() | invalid \n   actual: %v\n expected: %v", values, expected)
+	}
+	if value := store.Match("room:lab"); value != "r*" {
+		t.Errorf("WildcardStore.Match() | invalid \n   actual: %v\n expected: %v", value, "r*")
+	}
+	if value := store.Match("rabbit"); value != "r*" {
+		t.Errorf("WildcardStore.Match() | invalid \n   actual: %v\n expected: %v", value, "r*")
+	}
+	if value := store.Match("x"); value != "" {
+		t.Errorf("WildcardStore.Match() | invalid \n   actual: %v\n expected: %v", value, "")
+	}
+}
+
 func Test_WildcardStore_Errors(t *testing.T) {
 
 	store := &WildcardStore[int]{}
@@ -149,7 +295,11 @@ func Test_WildcardStore_Errors(t *testing.T) {
 		expected error
 	}{
 		{"   ", ErrInvalidPattern},
-		{"ke*y*", ErrInvalidSplatPattern},
+		{"ke**y", ErrInvalidSplatPattern},
+		{"ke{a}*", ErrInvalidSplatPattern},
+		{"ke{y", ErrInvalidPattern},
+		{"ke{}y", ErrInvalidPattern},
+		{"key}", ErrInvalidPattern},
 		{"key", ErrItemAlreadyExist},
 		{"wild*", ErrItemAlreadyExist},
 	}
@@ -211,3 +361,17 @@ func BenchmarkWildcardStore_MatchAll(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkWildcardStore_Match_ManyPrefixes(b *testing.B) {
+	store := &WildcardStore[int]{}
+	for i := 0; i < 1000; i++ {
+		store.Insert(fmt.Sprintf("tenant:%d:room:*", i), i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.Match("tenant:999:room:lobby")
+		store.Match("other:999:room:lobby")
+	}
+}
@@ -1,6 +1,10 @@
 package pubsub
 
-import "github.com/nidorx/chain/crypto"
+import (
+	"time"
+
+	"github.com/nidorx/chain/crypto"
+)
 
 // Adapter Specification to implement a custom PubSub adapter.
 type Adapter interface {
@@ -40,6 +44,38 @@ type AdapterConfig struct {
 	// DisableCompression is used to control message compression. This can be used to reduce bandwidth usage at
 	// the cost of slightly more CPU utilization.
 	DisableCompression bool
+
+	// Compression the algorithm used to compress messages: CompressionLZW (default), CompressionGzip,
+	// CompressionDeflate or an algorithm registered with RegisterCompressor. Envelope versions lower than EnvelopeV2
+	// always use CompressionLZW.
+	Compression uint8
+
+	// CompressionMinSize messages smaller than this size (in bytes) are not compressed.
+	CompressionMinSize int
+
+	// Authorizer consulted on the actions over the topics of this adapter, in addition to the global Authorizer (see
+	// SetAuthorizer)
+	Authorizer Authorizer
+
+	// MaxPayload max size (in bytes) of the messages accepted by the broker, larger messages are fragmented (see
+	// Fragmentation) or rejected with ErrPayloadTooLarge. No limit if zero
+	MaxPayload int
+
+	// Fragmentation messages larger than MaxPayload are split in fragments, reassembled by the receiving nodes (see
+	// SetFragmentTimeout). All nodes must run a release that supports fragments
+	Fragmentation bool
+
+	// AtLeastOnce the messages of the topics of this adapter are acknowledged only after being processed by the local
+	// dispatchers and redelivered on failure. Requires a PersistentAdapter
+	AtLeastOnce bool
+
+	// Consumer stable name of this node on the broker, used to track the offset of the node on the topics (see
+	// PersistentAdapter). Defaults to the hostname
+	Consumer string
+
+	// UnsubscribeGracePeriod overrides the global grace period for this adapter (see SetUnsubscribeGracePeriod). When
+	// zero, the global value is used. A negative value unsubscribes immediately.
+	UnsubscribeGracePeriod time.Duration
 }
 
 // DummyAdapter default adapter for local message distribution (only for the current node)
@@ -0,0 +1,72 @@
+package pubsub
+
+import (
+	"errors"
+	"sync"
+)
+
+// Action the pubsub operation being authorized, see SetAuthorizer
+type Action uint8
+
+const (
+	ActionSubscribe Action = iota + 1
+	ActionBroadcast
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionSubscribe:
+		return "subscribe"
+	case ActionBroadcast:
+		return "broadcast"
+	default:
+		return "unknown"
+	}
+}
+
+// Authorizer decides if the action is allowed on the topic, returning an error denies the action
+type Authorizer func(topic string, action Action) error
+
+// ErrUnauthorized can be returned by an Authorizer to deny an action
+var ErrUnauthorized = errors.New("pubsub action not authorized")
+
+var (
+	authorizerMutex  sync.RWMutex
+	globalAuthorizer Authorizer
+)
+
+// SetAuthorizer set the global Authorizer, consulted on Subscribe, Broadcast, DirectBroadcast and LocalBroadcast. The
+// Authorizer of the adapter that matches the topic (see AdapterConfig.Authorizer) is also consulted. Socket channels
+// check the authorization before joining a topic.
+//
+// ## Example
+//
+//	// code running for a tenant can't publish or subscribe to topics of other tenants
+//	pubsub.SetAuthorizer(func(topic string, action pubsub.Action) error {
+//		if strings.HasPrefix(topic, "tenant:") && !strings.HasPrefix(topic, "tenant:"+currentTenant+":") {
+//			return pubsub.ErrUnauthorized
+//		}
+//		return nil
+//	})
+func SetAuthorizer(authorizer Authorizer) {
+	authorizerMutex.Lock()
+	defer authorizerMutex.Unlock()
+	globalAuthorizer = authorizer
+}
+
+// Authorize checks if the action is allowed on the topic, by the global and by the adapter Authorizer
+func Authorize(topic string, action Action) error {
+	authorizerMutex.RLock()
+	authorizer := globalAuthorizer
+	authorizerMutex.RUnlock()
+
+	if authorizer != nil {
+		if err := authorizer(topic, action); err != nil {
+			return err
+		}
+	}
+	if config := GetAdapter(topic); config != nil && config.Authorizer != nil {
+		return config.Authorizer(topic, action)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package pubsub
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_PubSub_Authorizer(t *testing.T) {
+	testClearPubsub()
+
+	var checked []string
+	SetAuthorizer(func(topic string, action Action) error {
+		checked = append(checked, action.String()+" "+topic)
+		if strings.HasPrefix(topic, "tenant:b") {
+			return ErrUnauthorized
+		}
+		return nil
+	})
+	defer SetAuthorizer(nil)
+
+	adapterErr := errors.New("read only")
+	SetAdapters([]AdapterConfig{{
+		Adapter: testAdapter,
+		Topics:  []string{"*"},
+		Authorizer: func(topic string, action Action) error {
+			if strings.HasPrefix(topic, "readonly:") && action == ActionBroadcast {
+				return adapterErr
+			}
+			return nil
+		},
+	}})
+	defer testClearPubsub()
+
+	dispatcher := &testDispatcherStruct{}
+
+	if err := Subscribe("tenant:a:users", dispatcher); err != nil {
+		t.Errorf("Subscribe failed: Unexpected Error\n   actual: %v", err)
+	}
+	defer Unsubscribe("tenant:a:users", dispatcher)
+	if err := Subscribe("tenant:b:users", dispatcher); err != ErrUnauthorized {
+		t.Errorf("Subscribe failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrUnauthorized)
+	}
+	if err := Broadcast("tenant:b:users", []byte("x")); err != ErrUnauthorized {
+		t.Errorf("Broadcast failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrUnauthorized)
+	}
+	if err := DirectBroadcast(Self(), "tenant:b:users", []byte("x")); err != ErrUnauthorized {
+		t.Errorf("DirectBroadcast failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrUnauthorized)
+	}
+	if err := Broadcast("readonly:config", []byte("x")); err != adapterErr {
+		t.Errorf("Broadcast failed: Invalid Adapter Error\n   actual: %v\n expected: %v", err, adapterErr)
+	}
+	if err := Subscribe("readonly:config", dispatcher); err != nil {
+		t.Errorf("Subscribe failed: Unexpected Adapter Error\n   actual: %v", err)
+	}
+	defer Unsubscribe("readonly:config", dispatcher)
+
+	LocalBroadcast("tenant:b:users", []byte("x"))
+	if err := Broadcast("tenant:a:users", []byte("x")); err != nil {
+		t.Errorf("Broadcast failed: Unexpected Error\n   actual: %v", err)
+	}
+	<-time.After(time.Millisecond * 10)
+
+	if received := dispatcher.pop(); received == nil || received.topic != "tenant:a:users" {
+		t.Errorf("Broadcast failed: Message not delivered\n   actual: %v", received)
+	}
+	if received := dispatcher.pop(); received != nil {
+		t.Errorf("Broadcast failed: Unauthorized message delivered\n   actual: %v", received)
+	}
+
+	expected := "subscribe tenant:a:users"
+	if len(checked) == 0 || checked[0] != expected {
+		t.Errorf("SetAuthorizer failed: Invalid Action\n   actual: %v\n expected: %v", checked, expected)
+	}
+}
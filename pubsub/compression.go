@@ -2,15 +2,109 @@ package pubsub
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"compress/lzw"
+	"errors"
 	"io"
+	"sync"
 )
 
+// Built-in compression algorithms. The algorithm used is recorded in the message envelope (EnvelopeV2 or later) so
+// receivers pick the right decompressor.
+//
+// Other algorithms (ex. zstd, s2) are not built-in, to keep this package free of compression dependencies. They can be
+// registered by the application with ids starting at CompressionCustom, see RegisterCompressor.
+const (
+	CompressionLZW     uint8 = 0 // Default. The only algorithm understood by envelope versions lower than EnvelopeV2
+	CompressionGzip    uint8 = 1
+	CompressionDeflate uint8 = 2
+	CompressionCustom  uint8 = 128 // First id of the algorithms registered by the application
+)
+
+var ErrCompressorMissing = errors.New("no compressor registered for the compression algorithm")
+
+// Compressor implements a compression algorithm
+type Compressor interface {
+	Compress(payload []byte) ([]byte, error)
+	Decompress(compressed []byte) ([]byte, error)
+}
+
+var (
+	compressorsMutex sync.RWMutex
+	compressors      = map[uint8]Compressor{
+		CompressionLZW:     &lzwCompressor{},
+		CompressionGzip:    &gzipCompressor{},
+		CompressionDeflate: &deflateCompressor{},
+	}
+)
+
+// RegisterCompressor register the Compressor for the given algorithm. Allows using algorithms that are not built-in
+// (ex. zstd, s2), with ids starting at CompressionCustom. All nodes in the cluster must register the same algorithms.
+//
+// ## Example
+//
+//	const CompressionZstd = pubsub.CompressionCustom
+//
+//	type ZstdCompressor struct{}
+//
+//	func (c *ZstdCompressor) Compress(payload []byte) ([]byte, error) {
+//		encoder, _ := zstd.NewWriter(nil)
+//		return encoder.EncodeAll(payload, nil), nil
+//	}
+//
+//	func (c *ZstdCompressor) Decompress(compressed []byte) ([]byte, error) {
+//		decoder, _ := zstd.NewReader(nil)
+//		return decoder.DecodeAll(compressed, nil)
+//	}
+//
+//	pubsub.RegisterCompressor(CompressionZstd, &ZstdCompressor{})
+//	pubsub.SetAdapters([]pubsub.AdapterConfig{{Adapter: adapter, Topics: []string{"*"}, Compression: CompressionZstd}})
+func RegisterCompressor(algorithm uint8, compressor Compressor) {
+	compressorsMutex.Lock()
+	defer compressorsMutex.Unlock()
+	compressors[algorithm] = compressor
+}
+
+func getCompressor(algorithm uint8) Compressor {
+	compressorsMutex.RLock()
+	defer compressorsMutex.RUnlock()
+	return compressors[algorithm]
+}
+
 // compressPayload takes an opaque input buffer, compresses it and wraps it in a compress message that is encoded.
-func compressPayload(payload []byte) ([]byte, error) {
+func compressPayload(algorithm uint8, payload []byte) ([]byte, error) {
 	// ? metrics compression time, rate
+	compressor := getCompressor(algorithm)
+	if compressor == nil {
+		return nil, ErrCompressorMissing
+	}
+
+	compressed, err := compressor.Compress(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a compressed message
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte(byte(messageTypeCompress))
+	buf.Write(compressed)
+	return buf.Bytes(), nil
+}
+
+// decompressPayload is used to unpack an encoded message and return its payload uncompressed
+func decompressPayload(algorithm uint8, encoded []byte) ([]byte, error) {
+	compressor := getCompressor(algorithm)
+	if compressor == nil {
+		return nil, ErrCompressorMissing
+	}
+	return compressor.Decompress(encoded[1:])
+}
+
+type lzwCompressor struct{}
+
+func (c *lzwCompressor) Compress(payload []byte) ([]byte, error) {
 	var buffer bytes.Buffer
-	//writer := gzip.NewWriter(&buffer)
 	writer := lzw.NewWriter(&buffer, lzw.LSB, 8)
 	if _, err := writer.Write(payload); err != nil {
 		return nil, err
@@ -20,25 +114,62 @@ func compressPayload(payload []byte) ([]byte, error) {
 	if err := writer.Close(); err != nil {
 		return nil, err
 	}
+	return buffer.Bytes(), nil
+}
 
-	// Create a compressed message
-	buf := bytes.NewBuffer(nil)
-	buf.WriteByte(byte(messageTypeCompress))
-	buf.Write(buffer.Bytes())
-	return buf.Bytes(), nil
+func (c *lzwCompressor) Decompress(compressed []byte) ([]byte, error) {
+	reader := lzw.NewReader(bytes.NewReader(compressed), lzw.LSB, 8)
+	defer reader.Close()
+	return readAll(reader)
 }
 
-// decompressPayload is used to unpack an encoded message and return its payload uncompressed
-func decompressPayload(encoded []byte) ([]byte, error) {
-	r := bytes.NewReader(encoded[1:])
-	// Create a un compressor
-	//reader, err := gzip.NewReader(r)
-	//if err != nil {
-	//	return nil, err
-	//}
-	reader := lzw.NewReader(r, lzw.LSB, 8)
+type gzipCompressor struct{}
+
+func (c *gzipCompressor) Compress(payload []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (c *gzipCompressor) Decompress(compressed []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
 	defer reader.Close()
+	return readAll(reader)
+}
+
+type deflateCompressor struct{}
+
+func (c *deflateCompressor) Compress(payload []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer, err := flate.NewWriter(&buffer, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (c *deflateCompressor) Decompress(compressed []byte) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(compressed))
+	defer reader.Close()
+	return readAll(reader)
+}
 
+func readAll(reader io.Reader) ([]byte, error) {
 	// Read all the data
 	var b bytes.Buffer
 	if _, err := io.Copy(&b, reader); err != nil {
@@ -1,28 +1,93 @@
 package pubsub
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_PubSub_Compression(t *testing.T) {
-	for _, tt := range testPayloads {
-		t.Run(tt.content, func(t *testing.T) {
-			payload := []byte(tt.content)
-			compressed, err := compressPayload(payload)
-			if err != nil {
-				t.Fatalf("unexpected err: %s", err)
-			}
+	algorithms := []uint8{CompressionLZW, CompressionGzip, CompressionDeflate}
+	for _, algorithm := range algorithms {
+		for _, tt := range testPayloads {
+			t.Run(fmt.Sprintf("%d:%s", algorithm, tt.content), func(t *testing.T) {
+				payload := []byte(tt.content)
+				compressed, err := compressPayload(algorithm, payload)
+				if err != nil {
+					t.Fatalf("unexpected err: %s", err)
+				}
+
+				//fmt.Printf("Len from %d to %d", len(payload), len(compressed))
+
+				dec, err := decompressPayload(algorithm, compressed)
+				if err != nil {
+					t.Fatalf("unexpected err: %s", err)
+				}
+
+				if !reflect.DeepEqual(dec, payload) {
+					t.Fatalf("bad payload: %v", dec)
+				}
+			})
+		}
+	}
+}
+
+func Test_PubSub_Compression_Missing(t *testing.T) {
+	if _, err := compressPayload(CompressionCustom, []byte("test")); err != ErrCompressorMissing {
+		t.Errorf("Invalid error\n   actual: %v\n expected: %v", err, ErrCompressorMissing)
+	}
+}
 
-			//fmt.Printf("Len from %d to %d", len(payload), len(compressed))
+func Test_PubSub_Compression_Adapter_Config(t *testing.T) {
+	topic := "user:123"
+	message := []byte(testPayloads[3].content)
+
+	tests := []struct {
+		algorithm   uint8
+		minSize     int
+		compression bool
+	}{
+		{CompressionGzip, 0, true},
+		{CompressionDeflate, 0, true},
+		{CompressionGzip, len(message) * 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d:%d", tt.algorithm, tt.minSize), func(t *testing.T) {
+			testClearPubsub()
+			testAdapter.clear()
+			SetAdapters([]AdapterConfig{{
+				Adapter:            testAdapter,
+				Topics:             []string{"*"},
+				DisableEncryption:  true,
+				Compression:        tt.algorithm,
+				CompressionMinSize: tt.minSize,
+			}})
 
-			dec, err := decompressPayload(compressed)
-			if err != nil {
-				t.Fatalf("unexpected err: %s", err)
+			testAsRemote(func() {
+				if err := Broadcast(topic, message); err != nil {
+					t.Fatal(err)
+				}
+			})
+			remoteMessage := testAdapter.pop()
+
+			// [messageTypeEnvelope: byte] [version: byte] [compression: byte] [expires: int64] [messageType: byte] [...]
+			if remoteMessage.message[2] != tt.algorithm {
+				t.Errorf("Invalid compression\n   actual: %v\n expected: %v", remoteMessage.message[2], tt.algorithm)
+			}
+			if compressed := messageType(remoteMessage.message[11]) == messageTypeCompress; compressed != tt.compression {
+				t.Errorf("Invalid compression\n   actual: %v\n expected: %v", compressed, tt.compression)
 			}
 
-			if !reflect.DeepEqual(dec, payload) {
-				t.Fatalf("bad payload: %v", dec)
+			dispatcher := &testDispatcherStruct{}
+			Subscribe(topic, dispatcher)
+			Dispatch(remoteMessage.topic, remoteMessage.message)
+			<-time.After(time.Millisecond * 10)
+
+			received := dispatcher.pop()
+			expected := &testDispatcherMessage{topic: topic, message: message, from: remoteIdString}
+			if !reflect.DeepEqual(received, expected) {
+				t.Errorf("Invalid response\n   actual: %v\n expected: %v", received, expected)
 			}
 		})
 	}
@@ -0,0 +1,122 @@
+package pubsub
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync/atomic"
+)
+
+// Envelope versions.
+//
+// The envelope allows the evolution of the protocol (compression and encryption algorithms, message layout) without
+// downtime. Every node accepts messages with any version less than or equal to EnvelopeLatest, and silently ignores
+// messages with newer versions. A new version is rolled out cluster-wide in two steps:
+//
+//  1. Deploy the new release on all nodes while still sending the previous version (see SetEnvelopeVersion). All nodes
+//     are now able to read both versions.
+//  2. Change the version sent (or deploy a release whose default is the new version).
+//
+// The default is EnvelopeLatest. When upgrading a cluster whose nodes predate the envelope, use EnvelopeLegacy in the
+// first step: these nodes do not understand the envelope, they drop every enveloped message and log a decode error.
+// Until the version is changed, the features recorded in the envelope are disabled (the compression is always
+// CompressionLZW and TTL is not enforced by the receivers).
+const (
+	EnvelopeLegacy uint8 = 0 // No envelope, [messageType: byte] [...]
+	EnvelopeV1     uint8 = 1 // [messageTypeEnvelope: byte] [version: byte] [messageType: byte] [...]
+	EnvelopeV2     uint8 = 2 // [messageTypeEnvelope: byte] [version: byte] [compression: byte] [messageType: byte] [...]
+	EnvelopeV3     uint8 = 3 // [messageTypeEnvelope: byte] [version: byte] [compression: byte] [expires: int64] [messageType: byte] [...]
+	EnvelopeLatest       = EnvelopeV3
+)
+
+var (
+	envelopeVersion        = atomic.Uint32{}
+	ErrInvalidEnvelope     = errors.New("invalid message envelope")
+	ErrUnsupportedEnvelope = errors.New("unsupported envelope version")
+)
+
+func init() {
+	envelopeVersion.Store(uint32(EnvelopeLatest))
+}
+
+// SetEnvelopeVersion set the envelope version used when sending messages. Defaults to EnvelopeLatest.
+func SetEnvelopeVersion(version uint8) error {
+	if version > EnvelopeLatest {
+		return ErrUnsupportedEnvelope
+	}
+	envelopeVersion.Store(uint32(version))
+	return nil
+}
+
+// GetEnvelopeVersion get the envelope version used when sending messages
+func GetEnvelopeVersion() uint8 {
+	return uint8(envelopeVersion.Load())
+}
+
+// wrapEnvelope wraps the encoded message in the given envelope version. `expires` is the expiration time of the message
+// (unix milliseconds, zero if the message does not expire), only recorded by EnvelopeV3 or later.
+func wrapEnvelope(version uint8, compression uint8, expires int64, message []byte) []byte {
+	switch version {
+	case EnvelopeLegacy:
+		return message
+	case EnvelopeV1:
+		// [messageTypeEnvelope: byte] [version: byte] [message: ...]
+		wrapped := make([]byte, len(message)+2)
+		wrapped[0] = byte(messageTypeEnvelope)
+		wrapped[1] = version
+		copy(wrapped[2:], message)
+		return wrapped
+	case EnvelopeV2:
+		// [messageTypeEnvelope: byte] [version: byte] [compression: byte] [message: ...]
+		wrapped := make([]byte, len(message)+3)
+		wrapped[0] = byte(messageTypeEnvelope)
+		wrapped[1] = version
+		wrapped[2] = compression
+		copy(wrapped[3:], message)
+		return wrapped
+	default:
+		// [messageTypeEnvelope: byte] [version: byte] [compression: byte] [expires: int64] [message: ...]
+		wrapped := make([]byte, len(message)+11)
+		wrapped[0] = byte(messageTypeEnvelope)
+		wrapped[1] = version
+		wrapped[2] = compression
+		binary.BigEndian.PutUint64(wrapped[3:11], uint64(expires))
+		copy(wrapped[11:], message)
+		return wrapped
+	}
+}
+
+// unwrapEnvelope reads the envelope header and returns the wrapped message.
+//
+// Messages with unsupported version are returned without validation, the caller is responsible for ignoring them.
+func unwrapEnvelope(encoded []byte) (version uint8, compression uint8, expires int64, message []byte, err error) {
+	if len(encoded) < 2 {
+		return 0, 0, 0, nil, ErrInvalidEnvelope
+	}
+	version = encoded[1]
+	compression = CompressionLZW
+
+	switch {
+	case version > EnvelopeLatest:
+		return
+	case version >= EnvelopeV3:
+		if len(encoded) < 11 {
+			return 0, 0, 0, nil, ErrInvalidEnvelope
+		}
+		compression = encoded[2]
+		expires = int64(binary.BigEndian.Uint64(encoded[3:11]))
+		message = encoded[11:]
+	case version >= EnvelopeV2:
+		if len(encoded) < 3 {
+			return 0, 0, 0, nil, ErrInvalidEnvelope
+		}
+		compression = encoded[2]
+		message = encoded[3:]
+	default:
+		message = encoded[2:]
+	}
+
+	if len(message) == 0 {
+		return 0, 0, 0, nil, ErrInvalidEnvelope
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_PubSub_Envelope_Versions(t *testing.T) {
+	topic := "user:123"
+	message := []byte(`[{"id":1}, {"id":2}, {"id":3}, {"id":4}, {"id":5}]`)
+
+	defer SetEnvelopeVersion(EnvelopeLatest)
+
+	for _, version := range []uint8{EnvelopeLegacy, EnvelopeV1, EnvelopeV2, EnvelopeV3} {
+		testClearPubsub()
+		testAdapter.clear()
+
+		if err := SetEnvelopeVersion(version); err != nil {
+			t.Fatal(err)
+		}
+
+		testAsRemote(func() {
+			if err := Broadcast(topic, message); err != nil {
+				t.Fatal(err)
+			}
+		})
+		remoteMessage := testAdapter.pop()
+
+		isEnvelope := messageType(remoteMessage.message[0]) == messageTypeEnvelope
+		if isEnvelope != (version != EnvelopeLegacy) {
+			t.Errorf("Invalid envelope. Version: %d", version)
+		}
+
+		dispatcher := &testDispatcherStruct{}
+		Subscribe(topic, dispatcher)
+
+		Dispatch(remoteMessage.topic, remoteMessage.message)
+
+		<-time.After(time.Millisecond * 10)
+
+		received := dispatcher.pop()
+		expected := &testDispatcherMessage{topic: topic, message: message, from: remoteIdString}
+		if !reflect.DeepEqual(received, expected) {
+			t.Errorf("Invalid response. Version: %d\n   actual: %v\n expected: %v", version, received, expected)
+		}
+	}
+}
+
+func Test_PubSub_Envelope_Unsupported_Version(t *testing.T) {
+	topic := "user:123"
+
+	testClearPubsub()
+
+	var decodeErr error
+	OnDecodeError(func(topic string, message []byte, adapter string, err error) {
+		decodeErr = err
+	})
+	defer OnDecodeError(nil)
+
+	dispatcher := &testDispatcherStruct{}
+	Subscribe(topic, dispatcher)
+
+	Dispatch(topic, []byte{byte(messageTypeEnvelope), EnvelopeLatest + 1, 0xCA, 0xFE})
+
+	<-time.After(time.Millisecond * 10)
+
+	if received := dispatcher.pop(); received != nil {
+		t.Errorf("dispatcher must not receive the message")
+	}
+	if decodeErr != nil {
+		t.Errorf("unsupported versions must be ignored\n   error: %v", decodeErr)
+	}
+
+	if err := SetEnvelopeVersion(EnvelopeLatest + 1); err != ErrUnsupportedEnvelope {
+		t.Errorf("Invalid error\n   actual: %v\n expected: %v", err, ErrUnsupportedEnvelope)
+	}
+}
+
+func Test_PubSub_Envelope_TTL(t *testing.T) {
+	topic := "room:123:typing"
+	message := []byte(`{"typing":true}`)
+
+	testClearPubsub()
+	testAdapter.clear()
+
+	dispatcher := &testDispatcherStruct{}
+	Subscribe(topic, dispatcher)
+	defer UnsubscribeNow(topic, dispatcher)
+
+	testAsRemote(func() {
+		if err := Broadcast(topic, message, TTL(20*time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	remoteMessage := testAdapter.pop()
+	<-time.After(time.Millisecond * 5)
+	dispatcher.pop() // local dispatch
+	if ttl, _ := remoteMessage.opts[OptionTTL].(time.Duration); ttl != 20*time.Millisecond {
+		t.Errorf("Invalid adapter option\n   actual: %v\n expected: %v", ttl, 20*time.Millisecond)
+	}
+
+	// not expired
+	Dispatch(remoteMessage.topic, remoteMessage.message)
+	<-time.After(time.Millisecond * 10)
+	if received := dispatcher.pop(); received == nil {
+		t.Errorf("dispatcher did not receive the message")
+	}
+
+	// expired
+	<-time.After(time.Millisecond * 20)
+	Dispatch(remoteMessage.topic, remoteMessage.message)
+	<-time.After(time.Millisecond * 10)
+	if received := dispatcher.pop(); received != nil {
+		t.Errorf("dispatcher must not receive expired messages")
+	}
+
+	for _, stats := range Subscriptions() {
+		if stats.Topic == topic && stats.Expired != 1 {
+			t.Errorf("Invalid expired count\n   actual: %v\n expected: %v", stats.Expired, 1)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package pubsub
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// messageTypeFragment identifies a fragment of a message larger than AdapterConfig.MaxPayload.
+//
+// [messageTypeFragment: byte] [id: 20 bytes] [sequence: uint16] [count: uint16] [chunk: ...]
+const messageTypeFragment messageType = 0xFE
+
+const fragmentHeaderSize = 1 + 20 + 2 + 2
+
+// DefaultFragmentTimeout time that the node waits for the missing fragments of a message, see SetFragmentTimeout
+const DefaultFragmentTimeout = 30 * time.Second
+
+var ErrPayloadTooLarge = errors.New("message exceeds the max payload of the adapter")
+
+// fragmentBuffer the fragments received of a message
+type fragmentBuffer struct {
+	chunks   [][]byte
+	received int
+	timer    *time.Timer
+}
+
+var (
+	fragments        = map[string]*fragmentBuffer{}
+	fragmentsMutex   sync.Mutex
+	fragmentsTimeout = DefaultFragmentTimeout
+)
+
+// SetFragmentTimeout set the time that the node waits for the missing fragments of a message (see
+// AdapterConfig.Fragmentation), incomplete messages are discarded after it. Defaults to DefaultFragmentTimeout.
+func SetFragmentTimeout(timeout time.Duration) {
+	fragmentsMutex.Lock()
+	defer fragmentsMutex.Unlock()
+	if timeout <= 0 {
+		timeout = DefaultFragmentTimeout
+	}
+	fragmentsTimeout = timeout
+}
+
+// broadcastPayload sends the encoded message to the adapter, fragmenting it when it exceeds AdapterConfig.MaxPayload
+func broadcastPayload(config *AdapterConfig, topic string, encoded []byte, opts map[string]any) error {
+	if config.MaxPayload <= 0 || len(encoded) <= config.MaxPayload {
+		return config.Adapter.Broadcast(topic, encoded, opts)
+	}
+
+	chunkSize := config.MaxPayload - fragmentHeaderSize
+	if !config.Fragmentation || chunkSize <= 0 {
+		return ErrPayloadTooLarge
+	}
+	count := (len(encoded) + chunkSize - 1) / chunkSize
+	if count > 0xFFFF {
+		return ErrPayloadTooLarge
+	}
+
+	id := ksuid.New().Bytes()
+	for sequence := 0; sequence < count; sequence++ {
+		end := (sequence + 1) * chunkSize
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		chunk := encoded[sequence*chunkSize : end]
+
+		fragment := make([]byte, fragmentHeaderSize+len(chunk))
+		fragment[0] = byte(messageTypeFragment)
+		copy(fragment[1:21], id)
+		binary.BigEndian.PutUint16(fragment[21:23], uint16(sequence))
+		binary.BigEndian.PutUint16(fragment[23:25], uint16(count))
+		copy(fragment[fragmentHeaderSize:], chunk)
+
+		if err := config.Adapter.Broadcast(topic, fragment, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reassembleFragment stores the fragment, returning the message when all its fragments have been received
+func reassembleFragment(topic string, fragment []byte) (message []byte, complete bool, err error) {
+	if len(fragment) <= fragmentHeaderSize {
+		return nil, false, ErrInvalidRemoteMessage
+	}
+	key := topic + ":" + string(fragment[1:21])
+	sequence := int(binary.BigEndian.Uint16(fragment[21:23]))
+	count := int(binary.BigEndian.Uint16(fragment[23:25]))
+	if count == 0 || sequence >= count {
+		return nil, false, ErrInvalidRemoteMessage
+	}
+
+	fragmentsMutex.Lock()
+	defer fragmentsMutex.Unlock()
+
+	buffer, exists := fragments[key]
+	if !exists {
+		buffer = &fragmentBuffer{chunks: make([][]byte, count)}
+		buffer.timer = time.AfterFunc(fragmentsTimeout, func() {
+			// missing fragments, the message is discarded
+			fragmentsMutex.Lock()
+			defer fragmentsMutex.Unlock()
+			if fragments[key] == buffer {
+				delete(fragments, key)
+			}
+		})
+		fragments[key] = buffer
+	} else if len(buffer.chunks) != count {
+		return nil, false, ErrInvalidRemoteMessage
+	}
+
+	if buffer.chunks[sequence] == nil {
+		buffer.chunks[sequence] = append([]byte{}, fragment[fragmentHeaderSize:]...)
+		buffer.received++
+	}
+	if buffer.received < count {
+		return nil, false, nil
+	}
+
+	buffer.timer.Stop()
+	delete(fragments, key)
+	for _, chunk := range buffer.chunks {
+		message = append(message, chunk...)
+	}
+	return message, true, nil
+}
@@ -0,0 +1,83 @@
+package pubsub
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_PubSub_Fragmentation(t *testing.T) {
+	topic := "user:123"
+	message := bytes.Repeat([]byte(`{"id":1}`), 100)
+
+	testClearPubsub()
+	defer testClearPubsub()
+	testAdapter.clear()
+
+	SetAdapters([]AdapterConfig{{
+		Adapter:            testAdapter,
+		Topics:             []string{"*"},
+		DisableCompression: true,
+		MaxPayload:         128,
+		Fragmentation:      true,
+	}})
+
+	testAsRemote(func() {
+		if err := Broadcast(topic, message); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var remoteMessages []*testAdapterMessage
+	for remoteMessage := testAdapter.pop(); remoteMessage != nil; remoteMessage = testAdapter.pop() {
+		if len(remoteMessage.message) > 128 {
+			t.Errorf("Invalid fragment size\n   actual: %v\n expected: <= %v", len(remoteMessage.message), 128)
+		}
+		remoteMessages = append(remoteMessages, remoteMessage)
+	}
+	if len(remoteMessages) < 2 {
+		t.Fatalf("message was not fragmented\n   actual: %v fragments", len(remoteMessages))
+	}
+
+	<-time.After(time.Millisecond * 10)
+	dispatcher := &testDispatcherStruct{}
+	Subscribe(topic, dispatcher)
+	defer UnsubscribeNow(topic, dispatcher)
+
+	// fragments are reassembled in any order (pop returns the last one first)
+	for _, remoteMessage := range remoteMessages {
+		Dispatch(remoteMessage.topic, remoteMessage.message)
+	}
+	<-time.After(time.Millisecond * 10)
+
+	received := dispatcher.pop()
+	expected := &testDispatcherMessage{topic: topic, message: message, from: remoteIdString}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("Invalid response\n   actual: %v\n expected: %v", received, expected)
+	}
+	if received = dispatcher.pop(); received != nil {
+		t.Errorf("dispatcher must receive the message once")
+	}
+}
+
+func Test_PubSub_Max_Payload(t *testing.T) {
+	testClearPubsub()
+	defer testClearPubsub()
+	testAdapter.clear()
+
+	SetAdapters([]AdapterConfig{{
+		Adapter:            testAdapter,
+		Topics:             []string{"*"},
+		DisableCompression: true,
+		MaxPayload:         128,
+	}})
+
+	if err := Broadcast("user:123", bytes.Repeat([]byte("x"), 256)); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("Invalid error\n   actual: %v\n expected: %v", err, ErrPayloadTooLarge)
+	}
+	if remoteMessage := testAdapter.pop(); remoteMessage != nil {
+		t.Errorf("adapter must not receive the message")
+	}
+}
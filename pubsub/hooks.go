@@ -0,0 +1,150 @@
+package pubsub
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+var (
+	ErrDispatcherPanic         = errors.New("dispatcher panicked while processing the message")
+	ErrInvalidRemoteMessage    = errors.New("invalid remote message")
+	ErrEncryptionNotConfigured = errors.New("remote message is encrypted and encryption is not configured")
+	ErrMessageNotEncrypted     = errors.New("encryption is configured but remote message is not encrypted")
+)
+
+// DispatchErrorHandler invoked when a Dispatcher fails (panic) to process a message.
+type DispatchErrorHandler func(topic string, message any, from string, dispatcher Dispatcher, err error)
+
+// DecodeErrorHandler invoked when a remote message received by an adapter cannot be decrypted, decompressed or
+// decoded.
+type DecodeErrorHandler func(topic string, message []byte, adapter string, err error)
+
+// DeadLetter metadata of a message that could not be delivered. It is the message received by the dead-letter
+// Dispatcher (see SetDeadLetter).
+type DeadLetter struct {
+	Topic      string     // The topic of the message
+	Message    any        // The original message. For remote messages, the raw bytes received from the adapter
+	From       string     // The node that sent the message, empty when it could not be decoded
+	Adapter    string     // The name of the adapter that received the message
+	Dispatcher Dispatcher // The Dispatcher that failed, nil for decode errors
+	Error      error      // The reason why the message was not delivered
+}
+
+var (
+	hooksMutex         sync.RWMutex
+	onDispatchError    DispatchErrorHandler
+	onDecodeError      DecodeErrorHandler
+	deadLetterDispatch Dispatcher
+)
+
+// OnDispatchError set the handler invoked when a Dispatcher panics while processing a message.
+func OnDispatchError(handler DispatchErrorHandler) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	onDispatchError = handler
+}
+
+// OnDecodeError set the handler invoked when a remote message cannot be decoded.
+func OnDecodeError(handler DecodeErrorHandler) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	onDecodeError = handler
+}
+
+// SetDeadLetter set the Dispatcher that receives undeliverable messages. The message is always a *DeadLetter and the
+// topic is the topic of the original message.
+//
+// ## Example
+//
+//	pubsub.SetDeadLetter(pubsub.DispatcherFunc(func(topic string, message any, from string) {
+//		letter := message.(*pubsub.DeadLetter)
+//		metrics.Increment("pubsub.dead_letter", letter.Topic)
+//	}))
+func SetDeadLetter(dispatcher Dispatcher) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	deadLetterDispatch = dispatcher
+}
+
+// decodeFailed log and notify hooks about a remote message that could not be decoded
+func decodeFailed(topic string, message []byte, config *AdapterConfig, err error, msg string, attrs ...any) {
+	adapter := config.Adapter.Name()
+	slog.Error(msg, append([]any{
+		slog.Any("Error", err),
+		slog.String("Topic", topic),
+		slog.String("Adapter", adapter),
+	}, attrs...)...)
+
+	hooksMutex.RLock()
+	handler := onDecodeError
+	hooksMutex.RUnlock()
+
+	if handler != nil {
+		safeHook(func() { handler(topic, message, adapter, err) })
+	}
+
+	sendToDeadLetter(&DeadLetter{
+		Topic:   topic,
+		Message: message,
+		Adapter: adapter,
+		Error:   err,
+	})
+}
+
+// dispatchSafe deliver the message to the dispatcher, recovering from panics. Returns the error of the AckDispatcher
+// or ErrDispatcherPanic.
+func dispatchSafe(dispatcher Dispatcher, topic string, message any, from string) (err error) {
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			err = errors.Join(ErrDispatcherPanic, fmt.Errorf("%v", rcv))
+			slog.Error(
+				"[chain.pubsub] panic occurred in a dispatcher",
+				slog.Any("Error", err),
+				slog.String("Topic", topic),
+			)
+
+			hooksMutex.RLock()
+			handler := onDispatchError
+			hooksMutex.RUnlock()
+
+			if handler != nil {
+				safeHook(func() { handler(topic, message, from, dispatcher, err) })
+			}
+
+			sendToDeadLetter(&DeadLetter{
+				Topic:      topic,
+				Message:    message,
+				From:       from,
+				Dispatcher: dispatcher,
+				Error:      err,
+			})
+		}
+	}()
+	if acked, ok := dispatcher.(AckDispatcher); ok {
+		return acked.DispatchAck(topic, message, from)
+	}
+	dispatcher.Dispatch(topic, message, from)
+	return nil
+}
+
+func sendToDeadLetter(letter *DeadLetter) {
+	hooksMutex.RLock()
+	dispatcher := deadLetterDispatch
+	hooksMutex.RUnlock()
+
+	if dispatcher != nil {
+		safeHook(func() { dispatcher.Dispatch(letter.Topic, letter, letter.From) })
+	}
+}
+
+// safeHook prevents a faulty hook from stopping the delivery
+func safeHook(hook func()) {
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			slog.Warn("[chain.pubsub] panic occurred in a hook", slog.Any("panic", rcv))
+		}
+	}()
+	hook()
+}
@@ -0,0 +1,90 @@
+package pubsub
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_PubSub_Hooks_Dispatch_Error(t *testing.T) {
+	topic := "user:123"
+	message := []byte("Message 1")
+
+	testClearPubsub()
+
+	hookErrs := make(chan error, 1)
+	OnDispatchError(func(topic string, message any, from string, dispatcher Dispatcher, err error) {
+		hookErrs <- err
+	})
+	defer OnDispatchError(nil)
+
+	deadLetters := &testDispatcherStruct{}
+	SetDeadLetter(deadLetters)
+	defer SetDeadLetter(nil)
+
+	faulty := DispatcherFunc(func(topic string, message any, from string) {
+		panic("oops!")
+	})
+	Subscribe(topic, faulty)
+	defer Unsubscribe(topic, faulty)
+
+	if err := Broadcast(topic, message); err != nil {
+		t.Fatal(err)
+	}
+	<-time.After(time.Millisecond * 10)
+
+	var hookErr error
+	select {
+	case hookErr = <-hookErrs:
+	case <-time.After(time.Millisecond * 100):
+	}
+	if !errors.Is(hookErr, ErrDispatcherPanic) {
+		t.Errorf("Invalid hook error\n   actual: %v\n expected: %v", hookErr, ErrDispatcherPanic)
+	}
+
+	received := deadLetters.pop()
+	if received == nil {
+		t.Fatal("dead letter did not receive the message")
+	}
+	letter := received.message.(*DeadLetter)
+	if letter.Topic != topic || letter.From != Self() || letter.Dispatcher != faulty {
+		t.Errorf("Invalid dead letter\n   actual: %v", letter)
+	}
+}
+
+func Test_PubSub_Hooks_Decode_Error(t *testing.T) {
+	topic := "user:123"
+	message := []byte{byte(messageTypeBroadcast), 1, 2, 3}
+
+	testClearPubsub()
+
+	var hookErr error
+	var hookAdapter string
+	OnDecodeError(func(topic string, message []byte, adapter string, err error) {
+		hookErr = err
+		hookAdapter = adapter
+	})
+	defer OnDecodeError(nil)
+
+	deadLetters := &testDispatcherStruct{}
+	SetDeadLetter(deadLetters)
+	defer SetDeadLetter(nil)
+
+	Dispatch(topic, message)
+
+	if hookErr != ErrMessageNotEncrypted {
+		t.Errorf("Invalid hook error\n   actual: %v\n expected: %v", hookErr, ErrMessageNotEncrypted)
+	}
+	if hookAdapter != testAdapter.Name() {
+		t.Errorf("Invalid hook adapter\n   actual: %v\n expected: %v", hookAdapter, testAdapter.Name())
+	}
+
+	received := deadLetters.pop()
+	if received == nil {
+		t.Fatal("dead letter did not receive the message")
+	}
+	letter := received.message.(*DeadLetter)
+	if letter.Topic != topic || letter.Error != ErrMessageNotEncrypted {
+		t.Errorf("Invalid dead letter\n   actual: %v", letter)
+	}
+}
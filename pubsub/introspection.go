@@ -0,0 +1,99 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// TopicStats information about a topic this node is subscribed to, see Subscriptions
+type TopicStats struct {
+	Topic              string    `json:"topic"`
+	Adapter            string    `json:"adapter"`
+	Dispatchers        int       `json:"dispatchers"`         // Number of distinct dispatchers subscribed
+	Subscriptions      int       `json:"subscriptions"`       // Number of subscriptions (a dispatcher can subscribe more than once)
+	Received           uint64    `json:"received"`            // Messages dispatched to the local dispatchers
+	Sent               uint64    `json:"sent"`                // Messages broadcast by this node
+	Expired            uint64    `json:"expired"`             // Remote messages dropped because they expired (see TTL)
+	Since              time.Time `json:"since,omitempty"`     // When the topic was subscribed
+	PendingUnsubscribe bool      `json:"pending_unsubscribe"` // No dispatchers left, the adapter unsubscribes after the grace period
+}
+
+// Subscriptions returns the topics this node is subscribed to, ordered by topic. Topics without dispatchers that are
+// waiting for the grace period (see SetUnsubscribeGracePeriod) are included with PendingUnsubscribe.
+//
+// Counters are kept while the topic has dispatchers, they are reset when the topic is subscribed again.
+func Subscriptions() []TopicStats {
+	var list []TopicStats
+
+	p.subscriptionsMutex.RLock()
+	for topic, sub := range p.subscriptions {
+		stats := TopicStats{
+			Topic:       topic,
+			Dispatchers: len(sub.dispatchers),
+			Received:    sub.received.Load(),
+			Sent:        sub.sent.Load(),
+			Expired:     sub.expired.Load(),
+			Since:       sub.since,
+		}
+		for _, count := range sub.dispatchers {
+			stats.Subscriptions += count
+		}
+		list = append(list, stats)
+	}
+	p.subscriptionsMutex.RUnlock()
+
+	p.unsubscribeMutex.Lock()
+	for topic := range p.unsubscribeTimers {
+		list = append(list, TopicStats{Topic: topic, PendingUnsubscribe: true})
+	}
+	p.unsubscribeMutex.Unlock()
+
+	for i := range list {
+		if config := GetAdapter(list[i].Topic); config != nil {
+			list[i].Adapter = config.Adapter.Name()
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Topic < list[j].Topic
+	})
+	return list
+}
+
+// DebugHandler renders the Subscriptions of this node as JSON. It exposes the topics, so it must be protected.
+//
+// ## Example
+//
+//	admin := router.Group("/admin")
+//	admin.Use(authMiddleware)
+//	admin.GET("/debug/pubsub", pubsub.DebugHandler())
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(struct {
+			Node   string       `json:"node"`
+			Topics []TopicStats `json:"topics"`
+		}{Self(), Subscriptions()})
+	})
+}
+
+// countSent increments the sent counter of the topic, if subscribed
+func countSent(topic string) {
+	p.subscriptionsMutex.RLock()
+	defer p.subscriptionsMutex.RUnlock()
+	if sub, exist := p.subscriptions[topic]; exist {
+		sub.sent.Add(1)
+	}
+}
+
+// countExpired increments the expired counter of the topic, if subscribed
+func countExpired(topic string) {
+	p.subscriptionsMutex.RLock()
+	defer p.subscriptionsMutex.RUnlock()
+	if sub, exist := p.subscriptions[topic]; exist {
+		sub.expired.Add(1)
+	}
+}
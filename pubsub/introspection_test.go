@@ -0,0 +1,61 @@
+package pubsub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_PubSub_Subscriptions(t *testing.T) {
+	testClearPubsub()
+	defer SetUnsubscribeGracePeriod(time.Second * 15)
+	SetUnsubscribeGracePeriod(time.Minute)
+
+	first := &testDispatcherStruct{}
+	second := &testDispatcherStruct{}
+	Subscribe("stats:a", first)
+	Subscribe("stats:a", first)
+	Subscribe("stats:a", second)
+	Subscribe("stats:b", first)
+	defer UnsubscribeNow("stats:a", first)
+	defer UnsubscribeNow("stats:a", second)
+
+	if err := Broadcast("stats:a", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	<-time.After(time.Millisecond * 10)
+
+	Unsubscribe("stats:b", first)
+	<-time.After(time.Millisecond * 10)
+	defer unsubscribeAdapter("stats:b")
+
+	var stats []TopicStats
+	for _, topic := range Subscriptions() {
+		if strings.HasPrefix(topic.Topic, "stats:") {
+			stats = append(stats, topic)
+		}
+	}
+	if len(stats) != 2 {
+		t.Fatalf("Subscriptions failed: Invalid Topics\n   actual: %v\n expected: %v", len(stats), 2)
+	}
+	a, b := stats[0], stats[1]
+	if a.Topic != "stats:a" || a.Dispatchers != 2 || a.Subscriptions != 3 || a.Sent != 1 || a.Received != 1 || a.PendingUnsubscribe {
+		t.Errorf("Subscriptions failed: Invalid Stats\n   actual: %+v", a)
+	}
+	if b.Topic != "stats:b" || b.Dispatchers != 0 || !b.PendingUnsubscribe || b.Adapter != testAdapter.Name() {
+		t.Errorf("Subscriptions failed: Invalid Pending Stats\n   actual: %+v", b)
+	}
+
+	w := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	var body struct {
+		Node   string       `json:"node"`
+		Topics []TopicStats `json:"topics"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Node != Self() || len(body.Topics) < 2 {
+		t.Errorf("DebugHandler failed: Invalid Body\n   actual: %v", w.Body.String())
+	}
+}
@@ -20,3 +20,8 @@ const (
 	nackRespMsg
 	errMsg
 )
+
+// messageTypeEnvelope identifies a message wrapped in a versioned envelope. Nodes that predate the envelope reject this
+// type as an unknown message type: they do not misinterpret messages from newer nodes, but drop them and log a decode
+// error for each one (see EnvelopeLegacy for the upgrade of these clusters).
+const messageTypeEnvelope messageType = 0xFF
@@ -1,5 +1,10 @@
 package pubsub
 
+import "time"
+
+// OptionTTL key of the TTL option, see TTL
+const OptionTTL = "ttl"
+
 var (
 	globalOptions = map[string]any{}
 )
@@ -29,3 +34,23 @@ func SetGlobalOptions(options ...*Option) {
 		globalOptions[key] = value
 	}
 }
+
+// TTL the message expires after the ttl, expired messages are dropped by the nodes that receive them (see
+// TopicStats.Expired). Avoids that slow consumers and replayed backlogs process stale real-time data, like typing
+// indicators. The expiration is recorded in the envelope (EnvelopeV3 or later), the option is also passed to the
+// adapter, allowing brokers to discard the message.
+//
+// ## Example
+//
+//	pubsub.Broadcast("room:123:typing", message, pubsub.TTL(3*time.Second))
+func TTL(ttl time.Duration) *Option {
+	return O(OptionTTL, ttl)
+}
+
+// expiresAt gets the expiration time (unix milliseconds) of the message, zero if it does not expire. See TTL
+func expiresAt(opts map[string]any) int64 {
+	if ttl, ok := opts[OptionTTL].(time.Duration); ok && ttl > 0 {
+		return time.Now().Add(ttl).UnixMilli()
+	}
+	return 0
+}
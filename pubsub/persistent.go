@@ -0,0 +1,109 @@
+package pubsub
+
+import (
+	"log/slog"
+	"os"
+)
+
+// PersistentAdapter Adapter of the brokers with persistence (ex. Redis Streams, Kafka, NATS JetStream), allowing the
+// at-least-once delivery of the topics that must not lose messages (see AdapterConfig.AtLeastOnce).
+//
+// The broker tracks the offset of each consumer (node, see AdapterConfig.Consumer) on the topics. The adapter delivers
+// the messages with DispatchPersistent, which acknowledges the message (Ack) after the local dispatchers process it or
+// requests the redelivery (Nack) when a dispatcher fails. Messages not acknowledged (ex. the node crashed) must be
+// redelivered by the broker, so the dispatchers must be idempotent.
+type PersistentAdapter interface {
+	Adapter
+
+	// SubscribePersistent subscribes the consumer to the topic, resuming from the last message acknowledged by it
+	SubscribePersistent(topic string, consumer string)
+
+	// Ack confirms the processing of the message by the consumer, advancing its offset on the topic
+	Ack(topic string, consumer string, id string) error
+
+	// Nack informs that the consumer failed to process the message, the broker must redeliver it
+	Nack(topic string, consumer string, id string) error
+}
+
+// AckDispatcher optional interface of the Dispatcher, reports the failure to process a message. On topics with
+// at-least-once delivery, the error causes the redelivery of the message (see PersistentAdapter).
+type AckDispatcher interface {
+	Dispatcher
+	DispatchAck(topic string, message any, from string) error
+}
+
+// DispatchPersistent used by the PersistentAdapter, same as Dispatch, but the message is processed synchronously and
+// acknowledged (or not) on the broker. `id` is the id of the message on the broker.
+//
+// Messages that cannot be decoded are acknowledged, they would never be processed (see SetDeadLetter). Fragments (see
+// AdapterConfig.Fragmentation) are acknowledged as they are received, the message is processed with the last one.
+func DispatchPersistent(topic string, id string, message []byte) {
+	config := GetAdapter(topic)
+	if config == nil {
+		return
+	}
+	adapter, ok := config.Adapter.(PersistentAdapter)
+	if !ok || !config.AtLeastOnce {
+		Dispatch(topic, message)
+		return
+	}
+
+	var err error
+	if decodedTopic, payload, from, decoded := decodeRemote(config, topic, message); decoded {
+		err = dispatchMessageSync(decodedTopic, payload, from)
+	}
+
+	if err != nil {
+		slog.Warn(
+			"[chain.pubsub] persistent message not processed, requesting redelivery",
+			slog.Any("Error", err),
+			slog.String("Topic", topic),
+			slog.String("Id", id),
+		)
+		err = adapter.Nack(topic, config.Consumer, id)
+	} else {
+		err = adapter.Ack(topic, config.Consumer, id)
+	}
+	if err != nil {
+		slog.Error(
+			"[chain.pubsub] could not acknowledge persistent message",
+			slog.Any("Error", err),
+			slog.String("Topic", topic),
+			slog.String("Id", id),
+			slog.String("Adapter", adapter.Name()),
+		)
+	}
+}
+
+// dispatchMessageSync deliver the message locally, waiting for all dispatchers. Returns the first error
+func dispatchMessageSync(topic string, message any, from string) (err error) {
+	p.subscriptionsMutex.RLock()
+	sub, exist := p.subscriptions[topic]
+	if !exist {
+		p.subscriptionsMutex.RUnlock()
+		go scheduleUnsubscribe(topic)
+		return nil
+	}
+
+	sub.received.Add(1)
+	var dispatchers []Dispatcher
+	for dispatcher := range sub.dispatchers {
+		dispatchers = append(dispatchers, dispatcher)
+	}
+	p.subscriptionsMutex.RUnlock()
+
+	for _, dispatcher := range dispatchers {
+		if dispatchErr := dispatchSafe(dispatcher, topic, message, from); dispatchErr != nil && err == nil {
+			err = dispatchErr
+		}
+	}
+	return
+}
+
+// defaultConsumer name of the node on the brokers, see AdapterConfig.Consumer
+func defaultConsumer() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return selfIdString
+}
@@ -0,0 +1,119 @@
+package pubsub
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testPersistentAdapter struct {
+	testAdapterStruct
+	consumers map[string]string
+	acks      []string
+}
+
+func (a *testPersistentAdapter) SubscribePersistent(topic string, consumer string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.consumers[topic] = consumer
+}
+
+func (a *testPersistentAdapter) Ack(topic string, consumer string, id string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.acks = append(a.acks, "ack:"+consumer+":"+id)
+	return nil
+}
+
+func (a *testPersistentAdapter) Nack(topic string, consumer string, id string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.acks = append(a.acks, "nack:"+consumer+":"+id)
+	return nil
+}
+
+type testAckDispatcher struct {
+	failures int
+	received int
+	mutex    sync.Mutex
+}
+
+func (d *testAckDispatcher) Dispatch(topic string, message any, from string) {
+	_ = d.DispatchAck(topic, message, from)
+}
+
+func (d *testAckDispatcher) DispatchAck(topic string, message any, from string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("database is down")
+	}
+	d.received++
+	return nil
+}
+
+func Test_PubSub_Persistent(t *testing.T) {
+	topic := "orders:123"
+	message := []byte(`{"id":123}`)
+
+	testClearPubsub()
+	defer testClearPubsub()
+
+	adapter := &testPersistentAdapter{consumers: map[string]string{}}
+	adapter.clear()
+	SetAdapters([]AdapterConfig{{
+		Adapter:     adapter,
+		Topics:      []string{"*"},
+		AtLeastOnce: true,
+		Consumer:    "node-1",
+	}})
+
+	dispatcher := &testAckDispatcher{failures: 1}
+	Subscribe(topic, dispatcher)
+	defer UnsubscribeNow(topic, dispatcher)
+	<-time.After(time.Millisecond * 5)
+
+	adapter.mutex.Lock()
+	consumer := adapter.consumers[topic]
+	adapter.mutex.Unlock()
+	if consumer != "node-1" {
+		t.Errorf("SubscribePersistent failed: Invalid consumer\n   actual: %v\n expected: %v", consumer, "node-1")
+	}
+
+	testAsRemote(func() {
+		if err := Broadcast(topic, message); err != nil {
+			t.Fatal(err)
+		}
+	})
+	remoteMessage := adapter.pop()
+	if remoteMessage == nil {
+		t.Fatal("adapter did not receive the message")
+	}
+
+	// dispatcher fails, then the broker redelivers the message
+	DispatchPersistent(remoteMessage.topic, "1-0", remoteMessage.message)
+	DispatchPersistent(remoteMessage.topic, "1-0", remoteMessage.message)
+	// invalid messages are acknowledged
+	DispatchPersistent(remoteMessage.topic, "2-0", []byte{0})
+
+	expected := []string{"nack:node-1:1-0", "ack:node-1:1-0", "ack:node-1:2-0"}
+	if !reflect.DeepEqual(adapter.acks, expected) {
+		t.Errorf("DispatchPersistent failed: Invalid acks\n   actual: %v\n expected: %v", adapter.acks, expected)
+	}
+	if dispatcher.received != 1 {
+		t.Errorf("DispatchPersistent failed: Invalid received\n   actual: %v\n expected: %v", dispatcher.received, 1)
+	}
+}
+
+func Test_PubSub_Persistent_Requires_Adapter(t *testing.T) {
+	defer testClearPubsub()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("SetAdapters must panic when AtLeastOnce is used with a non persistent adapter")
+		}
+	}()
+	SetAdapters([]AdapterConfig{{Adapter: testAdapter, Topics: []string{"*"}, AtLeastOnce: true}})
+}
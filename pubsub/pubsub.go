@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nidorx/chain/pkg"
@@ -43,13 +44,18 @@ func DispatcherFunc(d func(topic string, message any, from string)) Dispatcher {
 // subscription represents the subscriptions that this server has. See pubsub.Subscribe
 type subscription struct {
 	dispatchers map[Dispatcher]int // incremental dispatcher subscriptions
+	since       time.Time
+	received    atomic.Uint64 // messages dispatched locally, see Subscriptions
+	sent        atomic.Uint64 // messages broadcast by this node
+	expired     atomic.Uint64 // remote messages dropped because they expired, see TTL
 }
 
 // pubsub Realtime Publisher/Subscriber service.
 type pubsub struct {
-	adapters           *pkg.WildcardStore[*AdapterConfig]
+	adapters           atomic.Pointer[pkg.WildcardStore[*AdapterConfig]]
 	subscriptions      map[string]*subscription
 	unsubscribeTimers  map[string]*time.Timer
+	unsubscribeGrace   time.Duration
 	unsubscribeMutex   sync.Mutex
 	subscriptionsMutex sync.RWMutex
 }
@@ -57,6 +63,7 @@ type pubsub struct {
 var p = &pubsub{
 	subscriptions:     map[string]*subscription{},
 	unsubscribeTimers: map[string]*time.Timer{},
+	unsubscribeGrace:  time.Second * 15,
 }
 
 // Self get node id
@@ -64,13 +71,19 @@ func Self() string {
 	return selfIdString
 }
 
-func Subscribe(topic string, dispatcher Dispatcher) {
+// Subscribe the dispatcher to the topic. Returns an error if the subscription is denied by the Authorizer (see
+// SetAuthorizer).
+func Subscribe(topic string, dispatcher Dispatcher) error {
+	if err := Authorize(topic, ActionSubscribe); err != nil {
+		return err
+	}
+
 	p.subscriptionsMutex.Lock()
 	defer p.subscriptionsMutex.Unlock()
 	var sub *subscription
 	var exist bool
 	if sub, exist = p.subscriptions[topic]; !exist {
-		sub = &subscription{dispatchers: map[Dispatcher]int{}}
+		sub = &subscription{dispatchers: map[Dispatcher]int{}, since: time.Now()}
 		p.subscriptions[topic] = sub
 		go trySubscribe(topic)
 	}
@@ -78,6 +91,7 @@ func Subscribe(topic string, dispatcher Dispatcher) {
 		sub.dispatchers[dispatcher] = 0
 	}
 	sub.dispatchers[dispatcher] = sub.dispatchers[dispatcher] + 1
+	return nil
 }
 
 // Unsubscribe the dispatchFunc from the pubsub adapter's topic.
@@ -95,18 +109,27 @@ func Unsubscribe(topic string, dispatcher Dispatcher) {
 	sub.dispatchers[dispatcher] = sub.dispatchers[dispatcher] - 1
 	if sub.dispatchers[dispatcher] < 1 {
 		delete(sub.dispatchers, dispatcher)
-		go scheduleUnsubscribe(topic)
+		if len(sub.dispatchers) == 0 {
+			// allows the next Subscribe to subscribe the adapter again
+			delete(p.subscriptions, topic)
+			go scheduleUnsubscribe(topic)
+		}
 	}
 }
 
 // Broadcast broadcasts message on given topic across the whole cluster.
 func Broadcast(topic string, message []byte, options ...*Option) (err error) {
+	if err = Authorize(topic, ActionBroadcast); err != nil {
+		return
+	}
+
 	var config *AdapterConfig
 	if config = GetAdapter(topic); config == nil {
 		return ErrNoAdapter
 	}
 
 	if config.Adapter.Name() == "dummy" {
+		countSent(topic)
 		dispatchMessage(topic, message, selfIdString)
 		return
 	}
@@ -124,34 +147,12 @@ func Broadcast(topic string, message []byte, options ...*Option) (err error) {
 	// [messageType: byte] [from: 20 bytes] [msgToSend: ...]
 	msgToSend = append(append([]byte{byte(messageTypeBroadcast)}, selfIdBytes...), msgToSend...)
 
-	// Check if we have compression enabled
-	if config.DisableCompression == false {
-		var compressed []byte
-		if compressed, err = compressPayload(msgToSend); err != nil {
-			slog.Warn(
-				"[chain.pubsub] failed to compress payload",
-				slog.Any("error", err),
-			)
-		} else if len(compressed) < len(msgToSend) {
-			// Only use compression if it reduced the size
-			msgToSend = compressed
-		}
-	}
-
-	// Check if we have encryption enabled
-	if config.DisableEncryption == false {
-		keyring := config.Keyring
-		if keyring == nil {
-			keyring = globalKeyring
-		}
-		var encrypted []byte
-		if encrypted, err = encryptPayload(keyring, msgToSend); err != nil {
-			return errors.Join(errors.New("encryption of message failed"), err)
-		}
-		msgToSend = encrypted
+	if msgToSend, err = encodePayload(config, msgToSend, expiresAt(opts)); err != nil {
+		return
 	}
 
-	if err = config.Adapter.Broadcast(topic, msgToSend, opts); err == nil {
+	if err = broadcastPayload(config, topic, msgToSend, opts); err == nil {
+		countSent(topic)
 		// local dispatch
 		dispatchMessage(topic, message, selfIdString)
 	}
@@ -160,6 +161,10 @@ func Broadcast(topic string, message []byte, options ...*Option) (err error) {
 
 // DirectBroadcast Broadcasts ServiceMsg on given topic to a given node.
 func DirectBroadcast(nodeId string, topic string, message []byte, options ...*Option) error {
+	if err := Authorize(topic, ActionBroadcast); err != nil {
+		return err
+	}
+
 	// [messageType: byte] [from: 20 bytes] [message: ...]
 
 	nodeIdK, err := ksuid.Parse(nodeId)
@@ -209,10 +214,28 @@ func broadcastMessage(msgType messageType, topic string, message []byte, options
 	buf.Write(message)
 	msgToSend := buf.Bytes()
 
+	if msgToSend, err = encodePayload(config, msgToSend, expiresAt(opts)); err != nil {
+		return
+	}
+
+	err = broadcastPayload(config, topic, msgToSend, opts)
+	return
+}
+
+// encodePayload compress, encrypt and wraps the message in the envelope, according to the adapter config
+func encodePayload(config *AdapterConfig, msgToSend []byte, expires int64) (encoded []byte, err error) {
+	version := GetEnvelopeVersion()
+
+	compression := config.Compression
+	if version < EnvelopeV2 {
+		// the algorithm is not recorded in older envelopes
+		compression = CompressionLZW
+	}
+
 	// Check if we have compression enabled
-	if config.DisableCompression == false {
+	if config.DisableCompression == false && len(msgToSend) >= config.CompressionMinSize {
 		var compressed []byte
-		if compressed, err = compressPayload(msgToSend); err != nil {
+		if compressed, err = compressPayload(compression, msgToSend); err != nil {
 			slog.Warn(
 				"[chain.pubsub] failed to compress payload",
 				slog.Any("error", err),
@@ -231,165 +254,183 @@ func broadcastMessage(msgType messageType, topic string, message []byte, options
 		}
 		var encrypted []byte
 		if encrypted, err = encryptPayload(keyring, msgToSend); err != nil {
-			return errors.Join(errors.New("encryption of message failed"), err)
+			return nil, errors.Join(errors.New("encryption of message failed"), err)
 		}
 		msgToSend = encrypted
 	}
 
-	err = config.Adapter.Broadcast(topic, msgToSend, opts)
-	return
+	return wrapEnvelope(version, compression, expires, msgToSend), nil
 }
 
 // Dispatch used by adapters, process and delivery messages coming from backend (redis, kafka, *MQ), decrypting and
 // decompressing if necessary.
 func Dispatch(topic string, message []byte) {
 	if config := GetAdapter(topic); config != nil {
-		// Read the message type
-		msgType := messageType(message[0])
-
-		// Check if the message is encrypted
-		if msgType == messageTypeEncrypt {
-			if config.DisableEncryption {
-				slog.Error(
-					"[chain.pubsub] remote message is encrypted and encryption is not configured",
-					slog.String("Topic", topic),
-					slog.String("Adapter", config.Adapter.Name()),
-				)
-				return
-			}
-
-			keyring := config.Keyring
-			if keyring == nil {
-				keyring = globalKeyring
-			}
-			plain, err := decryptPayload(keyring, message)
-			if err != nil {
-				slog.Error(
-					"[chain.pubsub] could not decrypt remote message",
-					slog.Any("Error", err),
-					slog.String("Topic", topic),
-					slog.String("Adapter", config.Adapter.Name()),
-				)
-				return
-			}
-
-			// Reset message type and buf
-			msgType = messageType(plain[0])
-			message = plain
-		} else if config.DisableEncryption == false {
-			slog.Error(
-				"[chain.pubsub] encryption is configured but remote message is not encrypted",
-				slog.String("Topic", topic),
-				slog.String("Adapter", config.Adapter.Name()),
-			)
-			return
+		if topic, message, from, ok := decodeRemote(config, topic, message); ok {
+			dispatchMessage(topic, message, from)
 		}
+	}
+}
 
-		// Check if we have a compressed message
-		if msgType == messageTypeCompress {
-			decompressed, err := decompressPayload(message)
-			if err != nil {
-				slog.Error(
-					"[chain.pubsub] could not decompress remote message",
-					slog.Any("Error", err),
-					slog.String("Topic", topic),
-					slog.String("Adapter", config.Adapter.Name()),
-				)
-				return
-			}
+// decodeRemote decrypts, decompresses and decodes the remote message received by the adapter. Returns the topic (of
+// the direct broadcasts), the payload and the sender node.
+func decodeRemote(config *AdapterConfig, topic string, message []byte) (string, []byte, string, bool) {
+	var err error
+	raw := message
 
-			// Reset message type and buf
-			msgType = messageType(decompressed[0])
-			message = decompressed
-		}
+	if len(message) == 0 {
+		decodeFailed(topic, raw, config, ErrInvalidRemoteMessage, "[chain.pubsub] invalid remote message length")
+		return "", nil, "", false
+	}
 
-		// [messageType: byte] [from: 20 bytes] [message: ...]
-		// [messageType: byte] [from: 20 bytes] [to: 20 bytes] [topicNameLen: uint] [topic: topicNameLen] [message: ...]
-		message = message[1:]
+	// Reassemble the fragmented messages, see AdapterConfig.Fragmentation
+	if messageType(message[0]) == messageTypeFragment {
+		var complete bool
+		if message, complete, err = reassembleFragment(topic, message); err != nil {
+			decodeFailed(topic, raw, config, err, "[chain.pubsub] invalid remote message fragment")
+			return "", nil, "", false
+		}
+		if !complete {
+			return "", nil, "", false
+		}
+		raw = message
+	}
 
-		if len(message) < 20 {
-			slog.Error(
-				"[chain.pubsub] invalid remote message length",
+	// Read the message type
+	msgType := messageType(message[0])
+	compression := CompressionLZW
+
+	// Check if the message is wrapped in a versioned envelope
+	if msgType == messageTypeEnvelope {
+		var version uint8
+		var expires int64
+		if version, compression, expires, message, err = unwrapEnvelope(message); err != nil {
+			decodeFailed(topic, raw, config, err, "[chain.pubsub] invalid remote message envelope")
+			return "", nil, "", false
+		}
+		if version > EnvelopeLatest {
+			// message sent by a node running a newer protocol version
+			slog.Debug(
+				"[chain.pubsub] ignoring remote message with unsupported envelope version",
+				slog.Int("Version", int(version)),
 				slog.String("Topic", topic),
 				slog.String("Adapter", config.Adapter.Name()),
 			)
-			return
+			return "", nil, "", false
 		}
-		fromBytes := message[:20]
-
-		fromID, err := ksuid.FromBytes(fromBytes)
-		if err != nil {
-			slog.Error(
-				"[chain.pubsub] invalid remote message from",
-				slog.Any("Error", err),
+		if expires > 0 && time.Now().UnixMilli() > expires {
+			// stale message (slow consumer or replayed backlog), see TTL
+			countExpired(topic)
+			slog.Debug(
+				"[chain.pubsub] ignoring expired remote message",
 				slog.String("Topic", topic),
 				slog.String("Adapter", config.Adapter.Name()),
 			)
-			return
+			return "", nil, "", false
 		}
-		from := fromID.String()
+		msgType = messageType(message[0])
+	}
 
-		// [message: ...]
-		// [to: 20 bytes] [topicNameLen: uint] [topic: topicNameLen] [message: ...]
-		message = message[20:]
+	// Check if the message is encrypted
+	if msgType == messageTypeEncrypt {
+		if config.DisableEncryption {
+			decodeFailed(topic, raw, config, ErrEncryptionNotConfigured, "[chain.pubsub] remote message is encrypted and encryption is not configured")
+			return "", nil, "", false
+		}
 
-		// Check if is a direct broadcast
-		if msgType == messageTypeDirectBroadcast {
-			if topic != directTopic {
-				slog.Error(
-					"[chain.pubsub] invalid topic for remote direct broadcast message",
-					slog.String("Topic", topic),
-					slog.String("Adapter", config.Adapter.Name()),
-					slog.String("Expected", directTopic),
-				)
-				return
-			}
+		keyring := config.Keyring
+		if keyring == nil {
+			keyring = globalKeyring
+		}
+		plain, err := decryptPayload(keyring, message)
+		if err != nil {
+			decodeFailed(topic, raw, config, err, "[chain.pubsub] could not decrypt remote message")
+			return "", nil, "", false
+		}
 
-			// [to: 20 bytes] [topicNameLen: uint] [topic: topicNameLen] [message: ...]
-			if len(message) < 25 {
-				slog.Error(
-					"[chain.pubsub] invalid remote direct broadcast length",
-					slog.String("Topic", topic),
-					slog.String("Adapter", config.Adapter.Name()),
-				)
-				return
-			}
+		// Reset message type and buf
+		msgType = messageType(plain[0])
+		message = plain
+	} else if config.DisableEncryption == false {
+		decodeFailed(topic, raw, config, ErrMessageNotEncrypted, "[chain.pubsub] encryption is configured but remote message is not encrypted")
+		return "", nil, "", false
+	}
 
-			toBytes := message[0:20]
-			message = message[20:]
+	// Check if we have a compressed message
+	if msgType == messageTypeCompress {
+		decompressed, err := decompressPayload(compression, message)
+		if err != nil {
+			decodeFailed(topic, raw, config, err, "[chain.pubsub] could not decompress remote message")
+			return "", nil, "", false
+		}
 
-			if !bytes.Equal(selfIdBytes, toBytes) {
-				slog.Error(
-					"[chain.pubsub] invalid remote direct broadcast destination",
-					slog.String("Adapter", config.Adapter.Name()),
-				)
-				return
-			}
+		// Reset message type and buf
+		msgType = messageType(decompressed[0])
+		message = decompressed
+	}
 
-			// [topicNameLen: uint] [topic: topicNameLen] [message: ...]
-			topicNameLen := int(binary.BigEndian.Uint32(message[0:4]))
-			message = message[4:]
+	// [messageType: byte] [from: 20 bytes] [message: ...]
+	// [messageType: byte] [from: 20 bytes] [to: 20 bytes] [topicNameLen: uint] [topic: topicNameLen] [message: ...]
+	message = message[1:]
 
-			if len(message) < topicNameLen {
-				slog.Error(
-					"[chain.pubsub] invalid remote direct broadcast length",
-					slog.String("Adapter", config.Adapter.Name()),
-				)
-				return
-			}
-			topic = string(message[:topicNameLen])
-			message = message[topicNameLen:]
-		} else if msgType != messageTypeBroadcast {
-			slog.Error(
-				"[chain.pubsub] invalid remote message type",
-				slog.String("Topic", topic),
-				slog.String("Adapter", config.Adapter.Name()),
+	if len(message) < 20 {
+		decodeFailed(topic, raw, config, ErrInvalidRemoteMessage, "[chain.pubsub] invalid remote message length")
+		return "", nil, "", false
+	}
+	fromBytes := message[:20]
+
+	fromID, err := ksuid.FromBytes(fromBytes)
+	if err != nil {
+		decodeFailed(topic, raw, config, err, "[chain.pubsub] invalid remote message from")
+		return "", nil, "", false
+	}
+	from := fromID.String()
+
+	// [message: ...]
+	// [to: 20 bytes] [topicNameLen: uint] [topic: topicNameLen] [message: ...]
+	message = message[20:]
+
+	// Check if is a direct broadcast
+	if msgType == messageTypeDirectBroadcast {
+		if topic != directTopic {
+			decodeFailed(
+				topic, raw, config, ErrInvalidRemoteMessage,
+				"[chain.pubsub] invalid topic for remote direct broadcast message",
+				slog.String("Expected", directTopic),
 			)
-			return
+			return "", nil, "", false
+		}
+
+		// [to: 20 bytes] [topicNameLen: uint] [topic: topicNameLen] [message: ...]
+		if len(message) < 25 {
+			decodeFailed(topic, raw, config, ErrInvalidRemoteMessage, "[chain.pubsub] invalid remote direct broadcast length")
+			return "", nil, "", false
+		}
+
+		toBytes := message[0:20]
+		message = message[20:]
+
+		if !bytes.Equal(selfIdBytes, toBytes) {
+			decodeFailed(topic, raw, config, ErrInvalidRemoteMessage, "[chain.pubsub] invalid remote direct broadcast destination")
+			return "", nil, "", false
 		}
 
-		dispatchMessage(topic, message, from)
+		// [topicNameLen: uint] [topic: topicNameLen] [message: ...]
+		topicNameLen := int(binary.BigEndian.Uint32(message[0:4]))
+		message = message[4:]
+
+		if len(message) < topicNameLen {
+			decodeFailed(topic, raw, config, ErrInvalidRemoteMessage, "[chain.pubsub] invalid remote direct broadcast length")
+			return "", nil, "", false
+		}
+		topic = string(message[:topicNameLen])
+		message = message[topicNameLen:]
+	} else if msgType != messageTypeBroadcast {
+		decodeFailed(topic, raw, config, ErrInvalidRemoteMessage, "[chain.pubsub] invalid remote message type")
+		return "", nil, "", false
 	}
+
+	return topic, message, from, true
 }
 
 // LocalBroadcast broadcasts message on given topic only for the current node.
@@ -397,6 +438,10 @@ func Dispatch(topic string, message []byte) {
 // `topic` - The topic to broadcast to, ie: `"users:123"`
 // `message` - The payload of the broadcast
 func LocalBroadcast(topic string, message any) {
+	if err := Authorize(topic, ActionBroadcast); err != nil {
+		slog.Warn("[chain.pubsub] local broadcast not authorized", slog.String("Topic", topic), slog.Any("Error", err))
+		return
+	}
 	dispatchMessage(topic, message, selfIdString)
 }
 
@@ -418,26 +463,46 @@ func SetAdapters(adapters []AdapterConfig) {
 	}
 	defer trySubscribe(directTopic)
 
-	p.adapters = &pkg.WildcardStore[*AdapterConfig]{}
+	store := &pkg.WildcardStore[*AdapterConfig]{}
 	for _, config := range adapters {
+		config := config
+		if config.AtLeastOnce {
+			if _, ok := config.Adapter.(PersistentAdapter); !ok {
+				panic(fmt.Sprintf("[chain.pubsub] at-least-once delivery requires a PersistentAdapter. Adapter: %s", config.Adapter.Name()))
+			}
+			if config.Consumer == "" {
+				config.Consumer = defaultConsumer()
+			}
+		}
 		for _, topic := range config.Topics {
-			if err := p.adapters.Insert(topic, &config); err != nil {
+			if err := store.Insert(topic, &config); err != nil {
 				panic(fmt.Sprintf("[chain.pubsub] invalid adapter config. Topic: %s, Error: %s", topic, err.Error()))
 			}
 		}
 	}
+	p.adapters.Store(store)
 }
 
 // GetAdapter Gets the adapter associated with a topic.
 func GetAdapter(topic string) *AdapterConfig {
-	if p.adapters == nil {
+	store := p.adapters.Load()
+	if store == nil {
 		return nil
 	}
-	return p.adapters.Match(topic)
+	return store.Match(topic)
 }
 
 // trySubscribe subscribe the adapter on the given topic
 func trySubscribe(topic string) {
+	if topic != directTopic {
+		p.subscriptionsMutex.RLock()
+		defer p.subscriptionsMutex.RUnlock()
+		if _, exist := p.subscriptions[topic]; !exist {
+			// unsubscribed before this goroutine was executed
+			return
+		}
+	}
+
 	p.unsubscribeMutex.Lock()
 	defer p.unsubscribeMutex.Unlock()
 	if timer, exist := p.unsubscribeTimers[topic]; exist {
@@ -446,33 +511,135 @@ func trySubscribe(topic string) {
 	}
 
 	if config := GetAdapter(topic); config != nil {
-		config.Adapter.Subscribe(topic)
+		if adapter, ok := config.Adapter.(PersistentAdapter); ok && config.AtLeastOnce {
+			adapter.SubscribePersistent(topic, config.Consumer)
+		} else {
+			config.Adapter.Subscribe(topic)
+		}
 	}
 }
 
-// scheduleUnsubscribe unsubscribe the adapter after 15 seconds
-func scheduleUnsubscribe(topic string) {
+// SetUnsubscribeGracePeriod set the global time that pubsub waits, after the last local Dispatcher is removed from a
+// topic, before unsubscribing the adapter from that topic. Defaults to 15 seconds.
+//
+// The grace period avoids unnecessary subscribe/unsubscribe traffic on the adapter when dispatchers are quickly
+// replaced (ex. client reconnection). A value less than or equal to zero unsubscribes immediately.
+//
+// Can be overridden per adapter by AdapterConfig.UnsubscribeGracePeriod
+func SetUnsubscribeGracePeriod(grace time.Duration) {
 	p.unsubscribeMutex.Lock()
-	if _, exist := p.unsubscribeTimers[topic]; exist {
-		p.unsubscribeMutex.Unlock()
+	defer p.unsubscribeMutex.Unlock()
+	p.unsubscribeGrace = grace
+}
+
+// UnsubscribeNow removes the dispatcher from the topic and, if there are no more dispatchers, unsubscribes the adapter
+// immediately, ignoring the grace period. Useful for tests and shutdown.
+func UnsubscribeNow(topic string, dispatcher Dispatcher) {
+	p.subscriptionsMutex.Lock()
+	defer p.subscriptionsMutex.Unlock()
+	var sub *subscription
+	var exist bool
+	if sub, exist = p.subscriptions[topic]; !exist {
 		return
 	}
+	delete(sub.dispatchers, dispatcher)
+	if len(sub.dispatchers) == 0 {
+		delete(p.subscriptions, topic)
+		unsubscribeAdapter(topic)
+	}
+}
 
-	timer := time.NewTimer(time.Second * 15)
-	p.unsubscribeTimers[topic] = timer
+// Shutdown removes all local subscriptions, cancels the scheduled unsubscriptions and unsubscribes the adapters from
+// all topics.
+func Shutdown() {
+	p.subscriptionsMutex.Lock()
+	var topics []string
+	for topic := range p.subscriptions {
+		topics = append(topics, topic)
+	}
+	p.subscriptions = map[string]*subscription{}
+	p.subscriptionsMutex.Unlock()
+
+	p.unsubscribeMutex.Lock()
+	for topic, timer := range p.unsubscribeTimers {
+		timer.Stop()
+		topics = append(topics, topic)
+	}
+	p.unsubscribeTimers = map[string]*time.Timer{}
 	p.unsubscribeMutex.Unlock()
 
-	// wait
-	<-timer.C
+	for _, topic := range append(topics, directTopic) {
+		if config := GetAdapter(topic); config != nil {
+			config.Adapter.Unsubscribe(topic)
+		}
+	}
+}
+
+// scheduleUnsubscribe unsubscribe the adapter after the grace period. See SetUnsubscribeGracePeriod
+func scheduleUnsubscribe(topic string) {
+	config := GetAdapter(topic)
+	if config == nil {
+		return
+	}
+
+	// the lock order is subscriptionsMutex -> unsubscribeMutex (see UnsubscribeNow)
+	p.subscriptionsMutex.RLock()
+	defer p.subscriptionsMutex.RUnlock()
+	if _, exist := p.subscriptions[topic]; exist {
+		// subscribed again before this goroutine was executed
+		return
+	}
 
 	p.unsubscribeMutex.Lock()
 	defer p.unsubscribeMutex.Unlock()
 
-	if _, exist := p.unsubscribeTimers[topic]; !exist {
-		// was removed by pubsub.trySubscribe
+	if _, exist := p.unsubscribeTimers[topic]; exist {
 		return
 	}
-	delete(p.unsubscribeTimers, topic)
+
+	grace := p.unsubscribeGrace
+	if config.UnsubscribeGracePeriod != 0 {
+		grace = config.UnsubscribeGracePeriod
+	}
+
+	if grace <= 0 {
+		config.Adapter.Unsubscribe(topic)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(grace, func() {
+		p.subscriptionsMutex.RLock()
+		defer p.subscriptionsMutex.RUnlock()
+		p.unsubscribeMutex.Lock()
+		defer p.unsubscribeMutex.Unlock()
+
+		if current, exist := p.unsubscribeTimers[topic]; !exist || current != timer {
+			// was removed by pubsub.trySubscribe
+			return
+		}
+		delete(p.unsubscribeTimers, topic)
+
+		if _, exist := p.subscriptions[topic]; exist {
+			// subscribed again, the pending pubsub.trySubscribe keeps the adapter subscription
+			return
+		}
+
+		if config := GetAdapter(topic); config != nil {
+			config.Adapter.Unsubscribe(topic)
+		}
+	})
+	p.unsubscribeTimers[topic] = timer
+}
+
+// unsubscribeAdapter cancels the scheduled unsubscription and unsubscribe the adapter immediately
+func unsubscribeAdapter(topic string) {
+	p.unsubscribeMutex.Lock()
+	defer p.unsubscribeMutex.Unlock()
+	if timer, exist := p.unsubscribeTimers[topic]; exist {
+		delete(p.unsubscribeTimers, topic)
+		timer.Stop()
+	}
 
 	if config := GetAdapter(topic); config != nil {
 		config.Adapter.Unsubscribe(topic)
@@ -497,6 +664,7 @@ func dispatchMessage(topic string, message any, from string) {
 			return
 		}
 
+		sub.received.Add(1)
 		var dispatchers []Dispatcher
 		for dispatchFunc, _ := range sub.dispatchers {
 			dispatchers = append(dispatchers, dispatchFunc)
@@ -504,7 +672,7 @@ func dispatchMessage(topic string, message any, from string) {
 		p.subscriptionsMutex.RUnlock()
 
 		for _, dispatcher := range dispatchers {
-			dispatcher.Dispatch(topic, message, from)
+			dispatchSafe(dispatcher, topic, message, from)
 		}
 	}()
 }
@@ -154,8 +154,16 @@ func testAsRemote(fn func()) {
 }
 
 func testClearPubsub() {
+	p.subscriptionsMutex.Lock()
 	p.subscriptions = map[string]*subscription{}
+	p.subscriptionsMutex.Unlock()
+
+	p.unsubscribeMutex.Lock()
+	for _, timer := range p.unsubscribeTimers {
+		timer.Stop()
+	}
 	p.unsubscribeTimers = map[string]*time.Timer{}
+	p.unsubscribeMutex.Unlock()
 
 	SetAdapters([]AdapterConfig{{
 		Adapter:            testAdapter,
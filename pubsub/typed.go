@@ -0,0 +1,136 @@
+package pubsub
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/nidorx/chain"
+)
+
+const DefaultCodec = "json"
+
+var (
+	codecs          = map[string]chain.Serializer{DefaultCodec: &chain.JsonSerializer{}}
+	codecsMutex     sync.RWMutex
+	ErrCodecMissing = errors.New("no codec registered with the given name")
+)
+
+// RegisterCodec register a codec that can be used by Typed topics. Replaces any codec previously registered with
+// the same name.
+//
+// ## Example
+//
+//	pubsub.RegisterCodec("msgpack", &MsgpackSerializer{})
+func RegisterCodec(name string, codec chain.Serializer) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[name] = codec
+}
+
+// GetCodec get the codec registered with the given name
+func GetCodec(name string) chain.Serializer {
+	if name == "" {
+		name = DefaultCodec
+	}
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+	return codecs[name]
+}
+
+// Typed allows publishing and receiving typed messages on a topic, the encoding and decoding of values is done by the
+// codec registered with the name Typed.Codec (defaults to "json").
+//
+// ## Example
+//
+//	type UserCreated struct {
+//		Id   int
+//		Name string
+//	}
+//
+//	users := &pubsub.Typed[UserCreated]{Topic: "users:created"}
+//
+//	users.Subscribe(func(topic string, message UserCreated, from string) {
+//		println(message.Name)
+//	})
+//
+//	users.Broadcast(UserCreated{Id: 1, Name: "Alex"})
+type Typed[T any] struct {
+	Topic string // The topic to broadcast to and subscribe
+	Codec string // The name of the registered codec. Defaults to "json"
+}
+
+// Broadcast encode and broadcasts message on the Typed topic across the whole cluster.
+func (t *Typed[T]) Broadcast(message T, options ...*Option) error {
+	codec := GetCodec(t.Codec)
+	if codec == nil {
+		return ErrCodecMissing
+	}
+	encoded, err := codec.Encode(message)
+	if err != nil {
+		return err
+	}
+	return Broadcast(t.Topic, encoded, options...)
+}
+
+// Subscribe the handler on the Typed topic. Messages are decoded before being delivered to the handler.
+//
+// Returns the Dispatcher that was subscribed, which can be used to Unsubscribe.
+func (t *Typed[T]) Subscribe(handler func(topic string, message T, from string)) Dispatcher {
+	dispatcher := &typedDispatcher[T]{codec: t.Codec, handler: handler}
+	if err := Subscribe(t.Topic, dispatcher); err != nil {
+		slog.Warn("[chain.pubsub] subscription not authorized", slog.String("Topic", t.Topic), slog.Any("Error", err))
+	}
+	return dispatcher
+}
+
+// BroadcastT is a shortcut for (&Typed[T]{Topic: topic}).Broadcast(message, options...)
+func BroadcastT[T any](topic string, message T, options ...*Option) error {
+	return (&Typed[T]{Topic: topic}).Broadcast(message, options...)
+}
+
+// SubscribeT is a shortcut for (&Typed[T]{Topic: topic}).Subscribe(handler)
+func SubscribeT[T any](topic string, handler func(topic string, message T, from string)) Dispatcher {
+	return (&Typed[T]{Topic: topic}).Subscribe(handler)
+}
+
+// typedDispatcher decodes the messages received before delivering them to the handler
+type typedDispatcher[T any] struct {
+	codec   string
+	handler func(topic string, message T, from string)
+}
+
+func (d *typedDispatcher[T]) Dispatch(topic string, message any, from string) {
+	switch msg := message.(type) {
+	case T:
+		// LocalBroadcast with the value itself
+		d.handler(topic, msg, from)
+	case []byte:
+		codec := GetCodec(d.codec)
+		if codec == nil {
+			slog.Error(
+				"[chain.pubsub] could not decode typed message",
+				slog.Any("Error", ErrCodecMissing),
+				slog.String("Codec", d.codec),
+				slog.String("Topic", topic),
+			)
+			return
+		}
+		value := new(T)
+		if _, err := codec.Decode(msg, value); err != nil {
+			slog.Error(
+				"[chain.pubsub] could not decode typed message",
+				slog.Any("Error", err),
+				slog.String("Codec", d.codec),
+				slog.String("Topic", topic),
+			)
+			return
+		}
+		d.handler(topic, *value, from)
+	default:
+		slog.Error(
+			"[chain.pubsub] invalid typed message",
+			slog.String("Topic", topic),
+		)
+	}
+}
@@ -0,0 +1,61 @@
+package pubsub
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testTypedMessage struct {
+	Id   int
+	Name string
+	Tags []string
+}
+
+func Test_PubSub_Typed(t *testing.T) {
+
+	topic := "typed:123"
+	message := testTypedMessage{Id: 1, Name: "Reds", Tags: []string{"Crimson", "Red"}}
+
+	testClearPubsub()
+	testAdapter.clear()
+
+	var mutex sync.Mutex
+	var received []testTypedMessage
+	dispatcher := SubscribeT(topic, func(topic string, message testTypedMessage, from string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, message)
+	})
+	defer Unsubscribe(topic, dispatcher)
+
+	if err := BroadcastT(topic, message); err != nil {
+		t.Fatal(err)
+	}
+
+	// local dispatch of the value itself
+	LocalBroadcast(topic, message)
+
+	<-time.After(time.Millisecond * 10)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if len(received) != 2 {
+		t.Fatalf("Invalid number of messages\n   actual: %d\n expected: %d", len(received), 2)
+	}
+
+	for _, r := range received {
+		if !reflect.DeepEqual(r, message) {
+			t.Errorf("Invalid response\n   actual: %v\n expected: %v", r, message)
+		}
+	}
+}
+
+func Test_PubSub_Typed_Codec_Missing(t *testing.T) {
+	typed := &Typed[testTypedMessage]{Topic: "typed:123", Codec: "missing"}
+	if err := typed.Broadcast(testTypedMessage{}); err != ErrCodecMissing {
+		t.Errorf("Invalid error\n   actual: %v\n expected: %v", err, ErrCodecMissing)
+	}
+}
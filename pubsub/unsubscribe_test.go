@@ -0,0 +1,118 @@
+package pubsub
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_PubSub_Unsubscribe_Grace_Period(t *testing.T) {
+	topic := "user:123"
+
+	testClearPubsub()
+	testAdapter.clear()
+
+	SetAdapters([]AdapterConfig{{
+		Adapter:                testAdapter,
+		Topics:                 []string{"*"},
+		UnsubscribeGracePeriod: time.Millisecond * 20,
+	}})
+
+	dispatcher := &testDispatcherStruct{}
+	Subscribe(topic, dispatcher)
+	<-time.After(time.Millisecond * 5)
+	if !testAdapter.subscribed(topic) {
+		t.Fatal("adapter is not subscribed")
+	}
+
+	Unsubscribe(topic, dispatcher)
+	<-time.After(time.Millisecond * 5)
+	if !testAdapter.subscribed(topic) {
+		t.Fatal("adapter unsubscribed before the grace period")
+	}
+
+	<-time.After(time.Millisecond * 40)
+	if testAdapter.subscribed(topic) {
+		t.Fatal("adapter is still subscribed after the grace period")
+	}
+
+	// subscribe again during the grace period
+	Subscribe(topic, dispatcher)
+	Unsubscribe(topic, dispatcher)
+	<-time.After(time.Millisecond * 5)
+	Subscribe(topic, dispatcher)
+	<-time.After(time.Millisecond * 40)
+	if !testAdapter.subscribed(topic) {
+		t.Fatal("adapter must keep the subscription when subscribed again during the grace period")
+	}
+	UnsubscribeNow(topic, dispatcher)
+}
+
+func Test_PubSub_Unsubscribe_Subscribe_Again(t *testing.T) {
+	topic := "user:123"
+
+	testClearPubsub()
+	testAdapter.clear()
+
+	SetAdapters([]AdapterConfig{{
+		Adapter:                testAdapter,
+		Topics:                 []string{"*"},
+		UnsubscribeGracePeriod: -1, // immediately
+	}})
+	defer testClearPubsub()
+
+	dispatcher := &testDispatcherStruct{}
+	for i := 0; i < 50; i++ {
+		Subscribe(topic, dispatcher)
+		Unsubscribe(topic, dispatcher)
+		Subscribe(topic, dispatcher)
+		<-time.After(time.Millisecond * 2)
+		if !testAdapter.subscribed(topic) {
+			t.Fatal("adapter was unsubscribed while the topic has a local subscriber")
+		}
+		UnsubscribeNow(topic, dispatcher)
+	}
+}
+
+func Test_PubSub_Unsubscribe_Now(t *testing.T) {
+	topic := "user:123"
+
+	testClearPubsub()
+	testAdapter.clear()
+
+	dispatcher := &testDispatcherStruct{}
+	Subscribe(topic, dispatcher)
+	Subscribe(topic, dispatcher)
+	<-time.After(time.Millisecond * 5)
+	if !testAdapter.subscribed(topic) {
+		t.Fatal("adapter is not subscribed")
+	}
+
+	UnsubscribeNow(topic, dispatcher)
+	if testAdapter.subscribed(topic) {
+		t.Fatal("adapter is still subscribed")
+	}
+}
+
+func Test_PubSub_Shutdown(t *testing.T) {
+	testClearPubsub()
+	testAdapter.clear()
+
+	dispatcher := &testDispatcherStruct{}
+	Subscribe("user:1", dispatcher)
+	Subscribe("user:2", dispatcher)
+	<-time.After(time.Millisecond * 5)
+	Unsubscribe("user:2", dispatcher)
+	<-time.After(time.Millisecond * 5)
+
+	Shutdown()
+
+	if testAdapter.subscribed("user:1") || testAdapter.subscribed("user:2") || testAdapter.subscribed(directTopic) {
+		t.Fatal("adapter is still subscribed")
+	}
+
+	p.unsubscribeMutex.Lock()
+	defer p.unsubscribeMutex.Unlock()
+	if len(p.unsubscribeTimers) > 0 {
+		t.Fatal("scheduled unsubscriptions were not cancelled")
+	}
+}
@@ -0,0 +1,189 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// DefaultRequestIDHeader header used by Recovery to identify the request in logs and error responses
+const DefaultRequestIDHeader = "X-Request-Id"
+
+var defaultRecovery = &Recovery{}
+
+// PanicInfo details of a panic recovered from a http handler or middleware. It is also the error received by the
+// AfterSendInfo callbacks (see ResponseInfo) and by the 500 error page (see Router.ErrorPage).
+type PanicInfo struct {
+	Value     any       // The value passed to panic
+	Stack     []byte    // Stack trace of the goroutine that panicked
+	Method    string    // Request method
+	Path      string    // Request path
+	Route     string    // The route matched, empty when the panic happened before routing
+	RequestID string    // Value of the RequestIDKey or of the Recovery.RequestIDHeader header
+	Time      time.Time // When the panic was recovered
+}
+
+func newPanicInfo(req *http.Request, value any) *PanicInfo {
+	return &PanicInfo{
+		Value:  value,
+		Stack:  debug.Stack(),
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Time:   time.Now(),
+	}
+}
+
+func (p *PanicInfo) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap returns the value passed to panic when it is an error
+func (p *PanicInfo) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// Recovery handles panics recovered from http handlers and middlewares.
+//
+// The panic is logged via slog with the stack trace, route and request ID, the OnPanic hook is invoked (use it to
+// report to error trackers like Sentry) and a 500 response is sent to the client. In DevMode the response is a debug
+// HTML page with the stack trace, otherwise it is a "application/problem+json" (RFC 9457) without internal details.
+//
+// A panic with http.ErrAbortHandler is not recovered, it is re-panicked so the http.Server aborts the response silently.
+//
+// ## Example
+//
+//	router := chain.New()
+//	router.Recovery = &chain.Recovery{
+//		DevMode: os.Getenv("ENV") == "dev",
+//		OnPanic: func(ctx *chain.Context, info *chain.PanicInfo) {
+//			sentry.CaptureException(info)
+//		},
+//	}
+type Recovery struct {
+	DevMode         bool                                // Render a debug HTML page with the stack trace
+	Logger          *slog.Logger                        // Default slog.Default()
+	RequestIDHeader string                              // Default DefaultRequestIDHeader
+	OnPanic         func(ctx *Context, info *PanicInfo) // Hook invoked for each recovered panic
+	Render          func(ctx *Context, info *PanicInfo) // Custom error response, replaces the default rendering
+}
+
+// handle process the recovered panic. The ctx can be nil if the panic happened before the Context was created
+func (rc *Recovery) handle(ctx *Context, w *ResponseWriterSpy, req *http.Request, info *PanicInfo) {
+	header := rc.RequestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	if ctx != nil {
+		info.RequestID = RequestIDKey.Value(ctx)
+	}
+	if info.RequestID == "" {
+		info.RequestID = req.Header.Get(header)
+	}
+	if info.RequestID == "" {
+		info.RequestID = w.Header().Get(header)
+	}
+	if ctx != nil && ctx.Route != nil {
+		info.Route = ctx.Route.Path()
+	}
+
+	logger := rc.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Error(
+		"[chain] panic recovered",
+		slog.Any("Error", info.Value),
+		slog.String("Method", info.Method),
+		slog.String("Path", info.Path),
+		slog.String("Route", info.Route),
+		slog.String("RequestID", info.RequestID),
+		slog.String("Stack", string(info.Stack)),
+	)
+
+	if rc.OnPanic != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Warn("[chain] panic occurred in a recovery hook", slog.Any("panic", r))
+				}
+			}()
+			rc.OnPanic(ctx, info)
+		}()
+	}
+
+	if w.writeStarted {
+		// too late, the response status has already been sent
+		return
+	}
+
+	if rc.Render != nil && ctx != nil {
+		rc.Render(ctx, info)
+	} else if rc.DevMode {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = recoveryDebugPage.Execute(w, map[string]any{
+			"Info":  info,
+			"Value": fmt.Sprintf("%v", info.Value),
+			"Stack": string(info.Stack),
+		})
+	} else if !rc.renderErrorPage(ctx, info, logger) {
+		problem := map[string]any{
+			"type":     "about:blank",
+			"title":    http.StatusText(http.StatusInternalServerError),
+			"status":   http.StatusInternalServerError,
+			"instance": info.Path,
+		}
+		if info.RequestID != "" {
+			problem["request_id"] = info.RequestID
+		}
+		body, _ := json.Marshal(problem)
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write(body)
+	}
+}
+
+// renderErrorPage renders the 500 page of the router (see Router.ErrorPage), returns false if there is no page
+func (rc *Recovery) renderErrorPage(ctx *Context, info *PanicInfo, logger *slog.Logger) (rendered bool) {
+	if ctx == nil || ctx.router == nil {
+		return false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Warn("[chain] panic occurred in an error page", slog.Any("panic", r))
+			rendered = ctx.WriteStarted()
+		}
+	}()
+	return ctx.router.renderErrorPage(ctx, http.StatusInternalServerError, info)
+}
+
+var recoveryDebugPage = template.Must(template.New("recovery").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>panic: {{.Value}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { color: #c00; font-size: 1.4em; }
+table td { padding: 2px 12px 2px 0; }
+pre { background: #f5f5f5; padding: 1em; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>panic: {{.Value}}</h1>
+<table>
+<tr><td>Method</td><td>{{.Info.Method}}</td></tr>
+<tr><td>Path</td><td>{{.Info.Path}}</td></tr>
+<tr><td>Route</td><td>{{.Info.Route}}</td></tr>
+<tr><td>Request ID</td><td>{{.Info.RequestID}}</td></tr>
+<tr><td>Time</td><td>{{.Info.Time}}</td></tr>
+</table>
+<pre>{{.Stack}}</pre>
+</body>
+</html>
+`))
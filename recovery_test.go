@@ -0,0 +1,113 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Router_Recovery(t *testing.T) {
+	var logs bytes.Buffer
+	var hookInfo *PanicInfo
+
+	router := New()
+	router.Recovery = &Recovery{
+		Logger: slog.New(slog.NewJSONHandler(&logs, nil)),
+		OnPanic: func(ctx *Context, info *PanicInfo) {
+			hookInfo = info
+		},
+	}
+	router.GET("/user/:name", func(ctx *Context) error {
+		panic("oops!")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/user/gopher", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Recovery failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusInternalServerError)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Recovery failed: Invalid Content-Type\n   actual: %v\n expected: %v", ct, "application/problem+json")
+	}
+
+	var problem map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem["request_id"] != "req-123" || problem["status"] != float64(500) {
+		t.Errorf("Recovery failed: Invalid problem\n   actual: %v", problem)
+	}
+	if strings.Contains(w.Body.String(), "oops") {
+		t.Errorf("Recovery failed: panic value leaked in production response")
+	}
+
+	if hookInfo == nil {
+		t.Fatal("Recovery failed: OnPanic not invoked")
+	}
+	if hookInfo.Value != "oops!" || hookInfo.Route != "/user/:name" || hookInfo.RequestID != "req-123" {
+		t.Errorf("Recovery failed: Invalid PanicInfo\n   actual: %+v", hookInfo)
+	}
+	if !bytes.Contains(hookInfo.Stack, []byte("recovery_test.go")) {
+		t.Errorf("Recovery failed: stack trace not captured")
+	}
+
+	if !strings.Contains(logs.String(), `"RequestID":"req-123"`) {
+		t.Errorf("Recovery failed: Invalid log\n   actual: %v", logs.String())
+	}
+}
+
+func Test_Router_Recovery_DevMode(t *testing.T) {
+	router := New()
+	router.Recovery = &Recovery{
+		DevMode: true,
+		Logger:  slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+	}
+	router.GET("/", func(ctx *Context) error {
+		panic("<oops>")
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Recovery failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusInternalServerError)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "panic: &lt;oops&gt;") || !strings.Contains(body, "recovery_test.go") {
+		t.Errorf("Recovery failed: Invalid debug page\n   actual: %v", body)
+	}
+}
+
+func Test_Router_Recovery_ErrAbortHandler(t *testing.T) {
+	var logs bytes.Buffer
+	hookCalled := false
+
+	router := New()
+	router.Recovery = &Recovery{
+		Logger:  slog.New(slog.NewJSONHandler(&logs, nil)),
+		OnPanic: func(ctx *Context, info *PanicInfo) { hookCalled = true },
+	}
+	router.GET("/", func(ctx *Context) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	var rcv any
+	func() {
+		defer func() { rcv = recover() }()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	if rcv != any(http.ErrAbortHandler) {
+		t.Errorf("Recovery failed: Invalid panic\n   actual: %v\n expected: %v", rcv, http.ErrAbortHandler)
+	}
+	if hookCalled || logs.Len() > 0 {
+		t.Errorf("Recovery failed: http.ErrAbortHandler must not be handled\n   actual: %v", logs.String())
+	}
+}
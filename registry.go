@@ -2,24 +2,24 @@ package chain
 
 import (
 	"log/slog"
+	"sort"
 	"strings"
 )
 
 // Registry is an algorithm-independent framework for recording routes. This division allows us to explore different
 // algorithms without breaking the contract.
 type Registry struct {
-	canBeStatic [2048]bool
 	storage     *RouteStorage
 	routes      []*Route
 	middlewares []*Middleware
-	static      map[string]*Route
+	static      map[string]*Route // static routes, by path
+	staticFold  map[string]*Route // static routes, by lower case path (see findHandleCaseInsensitive)
 }
 
 func (r *Registry) findHandle(ctx *Context) *Route {
-	if r.canBeStatic[len(ctx.path)] {
-		if route, found := r.static[ctx.path]; found {
-			return route
-		}
+	// direct hit, static routes have no length limit
+	if route, found := r.static[ctx.path]; found {
+		return route
 	}
 
 	if r.storage == nil {
@@ -30,12 +30,11 @@ func (r *Registry) findHandle(ctx *Context) *Route {
 }
 
 func (r *Registry) findHandleCaseInsensitive(ctx *Context) *Route {
-	if r.canBeStatic[len(ctx.path)] {
-		for key, route := range r.static {
-			if strings.EqualFold(ctx.path, key) {
-				return route
-			}
-		}
+	if route, found := r.static[ctx.path]; found {
+		return route
+	}
+	if route, found := r.staticFold[strings.ToLower(ctx.path)]; found {
+		return route
 	}
 
 	if r.storage == nil {
@@ -45,7 +44,7 @@ func (r *Registry) findHandleCaseInsensitive(ctx *Context) *Route {
 	return r.storage.lookupCaseInsensitive(ctx)
 }
 
-func (r *Registry) addHandle(path string, handle Handle) {
+func (r *Registry) addHandle(path string, handle Handle, options []RouteOption) {
 	if r.routes == nil {
 		r.routes = []*Route{}
 	}
@@ -60,13 +59,25 @@ func (r *Registry) addHandle(path string, handle Handle) {
 		}
 	}
 
+	r.insert(r.createRoute(handle, details, options))
+}
+
+// insert adds the route to the lookup structures
+func (r *Registry) insert(route *Route) {
+	r.routes = append(r.routes, route)
+
+	details := route.Info
 	if !details.hasParameter && !details.hasWildcard {
 		if r.static == nil {
 			r.static = map[string]*Route{}
+			r.staticFold = map[string]*Route{}
 		}
 
-		r.canBeStatic[len(path)] = true
-		r.static[path] = r.createRoute(handle, details)
+		r.static[details.path] = route
+		if fold := strings.ToLower(details.path); r.staticFold[fold] == nil {
+			// the first route registered wins when paths differ only in case
+			r.staticFold[fold] = route
+		}
 		return
 	}
 
@@ -74,46 +85,79 @@ func (r *Registry) addHandle(path string, handle Handle) {
 		r.storage = &RouteStorage{}
 	}
 
-	r.storage.add(r.createRoute(handle, details))
+	r.storage.add(route)
+}
+
+// clone copy of the registry (and its routes), the copy is not changed by the next registrations
+func (r *Registry) clone() *Registry {
+	c := &Registry{
+		routes: make([]*Route, 0, len(r.routes)),
+	}
+	if r.middlewares != nil {
+		c.middlewares = append(make([]*Middleware, 0, len(r.middlewares)), r.middlewares...)
+	}
+	for _, route := range r.routes {
+		c.insert(route.clone())
+	}
+	return c
+}
+
+// sortedRoutes the routes in the order they are evaluated
+func (r *Registry) sortedRoutes() []*Route {
+	routes := make([]*Route, len(r.routes))
+	copy(routes, r.routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		iStatic := !routes[i].Info.hasParameter && !routes[i].Info.hasWildcard
+		jStatic := !routes[j].Info.hasParameter && !routes[j].Info.hasWildcard
+		if iStatic != jStatic {
+			return iStatic
+		}
+		return routes[i].Info.priority > routes[j].Info.priority
+	})
+	return routes
 }
 
-func (r *Registry) createRoute(handle Handle, info *RouteInfo) *Route {
+func (r *Registry) createRoute(handle Handle, info *RouteInfo, options []RouteOption) *Route {
 	route := &Route{
 		Handle:           handle,
 		Info:             info,
 		middlewaresAdded: map[*Middleware]bool{},
 	}
-
-	r.routes = append(r.routes, route)
+	for _, option := range options {
+		option(&route.Options)
+	}
+	if route.Options.Priority != 0 {
+		info.priority = route.Options.Priority
+	}
 
 	for _, middleware := range r.middlewares {
-		if route.middlewaresAdded[middleware] != true && middleware.Path.Matches(route.Info) {
-			route.middlewaresAdded[middleware] = true
-			route.Middlewares = append(route.Middlewares, middleware)
+		if middleware.Path.Matches(route.Info) {
+			route.addMiddleware(middleware)
 		}
 	}
 
 	return route
 }
 
-func (r *Registry) addMiddleware(path string, middlewares []func(ctx *Context, next func() error) error) {
+func (r *Registry) addMiddleware(path string, priority Priority, middlewares []func(ctx *Context, next func() error) error, sources []any) {
 	if r.middlewares == nil {
 		r.middlewares = []*Middleware{}
 	}
 
-	for _, middleware := range middlewares {
+	for i, middleware := range middlewares {
 		info := &Middleware{
-			Path:   ParseRouteInfo(path),
-			Handle: middleware,
+			Path:     ParseRouteInfo(path),
+			Handle:   middleware,
+			Priority: priority,
+			source:   sources[i],
 		}
 
 		r.middlewares = append(r.middlewares, info)
 
 		// add this MiddlewareFunc to all compatible routes
 		for _, route := range r.routes {
-			if route.middlewaresAdded[info] != true && info.Path.Matches(route.Info) {
-				route.middlewaresAdded[info] = true
-				route.Middlewares = append(route.Middlewares, info)
+			if info.Path.Matches(route.Info) {
+				route.addMiddleware(info)
 			}
 		}
 	}
@@ -0,0 +1,95 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_Registry_Static_Long_Path(t *testing.T) {
+	router := New()
+
+	long := "/" + strings.Repeat("a", 4096)
+	router.GET(long, fakeHandler("long"))
+	router.GET("/short", fakeHandler("short"))
+	router.GET("/user/:name", fakeHandler("user"))
+
+	requests := []tRequest{
+		{path: long, route: "long"},
+		{path: "/short", route: "short"},
+		{path: "/" + strings.Repeat("b", 4096), nilHandler: true},
+		{path: long + "/x", nilHandler: true},
+		{path: "/user/" + strings.Repeat("c", 4096), route: "user", params: map[string]string{"name": strings.Repeat("c", 4096)}},
+	}
+	for _, request := range requests {
+		checkRequests(t, router, request)
+	}
+}
+
+func Test_Registry_Static_Same_Length(t *testing.T) {
+	router := New()
+	router.GET("/abc", fakeHandler("abc"))
+
+	// a path with the same length of a static route must not match it
+	for _, request := range []tRequest{
+		{path: "/abc", route: "abc"},
+		{path: "/xyz", nilHandler: true},
+		{path: "/ab", nilHandler: true},
+	} {
+		checkRequests(t, router, request)
+	}
+}
+
+func Test_Registry_Static_CaseInsensitive(t *testing.T) {
+	router := New()
+	long := "/Docs/" + strings.Repeat("x", 3000)
+	router.GET(long, fakeHandler("long"))
+	router.GET("/Users/Profile", fakeHandler("profile"))
+
+	tests := []struct {
+		path     string
+		location string
+	}{
+		{"/users/profile", "/Users/Profile"},
+		{"/USERS/PROFILE", "/Users/Profile"},
+		{strings.ToUpper(long), long},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusMovedPermanently {
+			t.Errorf("RedirectFixedPath failed: Invalid status\n   actual: %v\n expected: %v", w.Code, http.StatusMovedPermanently)
+		} else if location := w.Header().Get("Location"); location != tt.location {
+			t.Errorf("RedirectFixedPath failed: Invalid location\n   actual: %v\n expected: %v", location, tt.location)
+		}
+	}
+}
+
+func BenchmarkRegistry_Static_Many(b *testing.B) {
+	router := New()
+	for i := 0; i < 10000; i++ {
+		router.GET("/api/v1/resource"+strconv.Itoa(i)+"/list", fakeHandler("static"))
+	}
+	path := "/api/v1/resource9999/list"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Lookup(http.MethodGet, path)
+	}
+}
+
+func BenchmarkRegistry_Static_Long_Path(b *testing.B) {
+	router := New()
+	long := "/" + strings.Repeat("a", 4096)
+	router.GET(long, fakeHandler("long"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.Lookup(http.MethodGet, long)
+	}
+}
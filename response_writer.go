@@ -1,9 +1,13 @@
 package chain
 
 import (
+	"bufio"
 	"errors"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"time"
 )
 
 // ErrAlreadySent Error raised when trying to modify or send an already sent response
@@ -16,7 +20,19 @@ type ResponseWriterSpy struct {
 	writeCalled            bool
 	writeHeaderCalled      bool
 	beforeWriteHeaderHooks []func()
-	afterWriteHooks        []func()
+	afterWriteHooks        []func(info ResponseInfo)
+	teeWriters             []io.Writer
+	bytes                  int64     // bytes of the body written
+	started                time.Time // when the router received the request
+	err                    error     // error returned by the handler or the recovered panic
+}
+
+// ResponseInfo summary of the response, received by the AfterSendInfo callbacks (ex. audit logs and metrics)
+type ResponseInfo struct {
+	Status   int           // Status code sent, 0 if nothing was sent (ex. hijacked connections)
+	Bytes    int64         // Bytes of the body written
+	Duration time.Duration // Time elapsed since the router received the request
+	Error    error         // Error returned by the handler, or the recovered panic (see PanicInfo)
 }
 
 func (w *ResponseWriterSpy) Status() int {
@@ -24,6 +40,11 @@ func (w *ResponseWriterSpy) Status() int {
 }
 
 func (w *ResponseWriterSpy) WriteHeader(status int) {
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		// 1xx informational responses (ex. 103 Early Hints) are sent immediately, and are followed by the final response
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
 	w.status = status
 	w.writeHeaderCalled = true
 	w.execBeforeWriteHeaderHooks()
@@ -31,14 +52,87 @@ func (w *ResponseWriterSpy) WriteHeader(status int) {
 }
 
 func (w *ResponseWriterSpy) Write(b []byte) (int, error) {
+	w.writeCalled = true
+	w.startWrite()
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	if n > 0 {
+		for _, tee := range w.teeWriters {
+			_, _ = tee.Write(b[:n])
+		}
+	}
+	return n, err
+}
+
+// Unwrap returns the original http.ResponseWriter. Used by http.ResponseController to access optional interfaces.
+func (w *ResponseWriterSpy) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush implements http.Flusher, sends any buffered data to the client. It is a no-op if the original
+// http.ResponseWriter does not support flushing, use FlushError to detect it.
+func (w *ResponseWriterSpy) Flush() {
+	_ = w.FlushError()
+}
+
+// FlushError flushes buffered data to the client, returning http.ErrNotSupported if the original
+// http.ResponseWriter does not support flushing.
+func (w *ResponseWriterSpy) FlushError() error {
+	switch flusher := w.ResponseWriter.(type) {
+	case interface{ FlushError() error }:
+		w.startWrite()
+		return flusher.FlushError()
+	case http.Flusher:
+		w.startWrite()
+		flusher.Flush()
+		return nil
+	}
+	return http.ErrNotSupported
+}
+
+// Hijack implements http.Hijacker, lets the caller take over the connection (ex. websockets). After a call to Hijack
+// the router does not write anything else to the response.
+func (w *ResponseWriterSpy) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		// connection is now managed by the caller
+		w.writeStarted = true
+		w.beforeWriteHeaderHooks = nil
+	}
+	return conn, rw, err
+}
+
+// ReadFrom implements io.ReaderFrom, allowing the original http.ResponseWriter to use optimizations like sendfile.
+func (w *ResponseWriterSpy) ReadFrom(src io.Reader) (int64, error) {
+	if readerFrom, ok := w.ResponseWriter.(io.ReaderFrom); ok && len(w.teeWriters) == 0 {
+		w.startWrite()
+		w.writeCalled = true
+		n, err := readerFrom.ReadFrom(src)
+		w.bytes += n
+		return n, err
+	}
+	// hides ReadFrom from io.Copy, avoiding infinite recursion
+	return io.Copy(struct{ io.Writer }{w}, src)
+}
+
+// startWrite executes the pending bookkeeping before sending data to the client
+func (w *ResponseWriterSpy) startWrite() {
 	if w.status == 0 {
 		w.status = http.StatusOK
 	}
-	w.writeCalled = true
 	if !w.writeStarted {
 		w.execBeforeWriteHeaderHooks()
 	}
-	return w.ResponseWriter.Write(b)
+}
+
+// Tee registers a writer that receives a copy of the response body as it is written to the client. The response is
+// not buffered, so streaming (ex. SSE) keeps working. Errors returned by the writer are ignored.
+func (w *ResponseWriterSpy) Tee(writer io.Writer) {
+	w.teeWriters = append(w.teeWriters, writer)
 }
 
 // beforeWriteHeader Registers a callback to be invoked before the response is sent.
@@ -52,21 +146,21 @@ func (w *ResponseWriterSpy) beforeWriteHeader(callback func()) error {
 	return nil
 }
 
-// afterWrite Registers a callback to be invoked before the response is sent.
+// afterWrite Registers a callback to be invoked after the response is sent.
 //
 // Callbacks are invoked in the reverse order they are defined (callbacks defined first are invoked last).
-func (w *ResponseWriterSpy) afterWrite(callback func()) error {
+func (w *ResponseWriterSpy) afterWrite(callback func(info ResponseInfo)) error {
 	if w.writeStarted {
 		return ErrAlreadySent
 	}
-	w.afterWriteHooks = append(w.afterWriteHooks, func() {
+	w.afterWriteHooks = append(w.afterWriteHooks, func(info ResponseInfo) {
 		defer func() {
 			// no panic
 			if r := recover(); r != nil {
 				slog.Warn("[chain] panic occured in a after write hook", slog.Any("panic", r))
 			}
 		}()
-		callback()
+		callback(info)
 	})
 	return nil
 }
@@ -87,9 +181,23 @@ func (w *ResponseWriterSpy) execBeforeWriteHeaderHooks() {
 // execAfterWriteHooksCalledByRouter called by router.ServeHTTP
 func (w *ResponseWriterSpy) execAfterWriteHooksCalledByRouter() {
 	if w.afterWriteHooks != nil {
+		info := w.info()
 		for i := len(w.afterWriteHooks) - 1; i >= 0; i-- {
-			w.afterWriteHooks[i]()
+			w.afterWriteHooks[i](info)
 		}
 	}
 	w.afterWriteHooks = nil
 }
+
+// info summary of the response
+func (w *ResponseWriterSpy) info() ResponseInfo {
+	info := ResponseInfo{
+		Status: w.status,
+		Bytes:  w.bytes,
+		Error:  w.err,
+	}
+	if !w.started.IsZero() {
+		info.Duration = time.Since(w.started)
+	}
+	return info
+}
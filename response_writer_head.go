@@ -0,0 +1,72 @@
+package chain
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// headResponseWriter discards the body of responses to HEAD requests served by GET routes (see Router.AutoHEAD).
+//
+// The header is only sent when the handler finishes (or flushes), so the Content-Length of the discarded body can be
+// informed, like net/http does for small responses.
+type headResponseWriter struct {
+	http.ResponseWriter
+	status     int
+	length     int64
+	headerSent bool
+}
+
+func (w *headResponseWriter) WriteHeader(status int) {
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.length += int64(len(b))
+	return len(b), nil
+}
+
+// Unwrap returns the original http.ResponseWriter
+func (w *headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush sends the header, the Content-Length is not known at this point
+func (w *headResponseWriter) Flush() {
+	w.sendHeader()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish sends the header with the Content-Length of the discarded body. Called by router.ServeHTTP
+func (w *headResponseWriter) finish() {
+	if w.headerSent || w.status == 0 {
+		return
+	}
+	header := w.ResponseWriter.Header()
+	if w.length > 0 && header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" &&
+		w.status >= http.StatusOK && w.status != http.StatusNoContent && w.status != http.StatusNotModified {
+		header.Set("Content-Length", strconv.FormatInt(w.length, 10))
+	}
+	w.sendHeader()
+}
+
+func (w *headResponseWriter) sendHeader() {
+	if w.headerSent {
+		return
+	}
+	w.headerSent = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
@@ -25,34 +25,125 @@ type MiddlewareWithInitHandler interface {
 
 type Handle func(*Context) error
 
+// Middleware a middleware registered with Router.Use, see Route.Middlewares
 type Middleware struct {
-	Path   *RouteInfo
-	Handle func(ctx *Context, next func() error) error
+	Path     *RouteInfo
+	Handle   func(ctx *Context, next func() error) error
+	Priority Priority
+	source   any // the middleware informed to Router.Use, see Skip
 }
 
-// Route control of a registered route
+// Pattern the path of the middleware, without the names of the parameters (ex. "/api/:/*")
+func (m *Middleware) Pattern() string {
+	return m.Path.Pattern()
+}
+
+// Priority controls the execution order of middlewares. Middlewares with higher priority run first (outermost),
+// regardless of the registration order. Middlewares with the same priority run in the order they were registered.
+//
+// ## Example
+//
+//	router.Use(func(ctx *chain.Context, next func() error) error {
+//		// ...
+//	})
+//
+//	// registered later, but runs before all middlewares with the default priority
+//	router.Use(chain.PriorityFirst, &chain.Recovery{})
+type Priority int
+
+const (
+	PriorityLast    Priority = -1000
+	PriorityDefault Priority = 0
+	PriorityFirst   Priority = 1000
+)
+
+// Route control of a registered route.
+//
+// Tooling can inspect the routes (see Router.Routes) and wrap or decorate them at startup (see Router.Walk).
 type Route struct {
 	Info             *RouteInfo
 	Handle           Handle
-	Middlewares      []*Middleware
+	Options          RouteOptions
+	middlewares      []*Middleware
 	middlewaresAdded map[*Middleware]bool
 }
 
+// Path the path used to register the route (ex. "/users/:id")
+func (r *Route) Path() string {
+	return r.Info.Path()
+}
+
+// Pattern the path of the route, without the names of the parameters (ex. "/users/:")
+func (r *Route) Pattern() string {
+	return r.Info.Pattern()
+}
+
+// Priority of the route, see RouteInfo.Priority
+func (r *Route) Priority() int {
+	return r.Info.Priority()
+}
+
+// Middlewares the middlewares applied to the route, in the order they run
+func (r *Route) Middlewares() []*Middleware {
+	middlewares := make([]*Middleware, len(r.middlewares))
+	copy(middlewares, r.middlewares)
+	return middlewares
+}
+
+// Wrap decorates the handler of the route, the decorator runs after the middlewares of the route.
+//
+// ## Example
+//
+//	router.Walk(func(method string, route *chain.Route) error {
+//		route.Wrap(func(next chain.Handle) chain.Handle {
+//			return func(ctx *chain.Context) error {
+//				defer metrics.Observe(method, route.Pattern(), time.Now())
+//				return next(ctx)
+//			}
+//		})
+//		return nil
+//	})
+func (r *Route) Wrap(decorator func(next Handle) Handle) {
+	r.Handle = decorator(r.Handle)
+}
+
 // Dispatch ctx into this route
 func (r *Route) Dispatch(ctx *Context) error {
-	if len(r.Middlewares) == 0 {
-		return r.Handle(ctx)
+	if !r.admits(ctx) {
+		return nil
+	}
+	return r.dispatch(ctx)
+}
+
+// endpoint runs the handler of the route, enforcing the route options
+func (r *Route) endpoint(ctx *Context) error {
+	if r.Options.hasHandleOptions() {
+		return r.handleWithOptions(ctx, r.handle)
+	}
+	return r.handle(ctx)
+}
+
+func (r *Route) dispatch(ctx *Context) error {
+	if len(r.middlewares) == 0 {
+		if ctx.IsAborted() {
+			return nil
+		}
+		return r.endpoint(ctx)
 	}
 
 	index := 0
 	var next func() error
 	next = func() error {
-		if index > len(r.Middlewares)-1 {
+		if ctx.IsAborted() {
+			// Abort skips the remaining middlewares and the handler
+			return nil
+		}
+		if index > len(r.middlewares)-1 {
 			// end of middlewares
-			return r.Handle(ctx)
+			return r.endpoint(ctx)
 		}
 
-		middleware := r.Middlewares[index]
+		middleware := r.middlewares[index]
 		index++
 
 		match, names, values := middleware.Path.Match(ctx)
@@ -86,3 +177,31 @@ func (r *Route) Dispatch(ctx *Context) error {
 	}
 	return next()
 }
+
+// clone copy of the route, the middlewares added later to this route are not added to the copy
+func (r *Route) clone() *Route {
+	c := *r
+	c.middlewares = append(make([]*Middleware, 0, len(r.middlewares)), r.middlewares...)
+	c.middlewaresAdded = make(map[*Middleware]bool, len(r.middlewaresAdded))
+	for middleware := range r.middlewaresAdded {
+		c.middlewaresAdded[middleware] = true
+	}
+	return &c
+}
+
+// addMiddleware adds the middleware to the route, ordered by priority
+func (r *Route) addMiddleware(middleware *Middleware) {
+	if r.middlewaresAdded[middleware] || r.Options.skips(middleware) {
+		return
+	}
+	r.middlewaresAdded[middleware] = true
+
+	// keeps the registration order among middlewares with the same priority
+	i := len(r.middlewares)
+	for i > 0 && r.middlewares[i-1].Priority < middleware.Priority {
+		i--
+	}
+	r.middlewares = append(r.middlewares, nil)
+	copy(r.middlewares[i+1:], r.middlewares[i:])
+	r.middlewares[i] = middleware
+}
@@ -12,6 +12,7 @@ type RouteInfo struct {
 	path         string   // a rota original
 	pattern      string   // a rota, sem os nomes de parametros
 	priority     int      // calculo da prioridade desse path
+	computed     int      // prioridade calculada, antes de WithPriority
 	hasStatic    bool     // possui estático
 	hasParameter bool     // possui parametros
 	hasWildcard  bool     // possui wildcard
@@ -28,10 +29,16 @@ func (d *RouteInfo) Pattern() string {
 	return d.pattern
 }
 
+// Priority of the path, routes with higher priority are evaluated first. See WithPriority
 func (d *RouteInfo) Priority() int {
 	return d.priority
 }
 
+// ComputedPriority the priority computed from the segments of the path, ignoring WithPriority
+func (d *RouteInfo) ComputedPriority() int {
+	return d.computed
+}
+
 func (d *RouteInfo) Params() []string {
 	return d.params
 }
@@ -124,7 +131,7 @@ func (d RouteInfo) Match(ctx *Context) (match bool, paramNames []string, paramVa
 		paramNames = d.params
 		for _, index := range d.paramsIndex {
 			if strings.IndexByte(d.segments[index], wildcard) == 0 {
-				paramValues = append(paramValues, ctx.path[ctx.pathSegments[index]:])
+				paramValues = append(paramValues, ctx.wildcardValue(ctx.path[ctx.pathSegments[index]:]))
 				break
 			}
 			paramValues = append(paramValues, ctx.path[ctx.pathSegments[index]+1:ctx.pathSegments[index+1]])
@@ -202,7 +209,7 @@ func (d *RouteInfo) Matches(o *RouteInfo) bool {
 }
 
 func (d RouteInfo) conflictsWith(o *RouteInfo) bool {
-	if d.priority != o.priority {
+	if d.computed != o.computed {
 		return false
 	}
 
@@ -302,6 +309,7 @@ func ParseRouteInfo(pathOrig string) *RouteInfo {
 		details.priority = details.priority + (height * height * weight)
 	}
 
+	details.computed = details.priority
 	details.pattern = route.String()
 
 	return details
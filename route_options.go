@@ -0,0 +1,344 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// RouteOption configures per-endpoint policies of a Route, enforced during Route.Dispatch. WithHost and WithFeature are
+// checked before the middlewares (the request is handled as if the route does not exist), the other options are
+// enforced after the middlewares, just before the handler, so the responses they write (ex. 403, 415 or redirects) also
+// pass through the global middlewares (logging, CORS, recovery).
+//
+// ## Example
+//
+//	router.GET("/report", handler, chain.WithTimeout(2*time.Second), chain.WithCache(30*time.Second))
+//	router.POST("/upload", handler, chain.WithMaxBody(1<<20))
+type RouteOption func(options *RouteOptions)
+
+// RouteOptions per-endpoint policies of a Route
+type RouteOptions struct {
+	Timeout        time.Duration // Deadline for the request context. See WithTimeout
+	MaxBodySize    int64         // Max size of the request body, in bytes. See WithMaxBody
+	CacheTTL       time.Duration // How long the response can be cached. See WithCache
+	Priority       int           // Overrides the computed priority of the route, when non-zero. See WithPriority
+	Feature        string        // Feature flag required by the route. See WithFeature
+	ImplicitStatus int           // Status written when the handler returns without writing. See WithImplicitStatus
+	Hosts          []string      // Hosts served by the route. See WithHost
+	RequireTLS     bool          // Only HTTPS requests are served. See WithRequireTLS
+	TLSRedirect    bool          // Plain HTTP requests are redirected to HTTPS. See WithRequireTLS
+	Consumes       []string      // Media types accepted in the request body. See WithConsumes
+	Produces       []string      // Media types of the responses, negotiated with the Accept header. See WithProduces
+	Skip           []any         // Middlewares registered with Router.Use that are not applied to the route. See Skip
+}
+
+// NoImplicitStatus disables the implicit write of the status when the handler returns without writing a response, see
+// Router.ImplicitStatus and WithImplicitStatus
+const NoImplicitStatus = -1
+
+// WithTimeout sets a deadline on the request context (ctx.Request.Context()) seen by the handler. The handler is not
+// interrupted: it must observe the context cancellation, a handler that ignores the context runs until it returns. If
+// the deadline has expired when the handler returns without writing a response, the client receives a 503 Service
+// Unavailable. Use http.TimeoutHandler to bound the response time of handlers that do not observe the context.
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(options *RouteOptions) {
+		options.Timeout = timeout
+	}
+}
+
+// WithMaxBody limits the size of the request body. Requests whose Content-Length is greater than the limit are
+// rejected with 413 Request Entity Too Large, reading beyond the limit returns an error (see http.MaxBytesReader), also
+// when the body is read by a middleware.
+func WithMaxBody(size int64) RouteOption {
+	return func(options *RouteOptions) {
+		options.MaxBodySize = size
+	}
+}
+
+// WithCache allows GET and HEAD successful responses to be cached by clients and shared caches (CDNs, proxies) for
+// the given duration, sending the "Cache-Control: public, max-age=<ttl>" header (unless the handler has defined its
+// own Cache-Control). The responses are not cached by the server, see the middlewares/cache package for that.
+func WithCache(ttl time.Duration) RouteOption {
+	return func(options *RouteOptions) {
+		options.CacheTTL = ttl
+	}
+}
+
+// WithPriority overrides the priority computed from the path segments (see RouteInfo.ComputedPriority), forcing the
+// evaluation order of dynamic routes with the same number of segments. Routes with higher priority are evaluated first,
+// routes with the same priority are evaluated in the order they were registered. Static routes (without parameters)
+// always match first.
+//
+// ## Example
+//
+//	// by default "/files/:name/raw" (computed priority 38) is evaluated before "/files/:id/:version" (computed
+//	// priority 37), with the override "/files/123/raw" is handled by "/files/:id/:version"
+//	router.GET("/files/:id/:version", handler, chain.WithPriority(100))
+//	router.GET("/files/:name/raw", handler)
+func WithPriority(priority int) RouteOption {
+	return func(options *RouteOptions) {
+		options.Priority = priority
+	}
+}
+
+// WithFeature hides the route behind a feature flag (see Context.FeatureEnabled), when the flag is disabled the request
+// is handled as if the route does not exist (see Router.NotFoundHandler). Use RequireFeature to gate a group of routes.
+//
+// ## Example
+//
+//	router.GET("/checkout/v2", handler, chain.WithFeature("new-checkout"))
+func WithFeature(flag string) RouteOption {
+	return func(options *RouteOptions) {
+		options.Feature = flag
+	}
+}
+
+// WithImplicitStatus changes the status written when the handler returns without writing a response (default
+// Router.ImplicitStatus). With NoImplicitStatus, the response is left untouched, allowing proxy/middleware patterns
+// where a downstream handler writes it.
+//
+// ## Example
+//
+//	router.POST("/jobs", func(ctx *chain.Context) {
+//		jobs.Enqueue(ctx.Request)
+//	}, chain.WithImplicitStatus(http.StatusAccepted))
+//
+//	router.GET("/legacy/*path", func(ctx *chain.Context) {
+//		// the outer http.Handler writes the response
+//	}, chain.WithImplicitStatus(chain.NoImplicitStatus))
+func WithImplicitStatus(status int) RouteOption {
+	return func(options *RouteOptions) {
+		options.ImplicitStatus = status
+	}
+}
+
+// WithHost restricts the route to the hosts (see http.Request.Host, the port is ignored), requests for other hosts are
+// handled as if the route does not exist (see Router.NotFoundHandler). A leading "*." matches the subdomains
+// ("*.example.com" matches "api.example.com", but not "example.com"). Use Group.Host to restrict a group of routes.
+//
+// ## Example
+//
+//	router.GET("/dashboard", handler, chain.WithHost("admin.example.com"))
+func WithHost(hosts ...string) RouteOption {
+	return func(options *RouteOptions) {
+		options.Hosts = append(options.Hosts, hosts...)
+	}
+}
+
+// WithRequireTLS serves the route only for HTTPS requests (see Context.IsTLS, which trusts the X-Forwarded-Proto header
+// of the trusted proxies). Plain HTTP requests are redirected to HTTPS when redirect is true (301 for GET and HEAD, 308
+// for the other methods), otherwise are rejected with 403 Forbidden. Use Group.RequireTLS to protect a group of
+// routes.
+//
+// ## Example
+//
+//	router.POST("/webhooks/stripe", handler, chain.WithRequireTLS(false))
+func WithRequireTLS(redirect bool) RouteOption {
+	return func(options *RouteOptions) {
+		options.RequireTLS = true
+		options.TLSRedirect = redirect
+	}
+}
+
+// WithConsumes restricts the media types (Content-Type) of the request body, requests with a body of other types are
+// rejected with 415 Unsupported Media Type before the handler (and the binding) runs. Requests without body are not
+// affected. A "/*" suffix matches all the subtypes ("image/*").
+//
+// ## Example
+//
+//	router.POST("/users", handler, chain.WithConsumes("application/json"))
+//	router.PUT("/avatar", handler, chain.WithConsumes("image/*"))
+func WithConsumes(mediaTypes ...string) RouteOption {
+	return func(options *RouteOptions) {
+		options.Consumes = append(options.Consumes, mediaTypes...)
+	}
+}
+
+// WithProduces declares the media types of the responses of the route, in order of preference. The type is negotiated
+// with the Accept header of the request (see NegotiateContentType) and is available to the handler with
+// Context.NegotiatedType, requests that accept none of the types are rejected with 406 Not Acceptable.
+//
+// ## Example
+//
+//	router.GET("/report", func(ctx *chain.Context) error {
+//		if ctx.NegotiatedType() == "text/csv" {
+//			return ctx.Attachment(report.CSV(), "report.csv")
+//		}
+//		ctx.Json(report.Rows())
+//		return nil
+//	}, chain.WithProduces("application/json", "text/csv"))
+func WithProduces(mediaTypes ...string) RouteOption {
+	return func(options *RouteOptions) {
+		options.Produces = append(options.Produces, mediaTypes...)
+	}
+}
+
+// Skip opts the route out of middlewares registered with Router.Use (or Group.Use) whose path matches the route, ex.
+// health checks that should not touch the sessions. The middlewares are identified by the value informed to Use,
+// compared with ==, so each instance is skipped separately (two ipfilter instances with different configs are two
+// middlewares). Functions are not comparable, Skip panics when informed one; to skip a function middleware register it
+// as a MiddlewareHandler (ex. a pointer to a struct with a Handle method).
+//
+// ## Example
+//
+//	sessionManager := &session.Manager{Store: &session.Cookie{}}
+//	router.Use(sessionManager)
+//
+//	router.GET("/health", healthHandler, chain.Skip(sessionManager))
+func Skip(middlewares ...any) RouteOption {
+	for _, middleware := range middlewares {
+		if middleware == nil || !reflect.ValueOf(middleware).Comparable() {
+			panic(fmt.Sprintf("[chain] Skip requires a comparable middleware. middleware: %T", middleware))
+		}
+	}
+	return func(options *RouteOptions) {
+		options.Skip = append(options.Skip, middlewares...)
+	}
+}
+
+// skips checks if the middleware must not be applied to the route, see Skip
+func (o RouteOptions) skips(middleware *Middleware) bool {
+	for _, skip := range o.Skip {
+		if sameMiddleware(skip, middleware.source) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameMiddleware checks if the values are the same middleware, non-comparable values (functions, structs with functions
+// in interface fields) never match
+func sameMiddleware(a any, b any) bool {
+	if a == nil || b == nil || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if !reflect.ValueOf(a).Comparable() || !reflect.ValueOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// hasHandleOptions checks if the options must be enforced before the handler
+func (o RouteOptions) hasHandleOptions() bool {
+	return o.Timeout > 0 || o.MaxBodySize > 0 || o.CacheTTL > 0 || o.ImplicitStatus != 0 || o.RequireTLS ||
+		len(o.Consumes) > 0 || len(o.Produces) > 0
+}
+
+// admits checks the options enforced before the middlewares, writes the not found response when the route must be
+// handled as if it does not exist
+func (r *Route) admits(ctx *Context) bool {
+	options := r.Options
+
+	if len(options.Hosts) > 0 && !matchHost(options.Hosts, ctx.Request.Host) {
+		ctx.featureDisabled()
+		return false
+	}
+
+	if options.Feature != "" && !ctx.FeatureEnabled(options.Feature) {
+		ctx.featureDisabled()
+		return false
+	}
+
+	if options.MaxBodySize > 0 && ctx.Request.Body != nil {
+		// limits the body also for the middlewares, the 413 response is written before the handler
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, options.MaxBodySize)
+	}
+	return true
+}
+
+// handleWithOptions enforce the route options around the handler, after the middlewares
+func (r *Route) handleWithOptions(ctx *Context, handle func(ctx *Context) error) error {
+	options := r.Options
+
+	if options.RequireTLS && !ctx.IsTLS() {
+		if !options.TLSRedirect {
+			ctx.Error("HTTPS Required", http.StatusForbidden)
+			return nil
+		}
+		code := http.StatusMovedPermanently
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+		ctx.Redirect("https://"+ctx.Request.Host+ctx.Request.URL.RequestURI(), code)
+		return nil
+	}
+
+	if len(options.Consumes) > 0 && hasBody(ctx.Request) && !matchMediaType(options.Consumes, ctx.GetContentType()) {
+		ctx.Error(http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return nil
+	}
+
+	if len(options.Produces) > 0 {
+		ctx.Writer.Header().Add("Vary", "Accept")
+		negotiated := NegotiateContentType(ctx.Request.Header.Get("Accept"), options.Produces)
+		if negotiated == "" {
+			ctx.Error(http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+			return nil
+		}
+		ctx.root().negotiated = negotiated
+	}
+
+	if options.ImplicitStatus != 0 {
+		ctx.root().implicitStatus = options.ImplicitStatus
+	}
+
+	if options.MaxBodySize > 0 && ctx.Request.ContentLength > options.MaxBodySize {
+		ctx.WriteHeader(http.StatusRequestEntityTooLarge)
+		return nil
+	}
+
+	if options.CacheTTL > 0 && (ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead) {
+		_ = ctx.BeforeSend(func() {
+			header := ctx.Writer.Header()
+			if header.Get("Cache-Control") != "" {
+				return
+			}
+			if status := ctx.GetStatus(); status != 0 && status != http.StatusOK {
+				return
+			}
+			header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(options.CacheTTL.Seconds())))
+		})
+	}
+
+	if options.Timeout > 0 {
+		request := ctx.Request
+		timeoutCtx, cancel := context.WithTimeout(request.Context(), options.Timeout)
+		defer func() {
+			cancel()
+			ctx.Request = request
+		}()
+		ctx.Request = request.WithContext(timeoutCtx)
+
+		err := handle(ctx)
+		if err == nil && timeoutCtx.Err() == context.DeadlineExceeded && !ctx.WriteStarted() {
+			ctx.WriteHeader(http.StatusServiceUnavailable)
+		}
+		return err
+	}
+
+	return handle(ctx)
+}
+
+// matchHost checks if the host (port is ignored) matches one of the patterns, see WithHost
+func matchHost(patterns []string, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if suffix, found := strings.CutPrefix(pattern, "*"); found {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,458 @@
+package chain
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Route_Options_Timeout(t *testing.T) {
+	router := New()
+	router.GET("/slow", func(ctx *Context) error {
+		select {
+		case <-ctx.Request.Context().Done():
+		case <-time.After(time.Second):
+			ctx.Write([]byte("done"))
+		}
+		return nil
+	}, WithTimeout(10*time.Millisecond))
+
+	req, _ := http.NewRequest(http.MethodGet, "/slow", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("WithTimeout failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func Test_Route_Options_MaxBody(t *testing.T) {
+	router := New()
+
+	var readErr error
+	router.POST("/upload", func(ctx *Context) error {
+		_, readErr = io.ReadAll(ctx.Request.Body)
+		return nil
+	}, WithMaxBody(8))
+
+	// Content-Length greater than limit
+	req, _ := http.NewRequest(http.MethodPost, "/upload", strings.NewReader("0123456789"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("WithMaxBody failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	// unknown Content-Length, fails on read
+	req, _ = http.NewRequest(http.MethodPost, "/upload", io.NopCloser(strings.NewReader("0123456789")))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if readErr == nil {
+		t.Errorf("WithMaxBody failed: expected read error")
+	}
+
+	// within limit
+	req, _ = http.NewRequest(http.MethodPost, "/upload", strings.NewReader("0123"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if readErr != nil || w.Code != http.StatusOK {
+		t.Errorf("WithMaxBody failed: unexpected error %v, Code %d", readErr, w.Code)
+	}
+}
+
+func Test_Route_Options_Cache(t *testing.T) {
+	router := New()
+	router.GET("/cached", func(ctx *Context) error {
+		ctx.Write([]byte("ok"))
+		return nil
+	}, WithCache(30*time.Second))
+	router.GET("/custom", func(ctx *Context) error {
+		ctx.SetHeader("Cache-Control", "no-store")
+		ctx.Write([]byte("ok"))
+		return nil
+	}, WithCache(30*time.Second))
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/cached", "public, max-age=30"},
+		{"/custom", "no-store"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if actual := w.Header().Get("Cache-Control"); actual != tt.expected {
+			t.Errorf("WithCache failed: Invalid Cache-Control\n   actual: %v\n expected: %v", actual, tt.expected)
+		}
+	}
+}
+
+func Test_Route_Options_Priority(t *testing.T) {
+	router := New()
+	router.GET("/files/:id/:version", func(ctx *Context) error {
+		ctx.Write([]byte("version"))
+		return nil
+	}, WithPriority(100))
+	router.GET("/files/:name/raw", func(ctx *Context) error {
+		ctx.Write([]byte("raw"))
+		return nil
+	})
+	router.GET("/files/list/all", func(ctx *Context) error {
+		ctx.Write([]byte("static"))
+		return nil
+	})
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/files/123/raw", "version"},
+		{"/files/123/v2", "version"},
+		{"/files/list/all", "static"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if actual := w.Body.String(); actual != tt.expected {
+			t.Errorf("WithPriority failed: Invalid Route\n   actual: %v\n expected: %v", actual, tt.expected)
+		}
+	}
+
+	var paths []string
+	for _, route := range router.Routes(http.MethodGet) {
+		paths = append(paths, route.Info.Path())
+	}
+	expected := "/files/list/all,/files/:id/:version,/files/:name/raw"
+	if actual := strings.Join(paths, ","); actual != expected {
+		t.Errorf("Routes failed: Invalid Order\n   actual: %v\n expected: %v", actual, expected)
+	}
+
+	route, _ := router.Lookup(http.MethodGet, "/files/123/v2")
+	if route.Info.Priority() != 100 || route.Info.ComputedPriority() != 37 {
+		t.Errorf(
+			"WithPriority failed: Invalid Priority\n   actual: %v (computed %v)\n expected: %v (computed %v)",
+			route.Info.Priority(), route.Info.ComputedPriority(), 100, 37,
+		)
+	}
+}
+
+func Test_Route_Options_ImplicitStatus(t *testing.T) {
+	router := New()
+	router.POST("/jobs", func(ctx *Context) {}, WithImplicitStatus(http.StatusAccepted))
+	router.GET("/proxy", func(ctx *Context) {}, WithImplicitStatus(NoImplicitStatus))
+	router.GET("/default", func(ctx *Context) {})
+
+	// the outer handler writes the response when the router leaves it untouched
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		router.ServeHTTP(w, req)
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		implicit int
+		expected int
+	}{
+		{http.MethodPost, "/jobs", 0, http.StatusAccepted},
+		{http.MethodGet, "/proxy", 0, http.StatusTeapot},
+		{http.MethodGet, "/default", 0, http.StatusOK},
+		{http.MethodGet, "/default", http.StatusNoContent, http.StatusNoContent},
+		{http.MethodGet, "/default", NoImplicitStatus, http.StatusTeapot},
+		{http.MethodPost, "/jobs", NoImplicitStatus, http.StatusAccepted},
+	}
+	for _, test := range tests {
+		router.ImplicitStatus = test.implicit
+		req, _ := http.NewRequest(test.method, test.path, nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != test.expected {
+			t.Errorf("WithImplicitStatus failed: Invalid Code (%s, Router.ImplicitStatus %d)\n   actual: %v\n expected: %v", test.path, test.implicit, w.Code, test.expected)
+		}
+	}
+}
+
+func Test_Route_Options_RequireTLS(t *testing.T) {
+	router := New()
+	if err := router.SetTrustedProxies("10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	admin := router.Group("/admin").RequireTLS(true)
+	admin.GET("/users", func(ctx *Context) {})
+	admin.Group("/v2").POST("/users", func(ctx *Context) {})
+	router.Group("/webhooks").RequireTLS(false).POST("/stripe", func(ctx *Context) {})
+
+	tests := []struct {
+		method   string
+		url      string
+		remote   string
+		proto    string
+		expected int
+		location string
+	}{
+		{http.MethodGet, "http://example.com/admin/users?page=2", "192.168.0.1:1234", "", http.StatusMovedPermanently, "https://example.com/admin/users?page=2"},
+		{http.MethodPost, "http://example.com/admin/v2/users", "192.168.0.1:1234", "", http.StatusPermanentRedirect, "https://example.com/admin/v2/users"},
+		{http.MethodGet, "https://example.com/admin/users", "192.168.0.1:1234", "", http.StatusOK, ""},
+		// X-Forwarded-Proto of trusted proxy
+		{http.MethodGet, "http://example.com/admin/users", "10.0.0.1:1234", "https", http.StatusOK, ""},
+		// X-Forwarded-Proto of untrusted client
+		{http.MethodGet, "http://example.com/admin/users", "192.168.0.1:1234", "https", http.StatusMovedPermanently, "https://example.com/admin/users"},
+		{http.MethodPost, "http://example.com/webhooks/stripe", "192.168.0.1:1234", "", http.StatusForbidden, ""},
+		{http.MethodPost, "https://example.com/webhooks/stripe", "192.168.0.1:1234", "", http.StatusOK, ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.url, nil)
+		req.RemoteAddr = test.remote
+		if test.proto != "" {
+			req.Header.Set("X-Forwarded-Proto", test.proto)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != test.expected {
+			t.Errorf("RequireTLS failed: Invalid Code (%s %s)\n   actual: %v\n expected: %v", test.method, test.url, w.Code, test.expected)
+		}
+		if location := w.Header().Get("Location"); location != test.location {
+			t.Errorf("RequireTLS failed: Invalid Location (%s %s)\n   actual: %v\n expected: %v", test.method, test.url, location, test.location)
+		}
+	}
+}
+
+func Test_Route_Options_Host(t *testing.T) {
+	router := New()
+	router.Group("/admin").Host("admin.example.com").GET("/users", func(ctx *Context) {})
+	router.Host("*.example.org").GET("/status", func(ctx *Context) {})
+
+	tests := map[string]int{
+		"http://admin.example.com/admin/users":      http.StatusOK,
+		"http://ADMIN.example.com:8080/admin/users": http.StatusOK,
+		"http://www.example.com/admin/users":        http.StatusNotFound,
+		"http://api.example.org/status":             http.StatusOK,
+		"http://a.b.example.org/status":             http.StatusOK,
+		"http://example.org/status":                 http.StatusNotFound,
+	}
+	for url, expected := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != expected {
+			t.Errorf("Host failed: Invalid Code (%s)\n   actual: %v\n expected: %v", url, w.Code, expected)
+		}
+	}
+}
+
+func Test_Route_Options_Consumes(t *testing.T) {
+	router := New()
+	router.POST("/users", func(ctx *Context) error {
+		ctx.WriteHeader(http.StatusCreated)
+		return nil
+	}, WithConsumes("application/json", "image/*"))
+
+	tests := []struct {
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"application/json", "{}", http.StatusCreated},
+		{"application/json; charset=utf-8", "{}", http.StatusCreated},
+		{"Application/JSON", "{}", http.StatusCreated},
+		{"image/png", "png", http.StatusCreated},
+		{"application/xml", "<user/>", http.StatusUnsupportedMediaType},
+		{"", "{}", http.StatusUnsupportedMediaType},
+		{"", "", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		var body io.Reader
+		if tt.body != "" {
+			body = strings.NewReader(tt.body)
+		}
+		req, _ := http.NewRequest(http.MethodPost, "/users", body)
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.expected {
+			t.Errorf("WithConsumes(%q) failed: Invalid Code\n   actual: %v\n expected: %v", tt.contentType, w.Code, tt.expected)
+		}
+	}
+}
+
+func Test_Route_Options_Middlewares(t *testing.T) {
+	router := New()
+	router.Use(func(ctx *Context, next func() error) error {
+		ctx.SetHeader("Access-Control-Allow-Origin", "*")
+		return next()
+	})
+	router.POST("/users", func(ctx *Context) {}, WithConsumes("application/json"))
+	router.POST("/webhooks", func(ctx *Context) {}, WithRequireTLS(false))
+	router.GET("/admin", func(ctx *Context) {}, WithRequireTLS(true))
+
+	tests := []struct {
+		method   string
+		url      string
+		expected int
+	}{
+		{http.MethodPost, "http://example.com/users", http.StatusUnsupportedMediaType},
+		{http.MethodPost, "http://example.com/webhooks", http.StatusForbidden},
+		{http.MethodGet, "http://example.com/admin", http.StatusMovedPermanently},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, strings.NewReader("<user/>"))
+		req.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.expected {
+			t.Errorf("Options failed: Invalid Code (%s %s)\n   actual: %v\n expected: %v", tt.method, tt.url, w.Code, tt.expected)
+		}
+		if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "*" {
+			t.Errorf("Options failed: middleware not applied (%s %s)\n   actual: %v\n expected: %v", tt.method, tt.url, origin, "*")
+		}
+	}
+}
+
+func Test_Route_Options_Produces(t *testing.T) {
+	router := New()
+	router.GET("/report", func(ctx *Context) error {
+		ctx.Write([]byte(ctx.NegotiatedType()))
+		return nil
+	}, WithProduces("application/json", "text/csv"))
+
+	tests := []struct {
+		accept   string
+		code     int
+		expected string
+	}{
+		{"", http.StatusOK, "application/json"},
+		{"*/*", http.StatusOK, "application/json"},
+		{"text/csv", http.StatusOK, "text/csv"},
+		{"text/*, application/json;q=0.5", http.StatusOK, "text/csv"},
+		{"application/json;q=0.9, text/csv", http.StatusOK, "text/csv"},
+		{"*/*;q=0.1, text/csv;q=0", http.StatusOK, "application/json"},
+		{"application/xml", http.StatusNotAcceptable, ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/report", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("WithProduces(%q) failed: Invalid Code\n   actual: %v\n expected: %v", tt.accept, w.Code, tt.code)
+		} else if tt.code == http.StatusOK && w.Body.String() != tt.expected {
+			t.Errorf("WithProduces(%q) failed: Invalid type\n   actual: %v\n expected: %v", tt.accept, w.Body.String(), tt.expected)
+		}
+		if vary := w.Header().Get("Vary"); vary != "Accept" {
+			t.Errorf("WithProduces(%q) failed: Invalid Vary\n   actual: %v\n expected: %v", tt.accept, vary, "Accept")
+		}
+	}
+}
+
+type countingMiddleware struct {
+	calls int
+}
+
+func (m *countingMiddleware) Handle(ctx *Context, next func() error) error {
+	m.calls++
+	return next()
+}
+
+func Test_Route_Options_Skip(t *testing.T) {
+	sessions := &countingMiddleware{}
+	audit := &countingMiddleware{}
+
+	router := New()
+	router.Use(sessions)
+	router.Use("/api/*", audit)
+
+	handler := func(ctx *Context) {}
+	router.GET("/health", handler, Skip(sessions))
+	router.GET("/api/ping", handler, Skip(audit))
+	router.GET("/api/users", handler)
+
+	for _, path := range []string{"/health", "/api/ping", "/api/users"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if sessions.calls != 2 {
+		t.Errorf("Skip failed: Invalid middleware calls\n   actual: %v\n expected: %v", sessions.calls, 2)
+	}
+	if audit.calls != 1 {
+		t.Errorf("Skip failed: Invalid path middleware calls\n   actual: %v\n expected: %v", audit.calls, 1)
+	}
+
+	// middlewares registered after the route
+	late := &countingMiddleware{}
+	router.Use(late)
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if late.calls != 1 || sessions.calls != 2 {
+		t.Errorf("Skip failed: Invalid middleware calls\n   actual: %v %v\n expected: %v %v", late.calls, sessions.calls, 1, 2)
+	}
+}
+
+type handlerMiddleware struct {
+	Handler http.Handler
+}
+
+func (m handlerMiddleware) Handle(ctx *Context, next func() error) error {
+	m.Handler.ServeHTTP(ctx.Writer, ctx.Request)
+	return next()
+}
+
+func Test_Route_Options_Skip_Uncomparable(t *testing.T) {
+	calls := 0
+	middleware := handlerMiddleware{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ })}
+
+	router := New()
+	router.Use(middleware)
+	// same type, comparable dynamic value
+	redirect := handlerMiddleware{Handler: http.RedirectHandler("/", http.StatusFound)}
+	router.GET("/health", func(ctx *Context) {}, Skip(redirect))
+
+	req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if calls != 1 {
+		t.Errorf("Skip failed: Invalid middleware calls\n   actual: %v\n expected: %v", calls, 1)
+	}
+
+	// rejected by Skip, instead of panicking when the route is registered
+	defer func() {
+		if rcv := recover(); rcv == nil || !strings.HasPrefix(fmt.Sprint(rcv), "[chain] Skip") {
+			t.Errorf("Skip failed: Invalid panic for uncomparable middleware\n   actual: %v", rcv)
+		}
+	}()
+	router.GET("/ready", func(ctx *Context) {}, Skip(middleware))
+}
+
+func Test_Route_Options_Skip_Instances(t *testing.T) {
+	internal := &countingMiddleware{}
+	partners := &countingMiddleware{}
+
+	router := New()
+	router.Use(internal)
+	router.Use(partners)
+	router.GET("/partners", func(ctx *Context) {}, Skip(internal))
+
+	req, _ := http.NewRequest(http.MethodGet, "/partners", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if internal.calls != 0 || partners.calls != 1 {
+		t.Errorf("Skip failed: Invalid middleware calls\n   actual: %v %v\n expected: %v %v", internal.calls, partners.calls, 0, 1)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Skip failed: expected panic for function middleware")
+		}
+	}()
+	Skip(func(ctx *Context, next func() error) error { return next() })
+}
@@ -1,168 +1,176 @@
 package chain
 
 import (
-	"sort"
 	"strings"
 )
 
+// RouteStorage stores the dynamic routes (with parameters or wildcard) in a tree of segments, the lookup only visits
+// the branches compatible with the path, regardless of the number of routes registered.
+//
+// When more than one route matches the path, the route with the highest priority is used (see RouteInfo.Priority),
+// routes with the same priority are evaluated in the registration order.
 type RouteStorage struct {
-	routes map[int][]*Route // by num of segments
+	root  *routeNode
+	count int // number of routes added, used as registration order
 }
 
-func (s *RouteStorage) add(route *Route) {
-	details := route.Info
+// routeNode a segment of the tree
+type routeNode struct {
+	static   map[string]*routeNode // children by static segment
+	param    *routeNode            // child for the parameter segment (":name")
+	route    *storedRoute          // route that ends on this node
+	wildcard *storedRoute          // catch-all route ("*name") of the next segment
+	priority int                   // the highest priority of the routes of this subtree, used to prune the lookup
+}
 
-	numSegments := len(details.segments)
-	if s.routes == nil {
-		s.routes = map[int][]*Route{}
-	}
-	if s.routes[numSegments] == nil {
-		s.routes[numSegments] = []*Route{}
-
-		// inserts wildcards from lower levels into this list
-		for oNumSegments, routes := range s.routes {
-			if oNumSegments < numSegments {
-				for _, other := range routes {
-					if other.Info.hasWildcard {
-						s.routes[numSegments] = append(s.routes[numSegments], other)
-					}
-				}
-			}
-		}
-	}
-	s.routes[numSegments] = append(s.routes[numSegments], route)
+type storedRoute struct {
+	route *Route
+	order int
+}
 
-	sort.Slice(s.routes[numSegments], func(i, j int) bool {
-		// high priority at the beginning'
-		return s.routes[numSegments][i].Info.priority > s.routes[numSegments][j].Info.priority
-	})
+// routeMatch the best route found by the lookup
+type routeMatch struct {
+	found *storedRoute
+}
 
-	if details.hasWildcard {
-		// inserts this new path in the upper segments and does the reordering
-		for oNumSegments, _ := range s.routes {
-			if oNumSegments > numSegments {
-				s.routes[oNumSegments] = append(s.routes[oNumSegments], route)
-				sort.Slice(s.routes[oNumSegments], func(i, j int) bool {
-					return s.routes[oNumSegments][i].Info.priority > s.routes[oNumSegments][j].Info.priority
-				})
-			}
-		}
+func (m *routeMatch) offer(candidate *storedRoute) {
+	if candidate == nil {
+		return
+	}
+	if m.found == nil || candidate.route.Info.priority > m.found.route.Info.priority ||
+		(candidate.route.Info.priority == m.found.route.Info.priority && candidate.order < m.found.order) {
+		m.found = candidate
 	}
 }
 
-func (s *RouteStorage) lookup(ctx *Context) *Route {
+// skip checks if the subtree cannot have a better route than the current
+func (m *routeMatch) skip(node *routeNode) bool {
+	return m.found != nil && node.priority < m.found.route.Info.priority
+}
 
-	if s.routes == nil {
-		return nil
+func (s *RouteStorage) add(route *Route) {
+	priority := route.Info.priority
+	if s.root == nil {
+		s.root = &routeNode{priority: priority}
 	}
 
-	var (
-		path          = ctx.path
-		segments      = ctx.pathSegments
-		segmentsCount = ctx.pathSegmentsCount
-	)
+	stored := &storedRoute{route: route, order: s.count}
+	s.count++
 
-	for i := segmentsCount; i > 0; i-- {
-		routes := s.routes[i]
-		if routes == nil {
-			continue
+	node := s.root
+	for _, segment := range route.Info.segments {
+		if priority > node.priority {
+			node.priority = priority
 		}
 
-	nextRoute:
-		for _, route := range routes {
-			details := route.Info
-			if !details.hasWildcard && i < segmentsCount {
-				// at this point it's just looking for the wildcard that satisfies this route
-				continue
-			}
-
-			// same effect as ` !details.FastMatch(ctx)`, but faster
-
-			for j, segment := range details.segments {
-				if strings.IndexByte(segment, wildcard) == 0 {
-					break
-				}
-
-				if strings.IndexByte(segment, parameter) == 0 && ctx.path[ctx.pathSegments[j]+1:ctx.pathSegments[j+1]] != "" {
-					continue
-				}
+		if strings.IndexByte(segment, wildcard) == 0 {
+			node.wildcard = stored
+			return
+		}
 
-				if segment != ctx.path[ctx.pathSegments[j]+1:ctx.pathSegments[j+1]] {
-					continue nextRoute
-				}
+		var child *routeNode
+		if strings.IndexByte(segment, parameter) == 0 {
+			if node.param == nil {
+				node.param = &routeNode{priority: priority}
 			}
-
-			// found, populate parameters
-			if details.hasWildcard {
-				for j, index := range details.paramsIndex {
-					if j == len(details.paramsIndex)-1 {
-						ctx.addParameter(details.params[j], path[segments[index]:])
-						break
-					}
-					ctx.addParameter(details.params[j], path[segments[index]+1:segments[index+1]])
-				}
-			} else {
-				for j, index := range details.paramsIndex {
-					ctx.addParameter(details.params[j], path[segments[index]+1:segments[index+1]])
-				}
+			child = node.param
+		} else {
+			if node.static == nil {
+				node.static = map[string]*routeNode{}
+			}
+			if child = node.static[segment]; child == nil {
+				child = &routeNode{priority: priority}
+				node.static[segment] = child
 			}
-
-			return route
 		}
-
-		// it only does the search in a single height
-		break
+		node = child
 	}
 
-	return nil
+	if priority > node.priority {
+		node.priority = priority
+	}
+	node.route = stored
 }
 
-func (s *RouteStorage) lookupCaseInsensitive(ctx *Context) *Route {
+func (s *RouteStorage) lookup(ctx *Context) *Route {
+	if s.root == nil || !validLookupPath(ctx) {
+		return nil
+	}
 
-	if s.routes == nil {
+	match := &routeMatch{}
+	s.root.find(ctx, 0, false, match)
+	if match.found == nil {
 		return nil
 	}
 
+	// found, populate parameters
 	var (
-		segmentsCount = ctx.pathSegmentsCount
+		route    = match.found.route
+		details  = route.Info
+		path     = ctx.path
+		segments = ctx.pathSegments
 	)
-
-	for i := segmentsCount; i > 0; i-- {
-		routes := s.routes[i]
-		if routes == nil {
-			continue
+	if details.hasWildcard {
+		for j, index := range details.paramsIndex {
+			if j == len(details.paramsIndex)-1 {
+				ctx.addParameter(details.params[j], ctx.wildcardValue(path[segments[index]:]))
+				break
+			}
+			ctx.addParameter(details.params[j], path[segments[index]+1:segments[index+1]])
+		}
+	} else {
+		for j, index := range details.paramsIndex {
+			ctx.addParameter(details.params[j], path[segments[index]+1:segments[index+1]])
 		}
+	}
 
-	nextRoute:
-		for _, route := range routes {
-			details := route.Info
-			if !details.hasWildcard && i < segmentsCount {
-				// at this point it's just looking for the wildcard that satisfies this route
-				continue
-			}
+	return route
+}
 
-			// same effect as ` !details.FastMatch(ctx)`, but faster
+func (s *RouteStorage) lookupCaseInsensitive(ctx *Context) *Route {
+	if s.root == nil || !validLookupPath(ctx) {
+		return nil
+	}
 
-			for j, segment := range details.segments {
-				if strings.IndexByte(segment, wildcard) == 0 {
-					break
-				}
+	match := &routeMatch{}
+	s.root.find(ctx, 0, true, match)
+	if match.found == nil {
+		return nil
+	}
+	return match.found.route
+}
 
-				if strings.IndexByte(segment, parameter) == 0 && ctx.path[ctx.pathSegments[j]+1:ctx.pathSegments[j+1]] != "" {
-					continue
-				}
+// validLookupPath checks if the segments of the path are valid (ex. empty path or without the leading slash)
+func validLookupPath(ctx *Context) bool {
+	return ctx.pathSegments[ctx.pathSegmentsCount] <= len(ctx.path)
+}
 
-				if !strings.EqualFold(segment, ctx.path[ctx.pathSegments[j]+1:ctx.pathSegments[j+1]]) {
-					continue nextRoute
-				}
-			}
+// find visits the branches compatible with the segment `i` of the path
+func (n *routeNode) find(ctx *Context, i int, fold bool, match *routeMatch) {
+	if match.skip(n) {
+		return
+	}
 
-			return route
-		}
+	if i == ctx.pathSegmentsCount {
+		match.offer(n.route)
+		return
+	}
+
+	// `/assets/*` vs `/assets/js/chain.js`
+	match.offer(n.wildcard)
 
-		// it only does the search in a single height
-		break
+	segment := ctx.path[ctx.pathSegments[i]+1 : ctx.pathSegments[i+1]]
+	if fold {
+		for key, child := range n.static {
+			if strings.EqualFold(key, segment) {
+				child.find(ctx, i+1, fold, match)
+			}
+		}
+	} else if child, exists := n.static[segment]; exists {
+		child.find(ctx, i+1, fold, match)
 	}
 
-	return nil
+	if n.param != nil && segment != "" {
+		n.param.find(ctx, i+1, fold, match)
+	}
 }
@@ -1,6 +1,9 @@
 package chain
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 )
@@ -190,3 +193,103 @@ func Test_PathInfo_Match(t *testing.T) {
 		})
 	}
 }
+
+func Test_Route_Accessors(t *testing.T) {
+	router := New()
+	router.Use("/api/*", func(ctx *Context, next func() error) error { return next() })
+	router.Use(PriorityFirst, "/api/users/:id", func(ctx *Context, next func() error) error { return next() })
+	router.GET("/api/users/:id", fakeHandler("user"))
+
+	route, _ := router.Lookup("GET", "/api/users/42")
+	if route == nil {
+		t.Fatal("Route lookup failed")
+	}
+	if route.Path() != "/api/users/:id" {
+		t.Errorf("Route.Path() failed: Invalid path\n   actual: %v\n expected: %v", route.Path(), "/api/users/:id")
+	}
+	if route.Pattern() != "/api/users/:" {
+		t.Errorf("Route.Pattern() failed: Invalid pattern\n   actual: %v\n expected: %v", route.Pattern(), "/api/users/:")
+	}
+
+	var patterns []string
+	for _, middleware := range route.Middlewares() {
+		patterns = append(patterns, middleware.Pattern())
+	}
+	if expected := []string{"/api/users/:", "/api/*"}; !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Route.Middlewares() failed: Invalid middlewares\n   actual: %v\n expected: %v", patterns, expected)
+	}
+
+	// the slice returned is a copy
+	route.Middlewares()[0] = nil
+	if route.Middlewares()[0] == nil {
+		t.Errorf("Route.Middlewares() failed: Internal slice was changed")
+	}
+}
+
+func Test_Router_Walk(t *testing.T) {
+	router := New()
+	router.GET("/users/:id", fakeHandler("user"))
+	router.GET("/health", fakeHandler("health"))
+	router.POST("/users", fakeHandler("create"))
+
+	var visited []string
+	err := router.Walk(func(method string, route *Route) error {
+		visited = append(visited, method+" "+route.Path())
+		route.Wrap(func(next Handle) Handle {
+			return func(ctx *Context) error {
+				if err := next(ctx); err != nil {
+					return err
+				}
+				fakeHandlerValue = "wrapped:" + fakeHandlerValue
+				return nil
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Router.Walk() failed: Unexpected error\n   actual: %v", err)
+	}
+	if expected := []string{"GET /health", "GET /users/:id", "POST /users"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Router.Walk() failed: Invalid routes\n   actual: %v\n expected: %v", visited, expected)
+	}
+
+	checkRequests(t, router, tRequest{path: "/users/42", route: "wrapped:user"})
+	checkRequests(t, router, tRequest{path: "/health", route: "wrapped:health"})
+
+	stop := errors.New("stop")
+	count := 0
+	if err = router.Walk(func(method string, route *Route) error { count++; return stop }); err != stop || count != 1 {
+		t.Errorf("Router.Walk() failed: Invalid error\n   actual: %v (%d calls)\n expected: %v (1 call)", err, count, stop)
+	}
+}
+
+func Test_Router_Walk_Concurrent_Requests(t *testing.T) {
+	router := New()
+	router.GET("/health", func(ctx *Context) error { return nil })
+
+	stop := make(chan bool)
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				req, _ := http.NewRequest(http.MethodGet, "/health", nil)
+				router.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		_ = router.Walk(func(method string, route *Route) error {
+			route.Wrap(func(next Handle) Handle {
+				return next
+			})
+			return nil
+		})
+	}
+	close(stop)
+	<-done
+}
@@ -8,21 +8,52 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/nidorx/chain/crypto"
 	"github.com/nidorx/chain/pkg"
 )
 
 // Router is a high-performance router.
 type Router struct {
-	registries map[string]*Registry
+	registries      map[string]*Registry // registries being built, published on the next request (see routeTable)
+	registriesMutex sync.Mutex
+	table           atomic.Pointer[routeTable] // immutable snapshot of the registries, used to serve the requests
+	tableStale      atomic.Bool                // the registries were changed after the table was published
 
 	contextPool sync.Pool
 
 	Crypto cryptoImpl
 
+	// Keyring used by Context.Keyring. If it is not set, a Keyring derived from the router SecretKeyBase is used. The
+	// components of this router that need crypto keys (ex. the session Cookie store) never use it, their keys are
+	// derived for their own purpose (see DeriveKeyring).
+	Keyring *crypto.Keyring
+
+	// Defaults of the cookies created by Context.SetSecureCookie and by the session middleware
+	Cookie CookieOptions
+
+	secretKeys     atomic.Pointer[secretKeyStore] // see SetSecretKeyBase, when nil the global SecretKeyBase is used
+	trustedProxies atomic.Pointer[[]netip.Prefix] // see SetTrustedProxies
+	defaultKeyring *crypto.Keyring
+	keyringOnce    sync.Once
+
+	dispatchHooks dispatchHooks // see OnDispatch
+
+	beforeRouting []func(req *http.Request) *http.Request // see BeforeRouting
+
+	shutdownHooks shutdownHooks // see OnShutdown
+
+	fallbacks fallbacks // see Fallback
+
+	errorPages errorPages // see ErrorPage
+
 	// ConnContext optionally specifies a function that modifies
 	// the context used for a new connection c. The provided ctx
 	// is derived from the base context and has a ServerContextKey
@@ -33,9 +64,6 @@ type Router struct {
 	// the context used for the request.
 	ReqContext func(*Context) context.Context
 
-	// Cached value of global (*) getAllowedHeader methods
-	globalAllowed string
-
 	// If enabled, the router automatically replies to OPTIONS requests.
 	// Custom OPTIONS handlers take priority over automatic replies.
 	HandleOPTIONS bool
@@ -55,6 +83,16 @@ type Router struct {
 	// status code 301 for GET requests and 308 for all other request methods.
 	RedirectTrailingSlash bool
 
+	// If enabled, the wildcard params (ex. "/assets/*filepath") are normalized as Context.GetWildcard does, without the
+	// leading slash ("js/app.js" instead of "/js/app.js") and with the dot segments resolved. By default, the params
+	// keep the legacy behavior of returning the raw remainder of the path, with the leading slash.
+	CleanWildcard bool
+
+	// If enabled, HEAD requests for paths that only have a GET route are served by the GET handler. The response body
+	// is discarded, while the headers (and the Content-Length of the discarded body) are sent, as net/http does.
+	// HEAD routes registered explicitly take priority.
+	AutoHEAD bool
+
 	// If enabled, the router checks if another method is allowed for the current route, if the current request can not
 	// be routed.
 	// If this is the case, the request is answered with 'Method Not Allowed' and HTTP status code 405.
@@ -64,12 +102,27 @@ type Router struct {
 	// Function to handle panics recovered from http handlers.
 	// It should be used to generate a error page and return the http error code 500 (Internal Server Error).
 	// The handler can be used to keep your server from crashing because of unrecovered panics.
+	//
+	// Deprecated: use Recovery. When set, PanicHandler takes precedence over Recovery.
 	PanicHandler func(http.ResponseWriter, *http.Request, any)
 
+	// Handles panics recovered from http handlers and middlewares (stack capture, logging, error response and hooks
+	// for error trackers). If it is not set, a Recovery with the default settings is used.
+	Recovery *Recovery
+
 	// Function to handle errors recovered from http handlers and middlewares.
 	// The handler can be used to do global error handling (not handled in middlewares)
 	ErrorHandler func(*Context, error)
 
+	// Status written when the handler returns without writing a response. Default http.StatusOK. Use NoImplicitStatus
+	// to leave the response untouched (ex. when the router is wrapped by another http.Handler that writes the
+	// response). Can be overridden per route, see WithImplicitStatus.
+	ImplicitStatus int
+
+	// Evaluates the feature flags of the requests, see Context.FeatureEnabled and RequireFeature. When it is not set,
+	// all flags are disabled.
+	FeatureFlags FeatureFlags
+
 	// Configurable http.Handler function which is called when no matching route is found. If it is not set, http.NotFound is
 	// used.
 	NotFoundHandler http.Handler
@@ -89,39 +142,58 @@ func (r *Router) Group(route string) Group {
 	return &RouterGroup{p: route, r: r}
 }
 
+// RequireTLS returns a group (without prefix) whose routes are served only for HTTPS requests, see
+// RouterGroup.RequireTLS
+func (r *Router) RequireTLS(redirect bool) Group {
+	return r.Group("").RequireTLS(redirect)
+}
+
+// Host returns a group (without prefix) whose routes are served only for the hosts, see RouterGroup.Host
+func (r *Router) Host(hosts ...string) Group {
+	return r.Group("").Host(hosts...)
+}
+
 // GET is a shortcut for router.handleFunc(http.MethodGet, Route, handle)
-func (r *Router) GET(route string, handle any) error {
-	return r.Handle(http.MethodGet, route, handle)
+func (r *Router) GET(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodGet, route, handle, options...)
 }
 
 // HEAD is a shortcut for router.handleFunc(http.MethodHead, Route, handle)
-func (r *Router) HEAD(route string, handle any) error {
-	return r.Handle(http.MethodHead, route, handle)
+func (r *Router) HEAD(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodHead, route, handle, options...)
 }
 
 // OPTIONS is a shortcut for router.handleFunc(http.MethodOptions, Route, handle)
-func (r *Router) OPTIONS(route string, handle any) error {
-	return r.Handle(http.MethodOptions, route, handle)
+func (r *Router) OPTIONS(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodOptions, route, handle, options...)
 }
 
 // POST is a shortcut for router.handleFunc(http.MethodPost, Route, handle)
-func (r *Router) POST(route string, handle any) error {
-	return r.Handle(http.MethodPost, route, handle)
+func (r *Router) POST(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodPost, route, handle, options...)
 }
 
 // PUT is a shortcut for router.handleFunc(http.MethodPut, Route, handle)
-func (r *Router) PUT(route string, handle any) error {
-	return r.Handle(http.MethodPut, route, handle)
+func (r *Router) PUT(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodPut, route, handle, options...)
 }
 
 // PATCH is a shortcut for router.handleFunc(http.MethodPatch, Route, handle)
-func (r *Router) PATCH(route string, handle any) error {
-	return r.Handle(http.MethodPatch, route, handle)
+func (r *Router) PATCH(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodPatch, route, handle, options...)
 }
 
 // DELETE is a shortcut for router.handleFunc(http.MethodDelete, Route, handle)
-func (r *Router) DELETE(route string, handle any) error {
-	return r.Handle(http.MethodDelete, route, handle)
+func (r *Router) DELETE(route string, handle any, options ...RouteOption) error {
+	return r.Handle(http.MethodDelete, route, handle, options...)
+}
+
+// BeforeRouting registers a function invoked by ServeHTTP before the route lookup, allowing the request to be
+// modified (ex. URL rewrite, see middlewares/rewrite). The function returns the request that will be routed (nil keeps
+// the original request). Functions are invoked in the order they are registered, and must be registered before the
+// router starts serving requests.
+func (r *Router) BeforeRouting(fn func(req *http.Request) *http.Request) {
+	r.beforeRouting = append(r.beforeRouting, fn)
 }
 
 // Configure allows a RouteConfigurator to perform route configurations
@@ -136,8 +208,9 @@ var (
 	ErrHandlerIsNil   = errors.New("handle must not be nil")
 )
 
-// Handle registers a new Route for the given method and path.
-func (r *Router) Handle(method string, route string, handle any) error {
+// Handle registers a new Route for the given method and path. The options define per-endpoint policies (see
+// RouteOption).
+func (r *Router) Handle(method string, route string, handle any, options ...RouteOption) error {
 	method = strings.TrimSpace(method)
 	if method == "" {
 		return ErrInvalidMethod
@@ -153,6 +226,14 @@ func (r *Router) Handle(method string, route string, handle any) error {
 		return ErrHandlerIsNil
 	}
 
+	handler, err := Handler(handle)
+	if err != nil {
+		return err
+	}
+
+	r.registriesMutex.Lock()
+	defer r.registriesMutex.Unlock()
+
 	if r.registries == nil {
 		r.registries = make(map[string]*Registry)
 	}
@@ -161,16 +242,9 @@ func (r *Router) Handle(method string, route string, handle any) error {
 	if registry == nil {
 		registry = &Registry{}
 		r.registries[method] = registry
-
-		// refresh cache of methods allowed
-		r.globalAllowed = r.getAllowedHeader("*", "", nil)
-	}
-
-	if handler, err := Handler(handle); err != nil {
-		return err
-	} else {
-		registry.addHandle(route, handler)
 	}
+	registry.addHandle(route, handler, options)
+	r.tableStale.Store(true)
 
 	return nil
 }
@@ -233,9 +307,34 @@ func Handler(handle any) (h Handle, err error) {
 //	    return ctx.NextFunc()
 //	})
 func (r *Router) Use(args ...any) Group {
+	return r.use(nil, args)
+}
+
+// UseIf same as Router.Use, but the middlewares are only executed when the predicate returns true, otherwise they are
+// skipped (the next middleware is invoked). The predicate is evaluated for each request, before the middleware.
+//
+// ## Example
+//
+//	// validates the CSRF token only for form submissions
+//	router.UseIf(func(ctx *chain.Context) bool {
+//		return ctx.Request.Header.Get("Content-Type") == "application/x-www-form-urlencoded"
+//	}, "/admin/*", csrfMiddleware)
+//
+//	// feature flag
+//	router.UseIf(func(ctx *chain.Context) bool { return flags.Enabled("audit") }, auditMiddleware)
+func (r *Router) UseIf(predicate func(ctx *Context) bool, args ...any) Group {
+	if predicate == nil {
+		panic("[chain] UseIf requires a predicate")
+	}
+	return r.use(predicate, args)
+}
+
+func (r *Router) use(predicate func(ctx *Context) bool, args []any) Group {
 	var path string
 	var methodP string
+	var priority Priority
 	var middlewares []func(ctx *Context, next func() error) error
+	var sources []any // the registered middlewares, see Skip
 
 	for i := 0; i < len(args); i++ {
 		switch arg := args[i].(type) {
@@ -246,6 +345,8 @@ func (r *Router) Use(args ...any) Group {
 				methodP = path
 				path = arg
 			}
+		case Priority:
+			priority = arg
 		case func():
 			middlewares = append(middlewares, func(ctx *Context, next func() error) error {
 				arg()
@@ -307,6 +408,15 @@ func (r *Router) Use(args ...any) Group {
 		default:
 			panic(fmt.Sprintf("[chain] invalid middleware. middleware: %s", reflect.TypeOf(arg).String()))
 		}
+		if len(middlewares) > len(sources) {
+			sources = append(sources, args[i])
+		}
+	}
+
+	if predicate != nil {
+		for i, middleware := range middlewares {
+			middlewares[i] = conditionalMiddleware(predicate, middleware)
+		}
 	}
 
 	var methods []string
@@ -331,6 +441,9 @@ func (r *Router) Use(args ...any) Group {
 		path = "/*"
 	}
 
+	r.registriesMutex.Lock()
+	defer r.registriesMutex.Unlock()
+
 	if r.registries == nil {
 		r.registries = make(map[string]*Registry)
 	}
@@ -341,15 +454,84 @@ func (r *Router) Use(args ...any) Group {
 			registry = &Registry{}
 			r.registries[method] = registry
 		}
-		registry.addMiddleware(path, middlewares)
+		registry.addMiddleware(path, priority, middlewares, sources)
 	}
+	r.tableStale.Store(true)
 
 	return r
 }
 
+// Routes returns the routes registered for the method, in the order they are evaluated: static routes first, followed
+// by the dynamic routes ordered by priority (see RouteInfo.Priority). Useful for debugging the routing.
+//
+// ## Example
+//
+//	for _, route := range router.Routes(http.MethodGet) {
+//		fmt.Printf("%s %d (computed %d)\n", route.Info.Path(), route.Info.Priority(), route.Info.ComputedPriority())
+//	}
+func (r *Router) Routes(method string) []*Route {
+	if registry := r.routeTable().registries[method]; registry != nil {
+		return registry.sortedRoutes()
+	}
+	return nil
+}
+
+// Walk invokes fn for each registered route (all methods, in the order they are evaluated), allowing tooling to
+// inspect, wrap or decorate the routes at startup (see Route.Wrap). The changes made on the routes are applied to the
+// next requests. Stops on the first error returned by fn.
+//
+// fn runs with the routes of the router locked, so the requests served concurrently never see a route being changed.
+// It must not register routes or middlewares (ex. Router.GET, Router.Use), that would deadlock.
+//
+// Unlike Walk, the routes returned by Routes are a snapshot of the routes being served, changes on them are discarded.
+//
+// ## Example
+//
+//	router.Walk(func(method string, route *chain.Route) error {
+//		for _, middleware := range route.Middlewares() {
+//			fmt.Printf("%s %s <- %s\n", method, route.Pattern(), middleware.Pattern())
+//		}
+//		return nil
+//	})
+func (r *Router) Walk(fn func(method string, route *Route) error) error {
+	r.registriesMutex.Lock()
+	defer r.registriesMutex.Unlock()
+
+	methods := make([]string, 0, len(r.registries))
+	for method := range r.registries {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	// fn can change the routes, the table is published again after the lock is released (see publishRouteTable)
+	defer r.tableStale.Store(true)
+
+	for _, method := range methods {
+		for _, route := range r.registries[method].sortedRoutes() {
+			if err := fn(method, route); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// conditionalMiddleware skips the middleware when the predicate returns false
+func conditionalMiddleware(
+	predicate func(ctx *Context) bool,
+	middleware func(ctx *Context, next func() error) error,
+) func(ctx *Context, next func() error) error {
+	return func(ctx *Context, next func() error) error {
+		if !predicate(ctx) {
+			return next()
+		}
+		return middleware(ctx, next)
+	}
+}
+
 // Lookup finds the Route and parameters for the given Route and assigns them to the given Context.
 func (r *Router) Lookup(method string, path string) (*Route, *Context) {
-	if registry := r.registries[method]; registry != nil {
+	if registry := r.routeTable().registries[method]; registry != nil {
 		ctx := r.poolGetContext(nil, nil, path)
 		ctx.parsePathSegments()
 		if route := registry.findHandle(ctx); route != nil {
@@ -383,50 +565,79 @@ func (r *Router) updateContext(ctx *Context) *http.Request {
 // ServeHTTP responds to the given request.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
-	rw := &ResponseWriterSpy{ResponseWriter: w}
+	rw := &ResponseWriterSpy{ResponseWriter: w, started: time.Now()}
 	w = rw
 	var ctx *Context
+	var head *headResponseWriter
+
+	// execute after write hooks, also when the recovery panics
+	defer rw.execAfterWriteHooksCalledByRouter()
 
 	defer func() {
 		if rcv := recover(); rcv != any(nil) {
+			if rcv == any(http.ErrAbortHandler) {
+				// let the http.Server abort the response without logging
+				panic(rcv)
+			}
+			info := newPanicInfo(req, rcv)
+			rw.err = info
 			if r.PanicHandler != nil {
 				r.PanicHandler(w, req, rcv)
+			} else if r.Recovery != nil {
+				r.Recovery.handle(ctx, rw, req, info)
 			} else {
-				w.WriteHeader(http.StatusInternalServerError)
+				defaultRecovery.handle(ctx, rw, req, info)
 			}
 		} else if !rw.writeStarted && ctx != nil {
 			// if necessary, write header on exit
 			ctx.write()
 		}
 
-		// execute after write hooks
-		rw.execAfterWriteHooksCalledByRouter()
+		if head != nil {
+			head.finish()
+		}
 	}()
 
+	for _, fn := range r.beforeRouting {
+		if rewritten := fn(req); rewritten != nil {
+			req = rewritten
+		}
+	}
+
 	ctx = r.poolGetContext(req, w, "")
 	ctx.parsePathSegments()
 
+	done := req.Context().Done()
 	go func() {
 		// clear context when connection is closed
-		<-ctx.Request.Context().Done()
+		<-done
 		r.poolPutContext(ctx)
 	}()
 
 	path := req.URL.Path
 
-	if registry := r.registries[req.Method]; registry != nil {
+	table := r.routeTable()
+	registry := table.registries[req.Method]
+	if registry != nil {
 		if route := registry.findHandle(ctx); route != nil {
-			ctx.Route = route.Info
-			r.updateContext(ctx)
-			if err := route.Dispatch(ctx); err != nil {
-				if r.ErrorHandler != nil {
-					r.ErrorHandler(ctx, err)
-				} else {
-					w.WriteHeader(http.StatusInternalServerError)
-				}
-			}
+			r.dispatch(ctx, route)
 			return
-		} else if req.Method != http.MethodConnect && path != "/" {
+		}
+	}
+
+	if req.Method == http.MethodHead && r.AutoHEAD {
+		if getRegistry := table.registries[http.MethodGet]; getRegistry != nil {
+			if route := getRegistry.findHandle(ctx); route != nil {
+				head = &headResponseWriter{ResponseWriter: rw.ResponseWriter}
+				rw.ResponseWriter = head
+				r.dispatch(ctx, route)
+				return
+			}
+		}
+	}
+
+	if registry != nil {
+		if req.Method != http.MethodConnect && path != "/" {
 			// Moved Permanently, request with GET method
 			code := http.StatusMovedPermanently
 			if req.Method != http.MethodGet {
@@ -481,7 +692,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	req = r.updateContext(ctx)
 	if req.Method == http.MethodOptions && r.HandleOPTIONS {
 		// Handle OPTIONS requests
-		if allow := r.getAllowedHeader(path, http.MethodOptions, ctx); allow != "" {
+		if allow := r.getAllowedHeader(table, path, http.MethodOptions, ctx); allow != "" {
 			w.Header().Set("Allow", allow)
 			if r.GlobalOPTIONSHandler != nil {
 				r.GlobalOPTIONSHandler.ServeHTTP(w, req)
@@ -489,11 +700,14 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 	} else if r.HandleMethodNotAllowed { // Handle 405
-		if allow := r.getAllowedHeader(path, req.Method, ctx); allow != "" {
+		if allow := r.getAllowedHeader(table, path, req.Method, ctx); allow != "" {
 			w.Header().Set("Allow", allow)
+			if r.serveFallback(rw, req, path, true) {
+				return
+			}
 			if r.MethodNotAllowedHandler != nil {
 				r.MethodNotAllowedHandler.ServeHTTP(w, req)
-			} else {
+			} else if !r.renderErrorPage(ctx, http.StatusMethodNotAllowed, nil) {
 				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 			}
 			return
@@ -501,10 +715,25 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	// Handle 404
-	if r.NotFoundHandler != nil {
-		r.NotFoundHandler.ServeHTTP(w, req)
-	} else {
-		http.NotFound(w, req)
+	if r.serveFallback(rw, req, path, false) {
+		return
+	}
+	r.notFound(ctx, w, req)
+}
+
+// dispatch executes the route handler
+func (r *Router) dispatch(ctx *Context, route *Route) {
+	ctx.Route = route.Info
+	r.updateContext(ctx)
+	if err := route.Dispatch(ctx); err != nil {
+		if spy, ok := ctx.Writer.(*ResponseWriterSpy); ok {
+			spy.err = err
+		}
+		if r.ErrorHandler != nil {
+			r.ErrorHandler(ctx, err)
+		} else if ctx.WriteStarted() || !r.renderErrorPage(ctx, http.StatusInternalServerError, err) {
+			ctx.Writer.WriteHeader(http.StatusInternalServerError)
+		}
 	}
 }
 
@@ -540,28 +769,39 @@ func (r *Router) poolPutContext(ctx *Context) {
 	ctx.Request = nil
 	ctx.data = nil
 	ctx.parent = nil
+	ctx.aborted = false
+	ctx.implicitStatus = 0
+	ctx.negotiated = ""
+	ctx.shareData = false
+	ctx.query = nil
+	ctx.queryRaw = ""
 	r.contextPool.Put(ctx)
 }
 
-func (r *Router) getAllowedHeader(path string, reqMethod string, ctx *Context) (allow string) {
+func (r *Router) getAllowedHeader(table *routeTable, path string, reqMethod string, ctx *Context) (allow string) {
 	allowed := make([]string, 0, 9)
 
 	if path == "*" {
 		// server-wide
 		// empty method is used for internal calls to refresh the cache
 		if reqMethod == "" {
-			for method := range r.registries {
+			for method := range table.registries {
 				if method == http.MethodOptions {
 					continue
 				}
 				// Add request method to list of allowed methods
 				allowed = append(allowed, method)
 			}
+			if _, hasHead := table.registries[http.MethodHead]; !hasHead && r.AutoHEAD && table.registries[http.MethodGet] != nil {
+				allowed = append(allowed, http.MethodHead)
+			}
 		} else {
-			return r.globalAllowed
+			return table.globalAllowed
 		}
 	} else { // specific path
-		for method, registry := range r.registries {
+		autoHead := r.AutoHEAD && reqMethod != http.MethodHead
+		hasGet, hasHead := false, false
+		for method, registry := range table.registries {
 			// Skip the requested method - we already tried this one
 			if method == reqMethod || method == http.MethodOptions {
 				continue
@@ -570,8 +810,14 @@ func (r *Router) getAllowedHeader(path string, reqMethod string, ctx *Context) (
 			if route := registry.findHandle(ctx); route != nil {
 				// Add request method to list of allowed methods
 				allowed = append(allowed, method)
+				hasGet = hasGet || method == http.MethodGet
+				hasHead = hasHead || method == http.MethodHead
 			}
 		}
+
+		if autoHead && hasGet && !hasHead {
+			allowed = append(allowed, http.MethodHead)
+		}
 	}
 
 	if len(allowed) > 0 {
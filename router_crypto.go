@@ -0,0 +1,150 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/nidorx/chain/crypto"
+)
+
+// DefaultKeyringSalt salt of the Keyring returned by Context.Keyring when Router.Keyring is not set
+const DefaultKeyringSalt = "chain.router.keyring.salt"
+
+var (
+	globalKeyring     *crypto.Keyring // Keyring of contexts created without router
+	globalKeyringOnce sync.Once
+)
+
+// SetSecretKeyBase defines a SecretKeyBase exclusive to this router, instead of the global one (see chain.SetSecretKeyBase).
+// Allows multi-tenant deployments to use different keys per router. Can be invoked again to rotate the key.
+//
+// Keyrings created before the first invocation (ex. middlewares registered with Use) remain bound to the global
+// SecretKeyBase, so it must be defined before configuring the router.
+//
+// ## Example
+//
+//	router := chain.New()
+//	if err := router.SetSecretKeyBase("--- 32 BYTES SECRET KEY BASE ---"); err != nil {
+//		panic(err)
+//	}
+//	router.Use(&session.Manager{Store: &session.Cookie{}})
+func (r *Router) SetSecretKeyBase(secret string) error {
+	secrets := r.secretKeys.Load()
+	if secrets == nil {
+		secrets = &secretKeyStore{}
+		if err := secrets.set(secret); err != nil {
+			return err
+		}
+		if r.secretKeys.CompareAndSwap(nil, secrets) {
+			return nil
+		}
+		secrets = r.secretKeys.Load()
+	}
+	return secrets.set(secret)
+}
+
+// HasSecretKeyBase checks if this router defines its own SecretKeyBase (see SetSecretKeyBase)
+func (r *Router) HasSecretKeyBase() bool {
+	return r.secretKeys.Load() != nil
+}
+
+// SecretKeyBase the primary SecretKeyBase of this router, or the global chain.SecretKeyBase if the router does not
+// define its own.
+func (r *Router) SecretKeyBase() string {
+	return r.secrets().primary()
+}
+
+// SecretKeys all SecretKeyBase of this router (or the global ones), the LAST item is the primary key.
+func (r *Router) SecretKeys() []string {
+	return r.secrets().all()
+}
+
+// SecretKeySync same as chain.SecretKeySync, using the SecretKeyBase of this router
+func (r *Router) SecretKeySync(sync SecretKeySyncFunc) (cancel func()) {
+	return r.secrets().sync(sync)
+}
+
+// NewKeyring same as chain.NewKeyring, deriving the keys from the SecretKeyBase of this router
+func (r *Router) NewKeyring(salt string, iterations int, length int, digest string) *crypto.Keyring {
+	if iterations < 1 {
+		iterations = 1000
+	}
+	if digest == "" {
+		digest = "sha256"
+	}
+	return r.NewKeyringKDF(salt, length, crypto.PBKDF2{Iterations: iterations, Digest: digest})
+}
+
+// NewKeyringKDF same as chain.NewKeyringKDF, deriving the keys from the SecretKeyBase of this router
+func (r *Router) NewKeyringKDF(salt string, length int, kdf crypto.KDF) *crypto.Keyring {
+	return newKeyring(r.secrets(), salt, length, kdf)
+}
+
+// DeriveKeyring creates a Keyring exclusive to a purpose (ex. the session cookies), with keys derived from the
+// SecretKeyBase of this router (or the global one) and the salt of the purpose. The kdf defaults to crypto.PBKDF2.
+//
+// Components must use it instead of sharing the Router.Keyring, that the application uses to sign arbitrary data (see
+// Context.Keyring): a signature of user input would be a valid session cookie or token. Can be invoked on a nil
+// Router (components initialized without router), using the global SecretKeyBase.
+//
+// ## Example
+//
+//	func (s *MyStore) Init(config session.Config, router *chain.Router) error {
+//		s.keyring = router.DeriveKeyring("myapp.session.store.salt", 32, nil)
+//		return nil
+//	}
+func (r *Router) DeriveKeyring(salt string, length int, kdf crypto.KDF) *crypto.Keyring {
+	if kdf == nil {
+		kdf = crypto.PBKDF2{Iterations: 1000, Digest: "sha256"}
+	}
+	if r == nil {
+		return NewKeyringKDF(salt, length, kdf)
+	}
+	return r.NewKeyringKDF(salt, length, kdf)
+}
+
+func (r *Router) secrets() *secretKeyStore {
+	if secrets := r.secretKeys.Load(); secrets != nil {
+		return secrets
+	}
+	return secretKeys
+}
+
+// keyring the Router.Keyring or a Keyring derived from the router SecretKeyBase with the DefaultKeyringSalt
+func (r *Router) keyring() *crypto.Keyring {
+	if r.Keyring != nil {
+		return r.Keyring
+	}
+	r.keyringOnce.Do(func() {
+		r.defaultKeyring = r.NewKeyring(DefaultKeyringSalt, 1000, 32, "sha256")
+	})
+	return r.defaultKeyring
+}
+
+// SecretKeyBase the SecretKeyBase of the router that is handling the request (see Router.SetSecretKeyBase), or the
+// global chain.SecretKeyBase.
+func (ctx *Context) SecretKeyBase() string {
+	if ctx.router != nil {
+		return ctx.router.SecretKeyBase()
+	}
+	return SecretKeyBase()
+}
+
+// Keyring the Keyring of the router that is handling the request (see Router.Keyring). Use it to sign and encrypt data
+// with the keys of the router. The components of the router (ex. session cookies) derive their own keys (see
+// Router.DeriveKeyring), data signed with this Keyring is never accepted by them.
+//
+// ## Example
+//
+//	router.GET("/token", func(ctx *chain.Context) error {
+//		token, err := ctx.Keyring().MessageSign([]byte(ctx.QueryParam("user")), "sha256")
+//		// ...
+//	})
+func (ctx *Context) Keyring() *crypto.Keyring {
+	if ctx.router != nil {
+		return ctx.router.keyring()
+	}
+	globalKeyringOnce.Do(func() {
+		globalKeyring = NewKeyring(DefaultKeyringSalt, 1000, 32, "sha256")
+	})
+	return globalKeyring
+}
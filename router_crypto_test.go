@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Router_SecretKeyBase(t *testing.T) {
+	globalSecret := "router.global.secret.key.base.01"
+	tenantSecret := "router.tenant.secret.key.base.01"
+
+	if err := SetSecretKeyBase(globalSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	shared := New()
+	tenant := New()
+	if err := tenant.SetSecretKeyBase("short"); err == nil {
+		t.Errorf("Router.SetSecretKeyBase failed: expected error for invalid key size")
+	}
+	if tenant.HasSecretKeyBase() {
+		t.Errorf("Router.SetSecretKeyBase failed: invalid key should not be defined")
+	}
+	if err := tenant.SetSecretKeyBase(tenantSecret); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := shared.SecretKeyBase(); actual != globalSecret {
+		t.Errorf("Router.SecretKeyBase failed: Invalid key\n   actual: %v\n expected: %v", actual, globalSecret)
+	}
+	if actual := tenant.SecretKeyBase(); actual != tenantSecret {
+		t.Errorf("Router.SecretKeyBase failed: Invalid key\n   actual: %v\n expected: %v", actual, tenantSecret)
+	}
+	if actual := SecretKeyBase(); actual != globalSecret {
+		t.Errorf("SecretKeyBase failed: router key leaked to global\n   actual: %v\n expected: %v", actual, globalSecret)
+	}
+
+	var signed string
+	var ctxSecret string
+	tenant.GET("/sign", func(ctx *Context) error {
+		ctxSecret = ctx.SecretKeyBase()
+		var err error
+		signed, err = ctx.Keyring().MessageSign([]byte("tenant"), "sha256")
+		return err
+	})
+	req, _ := http.NewRequest(http.MethodGet, "/sign", nil)
+	tenant.ServeHTTP(httptest.NewRecorder(), req)
+
+	if ctxSecret != tenantSecret {
+		t.Errorf("Context.SecretKeyBase failed: Invalid key\n   actual: %v\n expected: %v", ctxSecret, tenantSecret)
+	}
+
+	expectedKey := Crypto().KeyGenerate([]byte(tenantSecret), []byte(DefaultKeyringSalt), 1000, 32, "sha256")
+	if !bytes.Equal(tenant.keyring().GetPrimaryKey(), expectedKey) {
+		t.Errorf("Context.Keyring failed: key was not derived from the router SecretKeyBase")
+	}
+	if _, err := tenant.keyring().MessageVerify([]byte(signed)); err != nil {
+		t.Errorf("Context.Keyring failed: %v", err)
+	}
+	if _, err := shared.keyring().MessageVerify([]byte(signed)); err == nil {
+		t.Errorf("Context.Keyring failed: message signed by a router must not be verified with the global keys")
+	}
+
+	// rotation
+	rotatedSecret := "router.tenant.secret.key.base.02"
+	if err := tenant.SetSecretKeyBase(rotatedSecret); err != nil {
+		t.Fatal(err)
+	}
+	if keys := tenant.SecretKeys(); len(keys) != 2 || keys[1] != rotatedSecret {
+		t.Errorf("Router.SecretKeys failed: Invalid keys\n   actual: %v\n expected: %v", keys, []string{tenantSecret, rotatedSecret})
+	}
+	if _, err := tenant.keyring().MessageVerify([]byte(signed)); err != nil {
+		t.Errorf("Context.Keyring failed: message signed with the old key should still verify: %v", err)
+	}
+}
+
+func Test_Router_Keyring_Override(t *testing.T) {
+	router := New()
+	router.Keyring = NewKeyring("router.keyring.override", 1000, 32, "sha256")
+
+	invoked := false
+	router.GET("/", func(ctx *Context) error {
+		invoked = true
+		if ctx.Keyring() != router.Keyring {
+			t.Errorf("Context.Keyring failed: Router.Keyring was not used")
+		}
+		return nil
+	})
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !invoked {
+		t.Errorf("Context.Keyring failed: handler was not invoked")
+	}
+}
+
+func Test_Router_DeriveKeyring(t *testing.T) {
+	if err := SetSecretKeyBase("router.global.secret.key.base.01"); err != nil {
+		t.Fatal(err)
+	}
+
+	router := New()
+	router.Keyring = NewKeyring("router.keyring.override", 1000, 32, "sha256")
+
+	keyring := router.DeriveKeyring("router.derive.salt", 32, nil)
+	expectedKey := Crypto().KeyGenerate([]byte(router.SecretKeyBase()), []byte("router.derive.salt"), 1000, 32, "sha256")
+	if !bytes.Equal(keyring.GetPrimaryKey(), expectedKey) {
+		t.Errorf("DeriveKeyring failed: key was not derived from the SecretKeyBase and salt")
+	}
+	if bytes.Equal(keyring.GetPrimaryKey(), router.Keyring.GetPrimaryKey()) {
+		t.Errorf("DeriveKeyring failed: the Router.Keyring must not be shared")
+	}
+
+	var nilRouter *Router
+	if key := nilRouter.DeriveKeyring("router.derive.salt", 32, nil).GetPrimaryKey(); !bytes.Equal(key, expectedKey) {
+		t.Errorf("DeriveKeyring failed: nil router must use the global SecretKeyBase")
+	}
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"expvar"
+	"net/http/pprof"
+	"strings"
+)
+
+// Debug mounts the net/http/pprof profiles and the expvar variables under the path, on the same router (and port) of
+// the application. The path is a mandatory prefix, Debug panics for the root path. The middlewares are applied only to
+// the routes under the path (see Router.Use), use them to restrict the access (ex. authentication, IP allowlist),
+// profiles expose sensitive information about the application.
+//
+//   - `{path}/pprof/`           - index of the profiles (see pprof.Index)
+//   - `{path}/pprof/:profile`   - the profile (heap, goroutine, allocs, block, mutex, threadcreate)
+//   - `{path}/pprof/profile`    - CPU profile, `?seconds=30`
+//   - `{path}/pprof/trace`      - execution trace, `?seconds=5`
+//   - `{path}/pprof/cmdline`    - command line of the process
+//   - `{path}/pprof/symbol`     - symbol lookup
+//   - `{path}/vars`             - expvar variables (JSON)
+//
+// Note: the net/http/pprof and expvar packages also register their handlers on http.DefaultServeMux, do not serve it
+// publicly.
+//
+// ## Example
+//
+//	router.Debug("/debug", &ipfilter.Filter{Allow: []string{"10.0.0.0/8"}})
+//
+//	// go tool pprof http://localhost:8080/debug/pprof/heap
+func (r *Router) Debug(path string, middlewares ...any) {
+	path = strings.TrimSuffix(path, "/")
+	if path == "" {
+		panic("[chain] Debug requires a non-root path")
+	}
+	if len(middlewares) > 0 {
+		r.Use(append([]any{path + "/*"}, middlewares...)...)
+	}
+
+	_ = r.GET(path+"/pprof/", pprof.Index)
+	_ = r.GET(path+"/pprof/cmdline", pprof.Cmdline)
+	_ = r.GET(path+"/pprof/profile", pprof.Profile)
+	_ = r.GET(path+"/pprof/symbol", pprof.Symbol)
+	_ = r.POST(path+"/pprof/symbol", pprof.Symbol)
+	_ = r.GET(path+"/pprof/trace", pprof.Trace)
+	_ = r.GET(path+"/pprof/:profile", func(ctx *Context) {
+		pprof.Handler(ctx.GetParam("profile")).ServeHTTP(ctx.Writer, ctx.Request)
+	})
+	_ = r.GET(path+"/vars", expvar.Handler())
+}
@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Router_Debug(t *testing.T) {
+	router := New()
+	router.Debug("/debug", func(ctx *Context, next func() error) error {
+		if ctx.Request.Header.Get("Authorization") != "secret" {
+			ctx.WriteHeader(http.StatusUnauthorized)
+			return nil
+		}
+		return next()
+	})
+	router.GET("/health", func(ctx *Context) {})
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{"/debug/pprof/", "goroutine"},
+		{"/debug/pprof/goroutine?debug=1", "goroutine profile"},
+		{"/debug/pprof/cmdline", ""},
+		{"/debug/vars", `"memstats"`},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Authorization", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("Debug(%s) failed: Invalid response\n   actual: %v %.80q\n expected: %v %q", tt.path, w.Code, w.Body.String(), http.StatusOK, tt.contains)
+		}
+
+		req, _ = http.NewRequest(http.MethodGet, tt.path, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Debug(%s) failed: Invalid Code\n   actual: %v\n expected: %v", tt.path, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	// the middlewares are not applied to the other routes
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Debug() failed: Invalid Code (/health)\n   actual: %v\n expected: %v", w.Code, http.StatusOK)
+	}
+
+	for _, path := range []string{"", "/"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Debug(%q) failed: expected panic for root path", path)
+				}
+			}()
+			New().Debug(path)
+		}()
+	}
+}
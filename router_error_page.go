@@ -0,0 +1,83 @@
+package chain
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ErrorPageHandler renders the error page of the status. The err is the error returned by the route handler, the
+// *PanicInfo of a recovered panic, or nil for 404 (Not Found) and 405 (Method Not Allowed).
+type ErrorPageHandler func(ctx *Context, status int, err error)
+
+type errorPages struct {
+	mutex sync.RWMutex
+	pages map[int]ErrorPageHandler
+}
+
+// ErrorPage registers the page rendered for the error status, status 0 registers the default page of all statuses.
+//
+// The pages are used by the not found and method not allowed replies, by the panic recovery and by the errors
+// returned by the handlers, when the specific handlers (NotFoundHandler, MethodNotAllowedHandler, Recovery.Render and
+// ErrorHandler) are not defined. Group fallbacks (see Fallback) take precedence over the pages.
+//
+// ## Example
+//
+//	router.ErrorPage(http.StatusNotFound, func(ctx *chain.Context, status int, err error) {
+//		ctx.SetHeader("Content-Type", "text/html; charset=utf-8")
+//		ctx.WriteHeader(status)
+//		notFoundTemplate.Execute(ctx, ctx.Request.URL.Path)
+//	})
+//
+//	// all other errors
+//	router.ErrorPage(0, func(ctx *chain.Context, status int, err error) {
+//		ctx.SetHeader("Content-Type", "text/html; charset=utf-8")
+//		ctx.WriteHeader(status)
+//		errorTemplate.Execute(ctx, map[string]any{"Status": status, "Text": http.StatusText(status)})
+//	})
+func (r *Router) ErrorPage(status int, handler ErrorPageHandler) {
+	r.errorPages.mutex.Lock()
+	defer r.errorPages.mutex.Unlock()
+	if r.errorPages.pages == nil {
+		r.errorPages.pages = map[int]ErrorPageHandler{}
+	}
+	if handler == nil {
+		delete(r.errorPages.pages, status)
+	} else {
+		r.errorPages.pages[status] = handler
+	}
+}
+
+// errorPage gets the page of the status, or the default page
+func (r *Router) errorPage(status int) ErrorPageHandler {
+	r.errorPages.mutex.RLock()
+	defer r.errorPages.mutex.RUnlock()
+	if page, exists := r.errorPages.pages[status]; exists {
+		return page
+	}
+	return r.errorPages.pages[0]
+}
+
+// renderErrorPage renders the error page of the status, returns false if there is no page for the status
+func (r *Router) renderErrorPage(ctx *Context, status int, err error) bool {
+	if ctx == nil {
+		return false
+	}
+	page := r.errorPage(status)
+	if page == nil {
+		return false
+	}
+	page(ctx, status, err)
+	if !ctx.WriteStarted() {
+		ctx.WriteHeader(status)
+	}
+	return true
+}
+
+// notFound replies the requests that don't match any route
+func (r *Router) notFound(ctx *Context, w http.ResponseWriter, req *http.Request) {
+	if r.NotFoundHandler != nil {
+		r.NotFoundHandler.ServeHTTP(w, req)
+	} else if !r.renderErrorPage(ctx, http.StatusNotFound, nil) {
+		http.NotFound(w, req)
+	}
+}
@@ -0,0 +1,77 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Router_ErrorPage(t *testing.T) {
+	router := New()
+	router.ErrorPage(http.StatusNotFound, func(ctx *Context, status int, err error) {
+		ctx.WriteHeader(status)
+		ctx.Write([]byte("page not found"))
+	})
+	router.ErrorPage(0, func(ctx *Context, status int, err error) {
+		ctx.WriteHeader(status)
+		detail := ""
+		if info, ok := err.(*PanicInfo); ok {
+			detail = fmt.Sprint(info.Value)
+		} else if err != nil {
+			detail = err.Error()
+		}
+		ctx.Write([]byte(fmt.Sprintf("error %d %s", status, detail)))
+	})
+
+	router.GET("/fail", func(ctx *Context) error {
+		return errors.New("failed")
+	})
+	router.GET("/panic", func(ctx *Context) error {
+		panic("oops")
+	})
+	router.GET("/missing", func(ctx *Context) error {
+		ctx.NotFound()
+		return nil
+	})
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{http.MethodGet, "/unknown", http.StatusNotFound, "page not found"},
+		{http.MethodGet, "/missing", http.StatusNotFound, "page not found"},
+		{http.MethodPost, "/fail", http.StatusMethodNotAllowed, "error 405 "},
+		{http.MethodGet, "/fail", http.StatusInternalServerError, "error 500 failed"},
+		{http.MethodGet, "/panic", http.StatusInternalServerError, "error 500 oops"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.expectedCode || w.Body.String() != tt.expectedBody {
+			t.Errorf("ErrorPage() failed: Invalid response of %s %s\n   actual: %d %q\n expected: %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.expectedCode, tt.expectedBody)
+		}
+	}
+
+	// the specific handlers take precedence
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("handler"))
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Body.String() != "handler" {
+		t.Errorf("ErrorPage() failed: NotFoundHandler must take precedence\n   actual: %q", w.Body.String())
+	}
+
+	// the page is removed with a nil handler
+	router.ErrorPage(0, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if w.Code != http.StatusInternalServerError || w.Body.Len() != 0 {
+		t.Errorf("ErrorPage() failed: Invalid response without page\n   actual: %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,83 @@
+package chain
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fallback handlers of the requests that don't match any route under a group prefix, replacing the global
+// Router.NotFoundHandler and Router.MethodNotAllowedHandler for that prefix.
+//
+// When groups are nested, the fallback of the most specific prefix is invoked first. If Fallthrough is enabled and the
+// handler does not write a response, the request falls through to the fallback of the parent group (and finally to
+// the global handlers).
+//
+// ## Example
+//
+//	api := router.Group("/api")
+//	api.Fallback(&chain.Fallback{
+//		NotFound: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+//			w.Header().Set("Content-Type", "application/json")
+//			w.WriteHeader(http.StatusNotFound)
+//			w.Write([]byte(`{"error":"not found"}`))
+//		}),
+//	})
+//
+//	// everything else renders the HTML page
+//	router.NotFoundHandler = notFoundPage
+type Fallback struct {
+	NotFound         http.Handler // Invoked when no matching route is found under the prefix
+	MethodNotAllowed http.Handler // Invoked when the request cannot be routed and Router.HandleMethodNotAllowed is true
+	Fallthrough      bool         // If the handler does not write a response, the next fallback is invoked
+	prefix           string
+}
+
+type fallbacks struct {
+	mutex sync.RWMutex
+	list  []*Fallback // sorted by prefix, most specific first
+}
+
+// Fallback registers the fallback handlers for all paths, invoked before the global handlers. See Fallback
+func (r *Router) Fallback(fallback *Fallback) {
+	r.addFallback("", fallback)
+}
+
+func (r *Router) addFallback(prefix string, fallback *Fallback) {
+	fallback.prefix = strings.TrimSuffix(prefix, "/")
+
+	r.fallbacks.mutex.Lock()
+	defer r.fallbacks.mutex.Unlock()
+	r.fallbacks.list = append(r.fallbacks.list, fallback)
+	sort.SliceStable(r.fallbacks.list, func(i, j int) bool {
+		return len(r.fallbacks.list[i].prefix) > len(r.fallbacks.list[j].prefix)
+	})
+}
+
+// serveFallback invokes the group fallbacks that match the path, returns true if the request was handled
+func (r *Router) serveFallback(w *ResponseWriterSpy, req *http.Request, path string, methodNotAllowed bool) bool {
+	r.fallbacks.mutex.RLock()
+	list := r.fallbacks.list
+	r.fallbacks.mutex.RUnlock()
+
+	for _, fallback := range list {
+		if fallback.prefix != "" && path != fallback.prefix && !strings.HasPrefix(path, fallback.prefix+"/") {
+			continue
+		}
+
+		handler := fallback.NotFound
+		if methodNotAllowed {
+			handler = fallback.MethodNotAllowed
+		}
+		if handler == nil {
+			continue
+		}
+
+		handler.ServeHTTP(w, req)
+		if !fallback.Fallthrough || w.writeStarted {
+			return true
+		}
+	}
+	return false
+}
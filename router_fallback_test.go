@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Router_Fallback(t *testing.T) {
+	router := New()
+	router.HandleMethodNotAllowed = true
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("html"))
+	})
+
+	api := router.Group("/api")
+	api.GET("/users", func(ctx *Context) {})
+	api.Fallback(&Fallback{
+		NotFound: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("json"))
+		}),
+		MethodNotAllowed: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte("json 405"))
+		}),
+	})
+
+	// falls through to "/api" when the path is not public
+	api.Group("/v2").Fallback(&Fallback{
+		Fallthrough: true,
+		NotFound: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/api/v2/public" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("v2"))
+			}
+		}),
+	})
+
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		expected string
+	}{
+		{http.MethodGet, "/api/missing", http.StatusNotFound, "json"},
+		{http.MethodGet, "/api", http.StatusNotFound, "json"},
+		{http.MethodPost, "/api/users", http.StatusMethodNotAllowed, "json 405"},
+		{http.MethodGet, "/api/v2/public", http.StatusNotFound, "v2"},
+		{http.MethodGet, "/api/v2/private", http.StatusNotFound, "json"},
+		{http.MethodGet, "/apix", http.StatusNotFound, "html"},
+		{http.MethodGet, "/page", http.StatusNotFound, "html"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code || w.Body.String() != tt.expected {
+			t.Errorf(
+				"Fallback failed: Invalid Response for %s %s\n   actual: %v %v\n expected: %v %v",
+				tt.method, tt.path, w.Code, w.Body.String(), tt.code, tt.expected,
+			)
+		}
+	}
+}
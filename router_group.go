@@ -1,36 +1,113 @@
 package chain
 
+import "strings"
+
 type Group interface {
-	GET(route string, handle any) error
-	HEAD(route string, handle any) error
-	OPTIONS(route string, handle any) error
-	POST(route string, handle any) error
-	PUT(route string, handle any) error
-	PATCH(route string, handle any) error
-	DELETE(route string, handle any) error
+	GET(route string, handle any, options ...RouteOption) error
+	HEAD(route string, handle any, options ...RouteOption) error
+	OPTIONS(route string, handle any, options ...RouteOption) error
+	POST(route string, handle any, options ...RouteOption) error
+	PUT(route string, handle any, options ...RouteOption) error
+	PATCH(route string, handle any, options ...RouteOption) error
+	DELETE(route string, handle any, options ...RouteOption) error
 	Use(args ...any) Group
+	UseIf(predicate func(ctx *Context) bool, args ...any) Group
 	Group(route string) Group
-	Handle(method string, route string, handle any) error
+	Handle(method string, route string, handle any, options ...RouteOption) error
 	Configure(route string, configurator RouteConfigurator)
+	Fallback(fallback *Fallback)
+	RequireTLS(redirect bool) Group
+	Host(hosts ...string) Group
 }
 
 type RouterGroup struct {
-	p string
-	r *Router
-}
-
-func (r *RouterGroup) GET(route string, handle any) error     { return r.r.GET(r.p+route, handle) }
-func (r *RouterGroup) HEAD(route string, handle any) error    { return r.r.HEAD(r.p+route, handle) }
-func (r *RouterGroup) OPTIONS(route string, handle any) error { return r.r.OPTIONS(r.p+route, handle) }
-func (r *RouterGroup) POST(route string, handle any) error    { return r.r.POST(r.p+route, handle) }
-func (r *RouterGroup) PUT(route string, handle any) error     { return r.r.PUT(r.p+route, handle) }
-func (r *RouterGroup) PATCH(route string, handle any) error   { return r.r.PATCH(r.p+route, handle) }
-func (r *RouterGroup) DELETE(route string, handle any) error  { return r.r.DELETE(r.p+route, handle) }
-func (r *RouterGroup) Use(args ...any) Group                  { return r.r.Use(args...) }
-func (r *RouterGroup) Group(route string) Group               { return &RouterGroup{r.p + route, r.r} }
-func (r *RouterGroup) Handle(method string, route string, handle any) error {
-	return r.r.Handle(method, r.p+route, handle)
+	p       string
+	r       *Router
+	options []RouteOption // options of the routes of the group, see RequireTLS and Host
+}
+
+func (r *RouterGroup) GET(route string, handle any, options ...RouteOption) error {
+	return r.r.GET(r.path(route), handle, r.routeOptions(options)...)
+}
+func (r *RouterGroup) HEAD(route string, handle any, options ...RouteOption) error {
+	return r.r.HEAD(r.path(route), handle, r.routeOptions(options)...)
+}
+func (r *RouterGroup) OPTIONS(route string, handle any, options ...RouteOption) error {
+	return r.r.OPTIONS(r.path(route), handle, r.routeOptions(options)...)
+}
+func (r *RouterGroup) POST(route string, handle any, options ...RouteOption) error {
+	return r.r.POST(r.path(route), handle, r.routeOptions(options)...)
 }
+func (r *RouterGroup) PUT(route string, handle any, options ...RouteOption) error {
+	return r.r.PUT(r.path(route), handle, r.routeOptions(options)...)
+}
+func (r *RouterGroup) PATCH(route string, handle any, options ...RouteOption) error {
+	return r.r.PATCH(r.path(route), handle, r.routeOptions(options)...)
+}
+func (r *RouterGroup) DELETE(route string, handle any, options ...RouteOption) error {
+	return r.r.DELETE(r.path(route), handle, r.routeOptions(options)...)
+}
+func (r *RouterGroup) Use(args ...any) Group { return r.r.Use(args...) }
+func (r *RouterGroup) UseIf(predicate func(ctx *Context) bool, args ...any) Group {
+	return r.r.UseIf(predicate, args...)
+}
+func (r *RouterGroup) Group(route string) Group { return &RouterGroup{r.path(route), r.r, r.options} }
+func (r *RouterGroup) Handle(method string, route string, handle any, options ...RouteOption) error {
+	return r.r.Handle(method, r.path(route), handle, r.routeOptions(options)...)
+}
+
+// Configure allows a RouteConfigurator to perform route configurations under the group prefix.
+//
+// ## Example
+//
+//	v1 := router.Group("/v1")
+//	v1.Configure("/socket", socketHandler) // endpoint "/v1/socket", client at "/v1/chain.js"
 func (r *RouterGroup) Configure(route string, configurator RouteConfigurator) {
-	r.r.Configure(r.p+route, configurator)
+	r.r.Configure(r.path(route), configurator)
+}
+
+// Fallback registers the fallback handlers for the paths under the group prefix. See Fallback
+func (r *RouterGroup) Fallback(fallback *Fallback) {
+	r.r.addFallback(r.p, fallback)
+}
+
+// RequireTLS returns a group (same prefix) whose routes are served only for HTTPS requests, plain HTTP requests are
+// redirected to HTTPS or rejected (see WithRequireTLS). Applies to the routes registered after the call, including
+// the subgroups, but not to the middlewares and RouteConfigurator.
+//
+// ## Example
+//
+//	admin := router.Group("/admin").RequireTLS(true).Host("admin.example.com")
+//	admin.GET("/users", listUsers)
+//
+//	webhooks := router.Group("/webhooks").RequireTLS(false)
+//	webhooks.POST("/stripe", stripeWebhook)
+func (r *RouterGroup) RequireTLS(redirect bool) Group {
+	return r.with(WithRequireTLS(redirect))
+}
+
+// Host returns a group (same prefix) whose routes are served only for the hosts (see WithHost), requests for other
+// hosts are handled as if the routes do not exist.
+func (r *RouterGroup) Host(hosts ...string) Group {
+	return r.with(WithHost(hosts...))
+}
+
+// with creates a copy of the group with the additional route options
+func (r *RouterGroup) with(option RouteOption) Group {
+	options := make([]RouteOption, 0, len(r.options)+1)
+	options = append(options, r.options...)
+	return &RouterGroup{r.p, r.r, append(options, option)}
+}
+
+// routeOptions the options of the group followed by the options of the route
+func (r *RouterGroup) routeOptions(options []RouteOption) []RouteOption {
+	if len(r.options) == 0 {
+		return options
+	}
+	return append(append(make([]RouteOption, 0, len(r.options)+len(options)), r.options...), options...)
+}
+
+// path joins the group prefix with the route, avoiding duplicated separators (ex. Group("/v1/").GET("/users"))
+func (r *RouterGroup) path(route string) string {
+	return strings.TrimSuffix(r.p, "/") + route
 }
@@ -0,0 +1,111 @@
+package chain
+
+import "sync"
+
+// DispatchHook callbacks invoked by the router around the route handler (after the middlewares), receiving the
+// Context and the matched Route. Allows frameworks built on chain to attach auth, instrumentation and auditing to all
+// routes without registering middlewares (see Router.OnDispatch). All callbacks are optional.
+type DispatchHook struct {
+	// Before is invoked before the route handler. Returning an error (or aborting the Context) prevents the handler
+	// from being executed, the error is handled as if it had been returned by the handler.
+	Before func(ctx *Context, route *Route) error
+
+	// After is invoked after the route handler is executed, with the error returned by it (if any).
+	After func(ctx *Context, route *Route, err error)
+
+	// OnError is invoked when the route handler (or a Before hook) returns an error.
+	OnError func(ctx *Context, route *Route, err error)
+}
+
+type dispatchHooks struct {
+	mutex sync.RWMutex
+	hooks []*DispatchHook
+}
+
+// OnDispatch registers a DispatchHook, hooks are invoked in the order they are registered. Returns a function that
+// removes the hook.
+//
+// ## Example
+//
+//	router.OnDispatch(&chain.DispatchHook{
+//		Before: func(ctx *chain.Context, route *chain.Route) error {
+//			if strings.HasPrefix(route.Info.Path(), "/admin") && !isAdmin(ctx) {
+//				ctx.AbortWithStatus(http.StatusForbidden)
+//			}
+//			return nil
+//		},
+//		After: func(ctx *chain.Context, route *chain.Route, err error) {
+//			audit(ctx, route.Info.Path(), err)
+//		},
+//	})
+func (r *Router) OnDispatch(hook *DispatchHook) (remove func()) {
+	r.dispatchHooks.mutex.Lock()
+	defer r.dispatchHooks.mutex.Unlock()
+
+	// copy on write, dispatches in progress keep using the previous list
+	hooks := make([]*DispatchHook, 0, len(r.dispatchHooks.hooks)+1)
+	hooks = append(hooks, r.dispatchHooks.hooks...)
+	r.dispatchHooks.hooks = append(hooks, hook)
+
+	return func() {
+		r.dispatchHooks.mutex.Lock()
+		defer r.dispatchHooks.mutex.Unlock()
+		var hooks []*DispatchHook
+		for _, h := range r.dispatchHooks.hooks {
+			if h != hook {
+				hooks = append(hooks, h)
+			}
+		}
+		r.dispatchHooks.hooks = hooks
+	}
+}
+
+func (r *Router) getDispatchHooks() []*DispatchHook {
+	r.dispatchHooks.mutex.RLock()
+	defer r.dispatchHooks.mutex.RUnlock()
+	return r.dispatchHooks.hooks
+}
+
+// handle executes the route handler, invoking the dispatch hooks of the router
+func (r *Route) handle(ctx *Context) (err error) {
+	if ctx.router == nil {
+		return r.Handle(ctx)
+	}
+	hooks := ctx.router.getDispatchHooks()
+	if len(hooks) == 0 {
+		return r.Handle(ctx)
+	}
+
+	for _, hook := range hooks {
+		if hook.Before == nil {
+			continue
+		}
+		if err = hook.Before(ctx, r); err != nil {
+			r.onError(ctx, hooks, err)
+			return err
+		}
+		if ctx.IsAborted() {
+			return nil
+		}
+	}
+
+	err = r.Handle(ctx)
+	if err != nil {
+		r.onError(ctx, hooks, err)
+	}
+
+	for _, hook := range hooks {
+		if hook.After != nil {
+			hook.After(ctx, r, err)
+		}
+	}
+	return err
+}
+
+func (r *Route) onError(ctx *Context, hooks []*DispatchHook, err error) {
+	for _, hook := range hooks {
+		if hook.OnError != nil {
+			hook.OnError(ctx, r, err)
+		}
+	}
+}
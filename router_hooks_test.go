@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func Test_Router_OnDispatch(t *testing.T) {
+	signature := ""
+	handlerErr := errors.New("handler error")
+
+	router := New()
+	router.ErrorHandler = func(ctx *Context, err error) {
+		ctx.WriteHeader(http.StatusTeapot)
+	}
+	router.Use(func(ctx *Context, next func() error) error {
+		signature += "M"
+		return next()
+	})
+	router.GET("/ok", func(ctx *Context) error {
+		signature += "X"
+		return nil
+	})
+	router.GET("/error", func(ctx *Context) error {
+		signature += "X"
+		return handlerErr
+	})
+
+	var routes []string
+	var hookErr error
+	remove := router.OnDispatch(&DispatchHook{
+		Before: func(ctx *Context, route *Route) error {
+			signature += "B"
+			routes = append(routes, route.Info.Path())
+			return nil
+		},
+		After: func(ctx *Context, route *Route, err error) {
+			signature += "A"
+		},
+		OnError: func(ctx *Context, route *Route, err error) {
+			signature += "E"
+			hookErr = err
+		},
+	})
+
+	PerformRequest(router, "GET", "/ok")
+	if signature != "MBXA" {
+		t.Errorf("OnDispatch failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "MBXA")
+	}
+	if len(routes) != 1 || routes[0] != "/ok" {
+		t.Errorf("OnDispatch failed: Invalid Route\n   actual: %v\n expected: %v", routes, []string{"/ok"})
+	}
+
+	signature = ""
+	w := PerformRequest(router, "GET", "/error")
+	if signature != "MBXEA" {
+		t.Errorf("OnDispatch failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "MBXEA")
+	}
+	if hookErr != handlerErr {
+		t.Errorf("OnDispatch failed: Invalid Error\n   actual: %v\n expected: %v", hookErr, handlerErr)
+	}
+	if w.Code != http.StatusTeapot {
+		t.Errorf("OnDispatch failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusTeapot)
+	}
+
+	remove()
+	signature = ""
+	PerformRequest(router, "GET", "/ok")
+	if signature != "MX" {
+		t.Errorf("OnDispatch failed: hook was not removed\n   actual: %v\n expected: %v", signature, "MX")
+	}
+}
+
+func Test_Router_OnDispatch_Before_Abort(t *testing.T) {
+	signature := ""
+	router := New()
+	router.GET("/admin", func(ctx *Context) error {
+		signature += "X"
+		return nil
+	})
+	router.OnDispatch(&DispatchHook{
+		Before: func(ctx *Context, route *Route) error {
+			signature += "B"
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return nil
+		},
+		After: func(ctx *Context, route *Route, err error) {
+			signature += "A"
+		},
+	})
+
+	w := PerformRequest(router, "GET", "/admin")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("OnDispatch failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusForbidden)
+	}
+	if signature != "B" {
+		t.Errorf("OnDispatch failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "B")
+	}
+}
@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type shutdownHooks struct {
+	mutex sync.Mutex
+	hooks []func(ctx context.Context) error
+	done  bool
+}
+
+// OnShutdown registers a function invoked by Router.Shutdown, allowing components attached to the router (ex. jobs,
+// socket handlers) to release resources and drain pending work. Functions are invoked in the reverse order they are
+// registered.
+//
+// ## Example
+//
+//	runner := &jobs.Runner{}
+//	router.OnShutdown(runner.Shutdown)
+func (r *Router) OnShutdown(fn func(ctx context.Context) error) {
+	r.shutdownHooks.mutex.Lock()
+	defer r.shutdownHooks.mutex.Unlock()
+	r.shutdownHooks.hooks = append(r.shutdownHooks.hooks, fn)
+}
+
+// Shutdown invokes the functions registered by Router.OnShutdown, waiting for them to finish or the ctx to be done.
+// The router does not own the http.Server, so Shutdown must be invoked after the server has stopped receiving
+// requests. Subsequent calls do nothing.
+//
+// ## Example
+//
+//	server := &http.Server{Addr: ":8080", Handler: router}
+//	go server.ListenAndServe()
+//
+//	<-stop // ex. signal.NotifyContext(ctx, os.Interrupt)
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	_ = server.Shutdown(ctx)
+//	_ = router.Shutdown(ctx)
+func (r *Router) Shutdown(ctx context.Context) error {
+	r.shutdownHooks.mutex.Lock()
+	if r.shutdownHooks.done {
+		r.shutdownHooks.mutex.Unlock()
+		return nil
+	}
+	r.shutdownHooks.done = true
+	hooks := r.shutdownHooks.hooks
+	r.shutdownHooks.mutex.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,38 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func Test_Router_Shutdown(t *testing.T) {
+	router := New()
+
+	signature := ""
+	hookErr := errors.New("hook error")
+	router.OnShutdown(func(ctx context.Context) error {
+		signature += "A"
+		return nil
+	})
+	router.OnShutdown(func(ctx context.Context) error {
+		signature += "B"
+		return hookErr
+	})
+	router.OnShutdown(func(ctx context.Context) error {
+		signature += "C"
+		return nil
+	})
+
+	err := router.Shutdown(context.Background())
+	if signature != "CBA" {
+		t.Errorf("Shutdown failed: Invalid Execution Order\n   actual: %v\n expected: %v", signature, "CBA")
+	}
+	if !errors.Is(err, hookErr) {
+		t.Errorf("Shutdown failed: Invalid Error\n   actual: %v\n expected: %v", err, hookErr)
+	}
+
+	if err = router.Shutdown(context.Background()); err != nil || signature != "CBA" {
+		t.Errorf("Shutdown failed: Hooks invoked twice\n   actual: %v\n expected: %v", signature, "CBA")
+	}
+}
@@ -0,0 +1,43 @@
+package chain
+
+// routeTable immutable snapshot of the registries of the router.
+//
+// The registrations (Router.Handle, Router.Use) change the registries under a lock and mark the table as stale, the
+// next request publishes a copy of the registries (atomic swap). The requests read the table without locks, allowing
+// routes to be registered while the router is serving (hot route updates) without data races.
+type routeTable struct {
+	registries    map[string]*Registry
+	globalAllowed string // cached value of global (*) getAllowedHeader methods
+}
+
+var emptyRouteTable = &routeTable{}
+
+// routeTable gets the current snapshot of the registries, publishing the pending registrations
+func (r *Router) routeTable() *routeTable {
+	if r.tableStale.Load() {
+		r.publishRouteTable()
+	}
+	if table := r.table.Load(); table != nil {
+		return table
+	}
+	return emptyRouteTable
+}
+
+func (r *Router) publishRouteTable() {
+	r.registriesMutex.Lock()
+	defer r.registriesMutex.Unlock()
+
+	if !r.tableStale.Load() {
+		// published by another request
+		return
+	}
+
+	table := &routeTable{registries: make(map[string]*Registry, len(r.registries))}
+	for method, registry := range r.registries {
+		table.registries[method] = registry.clone()
+	}
+	table.globalAllowed = r.getAllowedHeader(table, "*", "", nil)
+
+	r.table.Store(table)
+	r.tableStale.Store(false)
+}
@@ -0,0 +1,71 @@
+package chain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func Test_Router_Hot_Route_Updates(t *testing.T) {
+	router := New()
+	router.GET("/ping", func(ctx *Context) {
+		ctx.Write([]byte("pong"))
+	})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodGet, "/ping", nil)
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK || w.Body.String() != "pong" {
+					t.Errorf("ServeHTTP() failed: Invalid response\n   actual: %d %s\n expected: %d %s", w.Code, w.Body.String(), http.StatusOK, "pong")
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		router.GET("/items/"+strconv.Itoa(i)+"/:id", fakeHandler("item"))
+		router.Use("/items/"+strconv.Itoa(i)+"/*", func(ctx *Context, next func() error) error {
+			return next()
+		})
+	}
+	close(stop)
+	wg.Wait()
+
+	checkRequests(t, router, tRequest{path: "/items/99/42", route: "item", params: map[string]string{"id": "42"}})
+
+	route, _ := router.Lookup(http.MethodGet, "/items/99/42")
+	if len(route.Middlewares()) != 1 {
+		t.Errorf("Router.Use() failed: Invalid middlewares\n   actual: %d\n expected: %d", len(route.Middlewares()), 1)
+	}
+}
+
+func Test_Router_Route_Table_Snapshot(t *testing.T) {
+	router := New()
+	router.GET("/a", fakeHandler("a"))
+
+	before := router.routeTable()
+	router.GET("/b", fakeHandler("b"))
+
+	if route := before.registries[http.MethodGet].static["/b"]; route != nil {
+		t.Errorf("routeTable() failed: Published table was changed by the registration")
+	}
+	checkRequests(t, router, tRequest{path: "/b", route: "b"})
+	if after := router.routeTable(); after == before {
+		t.Errorf("routeTable() failed: Registration was not published")
+	}
+}
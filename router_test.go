@@ -370,6 +370,76 @@ func Test_Router_NotAllowed(t *testing.T) {
 	}
 }
 
+func Test_Router_AutoHEAD(t *testing.T) {
+	router := New()
+	router.AutoHEAD = true
+	router.HandleMethodNotAllowed = true
+	router.GET("/path", func(ctx *Context) error {
+		ctx.SetHeader("X-Custom", "value")
+		ctx.Write([]byte("hello world"))
+		return nil
+	})
+	router.GET("/explicit", func(ctx *Context) error {
+		ctx.Write([]byte("GET"))
+		return nil
+	})
+	router.HEAD("/explicit", func(ctx *Context) error {
+		ctx.SetHeader("X-Head", "explicit")
+		return nil
+	})
+	router.POST("/post", func(ctx *Context) error {
+		return nil
+	})
+
+	r, _ := http.NewRequest(http.MethodHead, "/path", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("AutoHEAD failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusOK)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("AutoHEAD failed: body must be discarded, got %q", w.Body.String())
+	}
+	if got := w.Header().Get("X-Custom"); got != "value" {
+		t.Errorf("AutoHEAD failed: Invalid Header\n   actual: %v\n expected: %v", got, "value")
+	}
+	if got := w.Header().Get("Content-Length"); got != "11" {
+		t.Errorf("AutoHEAD failed: Invalid Content-Length\n   actual: %v\n expected: %v", got, "11")
+	}
+
+	// explicit HEAD route takes priority
+	r, _ = http.NewRequest(http.MethodHead, "/explicit", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if got := w.Header().Get("X-Head"); got != "explicit" {
+		t.Errorf("AutoHEAD failed: HEAD route was not used\n   actual: %v\n expected: %v", got, "explicit")
+	}
+
+	// no GET route
+	r, _ = http.NewRequest(http.MethodHead, "/post", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("AutoHEAD failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusMethodNotAllowed)
+	}
+
+	r, _ = http.NewRequest(http.MethodPut, "/path", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Error("unexpected Allow header value: " + allow)
+	}
+
+	// disabled
+	router.AutoHEAD = false
+	r, _ = http.NewRequest(http.MethodHead, "/path", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("AutoHEAD failed: Invalid Code\n   actual: %v\n expected: %v", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
 func Test_Router_NotFound(t *testing.T) {
 	handlerFunc := func(ctx *Context) error {
 		return nil
@@ -1115,3 +1185,16 @@ func catchPanic(fn func()) (r interface{}) {
 	fn()
 	return
 }
+
+func Test_Router_Group_Path(t *testing.T) {
+	router := New()
+	router.Group("/app/").Group("/v1/").GET("/users", func(ctx *Context) error {
+		return nil
+	})
+
+	for path, expected := range map[string]bool{"/app/v1/users": true, "/app//v1//users": false} {
+		if route, _ := router.Lookup(http.MethodGet, path); (route != nil) != expected {
+			t.Errorf("Group() failed: Invalid lookup of %s\n   actual: %v\n expected: %v", path, route != nil, expected)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package socket
 
 import (
+	"encoding/binary"
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/nidorx/chain"
 	"github.com/nidorx/chain/pkg"
@@ -19,6 +21,18 @@ const (
 	LeaveReasonClose  = LeaveReason(2) // Connection lost and session is terminated. See Session.ScheduleShutdown
 )
 
+func (r LeaveReason) String() string {
+	switch r {
+	case LeaveReasonLeave:
+		return "leave"
+	case LeaveReasonRejoin:
+		return "rejoin"
+	case LeaveReasonClose:
+		return "close"
+	}
+	return "unknown"
+}
+
 var (
 	ErrJoinCrashed    = fmt.Errorf("join crashed")
 	ErrUnmatchedTopic = fmt.Errorf("unmatched topic")
@@ -34,6 +48,13 @@ type JoinHandler func(payload any, socket *Socket) (reply any, err error)
 // See Channel.HandleIn
 type InHandler func(event string, payload any, socket *Socket) (reply any, err error)
 
+// RejoinHandler invoked when the client rejoins a channel after a reconnection (event:_rejoin), after the JoinHandler
+// authorized the socket. `lastRef` is the Ref of the last push received by the client on the previous socket (see
+// Socket.Push), zero if none.
+//
+// See Channel.OnRejoin
+type RejoinHandler func(lastRef int, socket *Socket)
+
 // OutHandler invoked when a broadcast message is intercepted.
 //
 // See Channel.HandleOut
@@ -46,7 +67,8 @@ type LeaveHandler func(socket *Socket, reason LeaveReason)
 
 // NewChannel Defines a channel matching the given topic.
 func NewChannel(topicPattern string, factory func(channel *Channel)) *Channel {
-	channel := &Channel{TopicPattern: topicPattern}
+	channel := &Channel{}
+	channel.TopicPattern = channel.topicPattern(topicPattern)
 	factory(channel)
 	return channel
 }
@@ -54,14 +76,29 @@ func NewChannel(topicPattern string, factory func(channel *Channel)) *Channel {
 // Channel provide a means for bidirectional communication from clients that integrate with the pubsub layer for
 // soft-realtime functionality.
 type Channel struct {
-	TopicPattern  string // The string pattern, for example `"room:*"`, `"users:*"`, or `"system"`
-	joinHandlers  *pkg.WildcardStore[JoinHandler]
-	inHandlers    *pkg.WildcardStore[InHandler]
-	outHandlers   *pkg.WildcardStore[OutHandler]
-	leaveHandlers *pkg.WildcardStore[LeaveHandler]
-	serializer    chain.Serializer
-	sockets       map[string]map[*Socket]bool
-	socketsMutex  sync.RWMutex
+	TopicPattern   string // The string pattern, for example `"room:*"`, `"users:*"`, or `"system"` (see TopicTemplate)
+	joinHandlers   *pkg.WildcardStore[JoinHandler]
+	inHandlers     *pkg.WildcardStore[InHandler]
+	inValidators   *pkg.WildcardStore[PayloadValidator]
+	outHandlers    *pkg.WildcardStore[OutHandler]
+	outFilters     *pkg.WildcardStore[OutFilter]
+	leaveHandlers  *pkg.WildcardStore[LeaveHandler]
+	rejoinHandlers *pkg.WildcardStore[RejoinHandler]
+	timeouts       *pkg.WildcardStore[time.Duration]
+	shardNodes     ShardNodes
+	metrics        *channelMetrics
+	serializer     chain.Serializer
+	sockets        map[string]map[*Socket]bool
+	socketsMutex   sync.RWMutex
+	states         map[string]*State
+	statesMutex    sync.Mutex
+	stateHooks     *pkg.WildcardStore[StateHook]
+	batchWindows   *pkg.WildcardStore[time.Duration]
+	batches        map[string]*batch
+	batchesMutex   sync.Mutex
+	topicTemplates []*TopicTemplate
+	maxTopics      int  // see Channel.MaxTopics
+	events         bool // emits the lifecycle events, see Handler.Events
 }
 
 // Join Handle channel joins by `topic`.
@@ -78,11 +115,17 @@ type Channel struct {
 //	       	}
 //			return
 //	     })
+//
+//		// topic templates, see TopicTemplate
+//		channel.Join("room:{id}", func(payload any, socket *Socket) (reply any, err error) {
+//			room := socket.TopicParam("id")
+//			return
+//		})
 func (c *Channel) Join(topic string, handler JoinHandler) {
 	if c.joinHandlers == nil {
 		c.joinHandlers = &pkg.WildcardStore[JoinHandler]{}
 	}
-	if err := c.joinHandlers.Insert(topic, handler); err != nil {
+	if err := c.joinHandlers.Insert(c.topicPattern(topic), handler); err != nil {
 		panic(fmt.Sprintf("[chain.socket] invalid join handler for topic. Topic: %s, Error: %s", topic, err.Error()))
 	}
 	return
@@ -138,13 +181,72 @@ func (c *Channel) Leave(topic string, handler LeaveHandler) {
 	if c.leaveHandlers == nil {
 		c.leaveHandlers = &pkg.WildcardStore[LeaveHandler]{}
 	}
-	if err := c.leaveHandlers.Insert(topic, handler); err != nil {
+	if err := c.leaveHandlers.Insert(c.topicPattern(topic), handler); err != nil {
 		panic(fmt.Sprintf("[chain] invalid LeaveHandler for topic. Topic: %s, Error: %s", topic, err.Error()))
 	}
 }
 
+// OnRejoin Handle channel rejoins by `topic`, when the client joins again after a reconnection (distinct from the
+// first join). The socket has already been authorized by the Join handler and the join reply has been sent, so the
+// handler can push a state diff (or a full resync) based on the last push received by the client.
+//
+// ## Example
+//
+//	channel.OnRejoin("room:*", func(lastRef int, socket *Socket) {
+//		for _, event := range history.Since(socket.Topic(), lastRef) {
+//			socket.Push(event.Name, event.Payload)
+//		}
+//	})
+func (c *Channel) OnRejoin(topic string, handler RejoinHandler) {
+	if c.rejoinHandlers == nil {
+		c.rejoinHandlers = &pkg.WildcardStore[RejoinHandler]{}
+	}
+	if err := c.rejoinHandlers.Insert(c.topicPattern(topic), handler); err != nil {
+		panic(fmt.Sprintf("[chain.socket] invalid RejoinHandler for topic. Topic: %s, Error: %s", topic, err.Error()))
+	}
+}
+
+// Timeout defines the max processing time of the `event`s (including "_join" and "_leave"), overriding
+// Handler.MessageTimeout. When the timeout expires, an error reply is sent to the client and the reply of the handler
+// (if any) is discarded.
+//
+// ## Example
+//
+//	channel.Timeout("report:*", 30*time.Second)
+func (c *Channel) Timeout(event string, timeout time.Duration) {
+	if c.timeouts == nil {
+		c.timeouts = &pkg.WildcardStore[time.Duration]{}
+	}
+	if err := c.timeouts.Insert(event, timeout); err != nil {
+		panic(fmt.Sprintf("[chain.socket] invalid timeout for event. Event: %s, Error: %s", event, err.Error()))
+	}
+}
+
+// MaxTopics defines the max number of topics of this channel joined concurrently by a session (ex. "room:1", "room:2"),
+// bounding the memory used by abusive clients. When exceeded, the join fails with ErrMaxTopics and the reply payload
+// informs the limit ({"reason": "max topics exceeded", "max": 10}). No limit if zero. See Handler.MaxTopics
+//
+// ## Example
+//
+//	socket.NewChannel("room:*", func(channel *socket.Channel) {
+//		channel.MaxTopics(10)
+//		channel.Join("room:*", joinRoom)
+//	})
+func (c *Channel) MaxTopics(max int) {
+	c.maxTopics = max
+}
+
 // Broadcast on the pubsub server with the given topic, event and payload.
 func (c *Channel) Broadcast(topic string, event string, payload any) (err error) {
+	if c.events {
+		emitEvent(EventBroadcast, topic, nil, func(e *Event) { e.Event = event })
+	}
+
+	if window := c.batchWindow(topic, event); window > 0 {
+		// coalesced, see Channel.Batch
+		return c.enqueueBatch(topic, event, payload, window)
+	}
+
 	broadcast := newMessage(MessageTypeBroadcast, topic, event, payload)
 	defer deleteMessage(broadcast)
 
@@ -156,8 +258,31 @@ func (c *Channel) Broadcast(topic string, event string, payload any) (err error)
 	return
 }
 
+// BroadcastFrom on the pubsub server with the topic of the socket, the given event and payload, the message is not
+// delivered to the socket (on all nodes of the cluster). Avoids the client-side filtering of the echo of its own
+// messages.
+func (c *Channel) BroadcastFrom(socket *Socket, event string, payload any) (err error) {
+	if c.events {
+		emitEvent(EventBroadcast, socket.Topic(), socket, func(e *Event) { e.Event = event })
+	}
+
+	broadcast := newMessage(MessageTypeBroadcast, socket.Topic(), event, payload)
+	defer deleteMessage(broadcast)
+
+	var bytes []byte
+	if bytes, err = c.serializer.Encode(broadcast); err != nil {
+		return
+	}
+	err = pubsub.Broadcast(socket.Topic(), encodeBroadcastFrom(socket.Id(), bytes))
+	return
+}
+
 // LocalBroadcast on the pubsub server with the given topic, event and payload.
 func (c *Channel) LocalBroadcast(topic string, event string, payload any) (err error) {
+	if c.events {
+		emitEvent(EventBroadcast, topic, nil, func(e *Event) { e.Event = event })
+	}
+
 	broadcast := newMessage(MessageTypeBroadcast, topic, event, payload)
 	pubsub.LocalBroadcast(topic, broadcast)
 	return
@@ -170,8 +295,11 @@ func (c *Channel) Dispatch(topic string, msg any, from string) {
 	var payload []byte
 	isByteArray := false
 
+	var exclude string // id of the socket that originated the message, see Channel.BroadcastFrom
+
 	if payload, valid = msg.([]byte); valid {
 		isByteArray = true
+		exclude, payload = decodeBroadcastFrom(payload)
 		message = newMessageAny()
 		if _, err := c.serializer.Decode(payload, message); err != nil {
 			slog.Debug(
@@ -184,7 +312,7 @@ func (c *Channel) Dispatch(topic string, msg any, from string) {
 			deleteMessage(message)
 			return
 		}
-	} else if message, valid = msg.(*Message); !valid {
+	} else if message, valid = msg.(*Message); !valid || !checkMessage(message, "dispatch") {
 		return
 	}
 
@@ -193,7 +321,11 @@ func (c *Channel) Dispatch(topic string, msg any, from string) {
 	var sockets []*Socket
 	if len(c.sockets) > 0 {
 		if ss, exist := c.sockets[topic]; exist {
+			sockets = make([]*Socket, 0, len(ss))
 			for socket, _ := range ss {
+				if exclude != "" && socket.Id() == exclude {
+					continue
+				}
 				sockets = append(sockets, socket)
 			}
 		}
@@ -215,6 +347,16 @@ func (c *Channel) Dispatch(topic string, msg any, from string) {
 		}
 	}
 
+	// veto or modify the payload per socket, keeping the shared encodings (see Channel.FilterOut)
+	if filter := c.outFilter(message.Event); filter != nil {
+		var encoded []byte
+		if isByteArray {
+			encoded = payload
+		}
+		c.dispatchFiltered(topic, message, encoded, sockets, filter)
+		return
+	}
+
 	// fastlane (not intercepted, single encode for all sockets)
 
 	if !isByteArray {
@@ -229,6 +371,29 @@ func (c *Channel) Dispatch(topic string, msg any, from string) {
 	}
 }
 
+// encodeBroadcastFrom prefixes the encoded message with the id of the sender socket. The serialized messages never
+// start with a zero byte (JSON, binary frames or protobuf), which identifies the envelope.
+func encodeBroadcastFrom(socketId string, bytes []byte) []byte {
+	envelope := make([]byte, 0, 1+binary.MaxVarintLen64+len(socketId)+len(bytes))
+	envelope = append(envelope, 0)
+	envelope = binary.AppendUvarint(envelope, uint64(len(socketId)))
+	envelope = append(envelope, socketId...)
+	return append(envelope, bytes...)
+}
+
+// decodeBroadcastFrom extracts the id of the sender socket and the encoded message, see encodeBroadcastFrom
+func decodeBroadcastFrom(bytes []byte) (socketId string, message []byte) {
+	if len(bytes) == 0 || bytes[0] != 0 {
+		return "", bytes
+	}
+	size, n := binary.Uvarint(bytes[1:])
+	if n <= 0 || uint64(len(bytes)-1-n) < size {
+		return "", bytes
+	}
+	start := 1 + n
+	return string(bytes[start : start+int(size)]), bytes[start+int(size):]
+}
+
 // validate @todo checks if all handlers are configured correctly
 func (c *Channel) validate() (err error) {
 	return nil
@@ -245,9 +410,25 @@ func (c *Channel) handleJoin(topic string, payload any, socket *Socket) (reply a
 
 	if c.joinHandlers != nil {
 		if handler := c.joinHandlers.Match(topic); handler != nil {
+			// the topic must be authorized before the join (see pubsub.SetAuthorizer)
+			if err = pubsub.Authorize(topic, pubsub.ActionSubscribe); err != nil {
+				return
+			}
+
+			// the topic state is available to the join handler, released if the join fails (see Channel.State)
+			c.acquireState(topic)
+			joined := false
+			defer func() {
+				if !joined {
+					c.releaseState(topic)
+				}
+			}()
+
 			if reply, err = handler(payload, socket); err == nil {
 				// subscribe topic and configure fastlane
-				pubsub.Subscribe(topic, c)
+				if err = pubsub.Subscribe(topic, c); err != nil {
+					return nil, err
+				}
 
 				c.socketsMutex.Lock()
 				defer c.socketsMutex.Unlock()
@@ -259,6 +440,7 @@ func (c *Channel) handleJoin(topic string, payload any, socket *Socket) (reply a
 					c.sockets[socket.Topic()] = map[*Socket]bool{}
 				}
 				c.sockets[socket.Topic()][socket] = true
+				joined = true
 				return
 			}
 		}
@@ -268,12 +450,28 @@ func (c *Channel) handleJoin(topic string, payload any, socket *Socket) (reply a
 	return
 }
 
+func (c *Channel) handleRejoin(lastRef int, socket *Socket) {
+	if c.rejoinHandlers != nil {
+		if handler := c.rejoinHandlers.Match(socket.Topic()); handler != nil {
+			handler(lastRef, socket)
+		}
+	}
+}
+
 func (c *Channel) handleLeave(socket *Socket, reason LeaveReason) {
 	if socket.channel == c {
 		socket.channel = nil
 
+		if c.metrics != nil {
+			c.metrics.leaves.Add(1)
+		}
+
 		topic := socket.Topic()
 
+		if c.events {
+			emitEvent(EventLeave, topic, socket, func(e *Event) { e.Reason = reason.String() })
+		}
+
 		pubsub.Unsubscribe(topic, c)
 
 		// remove socket reference on channel
@@ -283,7 +481,11 @@ func (c *Channel) handleLeave(socket *Socket, reason LeaveReason) {
 			return
 		}
 
-		delete(c.sockets[topic], socket)
+		if _, joined := c.sockets[topic][socket]; joined {
+			delete(c.sockets[topic], socket)
+			// disposes the topic state after the leave handler, when the last socket leaves
+			defer c.releaseState(topic)
+		}
 		if c.leaveHandlers != nil {
 			if handler := c.leaveHandlers.Match(topic); handler != nil {
 				handler(socket, reason)
@@ -302,7 +504,7 @@ func (c *Channel) handleIn(event string, payload any, socket *Socket) (reply any
 	handler := c.inHandlers.Match(event)
 	if handler == nil {
 		err = ErrUnmatchedTopic
-	} else {
+	} else if payload, reply, err = c.validateIn(event, payload); err == nil {
 		reply, err = handler(event, payload, socket)
 	}
 
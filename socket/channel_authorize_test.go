@@ -0,0 +1,43 @@
+package socket
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nidorx/chain/pubsub"
+)
+
+func Test_Channel_Join_Authorizer(t *testing.T) {
+	pubsub.SetAuthorizer(func(topic string, action pubsub.Action) error {
+		if strings.HasSuffix(topic, ":private") {
+			return pubsub.ErrUnauthorized
+		}
+		return nil
+	})
+	defer pubsub.SetAuthorizer(nil)
+
+	joined := 0
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.Join("room:private", func(payload any, socket *Socket) (reply any, err error) {
+			joined++
+			return
+		})
+	})
+	if _, err := transport.Connect(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	join := newMessage(MessageTypePush, "room:private", "_join", nil)
+	join.Ref = 1
+	join.JoinRef = 1
+	transport.SendMessage(join)
+
+	messages := waitMessages(transport, 1)
+	if len(messages) != 1 || messages[0].Status != ReplyStatusCodeError {
+		t.Fatalf("Join() failed: Expected Error Reply\n   actual: %v", messages)
+	}
+	if joined != 0 {
+		t.Errorf("Join() failed: Join handler invoked for unauthorized topic")
+	}
+}
@@ -0,0 +1,120 @@
+package socket
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nidorx/chain/pkg"
+	"github.com/nidorx/chain/pubsub"
+)
+
+// BatchMaxMessages max number of messages of a batch, the batch is delivered before the window ends when full
+const BatchMaxMessages = 256
+
+// batchEvent event of the broadcasts coalesced by Channel.Batch, the payload is the list of [event, payload]
+const batchEvent = "_batch"
+
+// batch messages of a topic waiting for the end of the window
+type batch struct {
+	messages []any
+	timer    *time.Timer
+}
+
+// Batch enables the batching of broadcasts for the topics, for high-frequency topics (ex. tickers, telemetry). The
+// messages broadcast (see Channel.Broadcast and Socket.Broadcast) within the window are coalesced into a single
+// envelope, delivered as one message to the clients (chain.js unpacks the batch, triggering each event in order),
+// reducing pubsub traffic, syscalls and client wakeups.
+//
+// Intercepted events (see Channel.HandleOut and Channel.FilterOut) and Channel.BroadcastFrom are never batched.
+//
+// ## Example
+//
+//	channel.Batch("ticker:*", 10*time.Millisecond)
+func (c *Channel) Batch(topic string, window time.Duration) {
+	if c.batchWindows == nil {
+		c.batchWindows = &pkg.WildcardStore[time.Duration]{}
+	}
+	if err := c.batchWindows.Insert(c.topicPattern(topic), window); err != nil {
+		panic(fmt.Sprintf("[chain.socket] invalid batch window for topic. Topic: %s, Error: %s", topic, err.Error()))
+	}
+}
+
+// batchWindow gets the batch window of the broadcast, zero if not batched
+func (c *Channel) batchWindow(topic string, event string) time.Duration {
+	if c.batchWindows == nil {
+		return 0
+	}
+	if (c.outHandlers != nil && c.outHandlers.Match(event) != nil) || c.outFilter(event) != nil {
+		// intercepted events are handled one by one
+		return 0
+	}
+	return c.batchWindows.Match(topic)
+}
+
+// enqueueBatch adds the message to the batch of the topic, started on the first message of the window
+func (c *Channel) enqueueBatch(topic string, event string, payload any, window time.Duration) error {
+	// broadcast is authorized on enqueue, the caller receives the error
+	if err := pubsub.Authorize(topic, pubsub.ActionBroadcast); err != nil {
+		return err
+	}
+
+	c.batchesMutex.Lock()
+	defer c.batchesMutex.Unlock()
+
+	if c.batches == nil {
+		c.batches = map[string]*batch{}
+	}
+	b, exists := c.batches[topic]
+	if !exists {
+		b = &batch{}
+		b.timer = time.AfterFunc(window, func() {
+			c.flushBatch(topic, b)
+		})
+		c.batches[topic] = b
+	}
+	b.messages = append(b.messages, []any{event, payload})
+
+	if len(b.messages) >= BatchMaxMessages {
+		b.timer.Stop()
+		delete(c.batches, topic)
+		go c.publishBatch(topic, b.messages)
+	}
+	return nil
+}
+
+// flushBatch publishes the batch at the end of the window
+func (c *Channel) flushBatch(topic string, b *batch) {
+	c.batchesMutex.Lock()
+	if c.batches[topic] != b {
+		// already published (full)
+		c.batchesMutex.Unlock()
+		return
+	}
+	delete(c.batches, topic)
+	c.batchesMutex.Unlock()
+
+	c.publishBatch(topic, b.messages)
+}
+
+func (c *Channel) publishBatch(topic string, messages []any) {
+	if len(messages) == 0 {
+		return
+	}
+
+	broadcast := newMessage(MessageTypeBroadcast, topic, batchEvent, messages)
+	defer deleteMessage(broadcast)
+
+	bytes, err := c.serializer.Encode(broadcast)
+	if err == nil {
+		err = pubsub.Broadcast(topic, bytes)
+	}
+	if err != nil {
+		slog.Warn(
+			"[chain.socket] could not broadcast batch",
+			slog.Any("Error", err),
+			slog.String("Topic", topic),
+			slog.Int("Messages", len(messages)),
+		)
+	}
+}
@@ -0,0 +1,40 @@
+package socket
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Channel_Batch(t *testing.T) {
+	var channel *Channel
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(c *Channel) {
+		channel = c
+		c.Batch("room:*", 20*time.Millisecond)
+	})
+	joinPoolTestRoom(t, transport)
+	defer transport.Close()
+
+	for i := 1; i <= 3; i++ {
+		if err := channel.Broadcast("room:1", "tick", float64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	messages := waitMessages(transport, 1)
+	time.Sleep(40 * time.Millisecond)
+	messages = waitMessages(transport, len(messages))
+	if len(messages) != 1 || messages[0].Kind != MessageTypeBroadcast || messages[0].Event != batchEvent {
+		t.Fatalf("Batch() failed: Invalid messages\n   actual: %v\n expected: %v", messages, "one _batch")
+	}
+
+	expected := []any{
+		[]any{"tick", float64(1)},
+		[]any{"tick", float64(2)},
+		[]any{"tick", float64(3)},
+	}
+	if !reflect.DeepEqual(messages[0].Payload, expected) {
+		t.Errorf("Batch() failed: Invalid payload\n   actual: %v\n expected: %v", messages[0].Payload, expected)
+	}
+}
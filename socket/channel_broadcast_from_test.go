@@ -0,0 +1,68 @@
+package socket
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Channel_BroadcastFrom(t *testing.T) {
+	sender := &transportT{}
+	handler := newPoolTestHandler(sender, 0, func(channel *Channel) {
+		channel.HandleIn("shout", func(event string, payload any, socket *Socket) (reply any, err error) {
+			err = socket.BroadcastFrom("shout", payload)
+			return
+		})
+	})
+	other := &transportT{handler: handler}
+
+	for _, transport := range []*transportT{sender, other} {
+		if _, err := transport.Connect(map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+		join := newMessage(MessageTypePush, "room:from", "_join", nil)
+		join.Ref = 1
+		join.JoinRef = 1
+		transport.SendMessage(join)
+		if messages := waitMessages(transport, 1); len(messages) != 1 {
+			t.Fatalf("Join() failed: no reply")
+		}
+		transport.Clear()
+	}
+	defer sender.Close()
+	defer other.Close()
+
+	shout := newMessage(MessageTypePush, "room:from", "shout", "hello")
+	shout.Ref = 2
+	shout.JoinRef = 1
+	sender.SendMessage(shout)
+
+	messages := waitMessages(other, 1)
+	if len(messages) != 1 || messages[0].Kind != MessageTypeBroadcast || messages[0].Event != "shout" {
+		t.Fatalf("BroadcastFrom() failed: Invalid broadcast\n   actual: %v\n expected: %v", messages, "shout")
+	}
+	if messages[0].Payload != "hello" {
+		t.Errorf("BroadcastFrom() failed: Invalid payload\n   actual: %v\n expected: %v", messages[0].Payload, "hello")
+	}
+
+	waitMessages(sender, 1)
+	time.Sleep(50 * time.Millisecond)
+	for _, message := range waitMessages(sender, 1) {
+		if message.Kind == MessageTypeBroadcast {
+			t.Errorf("BroadcastFrom() failed: Message delivered to the sender socket")
+		}
+	}
+}
+
+func Test_BroadcastFrom_Envelope(t *testing.T) {
+	encoded := []byte(`[2,"room:1","shout","hello"]`)
+
+	id, message := decodeBroadcastFrom(encodeBroadcastFrom("socket-id", encoded))
+	if id != "socket-id" || string(message) != string(encoded) {
+		t.Errorf("decodeBroadcastFrom() failed: Invalid envelope\n   actual: %s %s\n expected: %s %s", id, message, "socket-id", encoded)
+	}
+
+	id, message = decodeBroadcastFrom(encoded)
+	if id != "" || string(message) != string(encoded) {
+		t.Errorf("decodeBroadcastFrom() failed: Invalid message\n   actual: %s %s\n expected: %s", id, message, encoded)
+	}
+}
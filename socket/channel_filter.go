@@ -0,0 +1,124 @@
+package socket
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/nidorx/chain/pkg"
+)
+
+// OutFilter invoked for each socket that will receive a broadcast message. Returns the payload delivered to the socket
+// (the payload received, when unmodified) or deliver=false to veto the delivery to this socket.
+//
+// See Channel.FilterOut
+type OutFilter func(event string, payload any, socket *Socket) (out any, deliver bool)
+
+// FilterOut Intercepts outgoing `event`s, allowing to veto the delivery for a socket or modify the payload, without
+// losing the fastlane (single encode shared by all the sockets) of HandleOut.
+//
+// Sockets that receive the payload unmodified share the encoding of the broadcast, modified payloads are encoded once
+// per distinct value (returning the same map/slice instance to multiple sockets shares its encoding). When both
+// HandleOut and FilterOut match the event, HandleOut takes precedence.
+//
+// ## Example
+//
+//	channel.FilterOut("new_msg", func(event string, payload any, socket *Socket) (out any, deliver bool) {
+//		if Blocked(socket.Get("user"), payload) {
+//			return nil, false // veto
+//		}
+//		return payload, true // unmodified, fastlane
+//	})
+//
+//	channel.FilterOut("price:*", func(event string, payload any, socket *Socket) (out any, deliver bool) {
+//		if socket.Get("plan") == "free" {
+//			return delayedPrices, true // encoded once for all free users
+//		}
+//		return payload, true
+//	})
+func (c *Channel) FilterOut(event string, filter OutFilter) {
+	if c.outFilters == nil {
+		c.outFilters = &pkg.WildcardStore[OutFilter]{}
+	}
+	if err := c.outFilters.Insert(event, filter); err != nil {
+		panic(fmt.Sprintf("[chain.socket] invalid OutFilter for event. Event: %s, Error: %s", event, err.Error()))
+	}
+}
+
+// outFilter gets the OutFilter that matches the event, if any
+func (c *Channel) outFilter(event string) OutFilter {
+	if c.outFilters == nil {
+		return nil
+	}
+	return c.outFilters.Match(event)
+}
+
+// filteredEncoding encoded payload shared by the sockets that receive the same value
+type filteredEncoding struct {
+	payload any
+	bytes   []byte
+}
+
+// dispatchFiltered delivers the message to the sockets accepted by the filter, `encoded` is the encoding of the
+// original message, when available
+func (c *Channel) dispatchFiltered(topic string, message *Message, encoded []byte, sockets []*Socket, filter OutFilter) {
+	var encodings []filteredEncoding
+	if encoded != nil {
+		encodings = append(encodings, filteredEncoding{payload: message.Payload, bytes: encoded})
+	}
+
+	for _, socket := range sockets {
+		out, deliver := filter(message.Event, message.Payload, socket)
+		if !deliver {
+			continue
+		}
+
+		var bytes []byte
+		for _, e := range encodings {
+			if samePayload(e.payload, out) {
+				bytes = e.bytes
+				break
+			}
+		}
+
+		if bytes == nil {
+			broadcast := newMessage(MessageTypeBroadcast, topic, message.Event, out)
+			var err error
+			bytes, err = c.serializer.Encode(broadcast)
+			deleteMessage(broadcast)
+			if err != nil {
+				slog.Debug(
+					"[chain.socket] could not encode filtered message",
+					slog.Any("Error", err),
+					slog.String("Topic", topic),
+					slog.String("Event", message.Event),
+				)
+				continue
+			}
+			encodings = append(encodings, filteredEncoding{payload: out, bytes: bytes})
+		}
+
+		socket.Send(bytes)
+	}
+}
+
+// samePayload checks if both payloads are the same value (or the same instance, for maps and slices)
+func samePayload(a any, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Map, reflect.Pointer, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return va.Pointer() == vb.Pointer()
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	}
+	if va.Comparable() {
+		return a == b
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package socket
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testCountingSerializer counts the number of encodes
+type testCountingSerializer struct {
+	MessageSerializer
+	encodes int
+}
+
+func (s *testCountingSerializer) Encode(v any) ([]byte, error) {
+	s.encodes++
+	return s.MessageSerializer.Encode(v)
+}
+
+func newFilterTestChannel(sockets int, buffer int, factory func(channel *Channel)) (*Channel, []*Socket) {
+	channel := NewChannel("room:*", factory)
+	channel.serializer = &testCountingSerializer{}
+	channel.sockets = map[string]map[*Socket]bool{"room:1": {}}
+
+	var list []*Socket
+	for i := 0; i < sockets; i++ {
+		socket := &Socket{
+			topic:   "room:1",
+			channel: channel,
+			data:    map[string]any{"id": i},
+			session: &Session{socketId: fmt.Sprint(i), messages: make(chan []byte, buffer)},
+		}
+		socket.setStatus(StatusJoined)
+		channel.sockets["room:1"][socket] = true
+		list = append(list, socket)
+	}
+	return channel, list
+}
+
+func Test_Channel_FilterOut(t *testing.T) {
+	modified := map[string]any{"price": "delayed"}
+	channel, sockets := newFilterTestChannel(6, 1, func(channel *Channel) {
+		channel.FilterOut("price:*", func(event string, payload any, socket *Socket) (out any, deliver bool) {
+			switch socket.Get("id").(int) % 3 {
+			case 0:
+				return nil, false // veto
+			case 1:
+				return modified, true
+			default:
+				return payload, true
+			}
+		})
+	})
+
+	encoded, _ := (&MessageSerializer{}).Encode(newMessage(MessageTypeBroadcast, "room:1", "price:btc", "live"))
+	channel.Dispatch("room:1", encoded, "")
+
+	serializer := channel.serializer.(*testCountingSerializer)
+	if serializer.encodes != 1 {
+		t.Errorf("FilterOut() failed: Invalid number of encodes\n   actual: %d\n expected: %d", serializer.encodes, 1)
+	}
+
+	for _, socket := range sockets {
+		var received *Message
+		select {
+		case bytes := <-socket.session.messages:
+			received = newMessageAny()
+			if _, err := (&MessageSerializer{}).Decode(bytes, received); err != nil {
+				t.Fatal(err)
+			}
+		default:
+		}
+
+		id := socket.Get("id").(int)
+		switch id % 3 {
+		case 0:
+			if received != nil {
+				t.Errorf("FilterOut() failed: Vetoed message delivered\n   socket: %d", id)
+			}
+		case 1:
+			if received == nil || received.Payload.(map[string]any)["price"] != "delayed" {
+				t.Errorf("FilterOut() failed: Invalid payload\n   actual: %v\n expected: %v", received, modified)
+			}
+		default:
+			if received == nil || received.Payload != "live" || received.Event != "price:btc" {
+				t.Errorf("FilterOut() failed: Invalid payload\n   actual: %v\n expected: %v", received, "live")
+			}
+		}
+	}
+}
+
+func Test_SamePayload(t *testing.T) {
+	m := map[string]any{"a": 1}
+	s := []any{1, 2}
+	tests := []struct {
+		a, b     any
+		expected bool
+	}{
+		{nil, nil, true},
+		{"a", "a", true},
+		{"a", "b", false},
+		{1, 1.0, false},
+		{m, m, true},
+		{m, map[string]any{"a": 1}, false},
+		{s, s, true},
+		{s, s[:1], false},
+		{struct{ V any }{s}, struct{ V any }{s}, false},
+	}
+	for _, tt := range tests {
+		if actual := samePayload(tt.a, tt.b); actual != tt.expected {
+			t.Errorf("samePayload() failed: Invalid result for %v, %v\n   actual: %v\n expected: %v", tt.a, tt.b, actual, tt.expected)
+		}
+	}
+}
+
+func benchmarkChannelDispatch(b *testing.B, factory func(channel *Channel)) {
+	channel, _ := newFilterTestChannel(1000, 0, factory)
+	encoded, _ := (&MessageSerializer{}).Encode(newMessage(MessageTypeBroadcast, "room:1", "tick", map[string]any{"v": 1}))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		channel.Dispatch("room:1", encoded, "")
+	}
+	b.ReportMetric(float64(channel.serializer.(*testCountingSerializer).encodes)/float64(b.N), "encodes/op")
+}
+
+func BenchmarkChannel_Dispatch_Fastlane(b *testing.B) {
+	benchmarkChannelDispatch(b, func(channel *Channel) {})
+}
+
+func BenchmarkChannel_Dispatch_FilterOut_Unmodified(b *testing.B) {
+	benchmarkChannelDispatch(b, func(channel *Channel) {
+		channel.FilterOut("tick", func(event string, payload any, socket *Socket) (out any, deliver bool) {
+			return payload, true
+		})
+	})
+}
+
+func BenchmarkChannel_Dispatch_FilterOut_Modified(b *testing.B) {
+	shared := map[string]any{"v": 0}
+	benchmarkChannelDispatch(b, func(channel *Channel) {
+		channel.FilterOut("tick", func(event string, payload any, socket *Socket) (out any, deliver bool) {
+			return shared, true
+		})
+	})
+}
+
+func BenchmarkChannel_Dispatch_HandleOut(b *testing.B) {
+	benchmarkChannelDispatch(b, func(channel *Channel) {
+		channel.HandleOut("tick", func(event string, payload any, socket *Socket) {
+			if encoded, err := socket.channel.serializer.Encode(newMessage(MessageTypeBroadcast, socket.topic, event, payload)); err == nil {
+				socket.Send(encoded)
+			}
+		})
+	})
+}
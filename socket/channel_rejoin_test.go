@@ -0,0 +1,70 @@
+package socket
+
+import (
+	"testing"
+)
+
+func Test_Channel_OnRejoin(t *testing.T) {
+	transport := &transportT{}
+	var joinPayload any
+	lastRefs := make(chan int, 1)
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.Join("room:1", func(payload any, socket *Socket) (reply any, err error) {
+			joinPayload = payload
+			return
+		})
+		channel.OnRejoin("room:*", func(lastRef int, socket *Socket) {
+			lastRefs <- lastRef
+			_ = socket.Push("diff", map[string]any{"since": lastRef})
+		})
+	})
+	if _, err := transport.Connect(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	rejoin := newMessage(MessageTypePush, "room:1", "_rejoin", map[string]any{
+		"params":   map[string]any{"token": "abc"},
+		"last_ref": float64(5),
+	})
+	rejoin.Ref = 1
+	rejoin.JoinRef = 1
+	transport.SendMessage(rejoin)
+
+	messages := waitMessages(transport, 2)
+	if len(messages) != 2 {
+		t.Fatalf("OnRejoin() failed: Invalid messages\n   actual: %d\n expected: %d", len(messages), 2)
+	}
+
+	if params, ok := joinPayload.(map[string]any); !ok || params["token"] != "abc" {
+		t.Errorf("OnRejoin() failed: Invalid join payload\n   actual: %v\n expected: %v", joinPayload, map[string]any{"token": "abc"})
+	}
+
+	if lastRef := <-lastRefs; lastRef != 5 {
+		t.Errorf("OnRejoin() failed: Invalid lastRef\n   actual: %d\n expected: %d", lastRef, 5)
+	}
+
+	reply := messages[0]
+	if reply.Kind != MessageTypeReply || reply.Ref != 1 || reply.Status != ReplyStatusCodeOk {
+		t.Errorf("OnRejoin() failed: Invalid join reply\n   actual: %+v", reply)
+	}
+
+	push := messages[1]
+	if push.Event != "diff" || push.Ref != 1 {
+		t.Errorf("OnRejoin() failed: Invalid push\n   actual: %+v\n expected: %s ref=%d", push, "diff", 1)
+	}
+}
+
+func Test_Channel_Join_Does_Not_Trigger_OnRejoin(t *testing.T) {
+	transport := &transportT{}
+	called := false
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.OnRejoin("room:*", func(lastRef int, socket *Socket) {
+			called = true
+		})
+	})
+	joinPoolTestRoom(t, transport)
+
+	if called {
+		t.Errorf("Join() failed: OnRejoin must not be invoked on the first join")
+	}
+}
@@ -0,0 +1,48 @@
+package socket
+
+import (
+	"github.com/cespare/xxhash/v2"
+)
+
+// ShardNodes returns the ids of the nodes of the cluster (see pubsub.Self) that can own the topics of a channel.
+//
+// See Channel.Shard
+type ShardNodes func() []string
+
+// Shard distributes the topics of this channel across the nodes of the cluster, each topic is owned by a single node
+// (rendezvous hash of the topic). Joins and events received by the other nodes are proxied to the owner node via
+// pubsub.DirectBroadcast, so the handlers of a topic (and the Socket state) are always executed on the same node,
+// enabling single-writer semantics (ex. game rooms, collaborative documents).
+//
+// The owner of a topic is defined when the client joins it, changes in the list of nodes only affect new joins. If
+// `nodes` returns an empty list, the topics are handled locally.
+//
+// ## Example
+//
+//	socket.NewChannel("game:*", func(channel *socket.Channel) {
+//		channel.Shard(func() []string {
+//			return cluster.Members() // ids of the nodes, see pubsub.Self()
+//		})
+//		channel.Join("game:*", func(payload any, socket *socket.Socket) (reply any, err error) {
+//			return
+//		})
+//	})
+func (c *Channel) Shard(nodes ShardNodes) {
+	c.shardNodes = nodes
+}
+
+// shardOwner gets the node that owns the topic, `self` if the channel is not sharded
+func (c *Channel) shardOwner(topic string, self string) string {
+	if c.shardNodes == nil {
+		return self
+	}
+	owner := self
+	var max uint64
+	for i, node := range c.shardNodes() {
+		if score := xxhash.Sum64String(node + "\x00" + topic); i == 0 || score > max {
+			owner = node
+			max = score
+		}
+	}
+	return owner
+}
@@ -0,0 +1,190 @@
+package socket
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"github.com/nidorx/chain/pkg"
+)
+
+// StateHook invoked after each change of the State of a topic (see Channel.OnStateChange), `deleted` is true when the
+// key was removed.
+type StateHook func(topic string, key string, value any, deleted bool)
+
+// State concurrent-safe key/value store scoped to a topic of the Channel, see Channel.State
+type State struct {
+	topic string
+	refs  int // sockets joining or joined the topic
+	mutex sync.RWMutex
+	data  map[string]any
+	hook  StateHook
+}
+
+// State gets the state of the topic, useful for room state (ex. game boards) without external storage.
+//
+// The state is created on the first join of the topic (it is already available in the JoinHandler) and is disposed
+// when the last socket leaves (after the LeaveHandler). Returns nil if there is no socket on the topic of this node.
+//
+// ## Example
+//
+//	channel.Join("game:*", func(payload any, socket *Socket) (reply any, err error) {
+//		state := channel.State(socket.Topic())
+//		state.Update("players", func(value any, exists bool) any {
+//			players, _ := value.(int)
+//			return players + 1
+//		})
+//		return
+//	})
+//
+//	channel.HandleIn("move", func(event string, payload any, socket *Socket) (reply any, err error) {
+//		board, _ := channel.State(socket.Topic()).Get("board")
+//		...
+//	})
+func (c *Channel) State(topic string) *State {
+	c.statesMutex.Lock()
+	defer c.statesMutex.Unlock()
+	if c.states == nil {
+		return nil
+	}
+	return c.states[topic]
+}
+
+// OnStateChange registers a hook invoked on the changes of the State of the topics, allowing to sync the state with the
+// other nodes of the cluster (changes received from other nodes are applied with State.Sync, which does not invoke the
+// hook).
+//
+// ## Example
+//
+//	channel.OnStateChange("game:*", func(topic string, key string, value any, deleted bool) {
+//		pubsub.Broadcast("state:"+topic, encodeChange(key, value, deleted))
+//	})
+func (c *Channel) OnStateChange(topic string, hook StateHook) {
+	if c.stateHooks == nil {
+		c.stateHooks = &pkg.WildcardStore[StateHook]{}
+	}
+	if err := c.stateHooks.Insert(c.topicPattern(topic), hook); err != nil {
+		panic(fmt.Sprintf("[chain.socket] invalid StateHook for topic. Topic: %s, Error: %s", topic, err.Error()))
+	}
+}
+
+// acquireState creates the state of the topic on the first join
+func (c *Channel) acquireState(topic string) {
+	c.statesMutex.Lock()
+	defer c.statesMutex.Unlock()
+
+	if c.states == nil {
+		c.states = map[string]*State{}
+	}
+	state, exists := c.states[topic]
+	if !exists {
+		state = &State{topic: topic, data: map[string]any{}}
+		if c.stateHooks != nil {
+			state.hook = c.stateHooks.Match(topic)
+		}
+		c.states[topic] = state
+	}
+	state.refs++
+}
+
+// releaseState disposes the state of the topic when the last socket leaves
+func (c *Channel) releaseState(topic string) {
+	c.statesMutex.Lock()
+	defer c.statesMutex.Unlock()
+
+	if state, exists := c.states[topic]; exists {
+		if state.refs--; state.refs <= 0 {
+			delete(c.states, topic)
+		}
+	}
+}
+
+// Topic of the state
+func (s *State) Topic() string {
+	return s.topic
+}
+
+// Get a value from the state
+func (s *State) Get(key string) (value any, exists bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	value, exists = s.data[key]
+	return
+}
+
+// Set a value on the state
+func (s *State) Set(key string, value any) {
+	s.mutex.Lock()
+	s.data[key] = value
+	s.mutex.Unlock()
+
+	s.notify(key, value, false)
+}
+
+// Delete a value from the state
+func (s *State) Delete(key string) {
+	s.mutex.Lock()
+	_, exists := s.data[key]
+	delete(s.data, key)
+	s.mutex.Unlock()
+
+	if exists {
+		s.notify(key, nil, true)
+	}
+}
+
+// Update atomically replaces the value of the key with the result of fn (read-modify-write)
+func (s *State) Update(key string, fn func(value any, exists bool) any) any {
+	s.mutex.Lock()
+	value, exists := s.data[key]
+	value = fn(value, exists)
+	s.data[key] = value
+	s.mutex.Unlock()
+
+	s.notify(key, value, false)
+	return value
+}
+
+// Snapshot copy of the state data
+func (s *State) Snapshot() map[string]any {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshot := make(map[string]any, len(s.data))
+	for key, value := range s.data {
+		snapshot[key] = value
+	}
+	return snapshot
+}
+
+// Sync applies a change received from another node of the cluster, without invoking the StateHook
+func (s *State) Sync(key string, value any, deleted bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if deleted {
+		delete(s.data, key)
+	} else {
+		s.data[key] = value
+	}
+}
+
+func (s *State) notify(key string, value any, deleted bool) {
+	if s.hook == nil {
+		return
+	}
+
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			slog.Error(
+				"[chain.socket] panic in StateHook",
+				slog.Any("Panic", rcv),
+				slog.String("Topic", s.topic),
+				slog.String("Key", key),
+				slog.String("Stack", string(debug.Stack())),
+			)
+		}
+	}()
+	s.hook(s.topic, key, value, deleted)
+}
@@ -0,0 +1,92 @@
+package socket
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func Test_Channel_State(t *testing.T) {
+	var channel *Channel
+	var changesMutex sync.Mutex
+	var changes []string
+
+	first := &transportT{}
+	handler := newPoolTestHandler(first, 0, func(c *Channel) {
+		channel = c
+		c.Join("room:game", func(payload any, socket *Socket) (reply any, err error) {
+			c.State(socket.Topic()).Update("players", func(value any, exists bool) any {
+				players, _ := value.(int)
+				return players + 1
+			})
+			return
+		})
+		c.Join("room:closed", func(payload any, socket *Socket) (reply any, err error) {
+			return nil, errors.New("closed")
+		})
+		c.OnStateChange("room:*", func(topic string, key string, value any, deleted bool) {
+			changesMutex.Lock()
+			defer changesMutex.Unlock()
+			changes = append(changes, topic+"."+key)
+		})
+	})
+	second := &transportT{handler: handler}
+
+	send := func(transport *transportT, topic string, event string) {
+		transport.Clear()
+		message := newMessage(MessageTypePush, topic, event, nil)
+		message.Ref = 1
+		message.JoinRef = 1
+		transport.SendMessage(message)
+		if messages := waitMessages(transport, 1); len(messages) != 1 {
+			t.Fatalf("%s failed: no reply", event)
+		}
+	}
+
+	for _, transport := range []*transportT{first, second} {
+		if _, err := transport.Connect(map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+		defer transport.Close()
+		send(transport, "room:game", "_join")
+	}
+
+	state := channel.State("room:game")
+	if state == nil {
+		t.Fatalf("State() failed: State not created")
+	}
+	if players, _ := state.Get("players"); players != 2 {
+		t.Errorf("State() failed: Invalid players\n   actual: %v\n expected: %v", players, 2)
+	}
+	changesMutex.Lock()
+	if len(changes) != 2 || changes[0] != "room:game.players" {
+		t.Errorf("OnStateChange() failed: Invalid changes\n   actual: %v\n expected: %v", changes, "[room:game.players room:game.players]")
+	}
+	changesMutex.Unlock()
+
+	state.Sync("board", "x", false)
+	if board, _ := state.Get("board"); board != "x" {
+		t.Errorf("Sync() failed: Invalid state\n   actual: %v\n expected: %v", board, "x")
+	}
+	changesMutex.Lock()
+	if len(changes) != 2 {
+		t.Errorf("Sync() failed: StateHook invoked\n   actual: %v", changes)
+	}
+	changesMutex.Unlock()
+
+	// rejected join does not create the state
+	send(first, "room:closed", "_join")
+	if channel.State("room:closed") != nil {
+		t.Errorf("State() failed: State created for rejected join")
+	}
+
+	send(first, "room:game", "_leave")
+	if channel.State("room:game") != state {
+		t.Errorf("State() failed: State disposed before the last socket leaves")
+	}
+
+	send(second, "room:game", "_leave")
+	if channel.State("room:game") != nil {
+		t.Errorf("State() failed: State not disposed after the last socket leaves")
+	}
+}
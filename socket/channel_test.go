@@ -0,0 +1,66 @@
+package socket
+
+import (
+	"fmt"
+	"testing"
+)
+
+var benchmarkDispatchSockets = []int{1, 10, 100, 1000, 10000}
+
+// benchmarkDispatch broadcast of a message to the sockets of the topic, `encoded` dispatches the message received
+// from pubsub ([]byte), otherwise the message is encoded by the channel (*Message)
+func benchmarkDispatch(b *testing.B, encoded bool, factory func(channel *Channel)) {
+	for _, count := range benchmarkDispatchSockets {
+		b.Run(fmt.Sprintf("sockets=%d", count), func(b *testing.B) {
+			channel, sockets := newFilterTestChannel(count, 0, factory)
+			bytes, _ := (&MessageSerializer{}).Encode(newMessage(MessageTypeBroadcast, "room:1", "tick", map[string]any{"v": 1}))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if encoded {
+					channel.Dispatch("room:1", bytes, "")
+				} else {
+					message := newMessage(MessageTypeBroadcast, "room:1", "tick", map[string]any{"v": 1})
+					channel.Dispatch("room:1", message, "")
+					deleteMessage(message)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(channel.serializer.(*testCountingSerializer).encodes)/float64(b.N), "encodes/op")
+			b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(sockets)), "ns/socket")
+		})
+	}
+}
+
+func BenchmarkChannel_Dispatch_Encoded(b *testing.B) {
+	benchmarkDispatch(b, true, func(channel *Channel) {})
+}
+
+func BenchmarkChannel_Dispatch_Message(b *testing.B) {
+	benchmarkDispatch(b, false, func(channel *Channel) {})
+}
+
+func BenchmarkChannel_Dispatch_Encoded_HandleOut(b *testing.B) {
+	benchmarkDispatch(b, true, func(channel *Channel) {
+		channel.HandleOut("tick", func(event string, payload any, socket *Socket) {
+			message := newMessage(MessageTypeBroadcast, socket.topic, event, payload)
+			if encoded, err := channel.serializer.Encode(message); err == nil {
+				socket.Send(encoded)
+			}
+			deleteMessage(message)
+		})
+	})
+}
+
+func BenchmarkChannel_Dispatch_Message_HandleOut(b *testing.B) {
+	benchmarkDispatch(b, false, func(channel *Channel) {
+		channel.HandleOut("tick", func(event string, payload any, socket *Socket) {
+			message := newMessage(MessageTypeBroadcast, socket.topic, event, payload)
+			if encoded, err := channel.serializer.Encode(message); err == nil {
+				socket.Send(encoded)
+			}
+			deleteMessage(message)
+		})
+	})
+}
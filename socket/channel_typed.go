@@ -0,0 +1,64 @@
+package socket
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/nidorx/chain"
+)
+
+// TypedInHandler InHandler that receives the payload decoded into T, see On
+type TypedInHandler[T any] func(event string, payload T, socket *Socket) (reply any, err error)
+
+// On Handle incoming `event`s (see Channel.HandleIn) with the payload decoded into T, removing the type assertions of
+// map[string]any. Struct payloads are also validated using chain.Validator (`binding` tags).
+//
+// If the payload cannot be decoded (or is invalid), the client receives an error reply
+// `{"reason": "invalid payload", "errors": [...]}`, and the handler is not invoked.
+//
+// ## Example
+//
+//	type NewMessage struct {
+//		Body string `json:"body" binding:"required,max=280"`
+//	}
+//
+//	socket.On(channel, "new_msg", func(event string, msg NewMessage, socket *socket.Socket) (reply any, err error) {
+//		err = socket.Broadcast("new_msg", msg)
+//		return
+//	})
+func On[T any](channel *Channel, event string, handler TypedInHandler[T]) {
+	channel.HandleIn(event, func(event string, payload any, socket *Socket) (reply any, err error) {
+		var value T
+		if value, err = decodeTypedPayload[T](payload); err != nil {
+			reply = map[string]any{
+				"reason": ErrInvalidPayload.Error(),
+				"errors": validationErrors(err),
+			}
+			err = errors.Join(ErrInvalidPayload, err)
+			return
+		}
+		return handler(event, value, socket)
+	})
+}
+
+// decodeTypedPayload converts the payload received from the client into T
+func decodeTypedPayload[T any](payload any) (value T, err error) {
+	if typed, isTyped := payload.(T); isTyped {
+		// already converted (ex. by Channel.ValidateIn)
+		return typed, nil
+	}
+
+	if payload != nil {
+		var encoded []byte
+		if encoded, err = json.Marshal(payload); err != nil {
+			return
+		}
+		if err = json.Unmarshal(encoded, &value); err != nil {
+			return
+		}
+	}
+	if chain.Validator != nil {
+		err = chain.Validator.ValidateStruct(value)
+	}
+	return
+}
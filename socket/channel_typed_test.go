@@ -0,0 +1,63 @@
+package socket
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Channel_On(t *testing.T) {
+	var received any
+	channel := NewChannel("chat:*", func(channel *Channel) {
+		On(channel, "user", func(event string, payload testValidateInPayload, socket *Socket) (reply any, err error) {
+			received = payload
+			return "ok", nil
+		})
+		On(channel, "ids", func(event string, payload []int, socket *Socket) (reply any, err error) {
+			received = payload
+			return
+		})
+		channel.ValidateIn("validated", &testValidateInPayload{})
+		On(channel, "validated", func(event string, payload *testValidateInPayload, socket *Socket) (reply any, err error) {
+			received = payload
+			return
+		})
+	})
+
+	tests := []struct {
+		event    string
+		payload  any
+		expected any
+		invalid  bool
+	}{
+		{"user", map[string]any{"name": "Alex", "age": float64(33)}, testValidateInPayload{Name: "Alex", Age: 33}, false},
+		{"user", map[string]any{"name": "Alex", "age": "33"}, nil, true},
+		{"user", map[string]any{"age": float64(33)}, nil, true},
+		{"ids", []any{float64(1), float64(2)}, []int{1, 2}, false},
+		{"validated", map[string]any{"name": "Alex", "age": float64(33)}, &testValidateInPayload{Name: "Alex", Age: 33}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			received = nil
+			reply, err := channel.handleIn(tt.event, tt.payload, nil)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidPayload) {
+					t.Fatalf("On() failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrInvalidPayload)
+				}
+				if r, _ := reply.(map[string]any); r["reason"] != ErrInvalidPayload.Error() {
+					t.Errorf("On() failed: Invalid reply\n   actual: %v", reply)
+				}
+				if received != nil {
+					t.Errorf("On() failed: Handler invoked with invalid payload")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("On() failed: Unexpected error\n   actual: %v", err)
+			}
+			if !reflect.DeepEqual(received, tt.expected) {
+				t.Errorf("On() failed: Invalid payload\n   actual: %v\n expected: %v", received, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package socket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/pkg"
+)
+
+var ErrInvalidPayload = fmt.Errorf("invalid payload")
+
+// PayloadValidator invoked before the InHandler, validates and converts the payload received from the client. The
+// value returned replaces the payload received by the InHandler.
+//
+// See Channel.ValidateIn
+type PayloadValidator func(event string, payload any) (any, error)
+
+// ValidationError describes a field that did not pass the validation, sent to the client in the error reply.
+type ValidationError struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidateIn validates incoming `event`s payload before the InHandler runs.
+//
+// The `schema` can be a struct (or pointer to struct) or a PayloadValidator. When a struct is informed, the payload is
+// decoded into a new instance of the struct and validated using chain.Validator (`binding` tags). The InHandler then
+// receives the pointer to the struct, eliminating type-assertion boilerplate.
+//
+// If the validation fails, the client receives an error reply `{"reason": "invalid payload", "errors": [...]}`, and
+// the InHandler is not invoked.
+//
+// ## Example
+//
+//	type NewMessage struct {
+//		Body string `json:"body" binding:"required,max=280"`
+//	}
+//
+//	channel.ValidateIn("new_msg", &NewMessage{})
+//
+//	channel.HandleIn("new_msg", func(event string, payload any, socket *Socket) (reply any, err error) {
+//		msg := payload.(*NewMessage)
+//		return
+//	})
+func (c *Channel) ValidateIn(event string, schema any) {
+	var v PayloadValidator
+	switch s := schema.(type) {
+	case PayloadValidator:
+		v = s
+	case func(event string, payload any) (any, error):
+		v = s
+	default:
+		typ := reflect.TypeOf(schema)
+		if typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ == nil || typ.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("[chain.socket] invalid schema for event. Event: %s, Schema: %v", event, schema))
+		}
+		v = structPayloadValidator(typ)
+	}
+
+	if c.inValidators == nil {
+		c.inValidators = &pkg.WildcardStore[PayloadValidator]{}
+	}
+	if err := c.inValidators.Insert(event, v); err != nil {
+		panic(fmt.Sprintf("[chain.socket] invalid PayloadValidator for event. Event: %s, Error: %s", event, err.Error()))
+	}
+}
+
+// validateIn invokes the PayloadValidator that matches the event, if any
+func (c *Channel) validateIn(event string, payload any) (out any, reply any, err error) {
+	out = payload
+	if c.inValidators == nil {
+		return
+	}
+	v := c.inValidators.Match(event)
+	if v == nil {
+		return
+	}
+
+	if out, err = v(event, payload); err != nil {
+		reply = map[string]any{
+			"reason": ErrInvalidPayload.Error(),
+			"errors": validationErrors(err),
+		}
+		err = errors.Join(ErrInvalidPayload, err)
+	}
+	return
+}
+
+// structPayloadValidator decodes the payload into a new instance of the given struct type and validates it
+func structPayloadValidator(typ reflect.Type) PayloadValidator {
+	return func(event string, payload any) (any, error) {
+		value := reflect.New(typ).Interface()
+		if payload != nil {
+			encoded, err := json.Marshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			if err = json.Unmarshal(encoded, value); err != nil {
+				return nil, err
+			}
+		}
+		if chain.Validator != nil {
+			if err := chain.Validator.ValidateStruct(value); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+}
+
+// validationErrors converts the error in a list of ValidationError that can be sent to the client
+func validationErrors(err error) []ValidationError {
+	var fieldErrors validator.ValidationErrors
+	if errors.As(err, &fieldErrors) {
+		out := make([]ValidationError, 0, len(fieldErrors))
+		for _, fe := range fieldErrors {
+			out = append(out, ValidationError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Param:   fe.Param(),
+				Message: fe.Error(),
+			})
+		}
+		return out
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return []ValidationError{{Field: typeError.Field, Rule: "type", Param: typeError.Type.String(), Message: err.Error()}}
+	}
+
+	return []ValidationError{{Message: err.Error()}}
+}
@@ -0,0 +1,78 @@
+package socket
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testValidateInPayload struct {
+	Name string `json:"name" binding:"required"`
+	Age  int    `json:"age" binding:"gte=18"`
+}
+
+func Test_Channel_ValidateIn(t *testing.T) {
+	var received any
+	channel := NewChannel("chat:*", func(channel *Channel) {
+		channel.ValidateIn("user", &testValidateInPayload{})
+		channel.ValidateIn("custom", PayloadValidator(func(event string, payload any) (any, error) {
+			if payload == nil {
+				return nil, errors.New("payload is required")
+			}
+			return payload, nil
+		}))
+		channel.HandleIn("*", func(event string, payload any, socket *Socket) (reply any, err error) {
+			received = payload
+			return
+		})
+	})
+
+	tests := []struct {
+		event    string
+		payload  any
+		expected any
+		errors   []ValidationError
+	}{
+		{"user", map[string]any{"name": "Alex", "age": float64(33)}, &testValidateInPayload{Name: "Alex", Age: 33}, nil},
+		{"user", map[string]any{"age": float64(10)}, nil, []ValidationError{
+			{Field: "Name", Rule: "required"},
+			{Field: "Age", Rule: "gte", Param: "18"},
+		}},
+		{"user", map[string]any{"name": "Alex", "age": "33"}, nil, []ValidationError{{Field: "age", Rule: "type", Param: "int"}}},
+		{"custom", nil, nil, []ValidationError{{Message: "payload is required"}}},
+		{"custom", "value", "value", nil},
+		{"other", "value", "value", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			received = nil
+			reply, err := channel.handleIn(tt.event, tt.payload, nil)
+			if tt.errors == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(received, tt.expected) {
+					t.Errorf("Invalid payload\n   actual: %v\n expected: %v", received, tt.expected)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("Invalid error\n   actual: %v\n expected: %v", err, ErrInvalidPayload)
+			}
+			if received != nil {
+				t.Errorf("InHandler must not be invoked")
+			}
+			validationErrors := reply.(map[string]any)["errors"].([]ValidationError)
+			if len(validationErrors) != len(tt.errors) {
+				t.Fatalf("Invalid errors\n   actual: %v\n expected: %v", validationErrors, tt.errors)
+			}
+			for i, e := range tt.errors {
+				a := validationErrors[i]
+				if a.Field != e.Field || a.Rule != e.Rule || a.Param != e.Param || (e.Message != "" && a.Message != e.Message) {
+					t.Errorf("Invalid error\n   actual: %v\n expected: %v", a, e)
+				}
+			}
+		})
+	}
+}
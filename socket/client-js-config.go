@@ -5,7 +5,10 @@ import (
 	"embed"
 	"fmt"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nidorx/chain"
@@ -13,11 +16,12 @@ import (
 
 var (
 	//go:embed client/chain.js
-	clientJsFS             embed.FS
-	clientJsContent        []byte
-	clientJsEtag           string
-	clientJsModTime, _     = time.Parse(time.DateTime, "2023-05-07 00:00:00")
-	configuredRouterClient = map[*chain.Router]bool{}
+	clientJsFS                  embed.FS
+	clientJsContent             []byte
+	clientJsEtag                string
+	clientJsModTime, _          = time.Parse(time.DateTime, "2023-05-07 00:00:00")
+	configuredRouterClient      = map[*chain.Router]map[string]bool{} // routes of chain.js by router
+	configuredRouterClientMutex sync.Mutex
 )
 
 func init() {
@@ -29,16 +33,54 @@ func init() {
 	}
 }
 
-// ClientJsHandler add "/chain.js" endpoint
-func ClientJsHandler(r *chain.Router, route string) {
-	if _, exist := configuredRouterClient[r]; exist {
+// ClientJsOptions configuration of the client endpoint, see Handler.ClientJs
+type ClientJsOptions struct {
+	Disabled     bool   // Does not register the endpoint (ex. client served from a CDN or bundler)
+	Path         string // Path of the endpoint. Default "chain.js" in the same directory of the socket endpoint
+	CacheControl string // Value of the Cache-Control header, not sent if empty
+}
+
+// ClientJsHandler add the "/chain.js" endpoint, in the same directory of the socket endpoint (ex. the endpoint
+// "/v1/socket" serves the client at "/v1/chain.js", see chain.RouterGroup.Configure)
+//
+// ## Example
+//
+//	socket.ClientJsHandler(router, "/socket", socket.ClientJsOptions{
+//		Path:         "/assets/chain.js",
+//		CacheControl: "public, max-age=86400",
+//	})
+func ClientJsHandler(r *chain.Router, route string, options ...ClientJsOptions) {
+	var config ClientJsOptions
+	if len(options) > 0 {
+		config = options[0]
+	}
+	if config.Disabled {
+		return
+	}
+
+	if config.Path != "" {
+		route = config.Path
+	} else {
+		route = strings.TrimSuffix(path.Dir(route), "/") + "/chain.js"
+	}
+
+	configuredRouterClientMutex.Lock()
+	defer configuredRouterClientMutex.Unlock()
+	if configuredRouterClient[r] == nil {
+		configuredRouterClient[r] = map[string]bool{}
+	}
+	if configuredRouterClient[r][route] {
 		return
 	}
+	configuredRouterClient[r][route] = true
 
-	r.GET("/chain.js", func(ctx *chain.Context) {
+	r.GET(route, func(ctx *chain.Context) {
 		ctx.SetHeader("Content-Type", "text/javascript; charset=utf-8")
 		ctx.SetHeader("Content-Length", strconv.Itoa(len(clientJsContent)))
 		ctx.SetHeader("ETag", clientJsEtag)
+		if config.CacheControl != "" {
+			ctx.SetHeader("Cache-Control", config.CacheControl)
+		}
 		http.ServeContent(ctx.Writer, ctx.Request, "/chain.js", clientJsModTime, bytes.NewReader(clientJsContent))
 	})
 }
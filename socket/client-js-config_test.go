@@ -0,0 +1,62 @@
+package socket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func newClientJsTestHandler() *Handler {
+	return &Handler{
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {}),
+		},
+	}
+}
+
+func Test_ClientJsHandler_Group(t *testing.T) {
+	router := chain.New()
+	router.Configure("/socket", newClientJsTestHandler())
+	router.Group("/v1").Configure("/socket", newClientJsTestHandler())
+
+	for _, route := range []string{"/chain.js", "/v1/chain.js"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, route, nil))
+		if w.Code != http.StatusOK || w.Body.Len() != len(clientJsContent) {
+			t.Errorf("ClientJsHandler() failed: Invalid response of %s\n   actual: %d\n expected: %d", route, w.Code, http.StatusOK)
+		}
+	}
+
+	if route, _ := router.Lookup(http.MethodPost, "/v1/socket/sse"); route == nil {
+		t.Errorf("Configure() failed: transport endpoint not registered under the group prefix")
+	}
+}
+
+func Test_ClientJsHandler_Options(t *testing.T) {
+	router := chain.New()
+
+	disabled := newClientJsTestHandler()
+	disabled.ClientJs = ClientJsOptions{Disabled: true}
+	router.Configure("/socket", disabled)
+
+	custom := newClientJsTestHandler()
+	custom.ClientJs = ClientJsOptions{Path: "/assets/chain.js", CacheControl: "public, max-age=86400"}
+	router.Configure("/ws", custom)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/chain.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("ClientJsHandler() failed: Invalid status of disabled endpoint\n   actual: %d\n expected: %d", w.Code, http.StatusNotFound)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/chain.js", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("ClientJsHandler() failed: Invalid status\n   actual: %d\n expected: %d", w.Code, http.StatusOK)
+	}
+	if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "public, max-age=86400" {
+		t.Errorf("ClientJsHandler() failed: Invalid Cache-Control\n   actual: %s\n expected: %s", cacheControl, "public, max-age=86400")
+	}
+}
@@ -4,15 +4,23 @@
     const CHANNEL = 'Channel';
     const TRANSPORT = 'Transport';
 
+    // protocol version of this client, negotiated with the server on connect (see socket.ProtocolVersionParam)
+    const PROTOCOL_VERSION = '1.0.0';
+
     const MESSAGE_KIND_PUSH = 0;
     const MESSAGE_KIND_REPLY = 1;
     const MESSAGE_KIND_BROADCAST = 2;
 
+    const BINARY_FRAME_FLAG = 0x01;
+    const BINARY_FRAME_HEADER_SIZE = 15;
+
     let logGroupLen = Math.max(TRANSPORT.length, CHANNEL.length, SOCKET.length);
 
     const Chain = window.Chain = {
+        Version: PROTOCOL_VERSION,
         Socket: Socket,
         Transport: { SSE: TransportSSE },
+        Negotiate: TransportNegotiate,
         Retry: Retry,
         Events: Events,
         Push: Push,
@@ -46,11 +54,72 @@
 
     function encode(message) {
         let { joinRef, ref, topic, event, payload } = message;
+        if (payload instanceof ArrayBuffer || ArrayBuffer.isView(payload)) {
+            // binary payload, without the base64/JSON overhead
+            return encodeBinary(MESSAGE_KIND_PUSH, joinRef, ref, topic, event, payload);
+        }
         let s = JSON.stringify([MESSAGE_KIND_PUSH, joinRef, ref, topic, event, payload]);
         return s.substr(1, s.length - 2);
     }
 
+    // Binary frame (see socket.encodeBinaryFrame)
+    // [flag: byte] [kind: byte] [status: byte] [joinRef: uint32] [ref: uint32] [topicLen: uint16] [eventLen: uint16]
+    // [topic: topicLen] [event: eventLen] [payload: ...]
+    function encodeBinary(kind, joinRef, ref, topic, event, payload) {
+        let encoder = new TextEncoder();
+        let topicBytes = encoder.encode(topic || '');
+        let eventBytes = encoder.encode(event || '');
+        let payloadBytes = payload instanceof ArrayBuffer
+            ? new Uint8Array(payload)
+            : new Uint8Array(payload.buffer, payload.byteOffset, payload.byteLength);
+
+        let frame = new Uint8Array(BINARY_FRAME_HEADER_SIZE + topicBytes.length + eventBytes.length + payloadBytes.length);
+        let view = new DataView(frame.buffer);
+        view.setUint8(0, BINARY_FRAME_FLAG);
+        view.setUint8(1, kind);
+        view.setUint8(2, 0);
+        view.setUint32(3, joinRef || 0);
+        view.setUint32(7, ref || 0);
+        view.setUint16(11, topicBytes.length);
+        view.setUint16(13, eventBytes.length);
+        frame.set(topicBytes, BINARY_FRAME_HEADER_SIZE);
+        frame.set(eventBytes, BINARY_FRAME_HEADER_SIZE + topicBytes.length);
+        frame.set(payloadBytes, BINARY_FRAME_HEADER_SIZE + topicBytes.length + eventBytes.length);
+        return frame;
+    }
+
+    function decodeBinary(frame) {
+        let view = new DataView(frame.buffer, frame.byteOffset, frame.byteLength);
+        let decoder = new TextDecoder();
+        let topicLen = view.getUint16(11);
+        let eventLen = view.getUint16(13);
+        let start = BINARY_FRAME_HEADER_SIZE;
+
+        let kind = view.getUint8(1);
+        let joinRef = view.getUint32(3);
+        let ref = view.getUint32(7);
+        let topic = decoder.decode(frame.subarray(start, start + topicLen));
+        let event = decoder.decode(frame.subarray(start + topicLen, start + topicLen + eventLen));
+        let payload = frame.slice(start + topicLen + eventLen).buffer;
+
+        if (kind === MESSAGE_KIND_REPLY) {
+            payload = { status: view.getUint8(2) === 0 ? 'ok' : 'error', response: payload };
+            event = '_reply';
+            topic = undefined;
+        } else if (kind === MESSAGE_KIND_BROADCAST) {
+            joinRef = ref = undefined;
+        }
+        return { joinRef: joinRef, ref: ref, topic: topic, event: event, payload: payload, kind: kind };
+    }
+
     function decode(rawMessage) {
+        if (typeof rawMessage !== 'string') {
+            return decodeBinary(new Uint8Array(rawMessage));
+        }
+        if (rawMessage[0] === 'b') {
+            // binary frame sent in base64 by text transports (SSE)
+            return decodeBinary(Uint8Array.from(atob(rawMessage.substring(1)), c => c.charCodeAt(0)));
+        }
         // Push      = [kind, joinRef, ref,  topic, event, payload]
         // Reply     = [kind, joinRef, ref, status,        payload]
         // Broadcast = [kind,                topic, event, payload]
@@ -83,9 +152,12 @@
 
         let ref = 1;
         let connected = false;
+        let heartbeatTimer = null;
 
-        let transport = options.transport || TransportSSE;
+        // without a transport, it is selected from the server capabilities (see TransportNegotiate)
+        let transport = options.transport || TransportNegotiate;
         let conn = transport(endpoint, options.transportOptions || {})
+        conn.on("info", onConnInfo);
         conn.on("open", onConnOpen);
         conn.on("error", onConnError);
         conn.on("message", onConnMessage);
@@ -95,6 +167,8 @@
             timeout: options.timeout || 30000,
             rejoinInterval: options.rejoinInterval || [1000, 2000, 5000, 10000],
             reconnectInterval: options.reconnectInterval || [10, 50, 100, 150, 200, 250, 500, 1000, 2000, 5000],
+            // interval of the heartbeats, keeps the session alive on servers with idle timeout. Disabled if zero
+            heartbeatInterval: options.heartbeatInterval || 0,
             on: events.on.bind(events),
             isConnected: () => connected,
             push: push,
@@ -167,6 +241,16 @@
             return ref;
         }
 
+        function onConnInfo(info) {
+            let major = PROTOCOL_VERSION.split('.')[0];
+            if (info.versions && !info.versions.some(version => version.split('.')[0] === major)) {
+                Chain.log(SOCKET, 'unsupported protocol version %s, server accepts %o', PROTOCOL_VERSION, info.versions);
+            }
+            if (!options.heartbeatInterval && info.heartbeatInterval > 0) {
+                socket.heartbeatInterval = info.heartbeatInterval;
+            }
+        }
+
         function onConnOpen() {
             Chain.log(SOCKET, 'connected to %s', endpoint);
 
@@ -178,14 +262,35 @@
                 sendBuffer.splice(0);
             }
 
+            startHeartbeat();
+
             events.emit('open');
         }
 
         function onConnClose(event) {
             connected = false;
+            stopHeartbeat();
             events.emit('close');
         }
 
+        function startHeartbeat() {
+            stopHeartbeat();
+            if (socket.heartbeatInterval > 0) {
+                heartbeatTimer = setInterval(() => {
+                    if (connected) {
+                        push({ topic: '', event: 'heartbeat', payload: {}, ref: makeRef(), joinRef: 0 });
+                    }
+                }, socket.heartbeatInterval);
+            }
+        }
+
+        function stopHeartbeat() {
+            if (heartbeatTimer) {
+                clearInterval(heartbeatTimer);
+                heartbeatTimer = null;
+            }
+        }
+
         function onConnMessage(data) {
             let message = decode(data);
             let { topic, event, payload, ref, joinRef } = message;
@@ -205,6 +310,7 @@
         }
 
         function disconnect(callback, code, reason) {
+            stopHeartbeat();
             conn.close();
         }
     }
@@ -229,6 +335,8 @@
 
         let state = CHANNEL_STATE_CLOSED;
         let joinedOnce = false;
+        let joinedSuccessfully = false;
+        let lastRef = 0; // ref of the last push received, sent to the server on rejoin
         let timeout = socket.timeout;
 
         const channel = {
@@ -246,6 +354,7 @@
             isLeaving: stateIsFn(CHANNEL_STATE_LEAVING),
             topic: () => topic,
             joinRef: joinRef,
+            onAsk: onAsk,
         };
 
         let rejoinRetry = Retry(() => {
@@ -268,6 +377,9 @@
         let joinPush = Push(socket, channel, '_join', chanParams, timeout)
             .on('ok', () => {
                 state = CHANNEL_STATE_JOINED;
+                joinedSuccessfully = true;
+                // refs of pushes restart on each join
+                lastRef = 0;
                 rejoinRetry.reset();
                 pushBuffer.forEach(push => push.send());
                 pushBuffer.splice(0);
@@ -320,6 +432,31 @@
             trigger(`chan_reply_${ref}`, payload);
         });
 
+        // server-initiated requests (see Socket.Ask)
+        const askHandlers = {};
+        channel.on('_ask', (ask, ref) => {
+            const reply = (status, response) => socket.push({
+                topic: topic,
+                event: '_ask_reply',
+                payload: { ref, status, response },
+                ref: socket.ref(),
+                joinRef: joinRef()
+            });
+
+            const handler = askHandlers[ask.event];
+            if (!handler) {
+                Chain.log(CHANNEL, 'unhandled ask %s %s (%s)', topic, ask.event, ref);
+                reply('error', { reason: 'unhandled' });
+                return;
+            }
+            Promise.resolve()
+                .then(() => handler(ask.payload))
+                .then(
+                    response => reply('ok', response),
+                    err => reply('error', { reason: err && err.message || String(err) })
+                );
+        });
+
         // Overridable message hook
         // Receives all events for specialized message handling before dispatching to the channel callbacks.
         // Must return the payload, modified or unmodified
@@ -354,7 +491,12 @@
             }
             socket.leaveOpenTopic(topic);
             state = CHANNEL_STATE_JOINING;
-            joinPush.resend(timeout);
+            if (joinedSuccessfully) {
+                // server can send a state diff, see Channel.OnRejoin
+                joinPush.resend(timeout, '_rejoin', { params: chanParams, last_ref: lastRef });
+            } else {
+                joinPush.resend(timeout);
+            }
         }
 
         /**
@@ -408,10 +550,17 @@
          *    .on("error", err => console.log("syntax errored", err))
          *    .on("timeout", () => console.log("timed out pushing"))
          *
+         *  // request/response, resolves with the reply payload, rejects on error reply or timeout
+         *  try {
+         *    const rank = await channel.push("rank", {}, 5000)
+         *  } catch (err) {
+         *    console.log(err.status, err.response)
+         *  }
+         *
          * @param event
          * @param payload
          * @param p_timeout
-         * @return {any}
+         * @return {any} the Push, also a Promise (thenable) of the reply payload
          */
         function push(event, payload, p_timeout = timeout) {
             payload = payload || {};
@@ -439,6 +588,16 @@
                 return;
             }
 
+            if (event === '_batch' && Array.isArray(payload)) {
+                // broadcasts coalesced by the server (see Channel.Batch)
+                payload.forEach(([b_event, b_payload]) => trigger(b_event, b_payload, p_topic, ref, p_joinRef));
+                return;
+            }
+
+            if (ref && event !== '_reply' && p_topic === topic) {
+                lastRef = ref;
+            }
+
             let handledPayload = onMessage(event, payload, ref, p_joinRef);
             if (payload && !handledPayload) {
                 throw new Error("channel onMessage callbacks must return the payload, modified or unmodified");
@@ -447,6 +606,26 @@
             events.emit(event, handledPayload, ref, p_joinRef || joinRef());
         }
 
+        /**
+         * Answers the requests of the server for the `event` (see Socket.Ask). The callback receives the payload and
+         * returns the response (or a Promise of it), throwing (or rejecting) responds with an error.
+         *
+         * @example
+         *  channel.onAsk('confirm', ({message}) => window.confirm(message))
+         *
+         * @param {string} event
+         * @param {function(any): any} callback
+         * @return {function()} removes the callback
+         */
+        function onAsk(event, callback) {
+            askHandlers[event] = callback;
+            return () => {
+                if (askHandlers[event] === callback) {
+                    delete askHandlers[event];
+                }
+            };
+        }
+
         function canPush() {
             return socket.isConnected() && channel.isJoined();
         }
@@ -478,6 +657,7 @@
         let ref = socket.ref();
         let refEvent;
         let refEventCancel;
+        let promise = null;
 
         const push = {
             on: (event, callback) => {
@@ -495,11 +675,37 @@
             timeout: () => timeout,
             cancelTimeout: cancelTimeout,
             startTimeout: startTimeout,
-            ref: () => ref
+            ref: () => ref,
+            then: (onFulfilled, onRejected) => toPromise().then(onFulfilled, onRejected),
+            catch: (onRejected) => toPromise().catch(onRejected),
+            finally: (onFinally) => toPromise().finally(onFinally)
         };
 
         return push;
 
+        /**
+         * Promise of the reply, resolves with the payload of the "ok" reply, rejects with an Error on "error" reply
+         * (err.response has the payload) or "timeout"
+         */
+        function toPromise() {
+            if (!promise) {
+                promise = new Promise((resolve, reject) => {
+                    push
+                        .on('ok', resolve)
+                        .on('error', response => reject(pushError(`push '${event}' to '${channel.topic()}' failed`, 'error', response)))
+                        .on('timeout', () => reject(pushError(`push '${event}' to '${channel.topic()}' timed out after ${timeout}ms`, 'timeout')));
+                });
+            }
+            return promise;
+        }
+
+        function pushError(message, status, response) {
+            const err = new Error(message);
+            err.status = status;
+            err.response = response;
+            return err;
+        }
+
         function send() {
             if (hasReceived('timeout')) {
                 return;
@@ -515,8 +721,12 @@
             });
         }
 
-        function resend(p_timeout) {
+        function resend(p_timeout, p_event, p_payload) {
             timeout = p_timeout;
+            if (p_event) {
+                event = p_event;
+                payload = p_payload || {};
+            }
             reset();
             send();
         }
@@ -588,7 +798,7 @@
             // fire and forget
             fetch(pushEndpoint, {
                 method: 'POST',
-                headers: { 'Content-Type': 'application/json' },
+                headers: { 'Content-Type': typeof data === 'string' ? 'application/json' : 'application/octet-stream' },
                 body: data,
             }).catch((error) => {
                 Chain.error(TRANSPORT, 'send error', error, data);
@@ -596,7 +806,7 @@
         }
 
         function connect() {
-            source = new EventSource(parseUrl(endpoint, '/sse', options.params));
+            source = new EventSource(parseUrl(endpoint, '/sse', Object.assign({vsn: PROTOCOL_VERSION}, options.params)));
 
             source.onmessage = (event) => {
                 Chain.log(TRANSPORT, 'message', event);
@@ -631,6 +841,85 @@
         };
     }
 
+    /**
+     * Selects the transport from the server capabilities (GET "{endpoint}/info"), the first transport published by the
+     * server that exists in Chain.Transport is used. Falls back to TransportSSE when the info is not available.
+     *
+     * Emits the "info" event with the capabilities (version, transports, serializers and heartbeatInterval).
+     *
+     * @param endpoint
+     * @param options - Options of the selected transport
+     * @return {{on, send, connect, close}}
+     * @constructor
+     */
+    function TransportNegotiate(endpoint, options = {}) {
+        const events = Events();
+
+        let conn = null;
+        let closed = false;
+        let infoEndpoint = (endpoint.split('?')[0] + '/info').replaceAll(/[/]+/g, '/');
+
+        function select(info) {
+            let transport = TransportSSE;
+            let names = (info && info.transports) || [];
+            for (let i = 0; i < names.length; i++) {
+                let key = Object.keys(Chain.Transport).find(key => key.toLowerCase() === names[i]);
+                if (key) {
+                    transport = Chain.Transport[key];
+                    break;
+                }
+            }
+            Chain.log(TRANSPORT, 'negotiated', info);
+
+            conn = transport(endpoint, options);
+            ['open', 'error', 'message', 'close'].forEach(event => {
+                conn.on(event, (...args) => events.emit(event, ...args));
+            });
+            if (info) {
+                events.emit('info', info);
+            }
+        }
+
+        function connect() {
+            closed = false;
+            if (conn) {
+                conn.connect();
+                return;
+            }
+            fetch(infoEndpoint)
+                .then(response => response.ok ? response.json() : null)
+                .catch(() => null)
+                .then(info => {
+                    if (!conn) {
+                        select(info);
+                    }
+                    if (!closed) {
+                        conn.connect();
+                    }
+                });
+        }
+
+        function send(data) {
+            if (conn) {
+                conn.send(data);
+            }
+        }
+
+        function close() {
+            closed = true;
+            if (conn) {
+                conn.close();
+            }
+        }
+
+        return {
+            on: events.on.bind(events),
+            send: send,
+            connect: connect,
+            close: close,
+        };
+    }
+
     /**
      * Timer to retry callback
      *
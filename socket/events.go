@@ -0,0 +1,60 @@
+package socket
+
+import (
+	"time"
+
+	"github.com/nidorx/chain/pubsub"
+)
+
+// EventsTopic pubsub topic of the lifecycle events of the channels, see Handler.Events and SubscribeEvents
+const EventsTopic = "chain:socket:events"
+
+// EventKind kind of the lifecycle Event
+type EventKind string
+
+const (
+	EventJoin      = EventKind("join")      // Client joined a topic. Error is informed when the join was refused
+	EventLeave     = EventKind("leave")     // Socket left a topic, see Event.Reason
+	EventIn        = EventKind("in")        // Client pushed an event to a topic (see Channel.HandleIn)
+	EventBroadcast = EventKind("broadcast") // Event broadcast on a topic by the server (see Channel.Broadcast)
+)
+
+// Event lifecycle event of a channel, emitted on the EventsTopic (local node only) when Handler.Events is enabled.
+// Allows applications to audit the activity, feed analytics or build admin dashboards without wrapping the handlers.
+type Event struct {
+	Kind     EventKind `json:"kind"`
+	Topic    string    `json:"topic"`
+	Event    string    `json:"event,omitempty"`     // Name of the event (EventIn and EventBroadcast)
+	SocketId string    `json:"socket_id,omitempty"` // Id of the session of the client, empty for broadcasts without socket
+	Endpoint string    `json:"endpoint,omitempty"`  // Endpoint of the socket handler
+	Reason   string    `json:"reason,omitempty"`    // Reason of the leave (see LeaveReason.String)
+	Error    string    `json:"error,omitempty"`     // Error of the join
+	Time     time.Time `json:"time"`
+}
+
+// SubscribeEvents subscribes the handler to the lifecycle events of the channels (see Handler.Events). The handler is
+// invoked synchronously, it must not block. Use pubsub.Unsubscribe(socket.EventsTopic, dispatcher) to stop receiving.
+//
+// ## Example
+//
+//	socket.SubscribeEvents(func(event socket.Event) {
+//		slog.Info("[audit] socket event", slog.String("Kind", string(event.Kind)), slog.String("Topic", event.Topic))
+//	})
+func SubscribeEvents(handler func(event Event)) pubsub.Dispatcher {
+	return pubsub.SubscribeT[Event](EventsTopic, func(topic string, event Event, from string) {
+		handler(event)
+	})
+}
+
+// emitEvent emits the lifecycle event on the EventsTopic
+func emitEvent(kind EventKind, topic string, socket *Socket, configure func(event *Event)) {
+	event := Event{Kind: kind, Topic: topic, Time: time.Now()}
+	if socket != nil && socket.session != nil {
+		event.SocketId = socket.session.socketId
+		event.Endpoint = socket.session.endpoint
+	}
+	if configure != nil {
+		configure(&event)
+	}
+	pubsub.LocalBroadcast(EventsTopic, event)
+}
@@ -0,0 +1,99 @@
+package socket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/pubsub"
+)
+
+func Test_Handler_Events(t *testing.T) {
+	var mutex sync.Mutex
+	var events []Event
+	dispatcher := SubscribeEvents(func(event Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, event)
+	})
+	defer pubsub.Unsubscribe(EventsTopic, dispatcher)
+
+	waitEvents := func(count int) {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mutex.Lock()
+			current := len(events)
+			mutex.Unlock()
+			if current >= count {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	transport := &transportT{}
+	var channel *Channel
+	handler := &Handler{
+		Transports: []Transport{transport},
+		Events:     true,
+		Channels: []*Channel{
+			NewChannel("room:*", func(c *Channel) {
+				channel = c
+				channel.Join("room:*", func(payload any, socket *Socket) (reply any, err error) {
+					return
+				})
+				channel.HandleIn("ping", func(event string, payload any, socket *Socket) (reply any, err error) {
+					return nil, channel.BroadcastFrom(socket, "pong", nil)
+				})
+			}),
+		},
+	}
+	chain.New().Configure("/socket", handler)
+	joinPoolTestRoom(t, transport)
+
+	ping := newMessage(MessageTypePush, "room:1", "ping", nil)
+	ping.Ref = 2
+	ping.JoinRef = 1
+	transport.SendMessage(ping)
+	waitEvents(3) // messages are processed concurrently
+
+	leave := newMessage(MessageTypePush, "room:1", "_leave", nil)
+	leave.Ref = 3
+	leave.JoinRef = 1
+	transport.SendMessage(leave)
+
+	expected := []Event{
+		{Kind: EventJoin, Topic: "room:1"},
+		{Kind: EventIn, Topic: "room:1", Event: "ping"},
+		{Kind: EventBroadcast, Topic: "room:1", Event: "pong"},
+		{Kind: EventLeave, Topic: "room:1", Reason: "leave"},
+	}
+
+	waitEvents(len(expected))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(events) != len(expected) {
+		t.Fatalf("Events failed: Invalid count\n   actual: %v\n expected: %v", len(events), len(expected))
+	}
+	// events are dispatched concurrently by pubsub, the order is not guaranteed
+	remaining := append([]Event{}, expected...)
+	for _, event := range events {
+		if event.SocketId == "" || event.Endpoint == "" || event.Time.IsZero() {
+			t.Errorf("Events failed: Invalid socket info\n   actual: %+v", event)
+		}
+		event.SocketId, event.Endpoint, event.Time = "", "", time.Time{}
+		found := false
+		for i, e := range remaining {
+			if e == event {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Events failed: Invalid event\n   actual: %+v\n expected: %+v", event, expected)
+		}
+	}
+}
@@ -1,17 +1,25 @@
 package socket
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/nidorx/chain"
 	"github.com/nidorx/chain/pkg"
 )
 
 var (
-	defaultSerializer = &MessageSerializer{}
-	socketPool        = &sync.Pool{
+	ErrMaxSessions = fmt.Errorf("max sessions exceeded")
+	ErrMaxTopics   = fmt.Errorf("max topics exceeded")
+)
+
+var (
+	criticalPushTimeout = time.Second
+	defaultSerializer   = &MessageSerializer{}
+	socketPool          = &sync.Pool{
 		New: func() any {
 			return &Socket{}
 		},
@@ -29,20 +37,36 @@ type ConfigHandler func(handler *Handler, router *chain.Router, endpoint string)
 // Once connected to a socket, incoming and outgoing events are routed to Channel. The incoming client data is routed
 // to channels via transports. It is the responsibility of the Handler to tie Transport and Channel together.
 type Handler struct {
-	Options       map[string]any   // Permite receber opções que estrão acessíveis
-	Channels      []*Channel       // Channels in this socket
-	Transports    []Transport      // Configured Transports
-	Serializer    chain.Serializer // Serializer definido para o Transport
-	OnConfig      ConfigHandler    // Called by Handler.Configure
-	OnConnect     ConnectHandler   // Called when client try to connect on a Transport
-	channels      *pkg.WildcardStore[*Channel]
-	sessions      map[string]*Session
-	sessionsMutex sync.RWMutex
+	Options        map[string]any   // Permite receber opções que estrão acessíveis
+	Channels       []*Channel       // Channels in this socket
+	Transports     []Transport      // Configured Transports
+	Serializer     chain.Serializer // Serializer definido para o Transport
+	OnConfig       ConfigHandler    // Called by Handler.Configure
+	OnConnect      ConnectHandler   // Called when client try to connect on a Transport
+	MaxConcurrency int              // Max number of messages processed concurrently. Default DefaultMaxConcurrency
+	MessageTimeout time.Duration    // Max processing time of each message, no limit if zero. See Channel.Timeout
+	SlowThreshold  time.Duration    // Messages whose processing takes longer are logged, disabled if zero
+	LatencyBuckets []time.Duration  // Buckets of the latency histogram (see Metrics). Default DefaultLatencyBuckets
+	SessionStore   SessionStore     // Externalizes the session state, restored by Resume. Disabled if nil
+	SessionTTL     time.Duration    // Expiration of the stored session state. Default DefaultSessionTTL
+	ClientJs       ClientJsOptions  // Configuration of the "/chain.js" endpoint, see ClientJsHandler
+	MaxSessions    int              // Max number of concurrent sessions, Connect fails with ErrMaxSessions. No limit if zero
+	IdleTimeout    time.Duration    // Sessions without activity are closed (see Session.Touch and the chain.js heartbeatInterval option), disabled if zero
+	Versions       []string         // Protocol versions accepted from the clients (see ProtocolVersionParam). Default []string{ProtocolVersion}
+	Events         bool             // Emits the lifecycle events of the channels (join, leave, in, broadcast) on the EventsTopic, see SubscribeEvents
+	MaxTopics      int              // Max number of topics joined concurrently by a session, the join fails with ErrMaxTopics. No limit if zero. See Channel.MaxTopics
+	Binding        *SessionBinding  // Binds the sessions to the client and requires a signed token to resume them (session takeover protection). Disabled if nil
+	channels       *pkg.WildcardStore[*Channel]
+	sessions       map[string]*Session
+	sessionsMutex  sync.RWMutex
+	workers        chan struct{}
+	workersOnce    sync.Once
+	shards         *shards
 }
 
 func (h *Handler) Configure(router *chain.Router, endpoint string) {
 
-	ClientJsHandler(router, endpoint)
+	ClientJsHandler(router, endpoint, h.ClientJs)
 
 	if h.Options == nil {
 		h.Options = map[string]any{}
@@ -66,13 +90,27 @@ func (h *Handler) Configure(router *chain.Router, endpoint string) {
 
 	h.channels = &pkg.WildcardStore[*Channel]{}
 
+	if len(h.LatencyBuckets) == 0 {
+		h.LatencyBuckets = DefaultLatencyBuckets
+	}
+
+	if h.SessionTTL <= 0 {
+		h.SessionTTL = DefaultSessionTTL
+	}
+
 	for _, channel := range h.Channels {
 		if err := h.channels.Insert(channel.TopicPattern, channel); err != nil {
 			panic(fmt.Sprintf("[chain.socket] invalid channel for topic. TopicPattern: %s, Error: %s", channel.TopicPattern, err.Error()))
 		}
 		channel.serializer = h.Serializer
+		channel.events = h.Events
+		channel.metrics = newChannelMetrics(h.LatencyBuckets)
 	}
 
+	h.configureBinding(router)
+	h.configureShards(endpoint)
+	h.startReaper(router)
+
 	if len(h.Transports) == 0 {
 		h.Transports = []Transport{&TransportSSE{}}
 	}
@@ -80,11 +118,52 @@ func (h *Handler) Configure(router *chain.Router, endpoint string) {
 	for _, transport := range h.Transports {
 		transport.Configure(h, router, endpoint)
 	}
+
+	h.configureInfo(router, endpoint)
 }
 
 // Connect invoked by Transport, initializes a new session
 func (h *Handler) Connect(endpoint string, params map[string]string) (session *Session, err error) {
-	socketId := chain.NewUID()
+	return h.connect(chain.NewUID(), endpoint, params)
+}
+
+// Resume used by Transport, tries to recover the session if it still alive.
+//
+// When the session does not exist on this node (ex. node restarted or client reconnected to another node) and a
+// Handler.SessionStore is configured, the session is restored from the store, see Handler.restoreSession.
+//
+// With Handler.Binding enabled, transports must use Handler.ResumeWithToken instead.
+func (h *Handler) Resume(socketId string) *Session {
+	h.sessionsMutex.RLock()
+	session, exist := h.sessions[socketId]
+	h.sessionsMutex.RUnlock()
+
+	if exist {
+		session.StopScheduledShutdown()
+		if !session.closed.Load() {
+			session.Touch()
+			return session
+		}
+		return nil
+	}
+
+	if h.SessionStore != nil {
+		return h.restoreSession(socketId)
+	}
+
+	return nil
+}
+
+func (h *Handler) connect(socketId string, endpoint string, params map[string]string) (session *Session, err error) {
+	if h.MaxSessions > 0 && h.SessionCount() >= h.MaxSessions {
+		return nil, ErrMaxSessions
+	}
+
+	var version string
+	if version, err = h.negotiateVersion(params[ProtocolVersionParam]); err != nil {
+		return nil, err
+	}
+
 	messages := make(chan []byte, 32)
 
 	session = &Session{
@@ -92,45 +171,39 @@ func (h *Handler) Connect(endpoint string, params map[string]string) (session *S
 		Options:  h.Options,
 		socketId: socketId,
 		endpoint: endpoint,
+		version:  version,
 		handler:  h,
-		closed:   false,
 		messages: messages,
+		instance: chain.NewUID(),
 	}
 
+	session.Touch()
+
 	if h.OnConnect != nil {
 		err = h.OnConnect(session)
 	}
 
 	if err == nil {
 		h.sessionsMutex.Lock()
-		h.sessions[socketId] = session
+		if h.MaxSessions > 0 && len(h.sessions) >= h.MaxSessions {
+			err = ErrMaxSessions
+		} else {
+			h.sessions[socketId] = session
+		}
 		h.sessionsMutex.Unlock()
 	}
 
 	return
 }
 
-// Resume used by Transport, tries to recover the session if it still alive
-func (h *Handler) Resume(socketId string) *Session {
-	h.sessionsMutex.RLock()
-	session, exist := h.sessions[socketId]
-	h.sessionsMutex.RUnlock()
-
-	if exist {
-		session.StopScheduledShutdown()
-		if !session.closed {
-			return session
-		}
-	}
-
-	return nil
-}
-
-// Dispatch Processes messages from Transport (client)
+// Dispatch Processes messages from Transport (client).
+//
+// Messages are processed concurrently, up to Handler.MaxConcurrency messages at a time (Dispatch blocks when the limit
+// is reached). Panics are recovered and replied to the client with an error.
 func (h *Handler) Dispatch(payload []byte, session *Session) {
+	h.acquireWorker()
 	go func() {
-		// @todo: goroutine using ants
-		// @todo: defer recovery
+		defer h.releaseWorker()
 
 		message := newMessageAny()
 		if _, err := h.Serializer.Decode(payload, message); err != nil {
@@ -144,21 +217,17 @@ func (h *Handler) Dispatch(payload []byte, session *Session) {
 			return
 		}
 
-		switch message.Event {
-		case "_join":
-			h.handleJoin(message, session)
-		case "_leave":
-			h.handleLeave(message, session)
-		case "heartbeat":
-			h.handleHeartbeat(message, session)
-		default:
-			h.handleMessage(message, session)
+		if h.forwardShard(message, session) {
+			deleteMessage(message)
+			return
 		}
+
+		h.process(message, session)
 	}()
 }
 
-// handleJoin Joins the channel in socket with authentication payload.
-func (h *Handler) handleJoin(message *Message, session *Session) {
+// handleJoin Joins the channel in socket with authentication payload. Returns the socket if joined.
+func (h *Handler) handleJoin(message *Message, session *Session) *Socket {
 	topic := message.Topic
 	channel := h.getChannel(topic)
 	if channel == nil {
@@ -169,7 +238,7 @@ func (h *Handler) handleJoin(message *Message, session *Session) {
 		)
 
 		h.pushIgnore(message, session, ErrUnmatchedTopic)
-		return
+		return nil
 	}
 	socket := session.GetSocket(topic)
 	if socket != nil {
@@ -182,7 +251,7 @@ func (h *Handler) handleJoin(message *Message, session *Session) {
 		// remove from transport
 		session.deleteSocket(topic)
 
-		if socket.status != StatusLeaving {
+		if socket.Status() != StatusLeaving {
 			if socket.channel != nil {
 				socket.channel.handleLeave(socket, LeaveReasonRejoin)
 			}
@@ -191,7 +260,7 @@ func (h *Handler) handleJoin(message *Message, session *Session) {
 				reply := newMessage(MessageTypePush, topic, "_close", nil)
 				reply.Ref = socket.ref
 				reply.JoinRef = socket.joinRef
-				h.push(reply, session)
+				h.pushCritical(reply, session)
 				deleteMessage(reply)
 			}
 
@@ -199,20 +268,45 @@ func (h *Handler) handleJoin(message *Message, session *Session) {
 		}
 	}
 
+	if limit, reserved := session.reserveTopic(channel, h.MaxTopics); !reserved {
+		slog.Info(
+			"[chain.socket] max topics exceeded",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", topic),
+			slog.Int("Max", limit),
+		)
+		h.pushLimit(message, session, ErrMaxTopics, limit)
+		return nil
+	}
+	defer session.releaseTopic(channel)
+
 	socket = newSocket(message.Ref, message.JoinRef, topic, channel, session, h)
 
 	socket.Params = session.Params
+	socket.joinPayload = message.Payload
 
 	payload, err := channel.handleJoin(topic, message.Payload, socket)
+	if h.Events {
+		emitEvent(EventJoin, topic, socket, func(e *Event) {
+			if err != nil {
+				e.Error = err.Error()
+			}
+		})
+	}
 	if err != nil {
 		deleteSocket(socket)
 		h.pushIgnore(message, session, err)
-		return
+		return nil
 	}
 
-	socket.status = StatusJoined
+	socket.setStatus(StatusJoined)
 
 	session.setSocket(topic, socket)
+	h.storeSession(session)
+
+	if channel.metrics != nil {
+		channel.metrics.joins.Add(1)
+	}
 
 	defer deleteMessage(message)
 	message.Kind = MessageTypeReply
@@ -220,13 +314,31 @@ func (h *Handler) handleJoin(message *Message, session *Session) {
 	message.Payload = payload
 
 	h.push(message, session)
+	return socket
+}
+
+// handleRejoin Joins the channel again after a reconnection, invoking the RejoinHandler of the channel.
+//
+// The payload of the _rejoin event is {"params": <join payload>, "last_ref": <ref of the last push received>}
+func (h *Handler) handleRejoin(message *Message, session *Session) {
+	lastRef := 0
+	if payload, ok := message.Payload.(map[string]any); ok {
+		if ref, ok := payload["last_ref"].(float64); ok {
+			lastRef = int(ref)
+		}
+		message.Payload = payload["params"]
+	}
+
+	if socket := h.handleJoin(message, session); socket != nil {
+		socket.channel.handleRejoin(lastRef, socket)
+	}
 }
 
 func (h *Handler) handleLeave(message *Message, info *Session) {
 	topic := message.Topic
 	socket := info.GetSocket(topic)
 	if socket != nil {
-		socket.status = StatusLeaving
+		socket.setStatus(StatusLeaving)
 
 		// remove from transport
 		info.deleteSocket(topic)
@@ -236,6 +348,7 @@ func (h *Handler) handleLeave(message *Message, info *Session) {
 		}
 
 		deleteSocket(socket)
+		h.storeSession(info)
 	}
 
 	defer deleteMessage(message)
@@ -262,7 +375,18 @@ func (h *Handler) handleMessage(message *Message, session *Session) {
 	defer deleteMessage(message)
 
 	channel := socket.channel
+	if h.Events {
+		emitEvent(EventIn, topic, socket, func(e *Event) { e.Event = message.Event })
+	}
 	payload, err := channel.handleIn(message.Event, message.Payload, socket)
+	if deferred, isDeferred := payload.(*Reply); isDeferred {
+		if err == nil {
+			// replied asynchronously, see Socket.ReplyLater
+			deferred.bind(h, message, session)
+			return
+		}
+		payload = nil
+	}
 	if err != nil {
 		message.Kind = MessageTypeReply
 		message.Status = ReplyStatusCodeError
@@ -278,15 +402,20 @@ func (h *Handler) handleMessage(message *Message, session *Session) {
 
 func (h *Handler) handleClose(info *Session) {
 	h.sessionsMutex.Lock()
-	delete(h.sessions, info.SocketId())
+	if h.sessions[info.SocketId()] == info {
+		delete(h.sessions, info.SocketId())
+	}
 	h.sessionsMutex.Unlock()
 
+	h.forgetSession(info)
+	h.closeShards(info)
+
 	info.socketsMutex.Lock()
 	defer info.socketsMutex.Unlock()
 
 	if info.sockets != nil {
 		for _, socket := range info.sockets {
-			if socket.status != StatusLeaving {
+			if socket.Status() != StatusLeaving {
 				if socket.channel != nil {
 					socket.channel.handleLeave(socket, LeaveReasonClose)
 				}
@@ -302,7 +431,46 @@ func (h *Handler) handleHeartbeat(message *Message, info *Session) {
 }
 
 func (h *Handler) push(reply *Message, info *Session) {
-	var bytes []byte
+	if reply.replyClaim != nil && !reply.replyClaim.CompareAndSwap(false, true) {
+		// already replied (ex. processing timeout)
+		return
+	}
+	if bytes, ok := h.encode(reply); ok {
+		info.Push(bytes)
+		if reply.Kind == MessageTypeReply {
+			if metrics := h.channelMetrics(reply.Topic); metrics != nil {
+				metrics.replies.Add(1)
+				if reply.Status == ReplyStatusCodeError {
+					metrics.errors.Add(1)
+				}
+			}
+		}
+	}
+}
+
+// pushCritical push messages that cannot be silently discarded (ex. _close). Invoked by the dispatch of the session
+// (ex. join), that only waits when the client is not consuming its messages, up to criticalPushTimeout or until the
+// session is closed.
+func (h *Handler) pushCritical(reply *Message, info *Session) {
+	if bytes, ok := h.encode(reply); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), criticalPushTimeout)
+		defer cancel()
+		if err := info.PushContext(ctx, bytes); err != nil {
+			slog.Warn(
+				"[chain.socket] could not deliver message",
+				slog.Any("Error", err),
+				slog.String("Topic", reply.Topic),
+				slog.String("Event", reply.Event),
+				slog.Uint64("Dropped", info.Dropped()),
+			)
+		}
+	}
+}
+
+func (h *Handler) encode(reply *Message) (bytes []byte, ok bool) {
+	if !checkMessage(reply, "encode") {
+		return nil, false
+	}
 	var err error
 	if bytes, err = h.Serializer.Encode(reply); err != nil {
 		slog.Debug(
@@ -318,7 +486,17 @@ func (h *Handler) push(reply *Message, info *Session) {
 		)
 		return
 	}
-	info.Push(bytes)
+	return bytes, true
+}
+
+// pushLimit replies with the error of an exceeded limit, the payload informs the limit:
+// {"reason": "max topics exceeded", "max": 10}
+func (h *Handler) pushLimit(message *Message, info *Session, reason error, limit int) {
+	defer deleteMessage(message)
+	message.Kind = MessageTypeReply
+	message.Status = ReplyStatusCodeError
+	message.Payload = map[string]any{"reason": reason.Error(), "max": limit}
+	h.push(message, info)
 }
 
 func (h *Handler) pushIgnore(message *Message, info *Session, reason error) {
@@ -344,17 +522,27 @@ func newSocket(ref int, joinRef int, topic string, channel *Channel, info *Sessi
 	socket.channel = channel
 	socket.session = info
 	socket.handler = handler
-	socket.status = StatusJoining
+	socket.setStatus(StatusJoining)
 	socket.data = map[string]any{}
+	socket.joinPayload = nil
+	socket.pushRef.Store(0)
+	if info != nil {
+		socket.ctx, socket.cancel = context.WithCancel(info.Context())
+	} else {
+		socket.ctx, socket.cancel = context.WithCancel(context.Background())
+	}
+	socket.topicParams = nil
+	if channel != nil {
+		socket.topicParams = channel.topicParams(topic)
+	}
 	return socket
 }
 
 func deleteSocket(socket *Socket) {
-	socket.topic = ""
-	socket.channel = nil
-	socket.session = nil
-	socket.handler = nil
-	socket.data = nil
-	socket.status = StatusRemoved
-	socketPool.Put(socket)
+	// the context remains cancelled (see Socket.Context) until the socket is reused
+	socket.cancel()
+	socket.setStatus(StatusRemoved)
+	socket.stopTimers()
+	socket.cancelAsks()
+	socket.release()
 }
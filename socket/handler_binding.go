@@ -0,0 +1,158 @@
+package socket
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/crypto"
+)
+
+// DefaultResumeTokenTTL expiry of the resume tokens, see SessionBinding
+const DefaultResumeTokenTTL = 24 * time.Hour
+
+const resumeTokenPrefix = "chain.socket.resume:"
+
+var (
+	defaultBindingSalt = "chain.socket.binding.keyring.salt"
+
+	ErrResumeTokenInvalid = errors.New("invalid resume token")
+	ErrResumeTokenExpired = errors.New("expired resume token")
+	ErrResumeBinding      = errors.New("resume token bound to another client")
+)
+
+// SessionBinding protects the sessions against takeover (stolen cookie or session id). When enabled (see
+// Handler.Binding), the transports resume the sessions only with a signed resume token (see Handler.ResumeToken),
+// the session id alone is not accepted.
+//
+// The token expires after TokenTTL (the transports renew it on each reconnection) and is bound to the attributes of
+// the client that created it: the User-Agent and/or the network of the client IP (IPv4Prefix and IPv6Prefix bits).
+// Resumes from other clients are rejected and a new session is started.
+//
+// ## Example
+//
+//	router.Configure("/socket", &socket.Handler{
+//		Binding:  &socket.SessionBinding{UserAgent: true, IPv4Prefix: 24, IPv6Prefix: 64},
+//		Channels: []*socket.Channel{ ... },
+//	})
+type SessionBinding struct {
+	UserAgent  bool            // Binds the session to the User-Agent of the client
+	IPv4Prefix int             // Binds the session to the network of the IPv4 clients (ex. 24 = "192.168.1.0/24"). Disabled if zero
+	IPv6Prefix int             // Binds the session to the network of the IPv6 clients (ex. 64). Disabled if zero
+	TokenTTL   time.Duration   // Expiry of the resume tokens. Default DefaultResumeTokenTTL
+	Keyring    *crypto.Keyring // Signs the resume tokens. Default, a Keyring derived from the router SecretKeyBase
+}
+
+// ClientInfo attributes of the client connection, informed by the transports, see SessionBinding
+type ClientInfo struct {
+	UserAgent string
+	Addr      netip.Addr // IP of the client, invalid if unknown
+}
+
+// clientInfoFromContext the attributes of the client of the HTTP transports
+func clientInfoFromContext(ctx *chain.Context) ClientInfo {
+	addr, _ := ctx.ClientAddr()
+	return ClientInfo{UserAgent: ctx.Request.UserAgent(), Addr: addr}
+}
+
+// clientInfoFromConn the attributes of the client of the connection oriented transports (no User-Agent)
+func clientInfoFromConn(conn net.Conn) ClientInfo {
+	info := ClientInfo{}
+	if addrPort, err := netip.ParseAddrPort(conn.RemoteAddr().String()); err == nil {
+		info.Addr = addrPort.Addr().Unmap()
+	}
+	return info
+}
+
+// configureBinding initializes the SessionBinding of the Handler
+func (h *Handler) configureBinding(router *chain.Router) {
+	if h.Binding == nil {
+		return
+	}
+	if h.Binding.TokenTTL <= 0 {
+		h.Binding.TokenTTL = DefaultResumeTokenTTL
+	}
+	if h.Binding.Keyring == nil {
+		h.Binding.Keyring = router.DeriveKeyring(defaultBindingSalt, 32, nil)
+	}
+}
+
+// ResumeToken creates the signed token that allows the client to resume the session, bound to the client attributes
+// (see SessionBinding).
+func (h *Handler) ResumeToken(session *Session, client ClientInfo) (string, error) {
+	if h.Binding == nil {
+		return "", ErrResumeTokenInvalid
+	}
+	expires := time.Now().Add(h.Binding.TokenTTL).Unix()
+	content := resumeTokenPrefix + session.SocketId() + ":" + strconv.FormatInt(expires, 10) + ":" + h.Binding.fingerprint(client)
+	return h.Binding.Keyring.MessageSign([]byte(content), "sha256")
+}
+
+// ResumeWithToken same as Handler.Resume, using the resume token created by Handler.ResumeToken. Fails when the token
+// is invalid, expired or was created for another client.
+func (h *Handler) ResumeWithToken(token string, client ClientInfo) (*Session, error) {
+	if h.Binding == nil {
+		return nil, ErrResumeTokenInvalid
+	}
+	decoded, err := h.Binding.Keyring.MessageVerify([]byte(token))
+	if err != nil {
+		return nil, ErrResumeTokenInvalid
+	}
+	content, found := strings.CutPrefix(string(decoded), resumeTokenPrefix)
+	if !found {
+		return nil, ErrResumeTokenInvalid
+	}
+	parts := strings.Split(content, ":")
+	if len(parts) != 3 {
+		return nil, ErrResumeTokenInvalid
+	}
+	socketId, fingerprint := parts[0], parts[2]
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrResumeTokenInvalid
+	}
+	if time.Now().Unix() > expires {
+		return nil, ErrResumeTokenExpired
+	}
+	if fingerprint != h.Binding.fingerprint(client) {
+		slog.Warn(
+			"[chain.socket] session resume rejected, the client does not match the session binding",
+			slog.String("SocketId", socketId),
+			slog.String("Addr", client.Addr.String()),
+		)
+		return nil, ErrResumeBinding
+	}
+
+	if session := h.Resume(socketId); session != nil {
+		return session, nil
+	}
+	return nil, ErrSessionClosed
+}
+
+// fingerprint hash of the bound attributes of the client
+func (b *SessionBinding) fingerprint(client ClientInfo) string {
+	hash := sha256.New()
+	if b.UserAgent {
+		hash.Write([]byte(client.UserAgent))
+	}
+	hash.Write([]byte{0})
+	if addr := client.Addr; addr.IsValid() {
+		bits := b.IPv6Prefix
+		if addr.Is4() {
+			bits = b.IPv4Prefix
+		}
+		if bits > 0 {
+			if prefix, err := addr.Prefix(bits); err == nil {
+				hash.Write([]byte(prefix.String()))
+			}
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:16])
+}
@@ -0,0 +1,72 @@
+package socket
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Handler_Binding(t *testing.T) {
+	handler := &Handler{
+		Binding:  &SessionBinding{UserAgent: true, IPv4Prefix: 24},
+		Channels: []*Channel{NewChannel("room:*", func(channel *Channel) {})},
+	}
+	router := chain.New()
+	if err := router.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		t.Fatal(err)
+	}
+	router.Keyring = router.NewKeyring("socket.router.keyring.salt", 1000, 32, "sha256")
+	router.Configure("/socket", handler)
+	if handler.Binding.Keyring == router.Keyring {
+		t.Fatal("ResumeToken() failed: the Router.Keyring must not be used to sign the resume tokens")
+	}
+
+	session, err := handler.Connect("/socket", map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ClientInfo{UserAgent: "Mozilla/5.0", Addr: netip.MustParseAddr("192.168.1.10")}
+	token, err := handler.ResumeToken(session, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		client   ClientInfo
+		expected error
+	}{
+		{"same client", token, client, nil},
+		{"same network", token, ClientInfo{UserAgent: "Mozilla/5.0", Addr: netip.MustParseAddr("192.168.1.99")}, nil},
+		{"other network", token, ClientInfo{UserAgent: "Mozilla/5.0", Addr: netip.MustParseAddr("10.0.0.1")}, ErrResumeBinding},
+		{"other user agent", token, ClientInfo{UserAgent: "curl/8.0", Addr: client.Addr}, ErrResumeBinding},
+		{"tampered token", token[:len(token)-2] + "xx", client, ErrResumeTokenInvalid},
+		{"session id", session.SocketId(), client, ErrResumeTokenInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resumed, err := handler.ResumeWithToken(tt.token, tt.client)
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("ResumeWithToken() failed: Invalid Error\n   actual: %v\n expected: %v", err, tt.expected)
+			}
+			if tt.expected == nil && resumed != session {
+				t.Errorf("ResumeWithToken() failed: Invalid session\n   actual: %v\n expected: %v", resumed, session)
+			}
+		})
+	}
+
+	handler.Binding.TokenTTL = -time.Second
+	expired, _ := handler.ResumeToken(session, client)
+	if _, err = handler.ResumeWithToken(expired, client); !errors.Is(err, ErrResumeTokenExpired) {
+		t.Errorf("ResumeWithToken() failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrResumeTokenExpired)
+	}
+
+	session.close()
+	if _, err = handler.ResumeWithToken(token, client); !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("ResumeWithToken() failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrSessionClosed)
+	}
+}
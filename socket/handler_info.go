@@ -0,0 +1,82 @@
+package socket
+
+import (
+	"github.com/nidorx/chain"
+)
+
+// ProtocolVersion version of the protocol of the socket (message format and control events), published by the info
+// endpoint so clients can detect incompatible servers
+const ProtocolVersion = "1.0.0"
+
+// NamedTransport optional interface of the Transport, names the transport on the info endpoint (see Handler.Info).
+// Transports without name are not published.
+type NamedTransport interface {
+	Transport
+	Name() string
+}
+
+// NamedSerializer optional interface of the serializers, names the serializer on the info endpoint (see Handler.Info)
+type NamedSerializer interface {
+	chain.Serializer
+	Name() string
+}
+
+// HandlerInfo capabilities of the Handler, served by the "{endpoint}/info" endpoint
+type HandlerInfo struct {
+	Version           string   `json:"version"`           // see ProtocolVersion
+	Versions          []string `json:"versions"`          // protocol versions accepted from the clients, see Handler.Versions
+	Transports        []string `json:"transports"`        // names of the transports, in order of preference
+	Serializers       []string `json:"serializers"`       // names of the serializers of the messages
+	HeartbeatInterval int64    `json:"heartbeatInterval"` // interval of the client heartbeats in ms, zero if not required
+}
+
+// Info gets the capabilities of the Handler, allowing clients to select the transport dynamically.
+//
+// The info is served as JSON by the GET "{endpoint}/info" endpoint (ex. "/socket/info").
+//
+// ## Example
+//
+//	{"version":"1.0.0","versions":["1.0.0"],"transports":["sse"],"serializers":["json"],"heartbeatInterval":30000}
+func (h *Handler) Info() HandlerInfo {
+	info := HandlerInfo{
+		Version:     ProtocolVersion,
+		Versions:    h.protocolVersions(),
+		Transports:  []string{},
+		Serializers: []string{},
+	}
+
+	addSerializer := func(serializer chain.Serializer) {
+		if named, ok := serializer.(NamedSerializer); ok {
+			for _, name := range info.Serializers {
+				if name == named.Name() {
+					return
+				}
+			}
+			info.Serializers = append(info.Serializers, named.Name())
+		}
+	}
+
+	addSerializer(h.Serializer)
+	for _, transport := range h.Transports {
+		if named, ok := transport.(NamedTransport); ok {
+			info.Transports = append(info.Transports, named.Name())
+		}
+		if stream, ok := transport.(*TransportStream); ok {
+			addSerializer(stream.Serializer)
+		}
+	}
+
+	if h.IdleTimeout > 0 {
+		// the client sends at least one heartbeat within the idle timeout
+		info.HeartbeatInterval = (h.IdleTimeout / 2).Milliseconds()
+	}
+	return info
+}
+
+// configureInfo adds the info endpoint, see Handler.Info
+func (h *Handler) configureInfo(router *chain.Router, endpoint string) {
+	router.GET(endpoint+"/info", func(ctx *chain.Context) {
+		ctx.SetHeader("Cache-Control", "no-cache")
+		ctx.Json(h.Info())
+	})
+}
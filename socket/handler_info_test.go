@@ -0,0 +1,46 @@
+package socket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Handler_Info(t *testing.T) {
+	router := chain.New()
+	handler := &Handler{
+		IdleTimeout: time.Minute,
+		Transports:  []Transport{&TransportSSE{}, &TransportStream{}, &transportT{}},
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {}),
+		},
+	}
+	router.Configure("/socket", handler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/socket/info", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Info() failed: Invalid status\n   actual: %d\n expected: %d", w.Code, http.StatusOK)
+	}
+
+	var info HandlerInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := HandlerInfo{
+		Version:           ProtocolVersion,
+		Versions:          []string{ProtocolVersion},
+		Transports:        []string{"sse", "stream"},
+		Serializers:       []string{"json", "protobuf"},
+		HeartbeatInterval: 30000,
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("Info() failed: Invalid info\n   actual: %+v\n expected: %+v", info, expected)
+	}
+}
@@ -0,0 +1,147 @@
+package socket
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatencyBuckets upper bounds of the buckets of the handler latency histogram, see Handler.LatencyBuckets
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// ChannelMetrics snapshot of the message pipeline metrics of a Channel, see Handler.Metrics
+type ChannelMetrics struct {
+	Joins    uint64           // Successful joins
+	Leaves   uint64           // Sockets that left the channel (leave, rejoin or connection closed)
+	Messages uint64           // Messages received from clients (including joins and leaves)
+	Replies  uint64           // Replies sent to clients
+	Errors   uint64           // Error replies sent to clients
+	Latency  LatencyHistogram // Processing time of the messages
+}
+
+// LatencyHistogram cumulative histogram of processing times
+type LatencyHistogram struct {
+	Buckets []time.Duration // Upper bound of each bucket
+	Counts  []uint64        // Number of observations in each bucket, the last item counts the observations above all buckets
+	Count   uint64          // Total number of observations
+	Sum     time.Duration   // Sum of all observations
+}
+
+// Mean average processing time
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+type channelMetrics struct {
+	joins    atomic.Uint64
+	leaves   atomic.Uint64
+	messages atomic.Uint64
+	replies  atomic.Uint64
+	errors   atomic.Uint64
+	buckets  []time.Duration
+	counts   []atomic.Uint64
+	count    atomic.Uint64
+	sum      atomic.Int64
+}
+
+func newChannelMetrics(buckets []time.Duration) *channelMetrics {
+	return &channelMetrics{
+		buckets: buckets,
+		counts:  make([]atomic.Uint64, len(buckets)+1),
+	}
+}
+
+func (m *channelMetrics) observe(elapsed time.Duration) {
+	i := 0
+	for ; i < len(m.buckets); i++ {
+		if elapsed <= m.buckets[i] {
+			break
+		}
+	}
+	m.counts[i].Add(1)
+	m.count.Add(1)
+	m.sum.Add(int64(elapsed))
+}
+
+func (m *channelMetrics) snapshot() ChannelMetrics {
+	counts := make([]uint64, len(m.counts))
+	for i := range m.counts {
+		counts[i] = m.counts[i].Load()
+	}
+	return ChannelMetrics{
+		Joins:    m.joins.Load(),
+		Leaves:   m.leaves.Load(),
+		Messages: m.messages.Load(),
+		Replies:  m.replies.Load(),
+		Errors:   m.errors.Load(),
+		Latency: LatencyHistogram{
+			Buckets: m.buckets,
+			Counts:  counts,
+			Count:   m.count.Load(),
+			Sum:     time.Duration(m.sum.Load()),
+		},
+	}
+}
+
+// Metrics gets a snapshot of the message pipeline metrics of each channel, by Channel.TopicPattern
+//
+// ## Example
+//
+//	router.GET("/debug/socket", func(ctx *chain.Context) error {
+//		ctx.Json(AppSocket.Metrics())
+//		return nil
+//	})
+func (h *Handler) Metrics() map[string]ChannelMetrics {
+	out := map[string]ChannelMetrics{}
+	for _, channel := range h.Channels {
+		if channel.metrics != nil {
+			out[channel.TopicPattern] = channel.metrics.snapshot()
+		}
+	}
+	return out
+}
+
+// channelMetrics gets the metrics of the channel that handles the topic
+func (h *Handler) channelMetrics(topic string) *channelMetrics {
+	if h.channels == nil {
+		return nil
+	}
+	if channel := h.getChannel(topic); channel != nil {
+		return channel.metrics
+	}
+	return nil
+}
+
+// observe records the processing of a message, logging slow handlers (see Handler.SlowThreshold)
+func (h *Handler) observe(target replyTarget, session *Session, elapsed time.Duration) {
+	if metrics := h.channelMetrics(target.topic); metrics != nil {
+		metrics.messages.Add(1)
+		metrics.observe(elapsed)
+	}
+
+	if h.SlowThreshold > 0 && elapsed >= h.SlowThreshold {
+		slog.Warn(
+			"[chain.socket] slow handler",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", target.topic),
+			slog.String("Event", target.event),
+			slog.Duration("Elapsed", elapsed),
+		)
+	}
+}
@@ -0,0 +1,79 @@
+package socket
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_Handler_Metrics(t *testing.T) {
+	transport := &transportT{}
+	handler := newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.HandleIn("ok", func(event string, payload any, socket *Socket) (reply any, err error) {
+			return "ok", nil
+		})
+		channel.HandleIn("fail", func(event string, payload any, socket *Socket) (reply any, err error) {
+			return nil, errors.New("fail")
+		})
+		channel.HandleIn("slow", func(event string, payload any, socket *Socket) (reply any, err error) {
+			time.Sleep(15 * time.Millisecond)
+			return
+		})
+	})
+	handler.SlowThreshold = 10 * time.Millisecond
+	joinPoolTestRoom(t, transport)
+
+	for i, event := range []string{"ok", "ok", "fail", "slow"} {
+		request := newMessage(MessageTypePush, "room:1", event, nil)
+		request.Ref = 10 + i
+		request.JoinRef = 1
+		transport.SendMessage(request)
+	}
+	waitMessages(transport, 3)
+	time.Sleep(30 * time.Millisecond)
+
+	metrics, exists := handler.Metrics()["room:*"]
+	if !exists {
+		t.Fatalf("Metrics failed: channel metrics not found")
+	}
+
+	expected := ChannelMetrics{Joins: 1, Messages: 5, Replies: 4, Errors: 1}
+	if metrics.Joins != expected.Joins || metrics.Messages != expected.Messages ||
+		metrics.Replies != expected.Replies || metrics.Errors != expected.Errors {
+		t.Errorf("Metrics failed: Invalid counters\n   actual: %+v\n expected: %+v", metrics, expected)
+	}
+
+	latency := metrics.Latency
+	if latency.Count != 5 {
+		t.Errorf("Metrics failed: Invalid latency count\n   actual: %v\n expected: %v", latency.Count, 5)
+	}
+	if len(latency.Counts) != len(DefaultLatencyBuckets)+1 {
+		t.Errorf("Metrics failed: Invalid number of buckets\n   actual: %v\n expected: %v", len(latency.Counts), len(DefaultLatencyBuckets)+1)
+	}
+	var total uint64
+	for _, count := range latency.Counts {
+		total += count
+	}
+	if total != latency.Count {
+		t.Errorf("Metrics failed: Invalid bucket counts\n   actual: %v\n expected: %v", total, latency.Count)
+	}
+	if latency.Sum < 15*time.Millisecond || latency.Mean() <= 0 {
+		t.Errorf("Metrics failed: Invalid latency sum\n   actual: %v", latency.Sum)
+	}
+}
+
+func Test_ChannelMetrics_Observe(t *testing.T) {
+	metrics := newChannelMetrics([]time.Duration{time.Millisecond, 10 * time.Millisecond})
+	metrics.observe(500 * time.Microsecond)
+	metrics.observe(time.Millisecond)
+	metrics.observe(5 * time.Millisecond)
+	metrics.observe(time.Second)
+
+	snapshot := metrics.snapshot().Latency
+	expected := []uint64{2, 1, 1}
+	for i, count := range expected {
+		if snapshot.Counts[i] != count {
+			t.Errorf("observe failed: Invalid bucket %d\n   actual: %v\n expected: %v", i, snapshot.Counts[i], count)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package socket
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxConcurrency default number of messages processed concurrently by a Handler
+const DefaultMaxConcurrency = 1024
+
+var (
+	ErrMessageTimeout = fmt.Errorf("timeout")
+	ErrMessageCrashed = fmt.Errorf("internal error")
+)
+
+// acquireWorker blocks until the number of messages being processed is below Handler.MaxConcurrency
+func (h *Handler) acquireWorker() {
+	h.workersOnce.Do(func() {
+		size := h.MaxConcurrency
+		if size <= 0 {
+			size = DefaultMaxConcurrency
+		}
+		h.workers = make(chan struct{}, size)
+	})
+	h.workers <- struct{}{}
+}
+
+func (h *Handler) releaseWorker() {
+	<-h.workers
+}
+
+// messageTimeout gets the processing timeout of the message, see Channel.Timeout and Handler.MessageTimeout
+func (h *Handler) messageTimeout(message *Message) time.Duration {
+	if channel := h.getChannel(message.Topic); channel != nil && channel.timeouts != nil {
+		if timeout := channel.timeouts.Match(message.Event); timeout > 0 {
+			return timeout
+		}
+	}
+	return h.MessageTimeout
+}
+
+// process handles the message, enforcing the processing timeout
+func (h *Handler) process(message *Message, session *Session) {
+	// the message is recycled by the handlers, keeps the data necessary for error replies
+	target := replyTarget{
+		ref:     message.Ref,
+		joinRef: message.JoinRef,
+		topic:   message.Topic,
+		event:   message.Event,
+	}
+
+	start := time.Now()
+	timeout := h.messageTimeout(message)
+	if timeout <= 0 {
+		h.handle(message, session, target)
+		h.observe(target, session, time.Since(start))
+		return
+	}
+
+	target.claim = &atomic.Bool{}
+	message.replyClaim = target.claim
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.handle(message, session, target)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		slog.Warn(
+			"[chain.socket] message processing timeout",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", target.topic),
+			slog.String("Event", target.event),
+			slog.Duration("Timeout", timeout),
+		)
+		if target.claim.CompareAndSwap(false, true) {
+			h.pushError(target, session, ErrMessageTimeout)
+		}
+		// the worker remains busy until the handler finishes, ensuring the concurrency limit
+		<-done
+	}
+	h.observe(target, session, time.Since(start))
+}
+
+// handle routes the message to the handler, recovering from panics
+func (h *Handler) handle(message *Message, session *Session, target replyTarget) {
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			slog.Error(
+				"[chain.socket] panic processing message",
+				slog.Any("Panic", rcv),
+				slog.Any("socket_id", session.SocketId()),
+				slog.String("Topic", target.topic),
+				slog.String("Event", target.event),
+				slog.String("Stack", string(debug.Stack())),
+			)
+			if target.claim == nil || target.claim.CompareAndSwap(false, true) {
+				h.pushError(target, session, ErrMessageCrashed)
+			}
+		}
+	}()
+
+	switch message.Event {
+	case "_join":
+		h.handleJoin(message, session)
+	case "_rejoin":
+		h.handleRejoin(message, session)
+	case "_leave":
+		h.handleLeave(message, session)
+	case "_ask_reply":
+		h.handleAskReply(message, session)
+	case "heartbeat":
+		h.handleHeartbeat(message, session)
+	default:
+		h.handleMessage(message, session)
+	}
+}
+
+// replyTarget identifies the client message being replied
+type replyTarget struct {
+	ref     int
+	joinRef int
+	topic   string
+	event   string
+	claim   *atomic.Bool // see Message.replyClaim
+}
+
+func (h *Handler) pushError(target replyTarget, session *Session, reason error) {
+	reply := newMessage(MessageTypeReply, target.topic, target.event, map[string]string{"reason": reason.Error()})
+	defer deleteMessage(reply)
+	reply.Ref = target.ref
+	reply.JoinRef = target.joinRef
+	reply.Status = ReplyStatusCodeError
+	h.push(reply, session)
+}
@@ -0,0 +1,166 @@
+package socket
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+// waitMessages waits until the transport receives `count` messages
+func waitMessages(transport *transportT, count int) []*Message {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		transport.mutex.Lock()
+		if len(transport.Messages) >= count {
+			messages := append([]*Message{}, transport.Messages...)
+			transport.mutex.Unlock()
+			return messages
+		}
+		transport.mutex.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return append([]*Message{}, transport.Messages...)
+}
+
+func newPoolTestHandler(transport *transportT, maxConcurrency int, factory func(channel *Channel)) *Handler {
+	handler := &Handler{
+		Transports:     []Transport{transport},
+		MaxConcurrency: maxConcurrency,
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {
+				channel.Join("room:*", func(payload any, socket *Socket) (reply any, err error) {
+					return
+				})
+				factory(channel)
+			}),
+		},
+	}
+	chain.New().Configure("/socket", handler)
+	return handler
+}
+
+func joinPoolTestRoom(t *testing.T, transport *transportT) {
+	if _, err := transport.Connect(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	join := newMessage(MessageTypePush, "room:1", "_join", nil)
+	join.Ref = 1
+	join.JoinRef = 1
+	transport.SendMessage(join)
+	if messages := waitMessages(transport, 1); len(messages) != 1 {
+		t.Fatalf("Join() failed: no reply")
+	}
+	transport.Clear()
+}
+
+func Test_Handler_Message_Timeout(t *testing.T) {
+	transport := &transportT{}
+	release := make(chan struct{})
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.Timeout("slow", 20*time.Millisecond)
+		channel.HandleIn("*", func(event string, payload any, socket *Socket) (reply any, err error) {
+			if event == "slow" {
+				<-release
+			}
+			return "late", nil
+		})
+	})
+	joinPoolTestRoom(t, transport)
+
+	request := newMessage(MessageTypePush, "room:1", "slow", nil)
+	request.Ref = 2
+	request.JoinRef = 1
+	transport.SendMessage(request)
+
+	messages := waitMessages(transport, 1)
+
+	if len(messages) != 1 {
+		t.Fatalf("Timeout failed: Invalid number of replies\n   actual: %v\n expected: %v", len(messages), 1)
+	}
+	reply := messages[0]
+	if reply.Ref != 2 || reply.Status != ReplyStatusCodeError {
+		t.Errorf("Timeout failed: Invalid reply\n   actual: %+v", reply)
+	}
+	if payload, _ := reply.Payload.(map[string]any); payload["reason"] != ErrMessageTimeout.Error() {
+		t.Errorf("Timeout failed: Invalid reason\n   actual: %v\n expected: %v", reply.Payload, ErrMessageTimeout.Error())
+	}
+
+	// the late reply of the handler is discarded
+	close(release)
+	time.Sleep(50 * time.Millisecond)
+	if messages = waitMessages(transport, 1); len(messages) != 1 {
+		t.Errorf("Timeout failed: late reply must be discarded\n   actual: %v", len(messages))
+	}
+}
+
+func Test_Handler_Message_Panic(t *testing.T) {
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.HandleIn("crash", func(event string, payload any, socket *Socket) (reply any, err error) {
+			panic("crash")
+		})
+	})
+	joinPoolTestRoom(t, transport)
+
+	request := newMessage(MessageTypePush, "room:1", "crash", nil)
+	request.Ref = 3
+	request.JoinRef = 1
+	transport.SendMessage(request)
+
+	messages := waitMessages(transport, 1)
+	if len(messages) != 1 {
+		t.Fatalf("Panic failed: Invalid number of replies\n   actual: %v\n expected: %v", len(messages), 1)
+	}
+	reply := messages[0]
+	if reply.Ref != 3 || reply.Status != ReplyStatusCodeError {
+		t.Errorf("Panic failed: Invalid reply\n   actual: %+v", reply)
+	}
+	if payload, _ := reply.Payload.(map[string]any); payload["reason"] != ErrMessageCrashed.Error() {
+		t.Errorf("Panic failed: Invalid reason\n   actual: %v\n expected: %v", reply.Payload, ErrMessageCrashed.Error())
+	}
+}
+
+func Test_Handler_MaxConcurrency(t *testing.T) {
+	transport := &transportT{}
+	var running, maxRunning atomic.Int32
+	newPoolTestHandler(transport, 2, func(channel *Channel) {
+		channel.HandleIn("work", func(event string, payload any, socket *Socket) (reply any, err error) {
+			current := running.Add(1)
+			for {
+				max := maxRunning.Load()
+				if current <= max || maxRunning.CompareAndSwap(max, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			return "ok", nil
+		})
+	})
+	joinPoolTestRoom(t, transport)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(ref int) {
+			defer wg.Done()
+			request := newMessage(MessageTypePush, "room:1", "work", nil)
+			request.Ref = ref
+			request.JoinRef = 1
+			transport.SendMessage(request)
+		}(10 + i)
+	}
+	wg.Wait()
+
+	if messages := waitMessages(transport, 8); len(messages) != 8 {
+		t.Errorf("MaxConcurrency failed: Invalid number of replies\n   actual: %v\n expected: %v", len(messages), 8)
+	}
+	if max := maxRunning.Load(); max > 2 {
+		t.Errorf("MaxConcurrency failed: Invalid concurrency\n   actual: %v\n expected: %v", max, 2)
+	}
+}
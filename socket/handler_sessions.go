@@ -0,0 +1,111 @@
+package socket
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+// minReapInterval min interval between the checks of idle sessions
+const minReapInterval = 10 * time.Millisecond
+
+// SessionCount number of active sessions of this Handler
+func (h *Handler) SessionCount() int {
+	h.sessionsMutex.RLock()
+	defer h.sessionsMutex.RUnlock()
+	return len(h.sessions)
+}
+
+// SessionStats snapshot of an active session, see Handler.Sessions
+type SessionStats struct {
+	SocketId   string    `json:"socket_id"`
+	Endpoint   string    `json:"endpoint"`
+	Topics     []string  `json:"topics"`           // Joined topics, see Session.Topics
+	Remote     string    `json:"remote,omitempty"` // Node of the client, for sessions proxied by sharded channels
+	LastActive time.Time `json:"last_active"`
+	Dropped    uint64    `json:"dropped"` // see Session.Dropped
+}
+
+// Sessions gets a snapshot of the active sessions of this Handler, ordered by SocketId. Useful for debugging and admin
+// dashboards.
+func (h *Handler) Sessions() []SessionStats {
+	h.sessionsMutex.RLock()
+	sessions := make([]*Session, 0, len(h.sessions))
+	for _, session := range h.sessions {
+		sessions = append(sessions, session)
+	}
+	h.sessionsMutex.RUnlock()
+
+	list := make([]SessionStats, 0, len(sessions))
+	for _, session := range sessions {
+		list = append(list, SessionStats{
+			SocketId:   session.SocketId(),
+			Endpoint:   session.Endpoint(),
+			Topics:     session.Topics(),
+			Remote:     session.remote,
+			LastActive: session.LastActive(),
+			Dropped:    session.Dropped(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].SocketId < list[j].SocketId
+	})
+	return list
+}
+
+// startReaper starts the goroutine that closes the idle sessions (see Handler.IdleTimeout), sessions of transports
+// that crashed or never scheduled the shutdown. Stopped by chain.Router.Shutdown.
+func (h *Handler) startReaper(router *chain.Router) {
+	if h.IdleTimeout <= 0 {
+		return
+	}
+
+	interval := h.IdleTimeout / 2
+	if interval < minReapInterval {
+		interval = minReapInterval
+	}
+
+	stop := make(chan struct{})
+	router.OnShutdown(func(ctx context.Context) error {
+		close(stop)
+		return nil
+	})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				h.reapSessions(now)
+			}
+		}
+	}()
+}
+
+// reapSessions closes the sessions without activity since IdleTimeout, returns the number of sessions closed
+func (h *Handler) reapSessions(now time.Time) int {
+	h.sessionsMutex.RLock()
+	var idle []*Session
+	for _, session := range h.sessions {
+		if now.Sub(session.LastActive()) > h.IdleTimeout {
+			idle = append(idle, session)
+		}
+	}
+	h.sessionsMutex.RUnlock()
+
+	for _, session := range idle {
+		slog.Info(
+			"[chain.socket] closing idle session",
+			slog.Any("socket_id", session.SocketId()),
+			slog.Time("LastActive", session.LastActive()),
+		)
+		session.ScheduleShutdown(0)
+	}
+	return len(idle)
+}
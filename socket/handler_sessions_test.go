@@ -0,0 +1,119 @@
+package socket
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func configureSessionsTestHandler(router *chain.Router, handler *Handler) {
+	handler.Transports = []Transport{&transportT{}}
+	handler.Channels = []*Channel{NewChannel("room:*", func(channel *Channel) {})}
+	router.Configure("/socket", handler)
+}
+
+// waitSessionCount waits until the handler has `count` sessions (the sessions are closed asynchronously)
+func waitSessionCount(handler *Handler, count int) int {
+	deadline := time.Now().Add(2 * time.Second)
+	for handler.SessionCount() != count && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return handler.SessionCount()
+}
+
+func hasSession(handler *Handler, session *Session) bool {
+	handler.sessionsMutex.RLock()
+	defer handler.sessionsMutex.RUnlock()
+	return handler.sessions[session.SocketId()] == session
+}
+
+func Test_Handler_MaxSessions(t *testing.T) {
+	handler := &Handler{MaxSessions: 2}
+	configureSessionsTestHandler(chain.New(), handler)
+
+	var sessions []*Session
+	for i := 0; i < 2; i++ {
+		session, err := handler.Connect("/test", map[string]string{})
+		if err != nil {
+			t.Fatalf("Connect() failed: unexpected error %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if count := handler.SessionCount(); count != 2 {
+		t.Errorf("SessionCount() failed: Invalid count\n   actual: %v\n expected: %v", count, 2)
+	}
+
+	if _, err := handler.Connect("/test", map[string]string{}); !errors.Is(err, ErrMaxSessions) {
+		t.Errorf("Connect() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrMaxSessions)
+	}
+
+	sessions[0].ScheduleShutdown(0)
+	if count := waitSessionCount(handler, 1); count != 1 {
+		t.Errorf("SessionCount() failed: Invalid count\n   actual: %v\n expected: %v", count, 1)
+	}
+	if _, err := handler.Connect("/test", map[string]string{}); err != nil {
+		t.Errorf("Connect() failed: unexpected error %v", err)
+	}
+}
+
+func Test_Handler_Reap_Idle_Sessions(t *testing.T) {
+	handler := &Handler{IdleTimeout: time.Minute}
+	configureSessionsTestHandler(chain.New(), handler)
+
+	idle, _ := handler.Connect("/test", map[string]string{})
+	active, _ := handler.Connect("/test", map[string]string{})
+
+	idle.lastActive.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	active.Dispatch([]byte(`0,0,1,"","heartbeat",null`))
+
+	if closed := handler.reapSessions(time.Now()); closed != 1 {
+		t.Errorf("reapSessions() failed: Invalid closed\n   actual: %v\n expected: %v", closed, 1)
+	}
+	if count := waitSessionCount(handler, 1); count != 1 {
+		t.Errorf("SessionCount() failed: Invalid count\n   actual: %v\n expected: %v", count, 1)
+	}
+	if hasSession(handler, idle) {
+		t.Errorf("reapSessions() failed: idle session not closed")
+	}
+	if !hasSession(handler, active) {
+		t.Errorf("reapSessions() failed: active session closed")
+	}
+}
+
+func Test_Handler_Reaper(t *testing.T) {
+	handler := &Handler{IdleTimeout: 20 * time.Millisecond}
+	configureSessionsTestHandler(chain.New(), handler)
+
+	handler.Connect("/test", map[string]string{})
+
+	if count := waitSessionCount(handler, 0); count != 0 {
+		t.Errorf("Reaper failed: idle session not closed\n   actual: %v\n expected: %v", count, 0)
+	}
+}
+
+func Test_Handler_Sessions(t *testing.T) {
+	handler := &Handler{}
+	configureSessionsTestHandler(chain.New(), handler)
+
+	session, err := handler.Connect("/test", map[string]string{})
+	if err != nil {
+		t.Fatalf("Connect() failed: unexpected error %v", err)
+	}
+	session.setSocket("room:2", &Socket{})
+	session.setSocket("room:1", &Socket{})
+
+	sessions := handler.Sessions()
+	if len(sessions) != 1 {
+		t.Fatalf("Sessions() failed: Invalid count\n   actual: %v\n expected: %v", len(sessions), 1)
+	}
+	stats := sessions[0]
+	if stats.SocketId != session.SocketId() || stats.Endpoint != "/test" {
+		t.Errorf("Sessions() failed: Invalid session\n   actual: %+v", stats)
+	}
+	if strings.Join(stats.Topics, ",") != "room:1,room:2" {
+		t.Errorf("Sessions() failed: Invalid topics\n   actual: %v\n expected: %v", stats.Topics, "room:1,room:2")
+	}
+}
@@ -0,0 +1,263 @@
+package socket
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/nidorx/chain/pubsub"
+)
+
+const shardTopicPrefix = "chain.socket.shard:"
+
+const (
+	shardKindMessage = byte('M') // client message, sent to the owner node
+	shardKindClose   = byte('C') // client session closed, sent to the owner node
+	shardKindPush    = byte('P') // message to the client, sent to the node of the client session
+)
+
+// shardSend sends the message to the node, replaced in tests
+var shardSend = func(node string, topic string, message []byte) error {
+	return pubsub.DirectBroadcast(node, topic, message)
+}
+
+// shardEnvelope message exchanged between the nodes of sharded channels, see Channel.Shard
+type shardEnvelope struct {
+	Kind     byte              `json:"k"`
+	Node     string            `json:"n"` // node of the client session
+	SocketId string            `json:"s"`
+	Endpoint string            `json:"e,omitempty"`
+	Params   map[string]string `json:"p,omitempty"`
+	Data     []byte            `json:"d,omitempty"`
+}
+
+// shardSession session of a client connected to another node, for the topics owned by this node
+type shardSession struct {
+	session *Session
+	stop    chan struct{}
+}
+
+// shards state of the sharded channels of the Handler
+type shards struct {
+	node     string // id of this node, see pubsub.Self
+	topic    string // pubsub topic used by the nodes of this Handler
+	sessions map[string]*shardSession
+	mutex    sync.Mutex
+}
+
+// configureShards subscribes the Handler to the messages of the other nodes, if any channel is sharded
+func (h *Handler) configureShards(endpoint string) {
+	for _, channel := range h.Channels {
+		if channel.shardNodes != nil {
+			h.shards = &shards{
+				node:     pubsub.Self(),
+				topic:    shardTopicPrefix + endpoint,
+				sessions: map[string]*shardSession{},
+			}
+			if err := pubsub.Subscribe(h.shards.topic, pubsub.DispatcherFunc(h.dispatchShard)); err != nil {
+				slog.Warn(
+					"[chain.socket] could not subscribe shard topic",
+					slog.String("Topic", h.shards.topic),
+					slog.Any("Error", err),
+				)
+			}
+			return
+		}
+	}
+}
+
+// forwardShard sends the message to the owner node of the topic, returns false if the topic is owned by this node.
+//
+// The owner is defined on the join, the next messages of the topic are sent to the same node until the leave.
+func (h *Handler) forwardShard(message *Message, session *Session) bool {
+	if h.shards == nil || session.remote != "" {
+		// sessions of other nodes are always handled locally
+		return false
+	}
+	channel := h.getChannel(message.Topic)
+	if channel == nil || channel.shardNodes == nil {
+		return false
+	}
+
+	session.socketsMutex.Lock()
+	owner, exists := session.shardOwners[message.Topic]
+	if !exists || message.Event == "_join" || message.Event == "_rejoin" {
+		owner = channel.shardOwner(message.Topic, h.shards.node)
+	}
+	if owner == h.shards.node || message.Event == "_leave" {
+		delete(session.shardOwners, message.Topic)
+	} else {
+		if session.shardOwners == nil {
+			session.shardOwners = map[string]string{}
+		}
+		session.shardOwners[message.Topic] = owner
+	}
+	session.socketsMutex.Unlock()
+
+	if owner == h.shards.node {
+		return false
+	}
+
+	data, err := h.Serializer.Encode(message)
+	if err == nil {
+		err = h.sendShard(owner, &shardEnvelope{
+			Kind:     shardKindMessage,
+			Node:     h.shards.node,
+			SocketId: session.socketId,
+			Endpoint: session.endpoint,
+			Params:   session.Params,
+			Data:     data,
+		})
+	}
+	if err != nil {
+		slog.Warn(
+			"[chain.socket] could not forward message to the owner node",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", message.Topic),
+			slog.String("Node", owner),
+			slog.Any("Error", err),
+		)
+		h.pushError(replyTarget{
+			ref:     message.Ref,
+			joinRef: message.JoinRef,
+			topic:   message.Topic,
+			event:   message.Event,
+		}, session, ErrMessageCrashed)
+	}
+	return true
+}
+
+// closeShards closes the sessions created on the owner nodes of the topics joined by the session
+func (h *Handler) closeShards(session *Session) {
+	if h.shards == nil || session.remote != "" {
+		return
+	}
+
+	session.socketsMutex.Lock()
+	nodes := map[string]bool{}
+	for _, node := range session.shardOwners {
+		nodes[node] = true
+	}
+	session.shardOwners = nil
+	session.socketsMutex.Unlock()
+
+	for node := range nodes {
+		if err := h.sendShard(node, &shardEnvelope{
+			Kind:     shardKindClose,
+			Node:     h.shards.node,
+			SocketId: session.socketId,
+		}); err != nil {
+			slog.Warn(
+				"[chain.socket] could not close session on the owner node",
+				slog.Any("socket_id", session.SocketId()),
+				slog.String("Node", node),
+				slog.Any("Error", err),
+			)
+		}
+	}
+}
+
+func (h *Handler) sendShard(node string, envelope *shardEnvelope) error {
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	return shardSend(node, h.shards.topic, data)
+}
+
+// dispatchShard receives the messages of the other nodes
+func (h *Handler) dispatchShard(topic string, message any, from string) {
+	data, ok := message.([]byte)
+	if !ok {
+		return
+	}
+	envelope := &shardEnvelope{}
+	if err := json.Unmarshal(data, envelope); err != nil {
+		slog.Debug(
+			"[chain.socket] could not decode shard message",
+			slog.Any("Error", err),
+			slog.String("Node", from),
+		)
+		return
+	}
+
+	switch envelope.Kind {
+	case shardKindMessage:
+		h.Dispatch(envelope.Data, h.getShardSession(envelope))
+	case shardKindClose:
+		h.closeShardSession(envelope)
+	case shardKindPush:
+		h.sessionsMutex.RLock()
+		session := h.sessions[envelope.SocketId]
+		h.sessionsMutex.RUnlock()
+		if session != nil {
+			session.Push(envelope.Data)
+		}
+	}
+}
+
+// getShardSession gets (or creates) the local session of a client connected to another node. The messages pushed to
+// this session are sent to the node of the client.
+func (h *Handler) getShardSession(envelope *shardEnvelope) *Session {
+	key := envelope.Node + "/" + envelope.SocketId
+
+	h.shards.mutex.Lock()
+	defer h.shards.mutex.Unlock()
+
+	if shard, exists := h.shards.sessions[key]; exists {
+		return shard.session
+	}
+
+	shard := &shardSession{
+		session: &Session{
+			Params:   envelope.Params,
+			Options:  h.Options,
+			socketId: envelope.SocketId,
+			endpoint: envelope.Endpoint,
+			handler:  h,
+			messages: make(chan []byte, 32),
+			remote:   envelope.Node,
+		},
+		stop: make(chan struct{}),
+	}
+	h.shards.sessions[key] = shard
+
+	go func() {
+		for {
+			select {
+			case <-shard.stop:
+				return
+			case data := <-shard.session.messages:
+				if err := h.sendShard(envelope.Node, &shardEnvelope{
+					Kind:     shardKindPush,
+					Node:     envelope.Node,
+					SocketId: envelope.SocketId,
+					Data:     data,
+				}); err != nil {
+					slog.Warn(
+						"[chain.socket] could not send message to the node of the client",
+						slog.Any("socket_id", envelope.SocketId),
+						slog.String("Node", envelope.Node),
+						slog.Any("Error", err),
+					)
+				}
+			}
+		}
+	}()
+
+	return shard.session
+}
+
+func (h *Handler) closeShardSession(envelope *shardEnvelope) {
+	key := envelope.Node + "/" + envelope.SocketId
+
+	h.shards.mutex.Lock()
+	shard, exists := h.shards.sessions[key]
+	delete(h.shards.sessions, key)
+	h.shards.mutex.Unlock()
+
+	if exists {
+		shard.session.close()
+		close(shard.stop)
+	}
+}
@@ -0,0 +1,137 @@
+package socket
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Channel_Shard_Owner(t *testing.T) {
+	channel := NewChannel("game:*", func(channel *Channel) {
+		channel.Shard(func() []string {
+			return []string{"A", "B", "C"}
+		})
+	})
+
+	owners := map[string]int{}
+	for i := 0; i < 300; i++ {
+		topic := fmt.Sprintf("game:%d", i)
+		owner := channel.shardOwner(topic, "A")
+		if owner != channel.shardOwner(topic, "B") {
+			t.Fatalf("shardOwner() failed: owner depends on the local node")
+		}
+		owners[owner]++
+	}
+	for _, node := range []string{"A", "B", "C"} {
+		if owners[node] < 50 {
+			t.Errorf("shardOwner() failed: Invalid distribution\n   actual: %v", owners)
+		}
+	}
+
+	local := NewChannel("game:*", func(channel *Channel) {})
+	if owner := local.shardOwner("game:1", "A"); owner != "A" {
+		t.Errorf("shardOwner() failed: Invalid owner\n   actual: %s\n expected: %s", owner, "A")
+	}
+}
+
+func Test_Handler_Shard_Proxy(t *testing.T) {
+	handlers := map[string]*Handler{}
+	defer func(send func(node string, topic string, message []byte) error) { shardSend = send }(shardSend)
+	shardSend = func(node string, topic string, message []byte) error {
+		go handlers[node].dispatchShard(topic, message, "")
+		return nil
+	}
+
+	var mutex sync.Mutex
+	var joins []string
+	leaves := make(chan LeaveReason, 1)
+
+	newNode := func(node string, transport *transportT) *Handler {
+		handler := &Handler{
+			Transports: []Transport{transport},
+			Channels: []*Channel{
+				NewChannel("game:*", func(channel *Channel) {
+					channel.Shard(func() []string {
+						return []string{"A", "B"}
+					})
+					channel.Join("game:*", func(payload any, socket *Socket) (reply any, err error) {
+						mutex.Lock()
+						joins = append(joins, node+":"+socket.Params["user"])
+						mutex.Unlock()
+						return
+					})
+					channel.HandleIn("ping", func(event string, payload any, socket *Socket) (reply any, err error) {
+						return "pong from " + node, nil
+					})
+					channel.Leave("game:*", func(socket *Socket, reason LeaveReason) {
+						leaves <- reason
+					})
+				}),
+			},
+		}
+		chain.New().Configure("/socket", handler)
+		handler.shards.node = node
+		handlers[node] = handler
+		return handler
+	}
+
+	transportA := &transportT{}
+	handlerA := newNode("A", transportA)
+	newNode("B", &transportT{})
+
+	topic := ""
+	for i := 0; topic == ""; i++ {
+		if candidate := fmt.Sprintf("game:%d", i); handlerA.getChannel(candidate).shardOwner(candidate, "A") == "B" {
+			topic = candidate
+		}
+	}
+
+	session, err := transportA.Connect(map[string]string{"user": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	join := newMessage(MessageTypePush, topic, "_join", nil)
+	join.Ref = 1
+	join.JoinRef = 1
+	transportA.SendMessage(join)
+
+	ping := newMessage(MessageTypePush, topic, "ping", nil)
+	ping.Ref = 2
+	ping.JoinRef = 1
+	if messages := waitMessages(transportA, 1); len(messages) != 1 || messages[0].Status != ReplyStatusCodeOk {
+		t.Fatalf("Join() failed: Invalid reply\n   actual: %v", messages)
+	}
+	transportA.SendMessage(ping)
+
+	messages := waitMessages(transportA, 2)
+	if len(messages) != 2 {
+		t.Fatalf("HandleIn() failed: no reply")
+	}
+	if reply := messages[1]; reply.Ref != 2 || reply.Payload != "pong from B" {
+		t.Errorf("HandleIn() failed: Invalid reply\n   actual: %v\n expected: %v", reply.Payload, "pong from B")
+	}
+
+	mutex.Lock()
+	if len(joins) != 1 || joins[0] != "B:1" {
+		t.Errorf("Join() failed: Invalid joins\n   actual: %v\n expected: %v", joins, []string{"B:1"})
+	}
+	mutex.Unlock()
+
+	if session.GetSocket(topic) != nil {
+		t.Errorf("Join() failed: socket of sharded topic created on the non-owner node")
+	}
+
+	handlerA.handleClose(session)
+	select {
+	case reason := <-leaves:
+		if reason != LeaveReasonClose {
+			t.Errorf("handleClose() failed: Invalid leave reason\n   actual: %v\n expected: %v", reason, LeaveReasonClose)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("handleClose() failed: session not closed on the owner node")
+	}
+}
@@ -0,0 +1,90 @@
+package socket
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ProtocolVersionParam connect param with the protocol version of the client (ex. "/socket/sse?vsn=1.0.0"), see
+// Handler.Versions
+const ProtocolVersionParam = "vsn"
+
+// ProtocolVersionHeader response header of the HTTP transports with the protocol version negotiated with the client
+const ProtocolVersionHeader = "X-Protocol-Version"
+
+// legacyProtocolVersion version of the clients that predate the negotiation (connect without ProtocolVersionParam)
+const legacyProtocolVersion = "1.0.0"
+
+var ErrProtocolVersion = errors.New("unsupported protocol version")
+
+// negotiateVersion selects the protocol version of the session.
+//
+// Versions are compatible when they have the same major version, the session uses the lowest version between the
+// client version and the most recent version of the Handler.Versions with the same major, so that servers can
+// evolve the wire format while keeping the deployed clients working (compatibility shims by Session.ProtocolVersion).
+func (h *Handler) negotiateVersion(clientVersion string) (string, error) {
+	if clientVersion == "" {
+		clientVersion = legacyProtocolVersion
+	}
+	client, valid := parseVersion(clientVersion)
+	if !valid {
+		return "", ErrProtocolVersion
+	}
+
+	var selected []int
+	var selectedVersion string
+	for _, version := range h.protocolVersions() {
+		server, valid := parseVersion(version)
+		if !valid || server[0] != client[0] {
+			continue
+		}
+		if selected == nil || compareVersion(server, selected) > 0 {
+			selected, selectedVersion = server, version
+		}
+	}
+	if selected == nil {
+		return "", ErrProtocolVersion
+	}
+	if compareVersion(client, selected) < 0 {
+		return clientVersion, nil
+	}
+	return selectedVersion, nil
+}
+
+// protocolVersions the protocol versions accepted by the Handler
+func (h *Handler) protocolVersions() []string {
+	if len(h.Versions) == 0 {
+		return []string{ProtocolVersion}
+	}
+	return h.Versions
+}
+
+// parseVersion parses the "major.minor.patch" version, minor and patch are optional
+func parseVersion(version string) ([]int, bool) {
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return nil, false
+	}
+	out := []int{0, 0, 0}
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return nil, false
+		}
+		out[i] = value
+	}
+	return out, true
+}
+
+func compareVersion(a []int, b []int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
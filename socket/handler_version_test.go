@@ -0,0 +1,51 @@
+package socket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Handler_Negotiate_Version(t *testing.T) {
+	tests := []struct {
+		versions []string
+		client   string
+		expected string
+		err      error
+	}{
+		{nil, "", ProtocolVersion, nil}, // legacy client
+		{nil, ProtocolVersion, ProtocolVersion, nil},
+		{nil, "1.0", ProtocolVersion, nil},
+		{nil, "2.0.0", "", ErrProtocolVersion},
+		{nil, "abc", "", ErrProtocolVersion},
+		{[]string{"1.2.0", "1.1.0", "2.0.0"}, "1.1.5", "1.1.5", nil}, // older client
+		{[]string{"1.2.0", "1.1.0", "2.0.0"}, "1.3.0", "1.2.0", nil}, // newer client
+		{[]string{"1.2.0", "1.1.0", "2.0.0"}, "2.1.0", "2.0.0", nil},
+		{[]string{"2.0.0"}, "", "", ErrProtocolVersion},
+	}
+	for _, test := range tests {
+		handler := &Handler{Versions: test.versions}
+		version, err := handler.negotiateVersion(test.client)
+		if version != test.expected || !errors.Is(err, test.err) {
+			t.Errorf("negotiateVersion(%v, %q) failed: Invalid version\n   actual: %q %v\n expected: %q %v", test.versions, test.client, version, err, test.expected, test.err)
+		}
+	}
+}
+
+func Test_Handler_Connect_Version(t *testing.T) {
+	handler := &Handler{Versions: []string{"1.1.0"}}
+	configureSessionsTestHandler(chain.New(), handler)
+
+	session, err := handler.Connect("/test", map[string]string{ProtocolVersionParam: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Connect() failed: unexpected error %v", err)
+	}
+	if session.ProtocolVersion() != "1.0.0" {
+		t.Errorf("Connect() failed: Invalid version\n   actual: %v\n expected: %v", session.ProtocolVersion(), "1.0.0")
+	}
+
+	if _, err = handler.Connect("/test", map[string]string{ProtocolVersionParam: "2.0.0"}); !errors.Is(err, ErrProtocolVersion) {
+		t.Errorf("Connect() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrProtocolVersion)
+	}
+}
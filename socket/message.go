@@ -1,6 +1,10 @@
 package socket
 
-import "sync"
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+)
 
 type MessageType int
 
@@ -21,33 +25,29 @@ type Message struct {
 	Topic   string      `json:"t,omitempty"` // The string topic or topic:subtopic pair namespace, for example "messages", "messages:123"
 	Event   string      `json:"e,omitempty"` // The string event name, for example "_join"
 	Payload any         `json:"p,omitempty"` // The Message payload
+	// replyClaim when defined, only the first reply to this message is sent (see Handler.process)
+	replyClaim *atomic.Bool
+	// pool ownership state of the message (atomic), see newMessage
+	pool int32
 }
 
-var messagePool = &sync.Pool{
-	New: func() any {
-		return &Message{}
-	},
-}
-
-func newMessageAny() *Message {
-	return messagePool.Get().(*Message)
-}
-
-func newMessage(kind MessageType, topic string, event string, payload any) *Message {
-	m := messagePool.Get().(*Message)
-	m.Kind = kind
-	m.Topic = topic
-	m.Event = event
-	m.Payload = payload
-	return m
+// EncodedPayload payload already encoded as JSON, embedded as is in the messages by the serializers. Allows encoding
+// a payload once and pushing it to many sockets (see Socket.PushEncoded).
+type EncodedPayload []byte
+
+// EncodePayload encodes the payload as JSON, see EncodedPayload
+func EncodePayload(payload any) (EncodedPayload, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
 }
 
-func deleteMessage(m *Message) {
-	m.Payload = nil
-	m.Event = ""
-	m.Topic = ""
-	m.Ref = 0
-	m.JoinRef = 0
-	m.Status = 0
-	messagePool.Put(m)
+// MarshalJSON embeds the payload as is, for serializers based on encoding/json
+func (p EncodedPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return nil, errors.New("empty EncodedPayload")
+	}
+	return p, nil
 }
@@ -0,0 +1,62 @@
+package socket
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// binaryFrameFlag first byte of the binary frames of the MessageSerializer. JSON frames start with the kind (digit)
+// and the broadcast envelope with a zero byte (see encodeBroadcastFrom).
+const binaryFrameFlag byte = 0x01
+
+// binaryFrameHeaderSize [flag: byte] [kind: byte] [status: byte] [joinRef: uint32] [ref: uint32] [topicLen: uint16] [eventLen: uint16]
+const binaryFrameHeaderSize = 1 + 1 + 1 + 4 + 4 + 2 + 2
+
+var errBinaryFrameInvalid = errors.New("invalid binary frame")
+
+// encodeBinaryFrame encodes the message with a binary payload ([]byte), avoiding the base64/JSON overhead for file
+// chunks and audio streams. The payload is appended as is:
+//
+//	[flag: byte] [kind: byte] [status: byte] [joinRef: uint32] [ref: uint32] [topicLen: uint16] [eventLen: uint16]
+//	[topic: topicLen] [event: eventLen] [payload: ...]
+func encodeBinaryFrame(msg *Message, payload []byte) ([]byte, error) {
+	if len(msg.Topic) > math.MaxUint16 || len(msg.Event) > math.MaxUint16 {
+		return nil, errBinaryFrameInvalid
+	}
+
+	data := make([]byte, binaryFrameHeaderSize, binaryFrameHeaderSize+len(msg.Topic)+len(msg.Event)+len(payload))
+	data[0] = binaryFrameFlag
+	data[1] = byte(msg.Kind)
+	data[2] = byte(msg.Status)
+	binary.BigEndian.PutUint32(data[3:7], uint32(msg.JoinRef))
+	binary.BigEndian.PutUint32(data[7:11], uint32(msg.Ref))
+	binary.BigEndian.PutUint16(data[11:13], uint16(len(msg.Topic)))
+	binary.BigEndian.PutUint16(data[13:15], uint16(len(msg.Event)))
+	data = append(data, msg.Topic...)
+	data = append(data, msg.Event...)
+	return append(data, payload...), nil
+}
+
+// decodeBinaryFrame decodes the binary frame, see encodeBinaryFrame. The payload ([]byte) references the data.
+func decodeBinaryFrame(data []byte, msg *Message) error {
+	if len(data) < binaryFrameHeaderSize || data[0] != binaryFrameFlag {
+		return errBinaryFrameInvalid
+	}
+	topicLen := int(binary.BigEndian.Uint16(data[11:13]))
+	eventLen := int(binary.BigEndian.Uint16(data[13:15]))
+	if len(data) < binaryFrameHeaderSize+topicLen+eventLen {
+		return errBinaryFrameInvalid
+	}
+
+	msg.Kind = MessageType(data[1])
+	msg.Status = int(data[2])
+	msg.JoinRef = int(binary.BigEndian.Uint32(data[3:7]))
+	msg.Ref = int(binary.BigEndian.Uint32(data[7:11]))
+
+	data = data[binaryFrameHeaderSize:]
+	msg.Topic = string(data[:topicLen])
+	msg.Event = string(data[topicLen : topicLen+eventLen])
+	msg.Payload = data[topicLen+eventLen:]
+	return nil
+}
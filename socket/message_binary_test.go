@@ -0,0 +1,50 @@
+package socket
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_Socket_MessageSerializer_Binary(t *testing.T) {
+	serializer := &MessageSerializer{}
+
+	chunk := []byte{0x00, 0x01, 0xFF, '[', '"', 0x7F}
+	tests := []Message{
+		{Kind: MessageTypePush, JoinRef: 2, Ref: 3, Topic: "room:1234", Event: "upload", Payload: chunk},
+		{Kind: MessageTypeReply, JoinRef: 2, Ref: 3, Topic: "room:1234", Status: 1, Payload: chunk},
+		{Kind: MessageTypeBroadcast, Topic: "audio:9", Event: "frame", Payload: chunk},
+		{Kind: MessageTypePush, JoinRef: 1, Ref: 1, Topic: "room:1234", Event: "empty", Payload: []byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Event, func(t *testing.T) {
+			encoded, err := serializer.Encode(&tt)
+			if err != nil {
+				t.Fatalf("Encode() failed: unexpected error\n   actual: %v\n expected: nil", err)
+			}
+			if encoded[0] != binaryFrameFlag {
+				t.Fatalf("Encode() failed: Invalid flag\n   actual: %v\n expected: %v", encoded[0], binaryFrameFlag)
+			}
+
+			decoded := &Message{}
+			if _, err = serializer.Decode(encoded, decoded); err != nil {
+				t.Fatalf("Decode() failed: unexpected error\n   actual: %v\n expected: nil", err)
+			}
+			if decoded.Kind != tt.Kind || decoded.JoinRef != tt.JoinRef || decoded.Ref != tt.Ref ||
+				decoded.Topic != tt.Topic || decoded.Event != tt.Event || decoded.Status != tt.Status {
+				t.Errorf("Decode() failed: Invalid Message\n   actual: %+v\n expected: %+v", decoded, tt)
+			}
+			if payload, ok := decoded.Payload.([]byte); !ok || !bytes.Equal(payload, tt.Payload.([]byte)) {
+				t.Errorf("Decode() failed: Invalid Payload\n   actual: %v\n expected: %v", decoded.Payload, tt.Payload)
+			}
+		})
+	}
+
+	for _, invalid := range [][]byte{
+		{binaryFrameFlag, 0, 0},
+		{binaryFrameFlag, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0, 9, 0, 0, 'a'},
+	} {
+		if _, err := serializer.Decode(invalid, &Message{}); err == nil {
+			t.Errorf("Decode(%v) failed: Invalid Error\n   actual: nil\n expected: %v", invalid, errBinaryFrameInvalid)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package socket
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// MessagePoolDebug enables the detection of misuses of the pooled messages: returned to the pool more than once
+// (double put) or used after being returned (use-after-put). In debug mode the messages returned are not reused, so
+// that any later use can be detected, and the misuses are logged with the stack trace.
+//
+// Meant for tests and troubleshooting, see MessagePoolStatistics. Can be toggled at runtime.
+//
+// ## Example
+//
+//	socket.MessagePoolDebug.Store(true)
+var MessagePoolDebug atomic.Bool
+
+// MessagePoolStats usage of the pool of messages, see MessagePoolStatistics
+type MessagePoolStats struct {
+	Gets        uint64 // Messages taken from the pool
+	Puts        uint64 // Messages returned to the pool
+	Misses      uint64 // Gets that allocated a new message (pool empty)
+	DoublePuts  uint64 // Messages returned to the pool more than once (the repeated puts are ignored)
+	UseAfterPut uint64 // Uses of messages after being returned to the pool (only detected with MessagePoolDebug)
+}
+
+// Hits gets that reused a message of the pool
+func (s MessagePoolStats) Hits() uint64 {
+	return s.Gets - s.Misses
+}
+
+// InUse messages taken and not returned to the pool yet
+func (s MessagePoolStats) InUse() int64 {
+	return int64(s.Gets) - int64(s.Puts)
+}
+
+// MessagePoolStatistics snapshot of the usage of the pool of messages.
+//
+// ## Example
+//
+//	stats := socket.MessagePoolStatistics()
+//	slog.Info("message pool", slog.Uint64("Hits", stats.Hits()), slog.Int64("InUse", stats.InUse()))
+func MessagePoolStatistics() MessagePoolStats {
+	return MessagePoolStats{
+		Gets:        messagePoolStats.gets.Load(),
+		Puts:        messagePoolStats.puts.Load(),
+		Misses:      messagePoolStats.misses.Load(),
+		DoublePuts:  messagePoolStats.doublePuts.Load(),
+		UseAfterPut: messagePoolStats.useAfterPut.Load(),
+	}
+}
+
+var messagePoolStats struct {
+	gets        atomic.Uint64
+	puts        atomic.Uint64
+	misses      atomic.Uint64
+	doublePuts  atomic.Uint64
+	useAfterPut atomic.Uint64
+}
+
+// pool ownership states of a Message
+const (
+	messageUnmanaged = int32(0) // not created by the pool (ex. &Message{})
+	messageInUse     = int32(1) // owned by the code that got it from the pool
+	messageReleased  = int32(2) // returned to the pool, must not be used
+)
+
+var messagePool = &sync.Pool{
+	New: func() any {
+		messagePoolStats.misses.Add(1)
+		m := &Message{}
+		atomic.StoreInt32(&m.pool, messageReleased)
+		return m
+	},
+}
+
+func newMessageAny() *Message {
+	m := messagePool.Get().(*Message)
+	atomic.StoreInt32(&m.pool, messageInUse)
+	messagePoolStats.gets.Add(1)
+	return m
+}
+
+// newMessage gets a message from the pool.
+//
+// Ownership rules: the code that gets the message owns it and must return it with deleteMessage once, when no
+// goroutine uses it anymore (messages handed to async handlers are returned by the handler). References to the
+// message (or to its fields, for reuse) must not be kept after deleteMessage.
+func newMessage(kind MessageType, topic string, event string, payload any) *Message {
+	m := newMessageAny()
+	m.Kind = kind
+	m.Topic = topic
+	m.Event = event
+	m.Payload = payload
+	return m
+}
+
+// deleteMessage returns the message to the pool, see newMessage
+func deleteMessage(m *Message) {
+	if !atomic.CompareAndSwapInt32(&m.pool, messageInUse, messageReleased) &&
+		!atomic.CompareAndSwapInt32(&m.pool, messageUnmanaged, messageReleased) {
+		// already returned, putting it again would give the same message to two owners
+		messagePoolStats.doublePuts.Add(1)
+		if MessagePoolDebug.Load() {
+			slog.Error(
+				"[chain.socket] message returned to the pool more than once",
+				slog.String("Topic", m.Topic),
+				slog.String("Event", m.Event),
+				slog.String("Stack", string(debug.Stack())),
+			)
+		}
+		return
+	}
+	messagePoolStats.puts.Add(1)
+
+	m.Payload = nil
+	m.Event = ""
+	m.Topic = ""
+	m.Ref = 0
+	m.JoinRef = 0
+	m.Status = 0
+	m.replyClaim = nil
+
+	if MessagePoolDebug.Load() {
+		// quarantined, later uses are detected by checkMessage
+		return
+	}
+	messagePool.Put(m)
+}
+
+// checkMessage detects the use of a message returned to the pool (MessagePoolDebug), returns false if released
+func checkMessage(m *Message, operation string) bool {
+	if !MessagePoolDebug.Load() || atomic.LoadInt32(&m.pool) != messageReleased {
+		return true
+	}
+	messagePoolStats.useAfterPut.Add(1)
+	slog.Error(
+		"[chain.socket] message used after returned to the pool",
+		slog.String("Operation", operation),
+		slog.String("Stack", string(debug.Stack())),
+	)
+	return false
+}
@@ -0,0 +1,62 @@
+package socket
+
+import (
+	"testing"
+)
+
+func Test_MessagePool_Statistics(t *testing.T) {
+	before := MessagePoolStatistics()
+
+	m := newMessage(MessageTypePush, "room:1", "ping", nil)
+	deleteMessage(m)
+	deleteMessage(m) // double put, ignored
+
+	after := MessagePoolStatistics()
+	if gets := after.Gets - before.Gets; gets != 1 {
+		t.Errorf("MessagePoolStatistics() failed: Invalid Gets\n   actual: %d\n expected: %d", gets, 1)
+	}
+	if puts := after.Puts - before.Puts; puts != 1 {
+		t.Errorf("MessagePoolStatistics() failed: Invalid Puts\n   actual: %d\n expected: %d", puts, 1)
+	}
+	if doublePuts := after.DoublePuts - before.DoublePuts; doublePuts != 1 {
+		t.Errorf("MessagePoolStatistics() failed: Invalid DoublePuts\n   actual: %d\n expected: %d", doublePuts, 1)
+	}
+
+	// the message returned twice is not given to two owners
+	a, b := newMessageAny(), newMessageAny()
+	if a == b {
+		t.Errorf("deleteMessage() failed: Same message given to two owners")
+	}
+	deleteMessage(a)
+	deleteMessage(b)
+}
+
+func Test_MessagePool_Debug(t *testing.T) {
+	MessagePoolDebug.Store(true)
+	defer MessagePoolDebug.Store(false)
+
+	before := MessagePoolStatistics()
+
+	m := newMessage(MessageTypePush, "room:1", "ping", nil)
+	if !checkMessage(m, "test") {
+		t.Errorf("checkMessage() failed: Message in use reported as released")
+	}
+	deleteMessage(m)
+
+	if other := newMessageAny(); other == m {
+		t.Errorf("deleteMessage() failed: Message reused in debug mode")
+	}
+	if checkMessage(m, "test") {
+		t.Errorf("checkMessage() failed: Use after put not detected")
+	}
+
+	channel, sockets := newFilterTestChannel(1, 1, func(channel *Channel) {})
+	channel.Dispatch("room:1", m, "")
+	if len(sockets[0].session.messages) != 0 {
+		t.Errorf("Dispatch() failed: Released message dispatched")
+	}
+
+	if useAfterPut := MessagePoolStatistics().UseAfterPut - before.UseAfterPut; useAfterPut != 2 {
+		t.Errorf("MessagePoolStatistics() failed: Invalid UseAfterPut\n   actual: %d\n expected: %d", useAfterPut, 2)
+	}
+}
@@ -5,10 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"sync"
+	"unicode/utf8"
 )
 
+// MessageSerializer encodes the Message as a JSON array, or as a binary frame when the payload is a []byte (see
+// encodeBinaryFrame). Binary payloads are received by the handlers as []byte.
 type MessageSerializer struct{}
 
+// Name of the serializer, see NamedSerializer
+func (s *MessageSerializer) Name() string {
+	return "json"
+}
+
 func (s *MessageSerializer) Encode(v any) (data []byte, err error) {
 	var msg *Message
 	var valid bool
@@ -17,61 +26,96 @@ func (s *MessageSerializer) Encode(v any) (data []byte, err error) {
 		return
 	}
 
+	if payload, isBinary := msg.Payload.([]byte); isBinary {
+		// binary payload, see encodeBinaryFrame
+		return encodeBinaryFrame(msg, payload)
+	}
+
 	// Push 		= [kind, joinRef, ref,  topic, event, payload]
 	// Reply 		= [kind, joinRef, ref, status,        payload]
 	// Broadcast 	= [kind,                topic, event, payload]
-	buf := &bytes.Buffer{}
-	buf.Write([]byte(strconv.Itoa(int(msg.Kind))))
+	buf := encodeBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= encodeBufferMaxSize {
+			encodeBufferPool.Put(buf)
+		}
+	}()
+
+	var scratch [20]byte
+	buf.Write(strconv.AppendInt(scratch[:0], int64(msg.Kind), 10))
 	if msg.Kind != MessageTypeBroadcast {
-		buf.WriteRune(',')
-		buf.Write([]byte(strconv.Itoa(msg.JoinRef)))
-		buf.WriteRune(',')
-		buf.Write([]byte(strconv.Itoa(msg.Ref)))
+		buf.WriteByte(',')
+		buf.Write(strconv.AppendInt(scratch[:0], int64(msg.JoinRef), 10))
+		buf.WriteByte(',')
+		buf.Write(strconv.AppendInt(scratch[:0], int64(msg.Ref), 10))
 	}
 
 	if msg.Kind == MessageTypeReply {
-		buf.WriteRune(',')
-		buf.Write([]byte(strconv.Itoa(msg.Status)))
+		buf.WriteByte(',')
+		buf.Write(strconv.AppendInt(scratch[:0], int64(msg.Status), 10))
 	} else {
-		if msg.Topic == "" {
-			buf.WriteString(`,""`)
-		} else {
-			data, err = json.Marshal(msg.Topic)
-			if err != nil {
-				return
-			}
-			buf.WriteRune(',')
-			buf.Write(data)
+		buf.WriteByte(',')
+		if err = writeJSONString(buf, msg.Topic); err != nil {
+			return
 		}
 	}
 
 	if msg.Kind != MessageTypeReply {
-		if msg.Event == "" {
-			buf.WriteString(`,""`)
-		} else {
-			data, err = json.Marshal(msg.Event)
-			if err != nil {
-				return
-			}
-			buf.WriteRune(',')
-			buf.Write(data)
+		buf.WriteByte(',')
+		if err = writeJSONString(buf, msg.Event); err != nil {
+			return
 		}
 	}
 
-	if msg.Payload != nil {
+	if encoded, isEncoded := msg.Payload.(EncodedPayload); isEncoded {
+		// pre-encoded, see Socket.PushEncoded
+		buf.WriteByte(',')
+		buf.Write(encoded)
+	} else if msg.Payload != nil {
 		data, err = json.Marshal(msg.Payload)
 		if err != nil {
 			return
 		}
-		buf.WriteRune(',')
+		buf.WriteByte(',')
 		buf.Write(data)
 	}
 
-	data = buf.Bytes()
+	// the buffer is reused, the output is the only allocation of the envelope
+	data = make([]byte, buf.Len())
+	copy(data, buf.Bytes())
 
 	return
 }
 
+// encodeBufferMaxSize larger buffers are not reused, avoids keeping memory of occasional large messages
+const encodeBufferMaxSize = 64 * 1024
+
+// encodeBufferPool buffers used by MessageSerializer.Encode
+var encodeBufferPool = sync.Pool{
+	New: func() any {
+		return &bytes.Buffer{}
+	},
+}
+
+// writeJSONString writes the string as JSON, without allocations for the common case (ASCII without escapes)
+func writeJSONString(buf *bytes.Buffer, value string) error {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c < 0x20 || c >= utf8.RuneSelf || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			buf.Write(data)
+			return nil
+		}
+	}
+	buf.WriteByte('"')
+	buf.WriteString(value)
+	buf.WriteByte('"')
+	return nil
+}
+
 func (s *MessageSerializer) Decode(data []byte, v any) (out any, err error) {
 	var valid bool
 	var msg *Message
@@ -81,6 +125,11 @@ func (s *MessageSerializer) Decode(data []byte, v any) (out any, err error) {
 	}
 	out = msg
 
+	if len(data) > 0 && data[0] == binaryFrameFlag {
+		err = decodeBinaryFrame(data, msg)
+		return
+	}
+
 	var (
 		auxInt     int
 		fieldIdx   = 0
@@ -96,9 +145,9 @@ func (s *MessageSerializer) Decode(data []byte, v any) (out any, err error) {
 			} else if i == 0 && b == '"' {
 				inQuote = true
 			}
-		} else if !inQuote && b == '{' {
+		} else if !inQuote && (b == '{' || b == '[') {
 			brackets++
-		} else if !inQuote && b == '}' {
+		} else if !inQuote && (b == '}' || b == ']') {
 			brackets--
 		}
 		if (!inQuote && brackets == 0 && b == ',') || i == len(data)-1 {
@@ -134,7 +183,7 @@ func (s *MessageSerializer) Decode(data []byte, v any) (out any, err error) {
 			case 2: // ref | event
 				if msg.Kind == MessageTypeBroadcast {
 					// event
-					msg.Event = string(data[fieldStart:fieldEnd])
+					msg.Event = string(data[fieldStart+1 : fieldEnd-1])
 				} else {
 					// ref
 					auxInt, err = strconv.Atoi(string(data[fieldStart:fieldEnd]))
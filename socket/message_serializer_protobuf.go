@@ -0,0 +1,161 @@
+package socket
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+)
+
+// ProtobufSerializer encodes Message using the protobuf wire format, allowing polyglot clients (ex. gRPC, see
+// TransportStream) to use the protobuf generated code for the schema below.
+//
+// The payload is encoded as JSON in the `payload` field, or kept as is in the `payload_raw` field when it is a []byte.
+//
+//	syntax = "proto3";
+//
+//	message Message {
+//	  int32 kind = 1;        // 0 = push, 1 = reply, 2 = broadcast
+//	  int64 join_ref = 2;
+//	  int64 ref = 3;
+//	  int32 status = 4;      // 0 = ok, 1 = error
+//	  string topic = 5;
+//	  string event = 6;
+//	  bytes payload = 7;     // JSON encoded payload
+//	  bytes payload_raw = 8; // binary payload
+//	}
+type ProtobufSerializer struct{}
+
+const (
+	protobufWireVarint = 0
+	protobufWireBytes  = 2
+)
+
+var errProtobufInvalid = errors.New("invalid protobuf message")
+
+// Name of the serializer, see NamedSerializer
+func (s *ProtobufSerializer) Name() string {
+	return "protobuf"
+}
+
+func (s *ProtobufSerializer) Encode(v any) (data []byte, err error) {
+	var msg *Message
+	var valid bool
+	if msg, valid = v.(*Message); !valid {
+		err = errors.New("can only serialize *Message")
+		return
+	}
+
+	data = make([]byte, 0, 32+len(msg.Topic)+len(msg.Event))
+	data = appendProtobufVarint(data, 1, int64(msg.Kind))
+	data = appendProtobufVarint(data, 2, int64(msg.JoinRef))
+	data = appendProtobufVarint(data, 3, int64(msg.Ref))
+	data = appendProtobufVarint(data, 4, int64(msg.Status))
+	data = appendProtobufBytes(data, 5, []byte(msg.Topic))
+	data = appendProtobufBytes(data, 6, []byte(msg.Event))
+
+	switch payload := msg.Payload.(type) {
+	case nil:
+	case EncodedPayload:
+		data = appendProtobufBytes(data, 7, payload)
+	case []byte:
+		data = appendProtobufBytes(data, 8, payload)
+	default:
+		var encoded []byte
+		if encoded, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+		data = appendProtobufBytes(data, 7, encoded)
+	}
+	return
+}
+
+func (s *ProtobufSerializer) Decode(data []byte, v any) (out any, err error) {
+	var valid bool
+	var msg *Message
+	if msg, valid = v.(*Message); !valid {
+		err = errors.New("can only deserialize *Message")
+		return
+	}
+	out = msg
+
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errProtobufInvalid
+		}
+		data = data[n:]
+		field, wire := key>>3, key&7
+
+		switch wire {
+		case protobufWireVarint:
+			value, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errProtobufInvalid
+			}
+			data = data[n:]
+			switch field {
+			case 1:
+				msg.Kind = MessageType(int64(value))
+			case 2:
+				msg.JoinRef = int(int64(value))
+			case 3:
+				msg.Ref = int(int64(value))
+			case 4:
+				msg.Status = int(int64(value))
+			}
+		case protobufWireBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return nil, errProtobufInvalid
+			}
+			value := data[n : n+int(size)]
+			data = data[n+int(size):]
+			switch field {
+			case 5:
+				msg.Topic = string(value)
+			case 6:
+				msg.Event = string(value)
+			case 7:
+				var payload any
+				if err = json.Unmarshal(value, &payload); err != nil {
+					return nil, err
+				}
+				msg.Payload = payload
+			case 8:
+				msg.Payload = append([]byte{}, value...)
+			}
+		case 1, 5:
+			// fixed64 and fixed32, unknown fields are ignored
+			size := 8
+			if wire == 5 {
+				size = 4
+			}
+			if len(data) < size {
+				return nil, errProtobufInvalid
+			}
+			data = data[size:]
+		default:
+			return nil, errProtobufInvalid
+		}
+	}
+	return
+}
+
+// appendProtobufVarint appends the field, omitting zero values (proto3 default)
+func appendProtobufVarint(data []byte, field int, value int64) []byte {
+	if value == 0 {
+		return data
+	}
+	data = binary.AppendUvarint(data, uint64(field)<<3|protobufWireVarint)
+	return binary.AppendUvarint(data, uint64(value))
+}
+
+// appendProtobufBytes appends the field, omitting empty values (proto3 default)
+func appendProtobufBytes(data []byte, field int, value []byte) []byte {
+	if len(value) == 0 {
+		return data
+	}
+	data = binary.AppendUvarint(data, uint64(field)<<3|protobufWireBytes)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
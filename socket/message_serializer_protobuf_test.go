@@ -0,0 +1,54 @@
+package socket
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_Socket_ProtobufSerializer(t *testing.T) {
+	serializer := &ProtobufSerializer{}
+
+	tests := []Message{
+		{Kind: MessageTypePush, JoinRef: 2, Ref: 3, Topic: "room:1234", Event: "_join", Payload: map[string]any{"param1": "foo"}},
+		{Kind: MessageTypePush, JoinRef: 2, Ref: 4, Topic: "room:1234", Event: "_leave"},
+		{Kind: MessageTypeReply, JoinRef: 2, Ref: 4, Status: ReplyStatusCodeError, Topic: "room:1234", Payload: []any{float64(1), "a"}},
+		{Kind: MessageTypeBroadcast, Topic: "room:1234", Event: "image", Payload: []byte{0, 1, 2, 255}},
+		{Kind: MessageTypePush, Ref: -1, Payload: "string"},
+	}
+	for _, expected := range tests {
+		encoded, err := serializer.Encode(&expected)
+		if err != nil {
+			t.Fatalf("Encode() failed: Invalid Error\n   actual: %v\n expected: nil", err)
+		}
+
+		decoded := &Message{}
+		if _, err = serializer.Decode(encoded, decoded); err != nil {
+			t.Fatalf("Decode() failed: Invalid Error\n   actual: %v\n expected: nil", err)
+		}
+		if !reflect.DeepEqual(*decoded, expected) {
+			t.Errorf("Decode() failed: Invalid Message\n   actual: %+v\n expected: %+v", *decoded, expected)
+		}
+	}
+}
+
+func Test_Socket_ProtobufSerializer_Wire(t *testing.T) {
+	// protoc --encode=Message: kind: 2, topic: "a", event: "b", payload: "1"
+	expected := []byte{0x08, 0x02, 0x2a, 0x01, 'a', 0x32, 0x01, 'b', 0x3a, 0x01, '1'}
+
+	encoded, err := (&ProtobufSerializer{}).Encode(&Message{Kind: MessageTypeBroadcast, Topic: "a", Event: "b", Payload: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Errorf("Encode() failed: Invalid bytes\n   actual: %v\n expected: %v", encoded, expected)
+	}
+
+	// unknown fields are ignored, truncated messages are rejected
+	if _, err = (&ProtobufSerializer{}).Decode(append(expected, 0x48, 0x01), &Message{}); err != nil {
+		t.Errorf("Decode() failed: Invalid Error\n   actual: %v\n expected: nil", err)
+	}
+	if _, err = (&ProtobufSerializer{}).Decode(expected[:len(expected)-1], &Message{}); err == nil {
+		t.Errorf("Decode() failed: Invalid Error\n   actual: nil\n expected: %v", errProtobufInvalid)
+	}
+}
@@ -1,9 +1,12 @@
 package socket
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/nidorx/chain"
 )
 
 //func Test_MessageSerializer_Encode(t *testing.T) {
@@ -29,11 +32,13 @@ func Test_Socket_MessageSerializer_Decode(t *testing.T) {
 		{`0,2,4,"","",{}`, "", Message{JoinRef: 2, Ref: 4, Payload: map[string]any{}}},
 		{`0,2,4,"","",[]`, "", Message{JoinRef: 2, Ref: 4, Payload: []any{}}},
 		{`0,2,4,"","",[{"param1":"foo"}]`, "", Message{JoinRef: 2, Ref: 4, Payload: []any{map[string]any{"param1": "foo"}}}},
+		{`0,2,4,"","",[1,[2,3]]`, "", Message{JoinRef: 2, Ref: 4, Payload: []any{float64(1), []any{float64(2), float64(3)}}}},
 		{`0,2,4,"","",1`, "", Message{JoinRef: 2, Ref: 4, Payload: float64(1)}},
 		{`0,2,4,"","","string"`, "", Message{JoinRef: 2, Ref: 4, Payload: "string"}},
 		{`0,2,4,"",""`, "", Message{JoinRef: 2, Ref: 4}},
 		// Reply 		= [kind, joinRef, ref, topic,        payload]
 		// Broadcast 	= [kind,               topic, event, payload]
+		{`2,"room:1234","shout","hello"`, "", Message{Kind: MessageTypeBroadcast, Topic: "room:1234", Event: "shout", Payload: "hello"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
@@ -66,3 +71,44 @@ func Test_Socket_MessageSerializer_Decode(t *testing.T) {
 		})
 	}
 }
+
+func Test_Socket_EncodedPayload(t *testing.T) {
+	payload := map[string]any{"score": float64(10), "name": "alice"}
+	encoded, err := EncodePayload(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serializers := []chain.Serializer{&MessageSerializer{}, &ProtobufSerializer{}}
+	for _, serializer := range serializers {
+		expected, _ := serializer.Encode(&Message{Kind: MessageTypePush, JoinRef: 1, Ref: 2, Topic: "room:1", Event: "score", Payload: payload})
+		actual, err := serializer.Encode(&Message{Kind: MessageTypePush, JoinRef: 1, Ref: 2, Topic: "room:1", Event: "score", Payload: encoded})
+		if err != nil {
+			t.Fatalf("Encode() failed: Invalid Error\n   actual: %v\n expected: nil", err)
+		}
+		if string(actual) != string(expected) {
+			t.Errorf("Encode() failed: Invalid EncodedPayload\n   actual: %s\n expected: %s", actual, expected)
+		}
+	}
+
+	// serializers based on encoding/json embed the payload as is
+	actual, _ := json.Marshal(&Message{Event: "score", Payload: encoded})
+	expected, _ := json.Marshal(&Message{Event: "score", Payload: payload})
+	if string(actual) != string(expected) {
+		t.Errorf("json.Marshal() failed: Invalid EncodedPayload\n   actual: %s\n expected: %s", actual, expected)
+	}
+}
+
+func Test_Socket_MessageSerializer_Encode_Strings(t *testing.T) {
+	serializer := &MessageSerializer{}
+	for _, value := range []string{"", "room:1", `a"b`, `a\b`, "<tag>&", "line\nbreak", "ção", " ", "\x7f"} {
+		encoded, err := serializer.Encode(&Message{Kind: MessageTypeBroadcast, Topic: value, Event: value})
+		if err != nil {
+			t.Fatalf("Encode() failed: Invalid Error\n   actual: %v\n expected: nil", err)
+		}
+		quoted, _ := json.Marshal(value)
+		if expected := "2," + string(quoted) + "," + string(quoted); string(encoded) != expected {
+			t.Errorf("Encode() failed: Invalid string\n   actual: %s\n expected: %s", encoded, expected)
+		}
+	}
+}
@@ -1,25 +1,46 @@
 package socket
 
 import (
+	"context"
+	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	ErrSessionClosed = fmt.Errorf("session closed")
+)
+
 // Session used by Transport, communication interface between Transport and Channel.
 //
 // Keeps an active session on the server. Transport should invoke ScheduleShutdown method when user connection drops
 type Session struct {
 	Params        map[string]string  // Initialization parameters, received at connection time
 	Options       map[string]any     // Reference to Handler.Options
-	closed        bool               // Session still active?
+	closed        atomic.Bool        // Session terminated? See Session.close
 	handler       *Handler           // Reference to the Handler of this session
 	socketId      string             // Session id
 	endpoint      string             // Path to socket endpoint
+	version       string             // Protocol version negotiated with the client, see Session.ProtocolVersion
 	sockets       map[string]*Socket // Socket by topic
+	joining       map[*Channel]int   // Joins in progress by channel, see reserveTopic
 	messages      chan []byte        // Messages that will be delivered to the client
 	shutdown      *time.Timer        // Session termination timeout
+	dropped       atomic.Uint64      // Number of messages discarded by Push because the buffer was full
+	lastActive    atomic.Int64       // Time of the last activity of the client (unix nano), see Session.Touch
+	instance      string             // Id of this instance of the session, see Handler.SessionStore
+	remote        string             // Node of the client, for sessions proxied by sharded channels (see Channel.Shard)
+	shardOwners   map[string]string  // Owner node by topic, for topics of sharded channels joined by the client
+	data          map[string]any     // Values shared by the sockets of the session, see Session.Set
+	ctx           context.Context    // Lifetime of the session, see Session.Context
+	cancel        context.CancelFunc
+	done          bool // the session context was cancelled (session closed)
+	dataMutex     sync.RWMutex
 	socketsMutex  sync.RWMutex
 	shutdownMutex sync.Mutex
+	storeMutex    sync.Mutex
 }
 
 // SocketId Session id
@@ -32,6 +53,52 @@ func (s *Session) Endpoint() string {
 	return s.endpoint
 }
 
+// Get a value from Session, shared by all the sockets of the session (server side only)
+func (s *Session) Get(key string) (value any) {
+	s.dataMutex.RLock()
+	defer s.dataMutex.RUnlock()
+	return s.data[key]
+}
+
+// Set a value on Session, visible to all the sockets of the session (server side only), allowing per-connection caches
+// shared by the channels joined by the client. Safe for concurrent use. The values are not saved by
+// Handler.SessionStore.
+//
+// ## Example
+//
+//	handler.OnConnect = func(session *socket.Session) error {
+//		claims, err := auth.Verify(session.Params["token"])
+//		session.Set("claims", claims)
+//		return err
+//	}
+//
+//	channel.Join("room:*", func(payload any, socket *Socket) (reply any, err error) {
+//		claims := socket.Session().Get("claims").(*auth.Claims)
+//		...
+//	})
+func (s *Session) Set(key string, value any) {
+	s.dataMutex.Lock()
+	defer s.dataMutex.Unlock()
+	if s.data == nil {
+		s.data = map[string]any{}
+	}
+	s.data[key] = value
+}
+
+// Context of the session lifetime, cancelled when the session is closed. The contexts of the sockets of the session
+// (see Socket.Context) are derived from it.
+func (s *Session) Context() context.Context {
+	s.dataMutex.Lock()
+	defer s.dataMutex.Unlock()
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+		if s.done {
+			s.cancel()
+		}
+	}
+	return s.ctx
+}
+
 // GetSocket get the Socket associated with the given topic
 func (s *Session) GetSocket(topic string) *Socket {
 	s.socketsMutex.RLock()
@@ -46,19 +113,91 @@ func (s *Session) GetSocket(topic string) *Socket {
 	return nil
 }
 
-// Push message to client
+// ProtocolVersion the protocol version negotiated with the client (see Handler.Versions). Allows the
+// handlers to keep compatibility with the clients of older versions.
+func (s *Session) ProtocolVersion() string {
+	return s.version
+}
+
+// Topics gets the topics joined by the session, sorted
+func (s *Session) Topics() []string {
+	s.socketsMutex.RLock()
+	topics := make([]string, 0, len(s.sockets))
+	for topic := range s.sockets {
+		topics = append(topics, topic)
+	}
+	s.socketsMutex.RUnlock()
+
+	sort.Strings(topics)
+	return topics
+}
+
+// Push message to client.
+//
+// Non-blocking, if the buffer of messages is full (slow or disconnected client) the message is discarded and the
+// Session.Dropped counter is incremented. Use Session.PushContext for messages that cannot be lost.
 func (s *Session) Push(bytes []byte) {
 	select {
 	case s.messages <- bytes:
 	default:
+		s.dropped.Add(1)
+	}
+}
 
+// PushContext push message to client, blocks until the message is enqueued or the ctx is done.
+//
+// Returns ctx.Err() if the message could not be enqueued before ctx is done, or ErrSessionClosed if the session has
+// been terminated (before or while waiting).
+//
+// A nil error confirms that the message was enqueued in the buffer of the session, not that it was delivered: the
+// transport writes it when the client is connected, and it is lost if the session is closed before that.
+//
+// ## Example
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	defer cancel()
+//	if err := session.PushContext(ctx, bytes); err != nil {
+//		slog.Warn("message not delivered", slog.Any("Error", err))
+//	}
+func (s *Session) PushContext(ctx context.Context, bytes []byte) error {
+	if s.closed.Load() {
+		return ErrSessionClosed
+	}
+	select {
+	case s.messages <- bytes:
+		return nil
+	case <-ctx.Done():
+		s.dropped.Add(1)
+		return ctx.Err()
+	case <-s.Context().Done():
+		s.dropped.Add(1)
+		return ErrSessionClosed
 	}
 }
 
+// Dropped number of messages discarded because they could not be delivered to the client
+func (s *Session) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Touch registers an activity of the client, the sessions without activity for Handler.IdleTimeout are closed.
+//
+// Invoked on connect, resume and for each message received from the client. Transports that keep connections alive
+// without messages (ex. keep-alive frames) should also invoke it.
+func (s *Session) Touch() {
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
+// LastActive time of the last activity of the client, see Session.Touch
+func (s *Session) LastActive() time.Time {
+	return time.Unix(0, s.lastActive.Load())
+}
+
 // Dispatch message to Channel
 func (s *Session) Dispatch(message []byte) {
+	s.Touch()
 	s.StopScheduledShutdown()
-	if !s.closed {
+	if !s.closed.Load() {
 		s.handler.Dispatch(message, s)
 	}
 }
@@ -104,6 +243,51 @@ func (s *Session) setSocket(topic string, socket *Socket) {
 	s.sockets[topic] = socket
 }
 
+// reserveTopic reserves a slot for the join of a topic of the channel, respecting the max topics of the session and of
+// the channel (see Handler.MaxTopics and Channel.MaxTopics). Returns the exceeded limit when the slot is not reserved.
+// The slot must be released by releaseTopic when the join completes.
+func (s *Session) reserveTopic(channel *Channel, maxTopics int) (limit int, reserved bool) {
+	s.socketsMutex.Lock()
+	defer s.socketsMutex.Unlock()
+
+	if maxTopics > 0 {
+		joining := 0
+		for _, count := range s.joining {
+			joining += count
+		}
+		if len(s.sockets)+joining >= maxTopics {
+			return maxTopics, false
+		}
+	}
+	if channel.maxTopics > 0 {
+		count := s.joining[channel]
+		for _, socket := range s.sockets {
+			if socket.channel == channel {
+				count++
+			}
+		}
+		if count >= channel.maxTopics {
+			return channel.maxTopics, false
+		}
+	}
+
+	if s.joining == nil {
+		s.joining = map[*Channel]int{}
+	}
+	s.joining[channel]++
+	return 0, true
+}
+
+// releaseTopic releases the slot reserved by reserveTopic
+func (s *Session) releaseTopic(channel *Channel) {
+	s.socketsMutex.Lock()
+	defer s.socketsMutex.Unlock()
+
+	if s.joining[channel]--; s.joining[channel] <= 0 {
+		delete(s.joining, channel)
+	}
+}
+
 func (s *Session) deleteSocket(topic string) {
 	s.socketsMutex.Lock()
 	defer s.socketsMutex.Unlock()
@@ -114,8 +298,16 @@ func (s *Session) deleteSocket(topic string) {
 
 // close invoked by ScheduleShutdown when session is permanently terminated
 func (s *Session) close() {
-	s.closed = true
+	s.closed.Store(true)
 	s.shutdown = nil
 	s.handler.handleClose(s)
 	s.sockets = nil
+
+	// after the LeaveHandlers of the sockets
+	s.dataMutex.Lock()
+	s.done = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.dataMutex.Unlock()
 }
@@ -0,0 +1,266 @@
+package socket
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultSessionTTL expiration of the session state kept in the SessionStore
+const DefaultSessionTTL = time.Hour
+
+// SessionStore storage of the session state (params and joined channels), allowing sessions to survive node restarts
+// and clients to reconnect to another node of the cluster (sticky-session handoff).
+//
+// Implementations must be safe for concurrent use and shared by all nodes (ex. Redis). See MemorySessionStore.
+//
+// ## Example
+//
+//	type RedisSessionStore struct {
+//		Client *redis.Client
+//	}
+//
+//	func (s *RedisSessionStore) Get(socketId string) ([]byte, error) {
+//		data, err := s.Client.Get(context.Background(), "chain:socket:"+socketId).Bytes()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return data, err
+//	}
+//
+//	func (s *RedisSessionStore) Set(socketId string, data []byte, ttl time.Duration) error {
+//		return s.Client.Set(context.Background(), "chain:socket:"+socketId, data, ttl).Err()
+//	}
+//
+//	func (s *RedisSessionStore) Delete(socketId string) error {
+//		return s.Client.Del(context.Background(), "chain:socket:"+socketId).Err()
+//	}
+//
+//	handler := &socket.Handler{
+//		SessionStore: &RedisSessionStore{Client: client},
+//		Channels:     []*socket.Channel{ ... },
+//	}
+type SessionStore interface {
+	// Get the session state, returns nil if it does not exist (or has expired)
+	Get(socketId string) ([]byte, error)
+	// Set the session state, expiring after ttl
+	Set(socketId string, data []byte, ttl time.Duration) error
+	// Delete the session state
+	Delete(socketId string) error
+}
+
+// sessionState the minimal state required to restore a Session
+type sessionState struct {
+	Instance string            `json:"i"` // Session instance that stored the state
+	Endpoint string            `json:"e"`
+	Params   map[string]string `json:"p,omitempty"`
+	Sockets  []socketState     `json:"s,omitempty"`
+}
+
+type socketState struct {
+	Topic   string `json:"t"`
+	Ref     int    `json:"r"`
+	JoinRef int    `json:"j"`
+	Payload any    `json:"p,omitempty"` // Payload of the join
+}
+
+// storeSession saves the state of the session in the Handler.SessionStore
+func (h *Handler) storeSession(session *Session) {
+	if h.SessionStore == nil || session.remote != "" {
+		return
+	}
+
+	session.storeMutex.Lock()
+	defer session.storeMutex.Unlock()
+
+	state := &sessionState{
+		Instance: session.instance,
+		Endpoint: session.endpoint,
+		Params:   session.Params,
+	}
+
+	session.socketsMutex.RLock()
+	for topic, socket := range session.sockets {
+		if socket.Status() == StatusJoined {
+			state.Sockets = append(state.Sockets, socketState{
+				Topic:   topic,
+				Ref:     socket.ref,
+				JoinRef: socket.joinRef,
+				Payload: socket.joinPayload,
+			})
+		}
+	}
+	session.socketsMutex.RUnlock()
+
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = h.SessionStore.Set(session.socketId, data, h.SessionTTL)
+	}
+	if err != nil {
+		slog.Warn(
+			"[chain.socket] could not store session",
+			slog.Any("socket_id", session.socketId),
+			slog.Any("Error", err),
+		)
+	}
+}
+
+// forgetSession removes the state of the session from the Handler.SessionStore, unless it has already been restored by
+// another instance (ex. the client reconnected to another node before this session has been closed)
+func (h *Handler) forgetSession(session *Session) {
+	if h.SessionStore == nil || session.remote != "" {
+		return
+	}
+
+	session.storeMutex.Lock()
+	defer session.storeMutex.Unlock()
+
+	if state := h.loadSession(session.socketId); state == nil || state.Instance != session.instance {
+		return
+	}
+
+	if err := h.SessionStore.Delete(session.socketId); err != nil {
+		slog.Warn(
+			"[chain.socket] could not delete stored session",
+			slog.Any("socket_id", session.socketId),
+			slog.Any("Error", err),
+		)
+	}
+}
+
+func (h *Handler) loadSession(socketId string) *sessionState {
+	data, err := h.SessionStore.Get(socketId)
+	if err != nil || len(data) == 0 {
+		if err != nil {
+			slog.Warn(
+				"[chain.socket] could not load stored session",
+				slog.Any("socket_id", socketId),
+				slog.Any("Error", err),
+			)
+		}
+		return nil
+	}
+
+	state := &sessionState{}
+	if err = json.Unmarshal(data, state); err != nil {
+		slog.Warn(
+			"[chain.socket] could not decode stored session",
+			slog.Any("socket_id", socketId),
+			slog.Any("Error", err),
+		)
+		return nil
+	}
+	return state
+}
+
+// restoreSession recreates the session from the Handler.SessionStore.
+//
+// The Handler.OnConnect is invoked again and the channels are joined again (the JoinHandler authorizes the socket
+// using the original join payload), followed by the RejoinHandler with lastRef = 0, so the channel can push a full
+// resync. Channels that refuse the join receive the "_close" event, so the client can join them again.
+func (h *Handler) restoreSession(socketId string) *Session {
+	state := h.loadSession(socketId)
+	if state == nil {
+		return nil
+	}
+
+	session, err := h.connect(socketId, state.Endpoint, state.Params)
+	if err != nil {
+		slog.Info(
+			"[chain.socket] could not restore session",
+			slog.Any("socket_id", socketId),
+			slog.Any("Error", err),
+		)
+		_ = h.SessionStore.Delete(socketId)
+		return nil
+	}
+
+	for _, item := range state.Sockets {
+		h.restoreSocket(item, session)
+	}
+
+	h.storeSession(session)
+
+	return session
+}
+
+func (h *Handler) restoreSocket(state socketState, session *Session) {
+	channel := h.getChannel(state.Topic)
+	if channel == nil {
+		return
+	}
+
+	socket := newSocket(state.Ref, state.JoinRef, state.Topic, channel, session, h)
+	socket.Params = session.Params
+	socket.joinPayload = state.Payload
+
+	if _, err := channel.handleJoin(state.Topic, state.Payload, socket); err != nil {
+		slog.Info(
+			"[chain.socket] could not restore channel",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", state.Topic),
+			slog.Any("Error", err),
+		)
+		deleteSocket(socket)
+
+		reply := newMessage(MessageTypePush, state.Topic, "_close", nil)
+		reply.Ref = state.Ref
+		reply.JoinRef = state.JoinRef
+		h.push(reply, session)
+		deleteMessage(reply)
+		return
+	}
+
+	socket.setStatus(StatusJoined)
+	session.setSocket(state.Topic, socket)
+
+	if channel.metrics != nil {
+		channel.metrics.joins.Add(1)
+	}
+
+	channel.handleRejoin(0, socket)
+}
+
+// MemorySessionStore in-memory SessionStore, only survives the restart of the Handler (not of the process). Useful for
+// tests and development.
+type MemorySessionStore struct {
+	mutex   sync.Mutex
+	entries map[string]memorySessionEntry
+}
+
+type memorySessionEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+func (s *MemorySessionStore) Get(socketId string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, exists := s.entries[socketId]
+	if !exists {
+		return nil, nil
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(s.entries, socketId)
+		return nil, nil
+	}
+	return entry.data, nil
+}
+
+func (s *MemorySessionStore) Set(socketId string, data []byte, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.entries == nil {
+		s.entries = map[string]memorySessionEntry{}
+	}
+	s.entries[socketId] = memorySessionEntry{data: data, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(socketId string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, socketId)
+	return nil
+}
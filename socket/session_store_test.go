@@ -0,0 +1,144 @@
+package socket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func newSessionStoreTestHandler(store SessionStore, transport *transportT, joins *[]any, lastRefs *[]int) *Handler {
+	handler := &Handler{
+		Transports:   []Transport{transport},
+		SessionStore: store,
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {
+				channel.Join("room:*", func(payload any, socket *Socket) (reply any, err error) {
+					*joins = append(*joins, payload)
+					return
+				})
+				channel.OnRejoin("room:*", func(lastRef int, socket *Socket) {
+					*lastRefs = append(*lastRefs, lastRef)
+				})
+				channel.HandleIn("ping", func(event string, payload any, socket *Socket) (reply any, err error) {
+					return "pong", nil
+				})
+			}),
+		},
+	}
+	chain.New().Configure("/socket", handler)
+	return handler
+}
+
+func Test_Handler_SessionStore_Restore(t *testing.T) {
+	store := &MemorySessionStore{}
+
+	var joinsA, joinsB []any
+	var lastRefsA, lastRefsB []int
+
+	transportA := &transportT{}
+	handlerA := newSessionStoreTestHandler(store, transportA, &joinsA, &lastRefsA)
+	session, err := transportA.Connect(map[string]string{"user": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	join := newMessage(MessageTypePush, "room:1", "_join", map[string]any{"token": "abc"})
+	join.Ref = 3
+	join.JoinRef = 3
+	transportA.SendMessage(join)
+	if messages := waitMessages(transportA, 1); len(messages) != 1 {
+		t.Fatalf("Join() failed: no reply")
+	}
+
+	// "restarted" node
+	transportB := &transportT{}
+	handlerB := newSessionStoreTestHandler(store, transportB, &joinsB, &lastRefsB)
+
+	restored := handlerB.Resume(session.SocketId())
+	if restored == nil {
+		t.Fatalf("Resume() failed: session not restored")
+	}
+	if restored.SocketId() != session.SocketId() || restored.Endpoint() != "/test" || restored.Params["user"] != "1" {
+		t.Errorf("Resume() failed: Invalid session\n   actual: %s %s %v", restored.SocketId(), restored.Endpoint(), restored.Params)
+	}
+
+	socket := restored.GetSocket("room:1")
+	if socket == nil || socket.Status() != StatusJoined {
+		t.Fatalf("Resume() failed: socket not restored")
+	}
+	if socket.joinRef != 3 {
+		t.Errorf("Resume() failed: Invalid joinRef\n   actual: %d\n expected: %d", socket.joinRef, 3)
+	}
+	if len(joinsB) != 1 || joinsB[0].(map[string]any)["token"] != "abc" {
+		t.Errorf("Resume() failed: Invalid join payload\n   actual: %v", joinsB)
+	}
+	if len(lastRefsB) != 1 || lastRefsB[0] != 0 {
+		t.Errorf("Resume() failed: Invalid OnRejoin calls\n   actual: %v\n expected: %v", lastRefsB, []int{0})
+	}
+
+	ping := newMessage(MessageTypePush, "room:1", "ping", nil)
+	ping.Ref = 4
+	ping.JoinRef = 3
+	bytes, _ := handlerB.Serializer.Encode(ping)
+	handlerB.Dispatch(bytes, restored)
+
+	select {
+	case msg := <-restored.messages:
+		reply := newMessageAny()
+		if _, err = handlerB.Serializer.Decode(msg, reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Payload != "pong" || reply.Ref != 4 {
+			t.Errorf("Resume() failed: Invalid reply\n   actual: %+v", reply)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Resume() failed: no reply")
+	}
+
+	// the old instance is closed after the handoff, the state must be kept
+	handlerA.handleClose(session)
+	if data, _ := store.Get(session.SocketId()); data == nil {
+		t.Errorf("handleClose() failed: state of restored session removed by the old instance")
+	}
+
+	handlerB.handleClose(restored)
+	if data, _ := store.Get(session.SocketId()); data != nil {
+		t.Errorf("handleClose() failed: state not removed")
+	}
+
+	if handlerB.Resume(session.SocketId()) != nil {
+		t.Errorf("Resume() failed: closed session restored")
+	}
+}
+
+func Test_Handler_SessionStore_Leave(t *testing.T) {
+	store := &MemorySessionStore{}
+	var joins []any
+	var lastRefs []int
+
+	transport := &transportT{}
+	handler := newSessionStoreTestHandler(store, transport, &joins, &lastRefs)
+	session, err := transport.Connect(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	join := newMessage(MessageTypePush, "room:1", "_join", nil)
+	join.Ref = 1
+	join.JoinRef = 1
+	transport.SendMessage(join)
+	waitMessages(transport, 1)
+
+	leave := newMessage(MessageTypePush, "room:1", "_leave", nil)
+	leave.Ref = 2
+	leave.JoinRef = 1
+	transport.SendMessage(leave)
+	waitMessages(transport, 2)
+
+	state := handler.loadSession(session.SocketId())
+	if state == nil {
+		t.Fatalf("storeSession() failed: state not stored")
+	}
+	if len(state.Sockets) != 0 {
+		t.Errorf("storeSession() failed: Invalid sockets\n   actual: %v\n expected: []", state.Sockets)
+	}
+}
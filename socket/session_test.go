@@ -0,0 +1,191 @@
+package socket
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_Session_Push_Dropped(t *testing.T) {
+	session := &Session{messages: make(chan []byte, 1)}
+
+	session.Push([]byte("1"))
+	session.Push([]byte("2"))
+
+	if dropped := session.Dropped(); dropped != 1 {
+		t.Errorf("Push() failed: Invalid Dropped\n   actual: %d\n expected: %d", dropped, 1)
+	}
+}
+
+func Test_Session_PushContext(t *testing.T) {
+	session := &Session{messages: make(chan []byte, 1)}
+
+	if err := session.PushContext(context.Background(), []byte("1")); err != nil {
+		t.Fatalf("PushContext() failed: unexpected error: %v", err)
+	}
+
+	// buffer full, blocks until the client consumes the message
+	go func() {
+		<-time.After(time.Millisecond * 10)
+		<-session.messages
+	}()
+	if err := session.PushContext(context.Background(), []byte("2")); err != nil {
+		t.Fatalf("PushContext() failed: unexpected error: %v", err)
+	}
+
+	// buffer full, nobody consuming
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := session.PushContext(ctx, []byte("3")); err != context.DeadlineExceeded {
+		t.Errorf("PushContext() failed: Invalid error\n   actual: %v\n expected: %v", err, context.DeadlineExceeded)
+	}
+	if dropped := session.Dropped(); dropped != 1 {
+		t.Errorf("PushContext() failed: Invalid Dropped\n   actual: %d\n expected: %d", dropped, 1)
+	}
+
+	// session closed while waiting
+	go func() {
+		<-time.After(time.Millisecond * 10)
+		session.closed.Store(true)
+		session.Context()
+		session.cancel()
+	}()
+	if err := session.PushContext(context.Background(), []byte("4")); err != ErrSessionClosed {
+		t.Errorf("PushContext() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrSessionClosed)
+	}
+
+	if err := session.PushContext(context.Background(), []byte("5")); err != ErrSessionClosed {
+		t.Errorf("PushContext() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrSessionClosed)
+	}
+}
+
+func Test_Session_Values_And_Context(t *testing.T) {
+	sockets := make(chan *Socket, 2)
+	transport := &transportT{}
+	handler := newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.HandleIn("remember", func(event string, payload any, socket *Socket) (reply any, err error) {
+			socket.Session().Set("color", payload)
+			sockets <- socket
+			return "ok", nil
+		})
+		channel.HandleIn("recall", func(event string, payload any, socket *Socket) (reply any, err error) {
+			sockets <- socket
+			return []any{socket.Session().Get("user"), socket.Session().Get("color")}, nil
+		})
+	})
+	handler.OnConnect = func(session *Session) error {
+		session.Set("user", "alice")
+		return nil
+	}
+	joinPoolTestRoom(t, transport)
+
+	join := newMessage(MessageTypePush, "room:2", "_join", nil)
+	join.Ref = 2
+	join.JoinRef = 2
+	transport.SendMessage(join)
+	waitMessages(transport, 1)
+	transport.Clear()
+
+	remember := newMessage(MessageTypePush, "room:1", "remember", "blue")
+	remember.Ref = 3
+	remember.JoinRef = 1
+	transport.SendMessage(remember)
+	waitMessages(transport, 1)
+	transport.Clear()
+
+	recall := newMessage(MessageTypePush, "room:2", "recall", nil)
+	recall.Ref = 4
+	recall.JoinRef = 2
+	transport.SendMessage(recall)
+	messages := waitMessages(transport, 1)
+	if len(messages) != 1 {
+		t.Fatalf("Session.Get() failed: Invalid replies\n   actual: %v\n expected: %v", len(messages), 1)
+	}
+	if expected := []any{"alice", "blue"}; !reflect.DeepEqual(messages[0].Payload, expected) {
+		t.Errorf("Session.Get() failed: Invalid values\n   actual: %v\n expected: %v", messages[0].Payload, expected)
+	}
+
+	session := transport.info
+	ctx := session.Context()
+	socketCtx := (<-sockets).Context()
+	if ctx.Err() != nil || socketCtx.Err() != nil {
+		t.Fatalf("Session.Context() failed: Context cancelled before close")
+	}
+
+	transport.Close() // session closed after 10ms
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(500 * time.Millisecond):
+		t.Fatalf("Session.Context() failed: Context not cancelled on close")
+	}
+	if socketCtx.Err() == nil {
+		t.Errorf("Session.Context() failed: Socket context not cancelled on close")
+	}
+	if session.Context().Err() == nil {
+		t.Errorf("Session.Context() failed: Context of closed session must be cancelled")
+	}
+}
+
+func Test_Session_MaxTopics(t *testing.T) {
+	transport := &transportT{}
+	join := func(channel *Channel) {
+		channel.Join("*", func(payload any, socket *Socket) (reply any, err error) {
+			return
+		})
+	}
+	handler := &Handler{
+		Transports: []Transport{transport},
+		MaxTopics:  3,
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {
+				channel.MaxTopics(2)
+				join(channel)
+			}),
+			NewChannel("user:*", join),
+		},
+	}
+	chain.New().Configure("/socket", handler)
+	if _, err := transport.Connect(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		topic  string
+		status int
+		max    int
+	}{
+		{"room:1", ReplyStatusCodeOk, 0},
+		{"room:2", ReplyStatusCodeOk, 0},
+		{"room:3", ReplyStatusCodeError, 2}, // Channel.MaxTopics
+		{"room:1", ReplyStatusCodeOk, 0},    // rejoin does not count
+		{"user:1", ReplyStatusCodeOk, 0},
+		{"user:2", ReplyStatusCodeError, 3}, // Handler.MaxTopics
+	}
+	for i, test := range tests {
+		message := newMessage(MessageTypePush, test.topic, "_join", nil)
+		message.Ref = i + 1
+		message.JoinRef = 1 // same join ref, the duplicate join does not push "_close"
+		transport.SendMessage(message)
+
+		messages := waitMessages(transport, 1)
+		if len(messages) != 1 {
+			t.Fatalf("MaxTopics failed: no reply (%s)", test.topic)
+		}
+		transport.Clear()
+
+		reply := messages[0]
+		if reply.Status != test.status {
+			t.Errorf("MaxTopics failed: Invalid status (%s)\n   actual: %v\n expected: %v", test.topic, reply.Status, test.status)
+		}
+		if test.max > 0 {
+			payload, _ := reply.Payload.(map[string]any)
+			if payload["reason"] != ErrMaxTopics.Error() || payload["max"] != float64(test.max) {
+				t.Errorf("MaxTopics failed: Invalid payload (%s)\n   actual: %v", test.topic, reply.Payload)
+			}
+		}
+	}
+}
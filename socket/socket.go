@@ -1,6 +1,11 @@
 package socket
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
 
 type Status int
 
@@ -19,15 +24,26 @@ var (
 //
 // Allows the channel to manage socket state data through the Socket.Set and Socket.Get
 type Socket struct {
-	Params  map[string]string // Initialization parameters, received at connection time.
-	ref     int
-	joinRef int
-	topic   string
-	channel *Channel
-	session *Session
-	data    map[string]any
-	status  Status
-	handler *Handler
+	Params        map[string]string // Initialization parameters, received at connection time.
+	ref           int
+	joinRef       int
+	topic         string
+	channel       *Channel
+	session       *Session
+	data          map[string]any
+	status        atomic.Int32 // Status, read by the timer callbacks without lock
+	handler       *Handler
+	joinPayload   any             // Payload of the join, used to restore the socket (see Handler.SessionStore)
+	pushRef       atomic.Int64    // Ref of the last push, see Socket.Push
+	timers        map[*Timer]bool // Active timers, see Socket.PushAfter and Socket.PushEvery
+	timersMutex   sync.Mutex
+	timersRunning int                   // Timer callbacks being executed
+	recycle       bool                  // Socket removed while timer callbacks were running, the last one returns it to the pool
+	asks          map[int]chan askReply // Pending asks, by ref, see Socket.Ask
+	asksMutex     sync.Mutex
+	topicParams   map[string]string // Parameters of the topic, see Socket.TopicParam
+	ctx           context.Context   // Lifetime of the socket, see Socket.Context
+	cancel        context.CancelFunc
 }
 
 func (s *Socket) Id() string {
@@ -43,13 +59,30 @@ func (s *Socket) Topic() string {
 }
 
 func (s *Socket) Status() Status {
-	return s.status
+	return Status(s.status.Load())
+}
+
+func (s *Socket) setStatus(status Status) {
+	s.status.Store(int32(status))
 }
 
 func (s *Socket) Session() *Session {
 	return s.session
 }
 
+// Context of the socket lifetime, cancelled when the socket leaves the channel or the session is closed, allowing
+// long-running handlers to abort their work.
+//
+// ## Example
+//
+//	channel.HandleIn("report", func(event string, payload any, socket *Socket) (reply any, err error) {
+//		rows, err := db.QueryContext(socket.Context(), reportQuery)
+//		...
+//	})
+func (s *Socket) Context() context.Context {
+	return s.ctx
+}
+
 // Get a value from Socket (server side only)
 func (s *Socket) Get(key string) (value any) {
 	return s.data[key]
@@ -60,15 +93,19 @@ func (s *Socket) Set(key string, value any) {
 	s.data[key] = value
 }
 
-// Push message to client
+// Push message to client.
+//
+// Each push has a sequential Ref (starting at 1 on each join), the client sends the Ref of the last push received when
+// rejoining the channel (see Channel.OnRejoin).
 func (s *Socket) Push(event string, payload any) (err error) {
-	if s.status != StatusJoined {
+	if s.Status() != StatusJoined {
 		// can only be called after the socket has finished joining.
 		return ErrSocketNotJoined
 	}
 
 	message := newMessage(MessageTypePush, s.topic, event, payload)
 	message.JoinRef = s.joinRef
+	message.Ref = int(s.pushRef.Add(1))
 	defer deleteMessage(message)
 
 	var encoded []byte
@@ -79,9 +116,35 @@ func (s *Socket) Push(event string, payload any) (err error) {
 	return
 }
 
+// PushEncoded push message to client, with the payload already encoded as JSON (see EncodePayload). Allows
+// encoding the payload once and pushing it to many sockets, the serializer only encodes the envelope of the message.
+//
+// ## Example
+//
+//	encoded, err := socket.EncodePayload(scoreboard)
+//	for _, s := range players {
+//		s.PushEncoded("scoreboard", encoded)
+//	}
+func (s *Socket) PushEncoded(event string, payload []byte) (err error) {
+	if len(payload) == 0 {
+		return s.Push(event, nil)
+	}
+	return s.Push(event, EncodedPayload(payload))
+}
+
+// PushJSON push message to client, encoding the payload as JSON regardless of the serializer of the Handler (ex.
+// []byte payloads are sent as JSON by ProtobufSerializer, instead of the raw payload)
+func (s *Socket) PushJSON(event string, payload any) (err error) {
+	var encoded EncodedPayload
+	if encoded, err = EncodePayload(payload); err != nil {
+		return
+	}
+	return s.Push(event, encoded)
+}
+
 // Send encoded message to client
 func (s *Socket) Send(bytes []byte) error {
-	if s.status != StatusJoined {
+	if s.Status() != StatusJoined {
 		// can only be called after the socket has finished joining.
 		return ErrSocketNotJoined
 	}
@@ -91,9 +154,26 @@ func (s *Socket) Send(bytes []byte) error {
 
 // Broadcast an event to all subscribers of the socket topic.
 func (s *Socket) Broadcast(event string, payload any) (err error) {
-	if s.status != StatusJoined {
+	if s.Status() != StatusJoined {
 		// can only be called after the socket has finished joining.
 		return ErrSocketNotJoined
 	}
 	return s.channel.Broadcast(s.Topic(), event, payload)
 }
+
+// BroadcastFrom an event to all subscribers of the socket topic, except this socket (on all nodes of the cluster).
+//
+// ## Example
+//
+//	channel.HandleIn("new_msg", func(event string, payload any, socket *Socket) (reply any, err error) {
+//		// the sender already rendered the message, no echo
+//		err = socket.BroadcastFrom("new_msg", payload)
+//		return
+//	})
+func (s *Socket) BroadcastFrom(event string, payload any) (err error) {
+	if s.Status() != StatusJoined {
+		// can only be called after the socket has finished joining.
+		return ErrSocketNotJoined
+	}
+	return s.channel.BroadcastFrom(s, event, payload)
+}
@@ -0,0 +1,154 @@
+package socket
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+var (
+	ErrAskTimeout  = errors.New("ask timeout")
+	ErrAskRejected = errors.New("ask rejected by client")
+	ErrAskCanceled = errors.New("ask canceled, socket left the channel")
+)
+
+// askReply response of the client to a Socket.Ask
+type askReply struct {
+	status   int
+	response any
+}
+
+// Ask pushes the `event` to the client and waits for its response (server-initiated RPC), enabling server-driven
+// interactions, such as requesting client capabilities or confirmation dialogs.
+//
+// The client answers through the handlers registered with `channel.onAsk(event, callback)` (see chain.js). Returns
+// ErrAskTimeout if the client does not respond within the timeout (DefaultReplyTimeout if zero), ErrAskRejected (with
+// the response) if the client callback fails and ErrAskCanceled if the socket leaves the channel while waiting.
+//
+// ## Example
+//
+//	channel.HandleIn("delete_room", func(event string, payload any, socket *Socket) (reply any, err error) {
+//		confirmed, err := socket.Ask("confirm", map[string]any{"message": "Delete the room?"}, time.Minute)
+//		if err != nil || confirmed != true {
+//			return "canceled", nil
+//		}
+//		...
+//	})
+//
+// Client (javascript)
+//
+//	channel.onAsk('confirm', ({message}) => window.confirm(message))
+func (s *Socket) Ask(event string, payload any, timeout time.Duration) (response any, err error) {
+	if s.Status() != StatusJoined {
+		// can only be called after the socket has finished joining.
+		return nil, ErrSocketNotJoined
+	}
+	if timeout <= 0 {
+		timeout = DefaultReplyTimeout
+	}
+
+	message := newMessage(MessageTypePush, s.topic, "_ask", map[string]any{"event": event, "payload": payload})
+	message.JoinRef = s.joinRef
+	message.Ref = int(s.pushRef.Add(1))
+	defer deleteMessage(message)
+
+	var encoded []byte
+	if encoded, err = s.handler.Serializer.Encode(message); err != nil {
+		return
+	}
+
+	ref := message.Ref
+	reply := make(chan askReply, 1)
+	s.asksMutex.Lock()
+	if s.asks == nil {
+		s.asks = map[int]chan askReply{}
+	}
+	s.asks[ref] = reply
+	s.asksMutex.Unlock()
+
+	defer func() {
+		s.asksMutex.Lock()
+		delete(s.asks, ref)
+		s.asksMutex.Unlock()
+	}()
+
+	s.session.Push(encoded)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case r, open := <-reply:
+		if !open {
+			return nil, ErrAskCanceled
+		}
+		if r.status != ReplyStatusCodeOk {
+			return r.response, ErrAskRejected
+		}
+		return r.response, nil
+	case <-timer.C:
+		return nil, ErrAskTimeout
+	}
+}
+
+// resolveAsk delivers the client response to the pending Socket.Ask
+func (s *Socket) resolveAsk(ref int, reply askReply) bool {
+	s.asksMutex.Lock()
+	defer s.asksMutex.Unlock()
+
+	if pending, exists := s.asks[ref]; exists {
+		delete(s.asks, ref)
+		pending <- reply
+		return true
+	}
+	return false
+}
+
+// cancelAsks unblocks the pending Socket.Ask, invoked when the socket is removed
+func (s *Socket) cancelAsks() {
+	s.asksMutex.Lock()
+	defer s.asksMutex.Unlock()
+
+	for ref, pending := range s.asks {
+		delete(s.asks, ref)
+		close(pending)
+	}
+}
+
+// handleAskReply processes the client response to a Socket.Ask (event:_ask_reply).
+//
+// Payload = {"ref": <ref of the _ask push>, "status": "ok" | "error", "response": <any>}
+func (h *Handler) handleAskReply(message *Message, session *Session) {
+	defer deleteMessage(message)
+
+	socket := session.GetSocket(message.Topic)
+	if socket == nil {
+		return
+	}
+
+	payload, valid := message.Payload.(map[string]any)
+	if !valid {
+		slog.Debug(
+			"[chain.socket] invalid ask reply",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", message.Topic),
+			slog.Any("Payload", message.Payload),
+		)
+		return
+	}
+
+	ref, _ := payload["ref"].(float64)
+	reply := askReply{status: ReplyStatusCodeError, response: payload["response"]}
+	if payload["status"] == "ok" {
+		reply.status = ReplyStatusCodeOk
+	}
+
+	if !socket.resolveAsk(int(ref), reply) {
+		slog.Debug(
+			"[chain.socket] ignoring ask reply, ask expired or unknown",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", message.Topic),
+			slog.Int("Ref", int(ref)),
+		)
+	}
+}
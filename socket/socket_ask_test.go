@@ -0,0 +1,73 @@
+package socket
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Socket_Ask(t *testing.T) {
+	type result struct {
+		response any
+		err      error
+	}
+	results := make(chan result, 1)
+
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.HandleIn("ask", func(event string, payload any, socket *Socket) (reply any, err error) {
+			go func() {
+				response, err := socket.Ask("confirm", payload, time.Duration(payload.(float64))*time.Millisecond)
+				results <- result{response, err}
+			}()
+			return
+		})
+	})
+	joinPoolTestRoom(t, transport)
+	defer transport.Close()
+
+	ask := func(timeout float64) *Message {
+		transport.Clear()
+		push := newMessage(MessageTypePush, "room:1", "ask", timeout)
+		push.Ref = 2
+		push.JoinRef = 1
+		transport.SendMessage(push)
+
+		messages := waitMessages(transport, 1)
+		if len(messages) != 1 || messages[0].Event != "_ask" {
+			t.Fatalf("Ask() failed: Invalid push\n   actual: %v\n expected: %v", messages, "_ask")
+		}
+		return messages[0]
+	}
+	answer := func(ref int, status string, response any) {
+		reply := newMessage(MessageTypePush, "room:1", "_ask_reply", map[string]any{
+			"ref": ref, "status": status, "response": response,
+		})
+		reply.Ref = 3
+		reply.JoinRef = 1
+		transport.SendMessage(reply)
+	}
+
+	// ok
+	message := ask(1000)
+	if payload := message.Payload.(map[string]any); payload["event"] != "confirm" || payload["payload"] != float64(1000) {
+		t.Errorf("Ask() failed: Invalid payload\n   actual: %v\n expected: %v", payload, "confirm")
+	}
+	answer(message.Ref, "ok", true)
+	if r := <-results; r.err != nil || r.response != true {
+		t.Errorf("Ask() failed: Invalid response\n   actual: %v %v\n expected: %v", r.response, r.err, true)
+	}
+
+	// rejected
+	message = ask(1000)
+	answer(message.Ref, "error", "no")
+	if r := <-results; r.err != ErrAskRejected || r.response != "no" {
+		t.Errorf("Ask() failed: Invalid response\n   actual: %v %v\n expected: %v", r.response, r.err, ErrAskRejected)
+	}
+
+	// timeout, late answer is ignored
+	message = ask(20)
+	if r := <-results; r.err != ErrAskTimeout {
+		t.Errorf("Ask() failed: Invalid Error\n   actual: %v\n expected: %v", r.err, ErrAskTimeout)
+	}
+	answer(message.Ref, "ok", true)
+}
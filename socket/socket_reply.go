@@ -0,0 +1,168 @@
+package socket
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultReplyTimeout max time to fulfill a deferred reply when the message has no processing timeout (see
+// Channel.Timeout and Handler.MessageTimeout)
+const DefaultReplyTimeout = 30 * time.Second
+
+var (
+	ErrReplySent    = errors.New("reply already sent")
+	ErrReplyExpired = errors.New("reply timeout expired")
+)
+
+// Reply token of a deferred reply, see Socket.ReplyLater
+type Reply struct {
+	socket    *Socket
+	mutex     sync.Mutex
+	handler   *Handler
+	session   *Session
+	target    replyTarget
+	timer     *time.Timer
+	bound     bool // the handler returned the token, the message is known
+	done      bool // already replied (or expired)
+	expired   bool
+	fulfilled bool // fulfilled before being bound
+	status    int
+	payload   any
+}
+
+// ReplyLater creates a deferred reply for the message being processed. The InHandler must return the token as its
+// reply, which can then be fulfilled asynchronously (ex. after a DB call completes in another goroutine) with
+// Reply.Ok or Reply.Error. The worker is released as soon as the handler returns.
+//
+// If the reply is not fulfilled within the processing timeout of the message (see Channel.Timeout and
+// Handler.MessageTimeout, DefaultReplyTimeout when not limited), an error reply is sent to the client.
+//
+// ## Example
+//
+//	channel.HandleIn("rank", func(event string, payload any, socket *Socket) (reply any, err error) {
+//		deferred := socket.ReplyLater()
+//		go func() {
+//			if rank, err := db.Rank(socket.Get("user_id")); err != nil {
+//				deferred.Error(err.Error())
+//			} else {
+//				deferred.Ok(rank)
+//			}
+//		}()
+//		return deferred, nil
+//	})
+func (s *Socket) ReplyLater() *Reply {
+	return &Reply{socket: s}
+}
+
+// Ok sends a success reply with the payload
+func (r *Reply) Ok(payload any) error {
+	return r.fulfill(ReplyStatusCodeOk, payload)
+}
+
+// Error sends an error reply with the payload
+func (r *Reply) Error(payload any) error {
+	return r.fulfill(ReplyStatusCodeError, payload)
+}
+
+func (r *Reply) fulfill(status int, payload any) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.expired {
+		return ErrReplyExpired
+	}
+	if r.done || r.fulfilled {
+		return ErrReplySent
+	}
+	if !r.bound {
+		// the handler has not returned yet, the reply is sent when the token is bound to the message
+		r.fulfilled = true
+		r.status = status
+		r.payload = payload
+		return nil
+	}
+	r.send(status, payload)
+	return nil
+}
+
+// bind associates the token with the message that was replied with it, starting the timeout
+func (r *Reply) bind(h *Handler, message *Message, session *Session) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.bound {
+		slog.Warn(
+			"[chain.socket] deferred reply returned more than once",
+			slog.Any("socket_id", session.SocketId()),
+			slog.String("Topic", message.Topic),
+			slog.String("Event", message.Event),
+		)
+		return
+	}
+
+	r.bound = true
+	r.handler = h
+	r.session = session
+	r.target = replyTarget{
+		ref:     message.Ref,
+		joinRef: message.JoinRef,
+		topic:   message.Topic,
+		event:   message.Event,
+		claim:   message.replyClaim,
+	}
+
+	if r.fulfilled {
+		r.send(r.status, r.payload)
+		r.payload = nil
+		return
+	}
+
+	timeout := h.messageTimeout(message)
+	if timeout <= 0 {
+		timeout = DefaultReplyTimeout
+	}
+	r.timer = time.AfterFunc(timeout, r.expire)
+}
+
+func (r *Reply) expire() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.done {
+		return
+	}
+	r.done = true
+	r.expired = true
+
+	slog.Warn(
+		"[chain.socket] deferred reply timeout",
+		slog.Any("socket_id", r.session.SocketId()),
+		slog.String("Topic", r.target.topic),
+		slog.String("Event", r.target.event),
+	)
+	if r.target.claim == nil || r.target.claim.CompareAndSwap(false, true) {
+		r.handler.pushError(r.target, r.session, ErrMessageTimeout)
+	}
+}
+
+// send pushes the reply to the client, must be invoked with the lock held
+func (r *Reply) send(status int, payload any) {
+	r.done = true
+	if r.timer != nil {
+		r.timer.Stop()
+	}
+	if r.session.GetSocket(r.target.topic) != r.socket || r.socket.Status() != StatusJoined {
+		// the client left the channel
+		return
+	}
+
+	reply := newMessage(MessageTypeReply, r.target.topic, r.target.event, payload)
+	defer deleteMessage(reply)
+	reply.Ref = r.target.ref
+	reply.JoinRef = r.target.joinRef
+	reply.Status = status
+	reply.replyClaim = r.target.claim
+	r.handler.push(reply, r.session)
+}
@@ -0,0 +1,81 @@
+package socket
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Socket_ReplyLater(t *testing.T) {
+	fulfilled := make(chan error, 1)
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.Timeout("slow", 50*time.Millisecond)
+		channel.HandleIn("rank", func(event string, payload any, socket *Socket) (reply any, err error) {
+			deferred := socket.ReplyLater()
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				deferred.Ok("first")
+			}()
+			return deferred, nil
+		})
+		channel.HandleIn("early", func(event string, payload any, socket *Socket) (reply any, err error) {
+			deferred := socket.ReplyLater()
+			deferred.Error("failed")
+			return deferred, nil
+		})
+		channel.HandleIn("slow", func(event string, payload any, socket *Socket) (reply any, err error) {
+			deferred := socket.ReplyLater()
+			go func() {
+				time.Sleep(150 * time.Millisecond)
+				fulfilled <- deferred.Ok("late")
+			}()
+			return deferred, nil
+		})
+	})
+	joinPoolTestRoom(t, transport)
+	defer transport.Close()
+
+	for i, event := range []string{"rank", "early", "slow"} {
+		push := newMessage(MessageTypePush, "room:1", event, nil)
+		push.Ref = 2 + i
+		push.JoinRef = 1
+		transport.SendMessage(push)
+	}
+
+	messages := waitMessages(transport, 3)
+	if len(messages) != 3 {
+		t.Fatalf("ReplyLater() failed: Invalid replies\n   actual: %v\n expected: %v", len(messages), 3)
+	}
+	replies := map[int]*Message{}
+	for _, message := range messages {
+		replies[message.Ref] = message
+	}
+
+	expected := []struct {
+		ref     int
+		status  int
+		payload any
+	}{
+		{2, ReplyStatusCodeOk, "first"},
+		{3, ReplyStatusCodeError, "failed"},
+		{4, ReplyStatusCodeError, map[string]any{"reason": ErrMessageTimeout.Error()}},
+	}
+	for _, e := range expected {
+		reply := replies[e.ref]
+		if reply == nil {
+			t.Errorf("ReplyLater() failed: Missing reply\n expected: %v", e.ref)
+			continue
+		}
+		if reply.Kind != MessageTypeReply || reply.JoinRef != 1 || reply.Status != e.status {
+			t.Errorf("ReplyLater() failed: Invalid reply\n   actual: %v\n expected: %v", reply, e)
+		}
+		if !reflect.DeepEqual(reply.Payload, e.payload) {
+			t.Errorf("ReplyLater() failed: Invalid payload\n   actual: %v\n expected: %v", reply.Payload, e.payload)
+		}
+	}
+
+	if err := <-fulfilled; err != ErrReplyExpired {
+		t.Errorf("Reply.Ok() failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrReplyExpired)
+	}
+}
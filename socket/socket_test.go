@@ -3,6 +3,7 @@ package socket
 import (
 	"context"
 	"errors"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -266,3 +267,80 @@ func Test_Socket(t *testing.T) {
 	}
 
 }
+
+func Test_Socket_Context(t *testing.T) {
+	started := make(chan context.Context, 1)
+	aborted := make(chan error, 1)
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.HandleIn("long", func(event string, payload any, socket *Socket) (reply any, err error) {
+			ctx := socket.Context()
+			started <- ctx
+			select {
+			case <-ctx.Done():
+				aborted <- ctx.Err()
+			case <-time.After(time.Second):
+				aborted <- nil
+			}
+			return
+		})
+	})
+	joinPoolTestRoom(t, transport)
+	defer transport.Close()
+
+	push := newMessage(MessageTypePush, "room:1", "long", nil)
+	push.Ref = 2
+	push.JoinRef = 1
+	transport.SendMessage(push)
+
+	ctx := <-started
+	if ctx.Err() != nil {
+		t.Fatalf("Socket.Context() failed: Context cancelled before leave\n   actual: %v", ctx.Err())
+	}
+
+	leave := newMessage(MessageTypePush, "room:1", "_leave", nil)
+	leave.Ref = 3
+	leave.JoinRef = 1
+	transport.SendMessage(leave)
+
+	select {
+	case err := <-aborted:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Socket.Context() failed: Invalid error\n   actual: %v\n expected: %v", err, context.Canceled)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Errorf("Socket.Context() failed: Context not cancelled on leave")
+	}
+}
+
+func Test_Socket_PushEncoded(t *testing.T) {
+	transport := &transportT{}
+	newPoolTestHandler(transport, 0, func(channel *Channel) {
+		channel.HandleIn("scores", func(event string, payload any, socket *Socket) (reply any, err error) {
+			encoded, _ := EncodePayload(map[string]any{"alice": 10})
+			if err = socket.PushEncoded("scores", encoded); err == nil {
+				err = socket.PushJSON("raw", []byte("hi"))
+			}
+			return
+		})
+	})
+	joinPoolTestRoom(t, transport)
+	defer transport.Close()
+
+	push := newMessage(MessageTypePush, "room:1", "scores", nil)
+	push.Ref = 2
+	push.JoinRef = 1
+	transport.SendMessage(push)
+
+	messages := waitMessages(transport, 2)
+	if len(messages) != 2 {
+		t.Fatalf("PushEncoded() failed: Invalid messages\n   actual: %v\n expected: %v", len(messages), 2)
+	}
+	if messages[0].Event != "scores" || !reflect.DeepEqual(messages[0].Payload, map[string]any{"alice": float64(10)}) {
+		t.Errorf("PushEncoded() failed: Invalid message\n   actual: %+v", messages[0])
+	}
+	// []byte is encoded as base64 by encoding/json
+	if messages[1].Event != "raw" || messages[1].Payload != "aGk=" {
+		t.Errorf("PushJSON() failed: Invalid message\n   actual: %+v", messages[1])
+	}
+}
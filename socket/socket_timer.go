@@ -0,0 +1,179 @@
+package socket
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Timer scheduled by Socket.PushAfter or Socket.PushEvery. It is tied to the Socket lifecycle and is automatically
+// stopped when the socket leaves the channel or the session is closed.
+type Timer struct {
+	socket   *Socket
+	timer    *time.Timer
+	interval time.Duration
+	callback func(socket *Socket)
+	stopped  bool
+}
+
+// Stop prevents the Timer from firing. Returns false if the timer has already been stopped or, for PushAfter, has
+// already fired.
+func (t *Timer) Stop() bool {
+	s := t.socket
+	s.timersMutex.Lock()
+	defer s.timersMutex.Unlock()
+	return s.stopTimer(t)
+}
+
+// PushAfter push a message to client after the given delay.
+//
+// ## Example
+//
+//	channel.HandleIn("typing", func(event string, payload any, s *socket.Socket) (reply any, err error) {
+//		if t, ok := s.Get("typing").(*socket.Timer); ok {
+//			t.Stop()
+//		}
+//		s.Set("typing", s.PushAfter(3*time.Second, "typing_stop", nil))
+//		return
+//	})
+func (s *Socket) PushAfter(delay time.Duration, event string, payload any) *Timer {
+	return s.schedule(delay, 0, func(socket *Socket) {
+		if err := socket.Push(event, payload); err != nil {
+			slog.Debug(
+				"[chain.socket] could not push scheduled message",
+				slog.Any("Error", err),
+				slog.String("Topic", socket.topic),
+				slog.String("Event", event),
+			)
+		}
+	})
+}
+
+// PushEvery invokes fn at each interval, until the Timer is stopped or the socket leaves the channel. Useful for
+// countdowns and periodic state sync.
+//
+// The socket can leave the channel while fn is running, the pushes of fn then fail with ErrSocketNotJoined. The
+// state of the socket (see Socket.Get) is only cleared, and the socket reused by other joins, after fn returns.
+//
+// ## Example
+//
+//	channel.Join("game:*", func(payload any, socket *Socket) (reply any, err error) {
+//		socket.PushEvery(time.Second, func(socket *Socket) {
+//			socket.Push("state", game.State())
+//		})
+//		return
+//	})
+func (s *Socket) PushEvery(interval time.Duration, fn func(socket *Socket)) *Timer {
+	if interval <= 0 {
+		panic(fmt.Sprintf("[chain.socket] invalid interval for PushEvery. Interval: %s", interval))
+	}
+	return s.schedule(interval, interval, fn)
+}
+
+func (s *Socket) schedule(delay time.Duration, interval time.Duration, fn func(socket *Socket)) *Timer {
+	t := &Timer{socket: s, interval: interval, callback: fn}
+
+	s.timersMutex.Lock()
+	defer s.timersMutex.Unlock()
+
+	if s.timers == nil {
+		s.timers = map[*Timer]bool{}
+	}
+	s.timers[t] = true
+	t.timer = time.AfterFunc(delay, func() { s.fire(t) })
+	return t
+}
+
+// fire executes the timer callback. The timers are stopped when the socket is removed, so the callback never runs on a
+// socket reused by the pool. A socket removed while callbacks are running only returns to the pool after them (see
+// Socket.release), the removal never waits for the callbacks, that may depend on the locks of the Session.
+func (s *Socket) fire(t *Timer) {
+	s.timersMutex.Lock()
+	if t.stopped {
+		s.timersMutex.Unlock()
+		return
+	}
+
+	if t.interval > 0 {
+		t.timer.Reset(t.interval)
+	} else {
+		t.stopped = true
+		delete(s.timers, t)
+	}
+	s.timersRunning++
+	s.timersMutex.Unlock()
+
+	defer s.fired()
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			slog.Error(
+				"[chain.socket] panic occurred in a socket timer",
+				slog.Any("Error", rcv),
+				slog.String("Topic", s.topic),
+			)
+		}
+	}()
+	t.callback(s)
+}
+
+// stopTimer must be invoked with timersMutex locked
+func (s *Socket) stopTimer(t *Timer) bool {
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	t.timer.Stop()
+	delete(s.timers, t)
+	return true
+}
+
+// fired invoked after the callback of a timer, returns the removed socket to the pool after the last callback
+func (s *Socket) fired() {
+	s.timersMutex.Lock()
+	s.timersRunning--
+	recycle := s.recycle && s.timersRunning == 0
+	if recycle {
+		s.recycle = false
+	}
+	s.timersMutex.Unlock()
+
+	if recycle {
+		s.recycleToPool()
+	}
+}
+
+// stopTimers cancel all timers of this socket, invoked when the socket is removed. Does not wait for the running
+// callbacks (see Socket.fire).
+func (s *Socket) stopTimers() {
+	s.timersMutex.Lock()
+	for t := range s.timers {
+		s.stopTimer(t)
+	}
+	s.timers = nil
+	s.timersMutex.Unlock()
+}
+
+// release clears the removed socket and returns it to the pool, or defers it until the running timer callbacks return
+func (s *Socket) release() {
+	s.timersMutex.Lock()
+	if s.timersRunning > 0 {
+		s.recycle = true
+		s.timersMutex.Unlock()
+		return
+	}
+	s.timersMutex.Unlock()
+	s.recycleToPool()
+}
+
+// recycleToPool clears the state of the removed socket and returns it to the pool. Only invoked when no timer callback
+// is running, so the callbacks never see the state being cleared.
+func (s *Socket) recycleToPool() {
+	s.topic = ""
+	s.channel = nil
+	s.session = nil
+	s.handler = nil
+	s.data = nil
+	s.joinPayload = nil
+	s.topicParams = nil
+	socketPool.Put(s)
+}
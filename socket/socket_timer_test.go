@@ -0,0 +1,146 @@
+package socket
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testTimerSocket() *Socket {
+	session := &Session{messages: make(chan []byte, 32)}
+	handler := &Handler{Serializer: defaultSerializer}
+	socket := newSocket(1, 1, "chat:lobby", nil, session, handler)
+	socket.setStatus(StatusJoined)
+	return socket
+}
+
+func Test_Socket_PushAfter(t *testing.T) {
+	socket := testTimerSocket()
+	session := socket.session
+
+	socket.PushAfter(time.Millisecond*10, "countdown", map[string]any{"value": 1})
+	canceled := socket.PushAfter(time.Millisecond*10, "canceled", nil)
+	if !canceled.Stop() {
+		t.Errorf("Stop() failed: expected true")
+	}
+
+	select {
+	case bytes := <-session.messages:
+		message := newMessageAny()
+		if _, err := defaultSerializer.Decode(bytes, message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Event != "countdown" {
+			t.Errorf("PushAfter() failed: Invalid Event\n   actual: %v\n expected: %v", message.Event, "countdown")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PushAfter() failed: message not received")
+	}
+
+	select {
+	case <-session.messages:
+		t.Errorf("PushAfter() failed: canceled timer fired")
+	case <-time.After(time.Millisecond * 30):
+	}
+}
+
+func Test_Socket_PushEvery(t *testing.T) {
+	socket := testTimerSocket()
+
+	var count atomic.Int32
+	socket.PushEvery(time.Millisecond*5, func(socket *Socket) {
+		count.Add(1)
+	})
+
+	<-time.After(time.Millisecond * 50)
+	if count.Load() < 2 {
+		t.Errorf("PushEvery() failed: Invalid count\n   actual: %d\n expected: >= 2", count.Load())
+	}
+
+	// cancelled on leave/close
+	deleteSocket(socket)
+	stopped := count.Load()
+	<-time.After(time.Millisecond * 30)
+	if count.Load() != stopped {
+		t.Errorf("PushEvery() failed: timer not canceled\n   actual: %d\n expected: %d", count.Load(), stopped)
+	}
+}
+
+func Test_Socket_PushEvery_Remove_While_Running(t *testing.T) {
+	socket := testTimerSocket()
+	session := socket.session
+	session.sockets = map[string]*Socket{socket.topic: socket}
+
+	started := make(chan bool, 1)
+	finished := make(chan bool, 1)
+	socket.PushEvery(time.Millisecond, func(s *Socket) {
+		select {
+		case started <- true:
+		default:
+			return
+		}
+		// blocks while the session is being closed (see Handler.handleClose)
+		session.GetSocket("chat:lobby")
+		finished <- true
+	})
+
+	session.socketsMutex.Lock()
+	<-started
+
+	removed := make(chan bool)
+	go func() {
+		deleteSocket(socket)
+		close(removed)
+	}()
+	select {
+	case <-removed:
+	case <-time.After(time.Second):
+		t.Fatal("deleteSocket() failed: deadlock waiting for the timer callback")
+	}
+	session.socketsMutex.Unlock()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("PushEvery() failed: callback did not finish")
+	}
+}
+
+func Test_Socket_PushEvery_Remove_While_Pushing(t *testing.T) {
+	socket := testTimerSocket()
+
+	errs := make(chan error, 1)
+	removed := make(chan bool)
+	started := make(chan bool)
+	var running atomic.Bool
+	socket.PushEvery(time.Millisecond, func(s *Socket) {
+		if !running.CompareAndSwap(false, true) {
+			return
+		}
+		close(started)
+		for {
+			select {
+			case <-removed:
+				// the socket was removed while the callback was running
+				errs <- s.Push("state", s.Get("value"))
+				return
+			default:
+				s.Set("value", 1)
+				_ = s.Push("state", s.Get("value"))
+			}
+		}
+	})
+
+	<-started
+	deleteSocket(socket)
+	close(removed)
+
+	select {
+	case err := <-errs:
+		if err != ErrSocketNotJoined {
+			t.Errorf("Push() failed: Invalid error\n   actual: %v\n expected: %v", err, ErrSocketNotJoined)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("PushEvery() failed: callback did not finish")
+	}
+}
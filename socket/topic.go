@@ -0,0 +1,199 @@
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nidorx/chain/pkg"
+)
+
+var ErrInvalidTopicTemplate = errors.New("invalid topic template")
+
+// TopicTemplate topic with named parameters (ex. "room:{id}" or "game:{game}:player:{player}"), allows building
+// topics and extracting the parameters of the topics, so channel code stops manually splitting topic strings.
+//
+// Templates can be used to register the channel and its handlers (NewChannel, Channel.Join, Channel.Leave, ...), the
+// parameters match one or more characters (see pkg.WildcardStore). The handlers then get the parameters with
+// Socket.TopicParam.
+//
+// ## Example
+//
+//	var RoomTopic = socket.MustTopicTemplate("room:{id}")
+//
+//	channel.Join(RoomTopic.String(), func(payload any, socket *Socket) (reply any, err error) {
+//		room := socket.TopicParam("id")
+//		return
+//	})
+//
+//	channel.Broadcast(RoomTopic.Format(42), "new_msg", msg) // "room:42"
+type TopicTemplate struct {
+	template string
+	parts    []topicPart
+	store    *pkg.WildcardStore[bool] // matches the topics, see Params
+}
+
+// topicPart literal text or parameter of the template
+type topicPart struct {
+	literal string
+	param   string
+}
+
+// ParseTopicTemplate parses the template, parameters are defined by `{name}` and must be separated by literal text
+func ParseTopicTemplate(template string) (*TopicTemplate, error) {
+	t := &TopicTemplate{template: template}
+	rest := template
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, fmt.Errorf("%w: unexpected '}' in %q", ErrInvalidTopicTemplate, template)
+			}
+			t.parts = append(t.parts, topicPart{literal: rest})
+			break
+		}
+		if start > 0 {
+			if strings.IndexByte(rest[:start], '}') >= 0 {
+				return nil, fmt.Errorf("%w: unexpected '}' in %q", ErrInvalidTopicTemplate, template)
+			}
+			t.parts = append(t.parts, topicPart{literal: rest[:start]})
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed '{' in %q", ErrInvalidTopicTemplate, template)
+		}
+		name := rest[start+1 : start+end]
+		if name == "" || strings.ContainsAny(name, "{*") {
+			return nil, fmt.Errorf("%w: invalid parameter name %q in %q", ErrInvalidTopicTemplate, name, template)
+		}
+		if n := len(t.parts); n > 0 && t.parts[n-1].param != "" {
+			return nil, fmt.Errorf("%w: parameters must be separated in %q", ErrInvalidTopicTemplate, template)
+		}
+		for _, part := range t.parts {
+			if part.param == name {
+				return nil, fmt.Errorf("%w: duplicated parameter %q in %q", ErrInvalidTopicTemplate, name, template)
+			}
+		}
+		t.parts = append(t.parts, topicPart{param: name})
+		rest = rest[start+end+1:]
+	}
+
+	t.store = &pkg.WildcardStore[bool]{}
+	if err := t.store.Insert(template, true); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTopicTemplate, err.Error())
+	}
+	return t, nil
+}
+
+// MustTopicTemplate is like ParseTopicTemplate but panics if the template is invalid
+func MustTopicTemplate(template string) *TopicTemplate {
+	t, err := ParseTopicTemplate(template)
+	if err != nil {
+		panic(fmt.Sprintf("[chain.socket] %s", err.Error()))
+	}
+	return t
+}
+
+// isTopicTemplate checks if the topic has parameters
+func isTopicTemplate(topic string) bool {
+	return strings.IndexByte(topic, '{') >= 0
+}
+
+// String the template
+func (t *TopicTemplate) String() string {
+	return t.template
+}
+
+// Pattern the prefix pattern of the template, before the first parameter (ex. "room:*"), for the APIs that only
+// support prefixes
+func (t *TopicTemplate) Pattern() string {
+	var prefix strings.Builder
+	for _, part := range t.parts {
+		if part.param != "" {
+			prefix.WriteByte('*')
+			return prefix.String()
+		}
+		prefix.WriteString(part.literal)
+	}
+	return prefix.String()
+}
+
+// literals length of the literal text of the template
+func (t *TopicTemplate) literals() int {
+	size := 0
+	for _, part := range t.parts {
+		size += len(part.literal)
+	}
+	return size
+}
+
+// Format builds a topic with the values of the parameters, in the order they appear in the template
+func (t *TopicTemplate) Format(values ...any) string {
+	var topic strings.Builder
+	i := 0
+	for _, part := range t.parts {
+		if part.param == "" {
+			topic.WriteString(part.literal)
+		} else if i < len(values) {
+			topic.WriteString(fmt.Sprint(values[i]))
+			i++
+		}
+	}
+	return topic.String()
+}
+
+// Params extracts the parameters of the topic. Returns false if the topic does not match the template.
+func (t *TopicTemplate) Params(topic string) (params map[string]string, ok bool) {
+	if matched, captures := t.store.MatchCaptures(topic); matched {
+		if captures == nil {
+			captures = map[string]string{}
+		}
+		return captures, true
+	}
+	return nil, false
+}
+
+// topicPattern keeps the topic templates for the extraction of the parameters (see Socket.TopicParam), the
+// WildcardStore matches the templates
+func (c *Channel) topicPattern(topic string) string {
+	if !isTopicTemplate(topic) {
+		return topic
+	}
+	template := MustTopicTemplate(topic)
+	for _, existing := range c.topicTemplates {
+		if existing.template == template.template {
+			return topic
+		}
+	}
+	c.topicTemplates = append(c.topicTemplates, template)
+	// the most specific template (more literal text) is used first
+	sort.SliceStable(c.topicTemplates, func(i, j int) bool {
+		return c.topicTemplates[i].literals() > c.topicTemplates[j].literals()
+	})
+	return topic
+}
+
+// topicParams extracts the parameters of the topic, using the most specific template that matches
+func (c *Channel) topicParams(topic string) map[string]string {
+	for _, template := range c.topicTemplates {
+		if params, ok := template.Params(topic); ok {
+			return params
+		}
+	}
+	return nil
+}
+
+// TopicParam gets the value of a parameter of the socket topic, defined by the topic template used to join the channel
+// (see TopicTemplate). Returns an empty string if the parameter does not exist.
+//
+// ## Example
+//
+//	channel.Join("game:{game}:player:{player}", func(payload any, socket *Socket) (reply any, err error) {
+//		game, player := socket.TopicParam("game"), socket.TopicParam("player")
+//		return
+//	})
+func (s *Socket) TopicParam(name string) string {
+	return s.topicParams[name]
+}
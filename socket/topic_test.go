@@ -0,0 +1,86 @@
+package socket
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_TopicTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		pattern  string
+		topic    string
+		params   map[string]string
+	}{
+		{"room:{id}", "room:*", "room:42", map[string]string{"id": "42"}},
+		{"room:{id}", "room:*", "room:", nil},
+		{"room:{id}", "room:*", "chat:42", nil},
+		{"game:{game}:player:{player}", "game:*", "game:7:player:alex", map[string]string{"game": "7", "player": "alex"}},
+		{"game:{game}:player:{player}", "game:*", "game:7:viewer:alex", nil},
+		{"{tenant}:room", "*", "acme:room", map[string]string{"tenant": "acme"}},
+		{"{tenant}:room", "*", "acme:room:1", nil},
+		{"system", "system", "system", map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template+"="+tt.topic, func(t *testing.T) {
+			template, err := ParseTopicTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pattern := template.Pattern(); pattern != tt.pattern {
+				t.Errorf("Pattern() failed: Invalid pattern\n   actual: %v\n expected: %v", pattern, tt.pattern)
+			}
+			params, ok := template.Params(tt.topic)
+			if ok != (tt.params != nil) || (ok && !reflect.DeepEqual(params, tt.params)) {
+				t.Errorf("Params() failed: Invalid params\n   actual: %v %v\n expected: %v", params, ok, tt.params)
+			}
+		})
+	}
+
+	if topic := MustTopicTemplate("game:{game}:player:{player}").Format(7, "alex"); topic != "game:7:player:alex" {
+		t.Errorf("Format() failed: Invalid topic\n   actual: %v\n expected: %v", topic, "game:7:player:alex")
+	}
+
+	for _, invalid := range []string{"room:{id", "room:id}", "room:{}", "room:{a}{b}", "room:{a}:{a}"} {
+		if _, err := ParseTopicTemplate(invalid); !errors.Is(err, ErrInvalidTopicTemplate) {
+			t.Errorf("ParseTopicTemplate() failed: Invalid Error for %s\n   actual: %v\n expected: %v", invalid, err, ErrInvalidTopicTemplate)
+		}
+	}
+}
+
+func Test_Socket_TopicParam(t *testing.T) {
+	params := make(chan string, 1)
+	transport := &transportT{}
+	handler := &Handler{
+		Transports: []Transport{transport},
+		Channels: []*Channel{
+			NewChannel("game:{game}", func(channel *Channel) {
+				channel.Join("game:{game}:player:{player}", func(payload any, socket *Socket) (reply any, err error) {
+					params <- socket.TopicParam("game") + "/" + socket.TopicParam("player")
+					return
+				})
+			}),
+		},
+	}
+	chain.New().Configure("/socket", handler)
+
+	if _, err := transport.Connect(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	defer transport.Close()
+
+	join := newMessage(MessageTypePush, "game:7:player:alex", "_join", nil)
+	join.Ref = 1
+	join.JoinRef = 1
+	transport.SendMessage(join)
+
+	if messages := waitMessages(transport, 1); len(messages) != 1 || messages[0].Status != ReplyStatusCodeOk {
+		t.Fatalf("Join() failed: Invalid reply\n   actual: %v", messages)
+	}
+	if actual := <-params; actual != "7/alex" {
+		t.Errorf("TopicParam() failed: Invalid params\n   actual: %v\n expected: %v", actual, "7/alex")
+	}
+}
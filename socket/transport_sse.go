@@ -1,6 +1,7 @@
 package socket
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/nidorx/chain"
 	"github.com/nidorx/chain/middlewares/session"
@@ -12,7 +13,14 @@ import (
 const sseSessionId = "_sse_"
 
 type TransportSSE struct {
-	sessionKey string
+	Compression      bool // Compresses the stream (gzip or deflate) when accepted by the client (Accept-Encoding)
+	CompressionLevel int  // Level of the compression (see compress/flate). Default flate.DefaultCompression
+	sessionKey       string
+}
+
+// Name of the transport, see NamedTransport
+func (t *TransportSSE) Name() string {
+	return "sse"
 }
 
 func (t *TransportSSE) Configure(handler *Handler, router *chain.Router, endpoint string) {
@@ -51,12 +59,11 @@ func (t *TransportSSE) Configure(handler *Handler, router *chain.Router, endpoin
 
 	// Starts a new session or listen to a ServiceMsg if one already exists.
 	router.GET(endpoint, func(ctx *chain.Context) {
-		var ok bool
-		var flusher http.Flusher
-		if flusher, ok = ctx.Writer.(*chain.ResponseWriterSpy).ResponseWriter.(http.Flusher); !ok {
+		if _, ok := ctx.Writer.(*chain.ResponseWriterSpy).Unwrap().(http.Flusher); !ok {
 			ctx.Error("Connection does not support streaming", http.StatusBadRequest)
 			return
 		}
+		flusher := ctx.Writer.(http.Flusher)
 
 		var socketSession *Session
 		if socketSession = t.resumeSession(ctx, handler); socketSession == nil {
@@ -65,6 +72,12 @@ func (t *TransportSSE) Configure(handler *Handler, router *chain.Router, endpoin
 				ctx.Error("Could not initialize connection: "+err.Error(), http.StatusForbidden)
 				return
 			}
+		} else if handler.Binding != nil {
+			// renews the resume token on each reconnection
+			if err := t.storeSession(ctx, handler, socketSession); err != nil {
+				ctx.Error("Could not initialize connection: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
 		}
 
 		if ctx.Request.ProtoMajor == 1 {
@@ -72,15 +85,31 @@ func (t *TransportSSE) Configure(handler *Handler, router *chain.Router, endpoin
 			// Source: RFC7540.
 			ctx.SetHeader("Connection", "keep-alive")
 		}
+		ctx.SetHeader(ProtocolVersionHeader, socketSession.ProtocolVersion())
 		ctx.SetHeader("X-Accel-Buffering", "no")
 		ctx.SetHeader("Content-Type", "text/event-stream; charset=utf-8")
 		ctx.SetHeader("Cache-Control", "private, no-cache, no-store, must-revalidate, max-age=0")
 		ctx.SetHeader("Pragma", "no-cache")
 		ctx.SetHeader("Expire", "0")
 		//ctx.SetHeader("Access-Control-Allow-Origin", "*")
+
+		encoding := ""
+		if t.Compression {
+			ctx.SetHeader("Vary", "Accept-Encoding")
+			if encoding = sseEncoding(ctx.Request.Header.Get("Accept-Encoding")); encoding != "" {
+				ctx.SetHeader("Content-Encoding", encoding)
+			}
+		}
+
 		ctx.WriteHeader(http.StatusOK)
 		flusher.Flush()
-		if err := t.listen(socketSession, ctx, flusher); err != nil {
+
+		writer, err := newSSEWriter(ctx.Writer, flusher, encoding, t.CompressionLevel)
+		if err != nil {
+			ctx.Error(err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := t.listen(socketSession, ctx, writer); err != nil {
 			ctx.Error(err.Error(), http.StatusInternalServerError)
 		}
 	})
@@ -92,6 +121,16 @@ func (t *TransportSSE) resumeSession(ctx *chain.Context, handler *Handler) *Sess
 	if sess, err = session.FetchByKey(ctx, t.sessionKey); err != nil {
 		return nil
 	}
+
+	if handler.Binding != nil {
+		token, _ := sess.Get("token").(string)
+		if token == "" {
+			return nil
+		}
+		socketSession, _ := handler.ResumeWithToken(token, clientInfoFromContext(ctx))
+		return socketSession
+	}
+
 	sid := sess.Get("sid")
 	if sid == nil {
 		return nil
@@ -102,8 +141,7 @@ func (t *TransportSSE) resumeSession(ctx *chain.Context, handler *Handler) *Sess
 
 func (t *TransportSSE) newSession(handler *Handler, ctx *chain.Context, endpoint string) (skt *Session, err error) {
 
-	var sess *session.Session
-	if sess, err = session.FetchByKey(ctx, t.sessionKey); err != nil {
+	if _, err = session.FetchByKey(ctx, t.sessionKey); err != nil {
 		return
 	}
 
@@ -116,29 +154,58 @@ func (t *TransportSSE) newSession(handler *Handler, ctx *chain.Context, endpoint
 	if skt, err = handler.Connect(endpoint, params); err != nil {
 		return
 	}
-	sess.Put("sid", skt.SocketId())
+	err = t.storeSession(ctx, handler, skt)
 
 	return
 }
 
-func (t *TransportSSE) listen(socketSession *Session, ctx *chain.Context, flusher http.Flusher) (err error) {
+// storeSession saves the session id (or the resume token, see Handler.Binding) on the cookie
+func (t *TransportSSE) storeSession(ctx *chain.Context, handler *Handler, skt *Session) error {
+	sess, err := session.FetchByKey(ctx, t.sessionKey)
+	if err != nil {
+		return err
+	}
+	if handler.Binding == nil {
+		sess.Put("sid", skt.SocketId())
+		return nil
+	}
+
+	token, err := handler.ResumeToken(skt, clientInfoFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	sess.Put("token", token)
+	return nil
+}
+
+func (t *TransportSSE) listen(socketSession *Session, ctx *chain.Context, w *sseWriter) (err error) {
 
 	// after disconnection, schedule session shutdown
 	defer socketSession.ScheduleShutdown(time.Second * 15)
+	defer w.Close()
 
-	w := ctx.Writer.(*chain.ResponseWriterSpy)
+	// the router releases the context when the request is done
+	done := ctx.Request.Context().Done()
 
 	// trap the request under loop forever
 	for {
 		select {
-		case <-ctx.Request.Context().Done():
+		case <-done:
 			return
 		case msg := <-socketSession.messages:
 			if msg != nil {
-				if _, err = fmt.Fprintf(w, "data: %s\n\n", msg); err != nil {
+				if len(msg) > 0 && msg[0] == binaryFrameFlag {
+					// SSE is a text protocol, binary frames are sent in base64 (prefixed by "b")
+					_, err = fmt.Fprintf(w, "data: b%s\n\n", base64.StdEncoding.EncodeToString(msg))
+				} else {
+					_, err = fmt.Fprintf(w, "data: %s\n\n", msg)
+				}
+				if err != nil {
+					return
+				}
+				if err = w.Flush(); err != nil {
 					return
 				}
-				flusher.Flush()
 			}
 		}
 	}
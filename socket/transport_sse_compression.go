@@ -0,0 +1,90 @@
+package socket
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// sseCompressor stream compressor (gzip.Writer or zlib.Writer)
+type sseCompressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// sseWriter writes the events of the SSE stream, compressing the stream if negotiated with the client (see
+// TransportSSE.Compression). Flush sends the compressed events, so each event is delivered immediately.
+type sseWriter struct {
+	writer     io.Writer
+	flusher    http.Flusher
+	compressor sseCompressor // nil if the stream is not compressed
+}
+
+func newSSEWriter(w io.Writer, flusher http.Flusher, encoding string, level int) (*sseWriter, error) {
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+
+	writer := &sseWriter{writer: w, flusher: flusher}
+	var err error
+	switch encoding {
+	case "gzip":
+		writer.compressor, err = gzip.NewWriterLevel(w, level)
+	case "deflate":
+		// HTTP "deflate" is the zlib format (RFC 9110)
+		writer.compressor, err = zlib.NewWriterLevel(w, level)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if writer.compressor != nil {
+		writer.writer = writer.compressor
+	}
+	return writer, nil
+}
+
+func (w *sseWriter) Write(data []byte) (int, error) {
+	return w.writer.Write(data)
+}
+
+// Flush sends the buffered events to the client
+func (w *sseWriter) Flush() error {
+	if w.compressor != nil {
+		if err := w.compressor.Flush(); err != nil {
+			return err
+		}
+	}
+	w.flusher.Flush()
+	return nil
+}
+
+// Close ends the compressed stream
+func (w *sseWriter) Close() error {
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// sseEncoding selects the compression of the stream from the Accept-Encoding header of the client, gzip is preferred.
+// Returns an empty string if the client does not accept compression.
+func sseEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, value := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(value), ";")
+		if q, hasQ := strings.CutPrefix(strings.TrimSpace(params), "q="); hasQ && strings.Trim(q, "0.") == "" {
+			// q=0, not acceptable
+			continue
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
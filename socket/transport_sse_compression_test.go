@@ -0,0 +1,83 @@
+package socket
+
+import (
+	"bufio"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_TransportSSE_Compression(t *testing.T) {
+	router := chain.New()
+	if err := router.SetSecretKeyBase("ZcbD0D29eYsGq89QjirJbPkw7Qxwxboy"); err != nil {
+		t.Fatal(err)
+	}
+	handler := &Handler{
+		Transports: []Transport{&TransportSSE{Compression: true}},
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {}),
+		},
+	}
+	router.Configure("/socket", handler)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/socket/sse", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if encoding := response.Header.Get("Content-Encoding"); encoding != "gzip" {
+		t.Fatalf("TransportSSE failed: Invalid Content-Encoding\n   actual: %v\n expected: %v", encoding, "gzip")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for handler.SessionCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	handler.sessionsMutex.RLock()
+	for _, session := range handler.sessions {
+		session.Push([]byte(`2,"room:1","hello",1`))
+	}
+	handler.sessionsMutex.RUnlock()
+
+	// each event is flushed, the reader gets it without closing the stream
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `data: 2,"room:1","hello",1`; strings.TrimSpace(line) != expected {
+		t.Errorf("TransportSSE failed: Invalid event\n   actual: %v\n expected: %v", line, expected)
+	}
+}
+
+func Test_sseEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"identity":               "",
+		"gzip":                   "gzip",
+		"deflate":                "deflate",
+		"deflate, gzip;q=1.0":    "gzip",
+		"GZIP;q=0, deflate;q=.5": "deflate",
+		"gzip;q=0.000, br":       "",
+		"br, deflate, gzip":      "gzip",
+	}
+	for header, expected := range tests {
+		if actual := sseEncoding(header); actual != expected {
+			t.Errorf("sseEncoding(%q) failed: Invalid encoding\n   actual: %v\n expected: %v", header, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package socket
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nidorx/chain"
+)
+
+// Stream a bidirectional stream of frames, ex. a gRPC streaming call. See TransportStream.
+type Stream interface {
+	Context() context.Context
+	Send(frame []byte) error
+	Recv() ([]byte, error)
+}
+
+// TransportStream bridge that maps streams (ex. gRPC bidirectional streaming calls) to sessions, so polyglot backends
+// can join topics and exchange events with browser clients connected by the other transports.
+//
+// The frames of the stream are the messages encoded by the TransportStream.Serializer (default ProtobufSerializer),
+// translated to the Handler.Serializer, so clients of different transports share the same channels.
+//
+// ## Example
+//
+//	// service Chain {
+//	//   rpc Connect(stream Frame) returns (stream Frame);
+//	// }
+//	// message Frame {
+//	//   bytes data = 1; // socket.Message, see socket.ProtobufSerializer
+//	// }
+//
+//	bridge := &socket.TransportStream{}
+//	router.Configure("/socket", &socket.Handler{
+//		Transports: []socket.Transport{&socket.TransportSSE{}, bridge},
+//		Channels:   []*socket.Channel{ ... },
+//	})
+//
+//	func (s *ChainServer) Connect(stream pb.Chain_ConnectServer) error {
+//		md, _ := metadata.FromIncomingContext(stream.Context())
+//		return bridge.Serve(&grpcStream{stream}, map[string]string{"token": md.Get("token")[0]})
+//	}
+//
+//	type grpcStream struct{ pb.Chain_ConnectServer }
+//
+//	func (s *grpcStream) Send(frame []byte) error {
+//		return s.Chain_ConnectServer.Send(&pb.Frame{Data: frame})
+//	}
+//
+//	func (s *grpcStream) Recv() ([]byte, error) {
+//		frame, err := s.Chain_ConnectServer.Recv()
+//		if err != nil {
+//			return nil, err
+//		}
+//		return frame.Data, nil
+//	}
+type TransportStream struct {
+	Serializer chain.Serializer // Serializer of the frames. Default ProtobufSerializer
+	handler    *Handler
+	endpoint   string
+}
+
+// Name of the transport, see NamedTransport
+func (t *TransportStream) Name() string {
+	return "stream"
+}
+
+func (t *TransportStream) Configure(handler *Handler, router *chain.Router, endpoint string) {
+	t.handler = handler
+	t.endpoint = endpoint
+	if t.Serializer == nil {
+		t.Serializer = &ProtobufSerializer{}
+	}
+}
+
+// Serve creates a session for the stream, blocking until the stream ends (Recv fails or the context is done). The
+// session is terminated when Serve returns.
+func (t *TransportStream) Serve(stream Stream, params map[string]string) error {
+	if params == nil {
+		params = map[string]string{}
+	}
+	session, err := t.handler.Connect(t.endpoint, params)
+	if err != nil {
+		return err
+	}
+	defer session.ScheduleShutdown(0)
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// writes the messages to the stream
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-session.messages:
+				if msg == nil {
+					continue
+				}
+				frame, ok := t.translate(msg, t.handler.Serializer, t.Serializer)
+				if !ok {
+					continue
+				}
+				if err := stream.Send(frame); err != nil {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		frame, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if payload, ok := t.translate(frame, t.Serializer, t.handler.Serializer); ok {
+			session.Dispatch(payload)
+		}
+	}
+}
+
+// translate decodes the message with the serializer `from`, encoding with `to`
+func (t *TransportStream) translate(data []byte, from chain.Serializer, to chain.Serializer) ([]byte, bool) {
+	if from == to {
+		return data, true
+	}
+	message := newMessageAny()
+	defer deleteMessage(message)
+
+	_, err := from.Decode(data, message)
+	if err == nil {
+		if data, err = to.Encode(message); err == nil {
+			return data, true
+		}
+	}
+	slog.Debug(
+		"[chain.socket] could not translate stream message",
+		slog.Any("Error", err),
+		slog.Any("Payload", data),
+	)
+	return nil, false
+}
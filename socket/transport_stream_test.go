@@ -0,0 +1,75 @@
+package socket
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+type streamT struct {
+	ctx context.Context
+	in  chan []byte
+	out chan []byte
+}
+
+func (s *streamT) Context() context.Context {
+	return s.ctx
+}
+
+func (s *streamT) Send(frame []byte) error {
+	s.out <- frame
+	return nil
+}
+
+func (s *streamT) Recv() ([]byte, error) {
+	frame, ok := <-s.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return frame, nil
+}
+
+func Test_TransportStream(t *testing.T) {
+	bridge := &TransportStream{}
+	chain.New().Configure("/socket", &Handler{
+		Transports: []Transport{bridge},
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {
+				channel.Join("room:*", func(payload any, socket *Socket) (reply any, err error) {
+					return socket.Params["service"], nil
+				})
+			}),
+		},
+	})
+
+	stream := &streamT{ctx: context.Background(), in: make(chan []byte), out: make(chan []byte, 1)}
+	done := make(chan error)
+	go func() {
+		done <- bridge.Serve(stream, map[string]string{"service": "billing"})
+	}()
+
+	serializer := &ProtobufSerializer{}
+	join, _ := serializer.Encode(&Message{Kind: MessageTypePush, JoinRef: 1, Ref: 1, Topic: "room:1", Event: "_join"})
+	stream.in <- join
+
+	select {
+	case frame := <-stream.out:
+		reply := &Message{}
+		if _, err := serializer.Decode(frame, reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Kind != MessageTypeReply || reply.Ref != 1 || reply.Payload != "billing" {
+			t.Errorf("Serve() failed: Invalid reply\n   actual: %+v", reply)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Serve() failed: no reply")
+	}
+
+	close(stream.in)
+	if err := <-done; err != io.EOF {
+		t.Errorf("Serve() failed: Invalid Error\n   actual: %v\n expected: %v", err, io.EOF)
+	}
+}
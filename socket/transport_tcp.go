@@ -0,0 +1,280 @@
+package socket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+const (
+	DefaultTCPMaxFrameSize  = 1 << 20
+	DefaultTCPShutdownAfter = 15 * time.Second
+)
+
+var (
+	ErrFrameTooLarge = fmt.Errorf("frame too large")
+)
+
+// TransportTCP serves the socket protocol over a plain TCP or Unix domain socket listener (non-HTTP), so backend
+// services and IoT devices can participate in channels.
+//
+// Each frame is prefixed by its length ([length: uint32 big endian][payload: length]), the payload of the frames are
+// the messages encoded by the Handler.Serializer. The first frame sent by the client is the handshake, a JSON object
+// with the connection params (including the protocol version, see ProtocolVersionParam) and, optionally, the id of the
+// session to resume:
+//
+//	{"params": {"token": "...", "vsn": "1.0.0"}, "sid": "..."}
+//
+// The server replies with a JSON frame containing the session id and the negotiated protocol version
+// ({"sid": "...", "vsn": "1.0.0"}), or the error ({"error": "..."}) closing the connection.
+//
+// With Handler.Binding enabled, the reply also contains the resume token ({"sid": "...", "token": "..."}), bound to
+// the IP of the client, that must be sent in the handshake instead of the session id to resume the session
+// ({"token": "..."}).
+//
+// ## Example
+//
+//	router.Configure("/socket", &socket.Handler{
+//		Transports: []socket.Transport{
+//			&socket.TransportSSE{},
+//			&socket.TransportTCP{Network: "unix", Address: "/var/run/app.sock"},
+//		},
+//		Channels: []*socket.Channel{ ... },
+//	})
+type TransportTCP struct {
+	Network       string        // "tcp" (default), "tcp4", "tcp6" or "unix"
+	Address       string        // Address to listen, ex. ":4000" or "/var/run/app.sock"
+	Listener      net.Listener  // Listener used instead of Network and Address, if defined
+	MaxFrameSize  int           // Max size of the frames sent by the client. Default DefaultTCPMaxFrameSize
+	ShutdownAfter time.Duration // Session termination after disconnection. Default DefaultTCPShutdownAfter
+	handler       *Handler
+	endpoint      string
+	conns         map[net.Conn]bool
+	connsMutex    sync.Mutex
+	closed        bool
+}
+
+type tcpHandshake struct {
+	Params map[string]string `json:"params,omitempty"`
+	Sid    string            `json:"sid,omitempty"`
+	Token  string            `json:"token,omitempty"`
+}
+
+type tcpHandshakeReply struct {
+	Sid   string `json:"sid,omitempty"`
+	Vsn   string `json:"vsn,omitempty"`
+	Token string `json:"token,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Name of the transport, see NamedTransport
+func (t *TransportTCP) Name() string {
+	return "tcp"
+}
+
+func (t *TransportTCP) Configure(handler *Handler, router *chain.Router, endpoint string) {
+	t.handler = handler
+	t.endpoint = endpoint
+
+	if t.MaxFrameSize <= 0 {
+		t.MaxFrameSize = DefaultTCPMaxFrameSize
+	}
+	if t.ShutdownAfter <= 0 {
+		t.ShutdownAfter = DefaultTCPShutdownAfter
+	}
+
+	if t.Listener == nil {
+		network := t.Network
+		if network == "" {
+			network = "tcp"
+		}
+		listener, err := net.Listen(network, t.Address)
+		if err != nil {
+			panic(fmt.Sprintf("[chain.socket] could not listen. Network: %s, Address: %s, Error: %s", network, t.Address, err.Error()))
+		}
+		t.Listener = listener
+	}
+
+	go t.serve()
+}
+
+// Addr the address of the listener
+func (t *TransportTCP) Addr() net.Addr {
+	return t.Listener.Addr()
+}
+
+// Close stops the listener and closes the active connections. The sessions are terminated after ShutdownAfter.
+func (t *TransportTCP) Close() error {
+	t.connsMutex.Lock()
+	t.closed = true
+	for conn := range t.conns {
+		_ = conn.Close()
+	}
+	t.connsMutex.Unlock()
+	return t.Listener.Close()
+}
+
+func (t *TransportTCP) serve() {
+	for {
+		conn, err := t.Listener.Accept()
+		if err != nil {
+			t.connsMutex.Lock()
+			closed := t.closed
+			t.connsMutex.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Warn("[chain.socket] could not accept connection", slog.Any("Error", err))
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+
+		t.connsMutex.Lock()
+		if t.closed {
+			t.connsMutex.Unlock()
+			_ = conn.Close()
+			return
+		}
+		if t.conns == nil {
+			t.conns = map[net.Conn]bool{}
+		}
+		t.conns[conn] = true
+		t.connsMutex.Unlock()
+
+		go t.handle(conn)
+	}
+}
+
+func (t *TransportTCP) handle(conn net.Conn) {
+	defer func() {
+		t.connsMutex.Lock()
+		delete(t.conns, conn)
+		t.connsMutex.Unlock()
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	socketSession, err := t.handshake(reader, conn)
+	if err != nil {
+		slog.Debug(
+			"[chain.socket] tcp handshake failed",
+			slog.Any("Error", err),
+			slog.String("Remote", conn.RemoteAddr().String()),
+		)
+		return
+	}
+
+	// after disconnection, schedule session shutdown
+	defer socketSession.ScheduleShutdown(t.ShutdownAfter)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// writes the messages to the client
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case msg := <-socketSession.messages:
+				if msg != nil {
+					if err := writeFrame(conn, msg); err != nil {
+						_ = conn.Close()
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	for {
+		payload, err := readFrame(reader, t.MaxFrameSize)
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				slog.Debug(
+					"[chain.socket] tcp connection closed",
+					slog.Any("socket_id", socketSession.SocketId()),
+					slog.Any("Error", err),
+				)
+			}
+			return
+		}
+		socketSession.Dispatch(payload)
+	}
+}
+
+// handshake reads the handshake frame, resuming or creating the session
+func (t *TransportTCP) handshake(reader *bufio.Reader, conn net.Conn) (socketSession *Session, err error) {
+	var payload []byte
+	if payload, err = readFrame(reader, t.MaxFrameSize); err != nil {
+		return
+	}
+
+	handshake := &tcpHandshake{}
+	if err = json.Unmarshal(payload, handshake); err == nil {
+		if t.handler.Binding != nil {
+			if handshake.Token != "" {
+				socketSession, _ = t.handler.ResumeWithToken(handshake.Token, clientInfoFromConn(conn))
+			}
+		} else if handshake.Sid != "" {
+			socketSession = t.handler.Resume(handshake.Sid)
+		}
+		if socketSession == nil {
+			if handshake.Params == nil {
+				handshake.Params = map[string]string{}
+			}
+			socketSession, err = t.handler.Connect(t.endpoint, handshake.Params)
+		}
+	}
+
+	reply := &tcpHandshakeReply{}
+	if err == nil && t.handler.Binding != nil {
+		reply.Token, err = t.handler.ResumeToken(socketSession, clientInfoFromConn(conn))
+	}
+	if err != nil {
+		reply.Error = err.Error()
+		reply.Token = ""
+	} else {
+		reply.Sid = socketSession.SocketId()
+		reply.Vsn = socketSession.ProtocolVersion()
+	}
+
+	encoded, _ := json.Marshal(reply)
+	if errWrite := writeFrame(conn, encoded); err == nil {
+		err = errWrite
+	}
+	return
+}
+
+func readFrame(reader io.Reader, maxSize int) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if int64(size) > int64(maxSize) {
+		return nil, ErrFrameTooLarge
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func writeFrame(writer io.Writer, payload []byte) error {
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+	_, err := writer.Write(frame)
+	return err
+}
@@ -0,0 +1,94 @@
+package socket
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+)
+
+func Test_TransportTCP(t *testing.T) {
+	transport := &TransportTCP{Address: "127.0.0.1:0"}
+	handler := &Handler{
+		Transports: []Transport{transport},
+		Channels: []*Channel{
+			NewChannel("room:*", func(channel *Channel) {
+				channel.Join("room:*", func(payload any, socket *Socket) (reply any, err error) {
+					return socket.Params["device"], nil
+				})
+			}),
+		},
+	}
+	chain.New().Configure("/socket", handler)
+	defer transport.Close()
+
+	conn, err := net.Dial("tcp", transport.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	handshake, _ := json.Marshal(tcpHandshake{Params: map[string]string{"device": "sensor-1"}})
+	if err = writeFrame(conn, handshake); err != nil {
+		t.Fatal(err)
+	}
+	frame, err := readFrame(reader, DefaultTCPMaxFrameSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := &tcpHandshakeReply{}
+	if err = json.Unmarshal(frame, reply); err != nil || reply.Sid == "" {
+		t.Fatalf("TransportTCP() failed: Invalid handshake reply\n   actual: %s", frame)
+	}
+
+	join := newMessage(MessageTypePush, "room:1", "_join", nil)
+	join.Ref = 1
+	join.JoinRef = 1
+	encoded, _ := handler.Serializer.Encode(join)
+	if err = writeFrame(conn, encoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if frame, err = readFrame(reader, DefaultTCPMaxFrameSize); err != nil {
+		t.Fatal(err)
+	}
+	message := newMessageAny()
+	if _, err = handler.Serializer.Decode(frame, message); err != nil {
+		t.Fatal(err)
+	}
+	if message.Kind != MessageTypeReply || message.Status != ReplyStatusCodeOk || message.Payload != "sensor-1" {
+		t.Errorf("TransportTCP() failed: Invalid join reply\n   actual: %+v", message)
+	}
+
+	if handler.Resume(reply.Sid) == nil {
+		t.Errorf("TransportTCP() failed: session not found")
+	}
+}
+
+func Test_TransportTCP_Frame_Too_Large(t *testing.T) {
+	transport := &TransportTCP{Address: "127.0.0.1:0", MaxFrameSize: 16}
+	chain.New().Configure("/socket", &Handler{
+		Transports: []Transport{transport},
+		Channels:   []*Channel{NewChannel("room:*", func(channel *Channel) {})},
+	})
+	defer transport.Close()
+
+	conn, err := net.Dial("tcp", transport.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	if err = writeFrame(conn, make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = readFrame(bufio.NewReader(conn), DefaultTCPMaxFrameSize); err == nil {
+		t.Errorf("TransportTCP() failed: connection must be closed")
+	}
+}
@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/pubsub"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultWorkers     = 4
+	DefaultQueueSize   = 1024
+	DefaultTimeout     = 10 * time.Second
+	EventHeader        = "X-Webhook-Event"
+	DeliveryHeader     = "X-Webhook-Delivery"
+)
+
+var (
+	ErrQueueFull        = errors.New("webhook queue is full")
+	ErrDispatcherClosed = errors.New("webhook dispatcher is closed")
+)
+
+// Delivery an outbound webhook
+type Delivery struct {
+	Id       string // Unique id of the delivery, sent in the DeliveryHeader
+	URL      string
+	Event    string // Sent in the EventHeader
+	Payload  []byte
+	Attempts int // Number of attempts made
+}
+
+// Dispatcher sends outbound webhooks, signed by the Scheme, with retries and exponential backoff.
+//
+// Responses 2xx are successful, 4xx (except 408 and 429) are permanent failures, everything else (including network
+// errors) is retried up to MaxAttempts times.
+//
+// ## Example
+//
+//	dispatcher := &webhook.Dispatcher{
+//		Secret: []byte(os.Getenv("WEBHOOK_SECRET")),
+//		OnFailure: func(delivery *webhook.Delivery, err error) {
+//			slog.Error("webhook failed", slog.String("URL", delivery.URL), slog.Any("Error", err))
+//		},
+//	}
+//	defer dispatcher.Shutdown(context.Background())
+//
+//	// send every message of the topic "orders:*"
+//	dispatcher.Subscribe("orders:*", "https://example.com/hooks", "order.updated")
+//
+//	// or send directly
+//	dispatcher.Send("https://example.com/hooks", "order.created", payload)
+type Dispatcher struct {
+	Client      *http.Client                        // Default &http.Client{Timeout: DefaultTimeout}
+	Scheme      Scheme                              // Default &Chain{}
+	Secret      []byte                              // Secret used to sign the deliveries
+	MaxAttempts int                                 // Default DefaultMaxAttempts
+	Workers     int                                 // Number of concurrent deliveries. Default DefaultWorkers
+	QueueSize   int                                 // Max number of pending deliveries. Default DefaultQueueSize
+	Backoff     func(attempt int) time.Duration     // Delay before the retry. Default exponential (1s, 2s, 4s...) with jitter
+	OnFailure   func(delivery *Delivery, err error) // Invoked when the delivery fails permanently
+	queue       chan *Delivery
+	pending     sync.WaitGroup // deliveries not finished (including scheduled retries)
+	workers     sync.WaitGroup
+	startOnce   sync.Once
+	closed      bool
+	closedMutex sync.RWMutex
+}
+
+func (d *Dispatcher) start() {
+	d.startOnce.Do(func() {
+		if d.Client == nil {
+			d.Client = &http.Client{Timeout: DefaultTimeout}
+		}
+		if d.Scheme == nil {
+			d.Scheme = &Chain{}
+		}
+		if d.MaxAttempts <= 0 {
+			d.MaxAttempts = DefaultMaxAttempts
+		}
+		if d.Workers <= 0 {
+			d.Workers = DefaultWorkers
+		}
+		if d.QueueSize <= 0 {
+			d.QueueSize = DefaultQueueSize
+		}
+		if d.Backoff == nil {
+			d.Backoff = defaultBackoff
+		}
+		d.queue = make(chan *Delivery, d.QueueSize)
+		for i := 0; i < d.Workers; i++ {
+			d.workers.Add(1)
+			go d.work()
+		}
+	})
+}
+
+// Send enqueues the delivery of the payload to the url. Returns ErrQueueFull if the queue is full.
+func (d *Dispatcher) Send(url string, event string, payload []byte) error {
+	d.start()
+
+	d.closedMutex.RLock()
+	defer d.closedMutex.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	d.pending.Add(1)
+	select {
+	case d.queue <- &Delivery{Id: chain.NewUID(), URL: url, Event: event, Payload: payload}:
+		return nil
+	default:
+		d.pending.Done()
+		return ErrQueueFull
+	}
+}
+
+// Subscribe sends the messages of the pubsub topic to the url. Messages that are not []byte are encoded as JSON.
+func (d *Dispatcher) Subscribe(topic string, url string, event string) (unsubscribe func()) {
+	dispatcher := pubsub.DispatcherFunc(func(topic string, message any, from string) {
+		payload, ok := message.([]byte)
+		if !ok {
+			var err error
+			if payload, err = json.Marshal(message); err != nil {
+				slog.Warn(
+					"[chain.webhook] could not encode message",
+					slog.String("Topic", topic),
+					slog.Any("Error", err),
+				)
+				return
+			}
+		}
+		if err := d.Send(url, event, payload); err != nil {
+			slog.Warn(
+				"[chain.webhook] could not enqueue delivery",
+				slog.String("Topic", topic),
+				slog.String("URL", url),
+				slog.Any("Error", err),
+			)
+		}
+	})
+	if err := pubsub.Subscribe(topic, dispatcher); err != nil {
+		slog.Warn(
+			"[chain.webhook] could not subscribe",
+			slog.String("Topic", topic),
+			slog.Any("Error", err),
+		)
+	}
+	return func() {
+		pubsub.Unsubscribe(topic, dispatcher)
+	}
+}
+
+// Shutdown stops accepting deliveries and waits for the pending ones (including retries), or the ctx to be done.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.start()
+
+	d.closedMutex.Lock()
+	if d.closed {
+		d.closedMutex.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.closedMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(d.queue)
+		d.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+	for delivery := range d.queue {
+		d.deliver(delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(delivery *Delivery) {
+	delivery.Attempts++
+	retry, err := d.post(delivery)
+	if err == nil {
+		d.pending.Done()
+		return
+	}
+
+	if retry && delivery.Attempts < d.MaxAttempts {
+		slog.Debug(
+			"[chain.webhook] delivery failed, retrying",
+			slog.String("URL", delivery.URL),
+			slog.String("Event", delivery.Event),
+			slog.Int("Attempts", delivery.Attempts),
+			slog.Any("Error", err),
+		)
+		time.AfterFunc(d.Backoff(delivery.Attempts), func() {
+			d.queue <- delivery
+		})
+		return
+	}
+
+	slog.Warn(
+		"[chain.webhook] delivery failed",
+		slog.String("URL", delivery.URL),
+		slog.String("Event", delivery.Event),
+		slog.Int("Attempts", delivery.Attempts),
+		slog.Any("Error", err),
+	)
+	if d.OnFailure != nil {
+		d.OnFailure(delivery, err)
+	}
+	d.pending.Done()
+}
+
+// post sends the delivery, returns if the error is temporary
+func (d *Dispatcher) post(delivery *Delivery) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.Id)
+	d.Scheme.Sign(req.Header, d.Secret, delivery.Payload, time.Now())
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = res.Body.Close()
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusRequestTimeout || res.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("unexpected status %d", res.StatusCode)
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		return false, fmt.Errorf("unexpected status %d", res.StatusCode)
+	default:
+		return true, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	delay := time.Second << (attempt - 1)
+	if delay > time.Minute || delay <= 0 {
+		delay = time.Minute
+	}
+	// jitter of up to 20%, avoids retries of many deliveries at the same time
+	return delay - time.Duration(rand.Int63n(int64(delay/5)))
+}
@@ -0,0 +1,310 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/crypto"
+)
+
+const (
+	DefaultTolerance   = 5 * time.Minute
+	DefaultMaxBodySize = 1 << 20
+	SignatureHeader    = "X-Webhook-Signature"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredSignature = errors.New("webhook signature timestamp out of tolerance")
+	ErrBodyTooLarge     = errors.New("webhook body too large")
+)
+
+// Scheme a webhook signature scheme, see HMAC, GitHub, Stripe and Chain
+type Scheme interface {
+	// Sign sets the signature headers of the outbound request
+	Sign(header http.Header, secret []byte, body []byte, now time.Time)
+	// Verify checks the signature headers of the inbound request
+	Verify(header http.Header, secret []byte, body []byte, now time.Time) error
+}
+
+// HMAC signature of the body in a single header, ex. "X-Signature: sha256=<hex>"
+type HMAC struct {
+	Header string // Name of the signature header
+	Prefix string // Prefix of the signature, ex. "sha256="
+	Digest string // "sha256" (default), "sha384" or "sha512"
+	Base64 bool   // Signature is base64 encoded, instead of hex
+}
+
+// GitHub scheme of GitHub webhooks ("X-Hub-Signature-256: sha256=<hex>")
+var GitHub = &HMAC{Header: "X-Hub-Signature-256", Prefix: "sha256="}
+
+func (s *HMAC) Sign(header http.Header, secret []byte, body []byte, now time.Time) {
+	mac := computeHMAC(s.Digest, secret, body)
+	if s.Base64 {
+		header.Set(s.Header, s.Prefix+base64.StdEncoding.EncodeToString(mac))
+	} else {
+		header.Set(s.Header, s.Prefix+hex.EncodeToString(mac))
+	}
+}
+
+func (s *HMAC) Verify(header http.Header, secret []byte, body []byte, now time.Time) error {
+	value := header.Get(s.Header)
+	if value == "" {
+		return ErrMissingSignature
+	}
+	if !strings.HasPrefix(value, s.Prefix) {
+		return ErrInvalidSignature
+	}
+	value = value[len(s.Prefix):]
+
+	var signature []byte
+	var err error
+	if s.Base64 {
+		signature, err = base64.StdEncoding.DecodeString(value)
+	} else {
+		signature, err = hex.DecodeString(value)
+	}
+	if err != nil || !crypto.SecureBytesCompare(computeHMAC(s.Digest, secret, body), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Stripe scheme of Stripe webhooks ("Stripe-Signature: t=<timestamp>,v1=<hex>"), the signed payload is
+// "<timestamp>.<body>". Timestamps out of the Tolerance are rejected, preventing replay attacks.
+type Stripe struct {
+	Header    string        // Name of the signature header. Default "Stripe-Signature"
+	Tolerance time.Duration // Default DefaultTolerance
+}
+
+func (s *Stripe) Sign(header http.Header, secret []byte, body []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := computeHMAC("sha256", secret, signedPayload(timestamp, body))
+	header.Set(s.header(), "t="+timestamp+",v1="+hex.EncodeToString(mac))
+}
+
+func (s *Stripe) Verify(header http.Header, secret []byte, body []byte, now time.Time) error {
+	value := header.Get(s.header())
+	if value == "" {
+		return ErrMissingSignature
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(value, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = val
+		case "v1":
+			if signature, err := hex.DecodeString(val); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if err := checkTimestamp(timestamp, s.Tolerance, now); err != nil {
+		return err
+	}
+
+	expected := computeHMAC("sha256", secret, signedPayload(timestamp, body))
+	for _, signature := range signatures {
+		if crypto.SecureBytesCompare(expected, signature) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func (s *Stripe) header() string {
+	if s.Header == "" {
+		return "Stripe-Signature"
+	}
+	return s.Header
+}
+
+// Chain scheme used by default by the Dispatcher, signs "<timestamp>.<sha256(body)>" with the crypto.MessageVerifier,
+// the token is sent in the SignatureHeader. Timestamps out of the Tolerance are rejected.
+type Chain struct {
+	Tolerance time.Duration // Default DefaultTolerance
+	Digest    string        // Digest of the MessageVerifier. Default "sha256"
+}
+
+func (s *Chain) Sign(header http.Header, secret []byte, body []byte, now time.Time) {
+	verifier := &crypto.MessageVerifier{}
+	header.Set(SignatureHeader, verifier.Sign(secret, chainPayload(strconv.FormatInt(now.Unix(), 10), body), s.Digest))
+}
+
+func (s *Chain) Verify(header http.Header, secret []byte, body []byte, now time.Time) error {
+	value := header.Get(SignatureHeader)
+	if value == "" {
+		return ErrMissingSignature
+	}
+	if strings.Count(value, ".") != 2 {
+		return ErrInvalidSignature
+	}
+
+	verifier := &crypto.MessageVerifier{}
+	payload, err := verifier.Verify(secret, []byte(value))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	timestamp, _, found := bytes.Cut(payload, []byte{'.'})
+	if !found {
+		return ErrInvalidSignature
+	}
+	if err = checkTimestamp(string(timestamp), s.Tolerance, now); err != nil {
+		return err
+	}
+	if !crypto.SecureBytesCompare(chainPayload(string(timestamp), body), payload) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Verify reads the body of the request (restoring it, so it can be read again) and verifies its signature. Accepts
+// multiple secrets, allowing the rotation of secrets.
+//
+// ## Example
+//
+//	router.POST("/webhooks/github", func(ctx *chain.Context) {
+//		body, err := webhook.Verify(ctx.Request, webhook.GitHub, secret)
+//		if err != nil {
+//			ctx.Unauthorized()
+//			return
+//		}
+//		// ...
+//	})
+func Verify(req *http.Request, scheme Scheme, secrets ...[]byte) (body []byte, err error) {
+	if body, err = readBody(req, DefaultMaxBodySize); err != nil {
+		return
+	}
+	err = verifySecrets(scheme, req.Header, secrets, body)
+	return
+}
+
+// Verifier middleware that rejects requests without a valid signature with 401 (Unauthorized).
+//
+// ## Example
+//
+//	router.Use("/webhooks/stripe", &webhook.Verifier{
+//		Scheme:  &webhook.Stripe{},
+//		Secrets: [][]byte{[]byte(os.Getenv("STRIPE_WEBHOOK_SECRET"))},
+//	})
+type Verifier struct {
+	Scheme      Scheme   // Default &Chain{}
+	Secrets     [][]byte // Valid secrets, the first one matching validates the request
+	MaxBodySize int64    // Larger bodies are rejected with 413. Default DefaultMaxBodySize
+}
+
+func (v *Verifier) Init(method string, path string, router *chain.Router) {
+	if v.Scheme == nil {
+		v.Scheme = &Chain{}
+	}
+	if v.MaxBodySize <= 0 {
+		v.MaxBodySize = DefaultMaxBodySize
+	}
+	if len(v.Secrets) == 0 {
+		panic(fmt.Sprintf("[chain.webhook] Verifier requires at least one secret. Path: %s", path))
+	}
+}
+
+func (v *Verifier) Handle(ctx *chain.Context, next func() error) error {
+	body, err := readBody(ctx.Request, v.MaxBodySize)
+	if err == ErrBodyTooLarge {
+		ctx.WriteHeader(http.StatusRequestEntityTooLarge)
+		return nil
+	} else if err != nil {
+		ctx.BadRequest()
+		return nil
+	}
+	if err = verifySecrets(v.Scheme, ctx.Request.Header, v.Secrets, body); err != nil {
+		ctx.Unauthorized()
+		return nil
+	}
+	return next()
+}
+
+func verifySecrets(scheme Scheme, header http.Header, secrets [][]byte, body []byte) (err error) {
+	now := time.Now()
+	err = ErrInvalidSignature
+	for _, secret := range secrets {
+		if err = scheme.Verify(header, secret, body, now); err != ErrInvalidSignature {
+			return
+		}
+	}
+	return
+}
+
+func readBody(req *http.Request, max int64) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, max+1))
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > max {
+		return nil, ErrBodyTooLarge
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func checkTimestamp(timestamp string, tolerance time.Duration, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if diff := now.Sub(time.Unix(seconds, 0)); diff > tolerance || diff < -tolerance {
+		return ErrExpiredSignature
+	}
+	return nil
+}
+
+func signedPayload(timestamp string, body []byte) []byte {
+	payload := make([]byte, 0, len(timestamp)+1+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
+
+func chainPayload(timestamp string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return []byte(timestamp + "." + hex.EncodeToString(sum[:]))
+}
+
+func computeHMAC(digest string, secret []byte, content []byte) []byte {
+	var fn func() hash.Hash
+	switch digest {
+	case "sha512":
+		fn = sha512.New
+	case "sha384":
+		fn = sha512.New384
+	default:
+		fn = sha256.New
+	}
+	mac := hmac.New(fn, secret)
+	mac.Write(content)
+	return mac.Sum(nil)
+}
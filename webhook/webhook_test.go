@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nidorx/chain"
+	"github.com/nidorx/chain/pubsub"
+)
+
+func Test_Schemes(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+
+	for name, scheme := range map[string]Scheme{
+		"hmac":   &HMAC{Header: "X-Signature", Base64: true, Digest: "sha512"},
+		"github": GitHub,
+		"stripe": &Stripe{},
+		"chain":  &Chain{},
+	} {
+		header := http.Header{}
+		scheme.Sign(header, secret, body, now)
+
+		if err := scheme.Verify(header, secret, body, now); err != nil {
+			t.Errorf("%s Verify() failed: Invalid Error\n   actual: %v\n expected: nil", name, err)
+		}
+		if err := scheme.Verify(header, []byte("other"), body, now); err != ErrInvalidSignature {
+			t.Errorf("%s Verify() failed: Invalid Error\n   actual: %v\n expected: %v", name, err, ErrInvalidSignature)
+		}
+		if err := scheme.Verify(header, secret, []byte(`{"id":2}`), now); err != ErrInvalidSignature {
+			t.Errorf("%s Verify() failed: Invalid Error\n   actual: %v\n expected: %v", name, err, ErrInvalidSignature)
+		}
+		if err := scheme.Verify(http.Header{}, secret, body, now); err != ErrMissingSignature {
+			t.Errorf("%s Verify() failed: Invalid Error\n   actual: %v\n expected: %v", name, err, ErrMissingSignature)
+		}
+	}
+
+	// replay
+	for name, scheme := range map[string]Scheme{"stripe": &Stripe{}, "chain": &Chain{}} {
+		header := http.Header{}
+		scheme.Sign(header, secret, body, now.Add(-time.Hour))
+		if err := scheme.Verify(header, secret, body, now); err != ErrExpiredSignature {
+			t.Errorf("%s Verify() failed: Invalid Error\n   actual: %v\n expected: %v", name, err, ErrExpiredSignature)
+		}
+	}
+}
+
+func Test_GitHub_Signature(t *testing.T) {
+	// https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries#testing-the-webhook-payload-validation
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17")
+	if err := GitHub.Verify(header, []byte("It's a Secret to Everybody"), []byte("Hello, World!"), time.Now()); err != nil {
+		t.Errorf("Verify() failed: Invalid Error\n   actual: %v\n expected: nil", err)
+	}
+}
+
+func Test_Verifier(t *testing.T) {
+	router := chain.New()
+	router.Use("/hooks", &Verifier{Scheme: &Stripe{}, Secrets: [][]byte{[]byte("old"), []byte("new")}})
+	var received string
+	router.POST("/hooks", func(ctx *chain.Context) {
+		body, _ := ctx.BodyBytes()
+		received = string(body)
+	})
+
+	body := `{"id":1}`
+	req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(body))
+	(&Stripe{}).Sign(req.Header, []byte("new"), []byte(body), time.Now())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || received != body {
+		t.Errorf("Verifier() failed: Invalid response\n   actual: %d %s\n expected: %d %s", w.Code, received, http.StatusOK, body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(body))
+	(&Stripe{}).Sign(req.Header, []byte("invalid"), []byte(body), time.Now())
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Verifier() failed: Invalid status\n   actual: %d\n expected: %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func Test_Dispatcher_Retry(t *testing.T) {
+	var attempts atomic.Int32
+	secret := []byte("secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := Verify(r, &Chain{}, secret); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != "order.created" || r.Header.Get(DeliveryHeader) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	dispatcher := &Dispatcher{
+		Secret:  secret,
+		Backoff: func(attempt int) time.Duration { return time.Millisecond },
+		OnFailure: func(delivery *Delivery, err error) {
+			t.Errorf("Dispatcher() failed: unexpected failure: %v", err)
+		},
+	}
+	if err := dispatcher.Send(server.URL, "order.created", []byte(`{"id":1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := dispatcher.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if attempts.Load() != 3 {
+		t.Errorf("Dispatcher() failed: Invalid attempts\n   actual: %d\n expected: %d", attempts.Load(), 3)
+	}
+	if err := dispatcher.Send(server.URL, "order.created", nil); err != ErrDispatcherClosed {
+		t.Errorf("Send() failed: Invalid Error\n   actual: %v\n expected: %v", err, ErrDispatcherClosed)
+	}
+}
+
+func Test_Dispatcher_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	failed := make(chan *Delivery, 1)
+	dispatcher := &Dispatcher{
+		Backoff: func(attempt int) time.Duration { return time.Millisecond },
+		OnFailure: func(delivery *Delivery, err error) {
+			failed <- delivery
+		},
+	}
+	defer dispatcher.Shutdown(context.Background())
+
+	unsubscribe := dispatcher.Subscribe("webhook:test", server.URL, "test")
+	defer unsubscribe()
+	if err := pubsub.Broadcast("webhook:test", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case delivery := <-failed:
+		if delivery.Attempts != 1 || delivery.Event != "test" {
+			t.Errorf("Dispatcher() failed: permanent failures must not be retried\n   actual: %d", delivery.Attempts)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("Dispatcher() failed: message of the topic not delivered")
+	}
+}